@@ -0,0 +1,179 @@
+// Package app holds the dependencies shared by every handler: the database
+// pool, the repositories, and the mail, storage, scanning and geocoding
+// services. It also registers the background tasks the worker runs. Handlers
+// are methods on types embedding *App, so tests can build an App from fakes
+// instead of a live Postgres and third-party accounts.
+package app
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"autumnomous-jobs-employer-api/shared/config"
+	"autumnomous-jobs-employer-api/shared/repository/attachments"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/geocodes"
+	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+	"autumnomous-jobs-employer-api/shared/services/messaging/email"
+	"autumnomous-jobs-employer-api/shared/services/presign"
+	"autumnomous-jobs-employer-api/shared/services/scan"
+	"autumnomous-jobs-employer-api/shared/services/storage"
+	"autumnomous-jobs-employer-api/shared/services/webhook"
+	"autumnomous-jobs-employer-api/shared/services/zipcode"
+)
+
+// App is the application container
+type App struct {
+	Config *config.Config
+	DB     *sql.DB
+
+	Employers   accountmanagement.EmployerRepository
+	Companies   companies.CompanyRepository
+	Jobs        jobs.JobRepository
+	JobPackages jobpackages.JobPackageRepository
+	Queue       queue.QueueRepository
+	Webhooks    webhooks.WebhookRepository
+	Audit       audit.AuditRepository
+	Uploads     uploads.UploadRepository
+	Attachments attachments.AttachmentRepository
+	Geocodes    geocodes.GeocodeRepository
+
+	// UnitOfWork runs writes that must succeed or fail together
+	UnitOfWork transaction.UnitOfWork
+
+	Mailer   email.Mailer
+	Storage  storage.Storage
+	Scanner  scan.Scanner
+	Geocoder zipcode.Geocoder
+
+	// WebhookClient calls the endpoints companies subscribe to events
+	WebhookClient *http.Client
+}
+
+// New wires the Postgres repositories and the production services around db
+func New(cfg *config.Config, db *sql.DB) (*App, error) {
+
+	objects, err := newStorage(cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, err := newScanner(cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	geocodeCache := geocodes.NewGeocodeRepository(db)
+
+	geocoder, err := newGeocoder(cfg, geocodeCache)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &App{
+		Config: cfg,
+		DB:     db,
+
+		Employers:   accountmanagement.NewEmployerRepository(db),
+		Companies:   companies.NewCompanyRepository(db),
+		Jobs:        jobs.NewJobRepository(db),
+		JobPackages: jobpackages.NewJobPackageRepository(db),
+		Queue:       queue.NewQueueRepository(db),
+		Webhooks:    webhooks.NewWebhookRepository(db),
+		Audit:       audit.NewAuditRepository(db),
+		Uploads:     uploads.NewUploadRepository(db),
+		Attachments: attachments.NewAttachmentRepository(db),
+		Geocodes:    geocodeCache,
+
+		UnitOfWork: transaction.NewUnitOfWork(db),
+
+		Mailer:   email.NewMailgunMailer(cfg.Mailgun),
+		Storage:  objects,
+		Scanner:  scanner,
+		Geocoder: geocoder,
+
+		WebhookClient: webhook.NewClient(!cfg.IsProduction()),
+	}, nil
+}
+
+// newStorage returns the storage driver cfg selects
+func newStorage(cfg *config.Config) (storage.Storage, error) {
+
+	if cfg.Storage.Driver == config.StorageLocal {
+		return storage.NewLocalStorage(cfg.Storage.Directory, cfg.StorageURL(), []byte(cfg.SigningKey))
+	}
+
+	return storage.NewS3Storage(cfg.Spaces)
+}
+
+// newScanner returns the upload scanner cfg selects. Every driver but
+// config.ScanNone applies the upload policy first.
+func newScanner(cfg *config.Config) (scan.Scanner, error) {
+
+	policy := &scan.Policy{MaxSize: presign.MaxSize, ContentTypes: presign.ContentTypes(), Sniff: presign.Sniff}
+
+	switch cfg.Scan.Driver {
+	case config.ScanNone:
+		return scan.Noop{}, nil
+	case config.ScanClamAV:
+		clamav, err := scan.NewClamAV(cfg.Scan.ClamAV, cfg.Scan.Timeout)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return scan.Chain{policy, clamav}, nil
+	}
+
+	return policy, nil
+}
+
+// newGeocoder returns the remote geocoder, falling back to the offline
+// dataset when one is configured, behind a cache kept in repository. Without
+// an API key only the dataset answers.
+func newGeocoder(cfg *config.Config, repository geocodes.GeocodeRepository) (zipcode.Geocoder, error) {
+
+	var chain zipcode.Fallback
+
+	if cfg.ZipCodeServices.APIKey != "" || cfg.ZipCodeServices.Dataset == "" {
+		remote := zipcode.NewZipCodeGateway(cfg.ZipCodeServices.APIKey)
+		remote.Client.Timeout = cfg.ZipCodeServices.Timeout
+
+		chain = append(chain, remote)
+	}
+
+	if cfg.ZipCodeServices.Dataset != "" {
+		offline, err := zipcode.LoadOffline(cfg.ZipCodeServices.Dataset)
+
+		if err != nil {
+			return nil, err
+		}
+
+		log.Println("Loaded", offline.Len(), "zip codes from", cfg.ZipCodeServices.Dataset)
+
+		chain = append(chain, offline)
+	}
+
+	return zipcode.NewCache(chain, repository, cfg.ZipCodeServices.CacheSize, cfg.ZipCodeServices.CacheTTL), nil
+}
+
+// Close releases the database pool. It is safe to call when DB is nil.
+func (application *App) Close() error {
+
+	if application.DB == nil {
+		return nil
+	}
+
+	return application.DB.Close()
+}
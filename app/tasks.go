@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/services/messaging/email"
+	"autumnomous-jobs-employer-api/shared/services/webhook"
+	"autumnomous-jobs-employer-api/shared/services/worker"
+)
+
+// Task kinds run on a schedule
+const (
+	GoLiveTask     = "jobs.golive"
+	ExpireJobsTask = "jobs.expire"
+	PurgeJobsTask  = "jobs.purge"
+	PurgeTasksTask = "tasks.purge"
+
+	PurgeUploadsTask  = "uploads.purge"
+	PurgeGeocodesTask = "geocodes.purge"
+)
+
+// finishedTaskRetention is how long completed tasks stay visible to admins
+const finishedTaskRetention = 7 * 24 * time.Hour
+
+// purgeUploadsBatch is how many orphaned uploads one run removes
+const purgeUploadsBatch = 500
+
+// Worker returns a worker with every task the application enqueues registered
+func (application *App) Worker() *worker.Worker {
+
+	tasks := worker.New(application.Queue, application.Config.Worker.Concurrency)
+
+	tasks.Register(worker.Handler{
+		Kind:    email.Kind,
+		Perform: email.Deliver(application.Mailer),
+	})
+
+	tasks.Register(worker.Handler{
+		Kind:        webhook.DeliverKind,
+		Perform:     webhook.NewDeliverer(application.Webhooks, application.WebhookClient).Perform,
+		MaxAttempts: webhook.MaxAttempts,
+	})
+
+	tasks.Register(worker.Handler{
+		Kind:    webhook.PublishKind,
+		Perform: webhook.PublishHandler(application.UnitOfWork),
+	})
+
+	tasks.Register(worker.Handler{
+		Kind:        GoLiveTask,
+		Perform:     application.goLive,
+		MaxAttempts: 3,
+	})
+	tasks.Every(GoLiveTask, time.Minute)
+
+	tasks.Register(worker.Handler{
+		Kind:        ExpireJobsTask,
+		Perform:     application.expireJobs,
+		MaxAttempts: 3,
+	})
+	tasks.Every(ExpireJobsTask, time.Hour)
+
+	tasks.Register(worker.Handler{
+		Kind:        PurgeJobsTask,
+		Perform:     application.purgeJobs,
+		MaxAttempts: 3,
+	})
+	tasks.Every(PurgeJobsTask, time.Hour)
+
+	tasks.Register(worker.Handler{
+		Kind:        PurgeTasksTask,
+		Perform:     application.purgeTasks,
+		MaxAttempts: 3,
+	})
+	tasks.Every(PurgeTasksTask, 24*time.Hour)
+
+	tasks.Register(worker.Handler{
+		Kind:        PurgeUploadsTask,
+		Perform:     application.purgeUploads,
+		MaxAttempts: 3,
+	})
+	tasks.Every(PurgeUploadsTask, time.Hour)
+
+	tasks.Register(worker.Handler{
+		Kind:        PurgeGeocodesTask,
+		Perform:     application.purgeGeocodes,
+		MaxAttempts: 3,
+	})
+	tasks.Every(PurgeGeocodesTask, 24*time.Hour)
+
+	return tasks
+}
+
+// goLive publishes job.live for the jobs whose visible date has arrived
+func (application *App) goLive(ctx context.Context, payload json.RawMessage) error {
+
+	return application.UnitOfWork.Do(ctx, func(tx *transaction.Repositories) error {
+
+		live, err := tx.Jobs.GoLive(time.Now())
+
+		if err != nil {
+			return err
+		}
+
+		return publishJobs(tx, webhook.JobLive, live)
+	})
+}
+
+func (application *App) expireJobs(ctx context.Context, payload json.RawMessage) error {
+
+	return application.UnitOfWork.Do(ctx, func(tx *transaction.Repositories) error {
+
+		expired, err := tx.Jobs.ExpireJobs(time.Now())
+
+		if err != nil {
+			return err
+		}
+
+		if len(expired) > 0 {
+			log.Println("Expired", len(expired), "jobs")
+		}
+
+		return publishJobs(tx, webhook.JobExpired, expired)
+	})
+}
+
+func publishJobs(tx *transaction.Repositories, event string, changed []*jobs.Job) error {
+
+	for _, job := range changed {
+		if err := webhook.Publish(tx.Webhooks, tx.Queue, job.EmployerPublicID, event, job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// purgeJobs permanently removes jobs deleted longer ago than they can be
+// restored, with their attachments, whose documents then expire unless
+// something else uses them
+func (application *App) purgeJobs(ctx context.Context, payload json.RawMessage) error {
+
+	deletedBefore := time.Now().Add(-jobs.DeleteRetention)
+
+	var purged int64
+
+	err := application.UnitOfWork.Do(ctx, func(tx *transaction.Repositories) error {
+
+		purgeable, err := tx.Attachments.GetPurgeableAttachments(deletedBefore)
+
+		if err != nil {
+			return err
+		}
+
+		// each attachment is one use, even of a document attached to several jobs
+		for _, attachment := range purgeable {
+			if err := tx.Uploads.Release([]string{attachment.URL}, time.Now().Add(uploads.OrphanRetention)); err != nil {
+				return err
+			}
+		}
+
+		purged, err = tx.Jobs.PurgeDeletedJobs(deletedBefore)
+
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		log.Println("Purged", purged, "deleted jobs")
+	}
+
+	return nil
+}
+
+// purgeUploads sweeps the uploads that nothing has used since they expired,
+// whether never completed, never used once complete or no longer used, and
+// their objects
+func (application *App) purgeUploads(ctx context.Context, payload json.RawMessage) error {
+
+	now := time.Now()
+	orphaned, err := application.Uploads.GetOrphanedUploads(now, purgeUploadsBatch)
+
+	if err != nil {
+		return err
+	}
+
+	swept := 0
+
+	for _, upload := range orphaned {
+
+		// the row goes only with its object, and stays when it was used again
+		// since it was read
+		err := application.UnitOfWork.Do(ctx, func(tx *transaction.Repositories) error {
+
+			if err := tx.Uploads.DeleteOrphanedUpload(upload.PublicID, now); err != nil {
+				return err
+			}
+
+			return application.Storage.Delete(upload.Key)
+		})
+
+		if err == uploads.ErrNotFound {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		swept++
+	}
+
+	if swept > 0 {
+		log.Println("Swept", swept, "orphaned uploads")
+	}
+
+	return nil
+}
+
+func (application *App) purgeTasks(ctx context.Context, payload json.RawMessage) error {
+
+	_, err := application.Queue.DeleteFinished(time.Now().Add(-finishedTaskRetention))
+
+	return err
+}
+
+// purgeGeocodes removes the cached geocoder answers that have expired
+func (application *App) purgeGeocodes(ctx context.Context, payload json.RawMessage) error {
+
+	purged, err := application.Geocodes.PurgeExpiredGeocodes(time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		log.Println("Purged", purged, "expired geocodes")
+	}
+
+	return nil
+}
@@ -0,0 +1,348 @@
+// Package client is a typed Go SDK for the employer API. The methods calling
+// each operation are generated into operations.go from the operations the
+// route package documents, using the request and response types of the
+// handlers; this file holds the transport they share.
+//
+// A Client authenticates with the API key for sign up, log in and uploads,
+// and with the token Authenticate stores for everything else. Requests are
+// retried when the server is overloaded, and failures are returned as *Error.
+package client
+
+//go:generate go run ./internal/generate
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"autumnomous-jobs-employer-api/controller/v1/employers"
+)
+
+// Client calls the employer API at one base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	adminKey   string
+	retries    int
+	backoff    time.Duration
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends requests with httpClient instead of http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates sign up, log in and uploads with key
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithAdminKey authenticates the /admin operations with key
+func WithAdminKey(key string) Option {
+	return func(c *Client) {
+		c.adminKey = key
+	}
+}
+
+// WithToken authenticates as an employer with a token from an earlier log in
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRetries retries a request up to retries times, waiting backoff before
+// the first retry and doubling it before each next one
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New returns a Client for the API at baseURL, such as https://api.example.com
+func New(baseURL string, options ...Option) *Client {
+
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retries:    2,
+		backoff:    200 * time.Millisecond,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// Token returns the token the client authenticates with, empty before
+// Authenticate
+func (c *Client) Token() string {
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.token
+}
+
+// SetToken authenticates the client's later requests with token
+func (c *Client) SetToken(token string) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = token
+}
+
+// Authenticate logs in and authenticates the client's later requests with
+// the token it returns
+func (c *Client) Authenticate(ctx context.Context, email, password string) (*employers.LoginResponse, error) {
+
+	login, err := c.Login(ctx, employers.LoginCredentials{Email: email, Password: password})
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetToken(login.Token)
+
+	return login, nil
+}
+
+// request is one call of an operation
+type request struct {
+	method   string
+	path     string
+	query    url.Values
+	security string // the OpenAPI security scheme of the operation
+
+	body      interface{} // sent as JSON, or as is when a []byte
+	mediaType string
+
+	form map[string]*formFile
+}
+
+type formFile struct {
+	name string
+	body io.Reader
+}
+
+// do sends req, retrying it when that is safe, and decodes a successful
+// response into out unless it is nil
+func (c *Client) do(ctx context.Context, req *request, out interface{}) error {
+
+	body, contentType, err := encode(req)
+
+	if err != nil {
+		return err
+	}
+
+	target := c.baseURL + req.path
+
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	backoff := c.backoff
+
+	for attempt := 0; ; attempt++ {
+
+		var reader io.Reader
+
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+
+		httpRequest, err := http.NewRequestWithContext(ctx, req.method, target, reader)
+
+		if err != nil {
+			return err
+		}
+
+		if contentType != "" {
+			httpRequest.Header.Set("Content-Type", contentType)
+		}
+
+		httpRequest.Header.Set("Accept", "application/json")
+
+		if authorization := c.authorization(req.security); authorization != "" {
+			httpRequest.Header.Set("Authorization", authorization)
+		}
+
+		response, err := c.httpClient.Do(httpRequest)
+
+		if err == nil && response.StatusCode < 300 {
+			return decode(response, out)
+		}
+
+		var wait time.Duration
+
+		if err == nil {
+			wait = retryAfter(response, backoff)
+			err = readError(response)
+		}
+
+		if attempt >= c.retries || !retryable(ctx, req.method, response) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+	}
+}
+
+func (c *Client) authorization(security string) string {
+
+	switch security {
+	case "apiKey":
+		return "Bearer " + base64.StdEncoding.EncodeToString([]byte(c.apiKey))
+	case "adminKey":
+		return "Bearer " + base64.StdEncoding.EncodeToString([]byte(c.adminKey))
+	case "token":
+		if token := c.Token(); token != "" {
+			return "Bearer " + token
+		}
+	}
+
+	return ""
+}
+
+func encode(req *request) ([]byte, string, error) {
+
+	if req.form != nil {
+
+		var buffer bytes.Buffer
+		writer := multipart.NewWriter(&buffer)
+
+		for field, file := range req.form {
+
+			content, err := ioutil.ReadAll(file.body)
+
+			if err != nil {
+				return nil, "", err
+			}
+
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", `form-data; name="`+field+`"; filename="`+strings.ReplaceAll(file.name, `"`, "")+`"`)
+			header.Set("Content-Type", http.DetectContentType(content))
+
+			part, err := writer.CreatePart(header)
+
+			if err != nil {
+				return nil, "", err
+			}
+
+			part.Write(content)
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+
+		return buffer.Bytes(), writer.FormDataContentType(), nil
+	}
+
+	if req.body == nil {
+		return nil, "", nil
+	}
+
+	mediaType := req.mediaType
+
+	if mediaType == "" {
+		mediaType = "application/json"
+	}
+
+	if raw, ok := req.body.([]byte); ok {
+		return raw, mediaType, nil
+	}
+
+	body, err := json.Marshal(req.body)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return body, mediaType, nil
+}
+
+func decode(response *http.Response, out interface{}) error {
+
+	defer response.Body.Close()
+
+	if out == nil || response.StatusCode == http.StatusNoContent {
+		io.Copy(ioutil.Discard, response.Body)
+		return nil
+	}
+
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// retryable reports whether a request that failed with response, or without
+// one, may be sent again. A 429 was not processed, so any request may; the
+// other failures might have been, so only idempotent requests may.
+func retryable(ctx context.Context, method string, response *http.Response) bool {
+
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if response != nil && response.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+
+	if response == nil {
+		return true
+	}
+
+	switch response.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// retryAfter returns how long response asks to wait, or backoff
+func retryAfter(response *http.Response, backoff time.Duration) time.Duration {
+
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return backoff
+}
@@ -0,0 +1,473 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/client"
+	"autumnomous-jobs-employer-api/controller/v1/employers"
+	"autumnomous-jobs-employer-api/route"
+	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/imaging"
+	"autumnomous-jobs-employer-api/shared/services/presign"
+	"autumnomous-jobs-employer-api/shared/services/security/encryption"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/storage"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newServer returns a client of an API keeping uploads in a temporary
+// directory, and the URL it serves them from
+func newServer(t *testing.T) (*client.Client, string) {
+
+	jwt.SetSigningKey("client-test")
+
+	application, store := testhelper.NewMemoryApp(&testhelper.Mailer{})
+	application.Config.APIKey = "client-key"
+
+	hashed, err := encryption.HashPassword([]byte("password"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	employer, err := store.EmployerRepository().CreateEmployer("First", "Last", "employer@example.com", string(hashed))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	company, err := store.CompanyRepository().GetOrCreateCompany("example.com", "Example", "", "", "", "", "", "", "", "", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.EmployerRepository().SetEmployerCompany(employer.PublicID, company.PublicID); err != nil {
+		t.Fatal(err)
+	}
+
+	store.AddJobPackage(&jobpackages.JobPackage{TypeID: "pack", IsActive: true, Title: "Pack", NumberOfJobs: 2, Price: 10})
+
+	server := httptest.NewServer(route.LoadRoutes(application))
+	t.Cleanup(server.Close)
+
+	uploads, err := storage.NewLocalStorage(t.TempDir(), server.URL+storage.LocalPath, []byte("client-test"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	application.Storage = uploads
+
+	return client.New(server.URL, client.WithAPIKey("client-key")), server.URL + storage.LocalPath
+}
+
+func Test_Client_Jobs(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	api, _ := newServer(t)
+
+	_, err := api.ListJobs(ctx, nil)
+	assert.Equal(http.StatusUnauthorized, client.StatusCode(err))
+
+	login, err := api.Authenticate(ctx, "employer@example.com", "password")
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal(login.Token, api.Token())
+
+	created, err := api.CreateJob(ctx, jobs.RevisionContent{Title: "Engineer", JobType: "full-time"})
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	job, err := api.GetJob(ctx, created.PublicID)
+	assert.NoError(err)
+	assert.Equal("Engineer", job.Title)
+
+	job, err = api.PatchJob(ctx, created.PublicID, map[string]interface{}{"title": "Senior Engineer"})
+	assert.NoError(err)
+	assert.Equal("Senior Engineer", job.Title)
+
+	revisions, err := api.GetJobRevisions(ctx, created.PublicID)
+	assert.NoError(err)
+	assert.Len(revisions.Revisions, 2)
+
+	list, err := api.ListJobs(ctx, nil)
+	assert.NoError(err)
+	assert.Len(list, 1)
+
+	_, err = api.DeleteJob(ctx, created.PublicID)
+	assert.NoError(err)
+
+	_, err = api.GetJob(ctx, created.PublicID)
+	assert.Equal(http.StatusNotFound, client.StatusCode(err))
+	assert.Equal(jobs.ErrNotFound.Code, client.Code(err))
+
+	list, err = api.ListJobs(ctx, nil)
+	assert.NoError(err)
+	assert.Len(list, 0)
+
+	list, err = api.ListJobs(ctx, &client.ListJobsQuery{Deleted: true})
+
+	if assert.NoError(err) && assert.Len(list, 1) {
+		assert.NotEqual("", list[0].DeletedAt)
+	}
+
+	_, err = api.RestoreJob(ctx, created.PublicID)
+	assert.NoError(err)
+
+	_, err = api.CreateJob(ctx, jobs.RevisionContent{})
+	var apiError *client.Error
+	if assert.ErrorAs(err, &apiError) {
+		assert.Equal(response.CodeValidation, apiError.Problem.Code)
+		assert.Equal("title", apiError.Problem.Errors[0].Field)
+	}
+}
+
+func Test_Client_AccountAndPackages(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	api, uploads := newServer(t)
+
+	_, err := api.Authenticate(ctx, "employer@example.com", "wrong")
+	assert.Equal(response.CodeInvalidCredentials, client.Code(err))
+	assert.Empty(api.Token())
+
+	if _, err := api.Authenticate(ctx, "employer@example.com", "password"); !assert.NoError(err) {
+		return
+	}
+
+	employer, err := api.PatchAccount(ctx, map[string]interface{}{"firstname": "Changed"})
+	assert.NoError(err)
+	assert.Equal("Changed", employer.FirstName)
+
+	employer, err = api.GetAccount(ctx)
+	assert.NoError(err)
+	assert.Equal("Changed", employer.FirstName)
+
+	_, err = api.UpdatePassword(ctx, employers.UpdatePasswordCredentials{Password: "password", NewPassword: "new-password"})
+	assert.NoError(err)
+
+	packages, err := api.GetActiveJobPackages(ctx)
+	assert.NoError(err)
+
+	if assert.Len(packages, 1) {
+		_, err = api.PurchaseJobPackage(ctx, employers.PurchaseJobPackageDetails{JobPackage: packages[0].TypeID})
+		assert.NoError(err)
+	}
+
+	list, err := api.ListJobs(ctx, nil)
+	assert.NoError(err)
+	assert.Len(list, 2)
+
+	var logo bytes.Buffer
+	assert.NoError(png.Encode(&logo, image.NewGray(image.Rect(0, 0, 1024, 512))))
+
+	upload, err := api.UploadImage(ctx, "logo.png", bytes.NewReader(logo.Bytes()))
+	assert.NoError(err)
+
+	assert.True(strings.HasPrefix(upload.URL, uploads+"/images/"))
+	assert.Equal(upload.URL, upload.Variants["logo"])
+	assert.NotEqual(upload.URL, upload.Variants["thumbnail"])
+
+	// the local storage driver serves what was stored
+	served, err := http.Get(upload.Variants["thumbnail"])
+
+	if assert.NoError(err) {
+		defer served.Body.Close()
+		thumbnail, err := png.DecodeConfig(served.Body)
+		assert.NoError(err)
+		assert.Equal("image/png", served.Header.Get("Content-Type"))
+		assert.Equal(128, thumbnail.Width)
+	}
+
+	_, err = api.UploadImage(ctx, "logo.png", strings.NewReader("<svg/>"))
+	assert.Equal(imaging.ErrUnsupported.Code, client.Code(err))
+
+	company, err := api.UploadCompanyLogo(ctx, "logo.png", bytes.NewReader(logo.Bytes()))
+
+	if assert.NoError(err) {
+		assert.Equal(upload.URL, company.Logo)
+		assert.Equal(upload.Variants, company.LogoVariants)
+	}
+
+	company, err = api.PatchCompany(ctx, map[string]interface{}{"logo": "https://example.com/logo.png"})
+
+	if assert.NoError(err) {
+		assert.Empty(company.LogoVariants)
+	}
+}
+
+func Test_Client_PresignedUpload(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	api, _ := newServer(t)
+
+	if _, err := api.Authenticate(ctx, "employer@example.com", "password"); !assert.NoError(err) {
+		return
+	}
+
+	var logo bytes.Buffer
+	assert.NoError(png.Encode(&logo, image.NewGray(image.Rect(0, 0, 600, 300))))
+
+	presigned, err := api.PresignUpload(ctx, presign.Request{FileName: "logo.png", ContentType: imaging.PNG, Size: int64(logo.Len())})
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	// a pending upload cannot be used
+	_, err = api.SetCompanyLogo(ctx, presign.UploadReference{Upload: presigned.Upload.PublicID})
+	assert.Equal(presign.ErrNotComplete.Code, client.Code(err))
+
+	request, _ := http.NewRequest(presigned.Method, presigned.URL, bytes.NewReader(logo.Bytes()))
+
+	for name, value := range presigned.Headers {
+		request.Header.Set(name, value)
+	}
+
+	uploaded, err := http.DefaultClient.Do(request)
+
+	if assert.NoError(err) {
+		uploaded.Body.Close()
+		assert.Equal(http.StatusOK, uploaded.StatusCode)
+	}
+
+	upload, err := api.CompleteUpload(ctx, presign.UploadReference{Upload: presigned.Upload.PublicID})
+
+	if assert.NoError(err) {
+		assert.Equal(uploads.Complete, upload.Status)
+	}
+
+	company, err := api.SetCompanyLogo(ctx, presign.UploadReference{Upload: presigned.Upload.PublicID})
+
+	if !assert.NoError(err) || !assert.Len(company.LogoVariants, 2) {
+		return
+	}
+
+	assert.Equal(company.LogoVariants["logo"], company.Logo)
+
+	// the original and the variants stored from it, which the logo uses
+	list, err := api.ListUploads(ctx)
+
+	if !assert.NoError(err) || !assert.Len(list, 3) {
+		return
+	}
+
+	used := map[string]*uploads.Upload{}
+
+	for _, upload := range list {
+		if upload.URL != "" {
+			used[upload.URL] = upload
+		}
+	}
+
+	thumbnail := used[company.LogoVariants["thumbnail"]]
+
+	if assert.NotNil(thumbnail) {
+		assert.Equal(1, thumbnail.RefCount)
+
+		_, err = api.DeleteUpload(ctx, thumbnail.PublicID)
+		assert.Equal(uploads.ErrInUse.Code, client.Code(err))
+	}
+
+	deleted, err := api.DeleteUpload(ctx, presigned.Upload.PublicID)
+
+	if assert.NoError(err) {
+		assert.Equal(presigned.Upload.Key, deleted.Key)
+	}
+
+	_, err = api.DeleteUpload(ctx, presigned.Upload.PublicID)
+	assert.Equal(uploads.ErrNotFound.Code, client.Code(err))
+
+	// replacing the logo leaves its images unused, so they can be deleted
+	_, err = api.PatchCompany(ctx, map[string]interface{}{"logo": "https://example.com/logo.png"})
+	assert.NoError(err)
+
+	if thumbnail != nil {
+		_, err = api.DeleteUpload(ctx, thumbnail.PublicID)
+		assert.NoError(err)
+
+		served, err := http.Get(thumbnail.URL)
+
+		if assert.NoError(err) {
+			served.Body.Close()
+			assert.Equal(http.StatusNotFound, served.StatusCode)
+		}
+	}
+}
+
+func Test_Client_Retries(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	var calls int32
+	status := int32(http.StatusServiceUnavailable)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			response.SendProblem(w, int(atomic.LoadInt32(&status)), response.CodeInternal, "")
+			return
+		}
+		if r.Method == http.MethodGet {
+			response.SendJSON(w, []*jobs.Job{})
+			return
+		}
+		response.SendJSON(w, jobs.Job{})
+	}))
+	defer server.Close()
+
+	api := client.New(server.URL, client.WithToken("token"), client.WithRetries(2, time.Millisecond))
+
+	_, err := api.ListJobs(ctx, nil)
+	assert.NoError(err)
+	assert.Equal(int32(3), atomic.LoadInt32(&calls))
+
+	// a POST might have been processed, so it is not sent again
+	atomic.StoreInt32(&calls, 0)
+	_, err = api.CreateJob(ctx, jobs.RevisionContent{Title: "Engineer"})
+	assert.Equal(http.StatusServiceUnavailable, client.StatusCode(err))
+	assert.Equal(int32(1), atomic.LoadInt32(&calls))
+
+	// unless the server turned it away
+	atomic.StoreInt32(&calls, 0)
+	atomic.StoreInt32(&status, http.StatusTooManyRequests)
+	_, err = api.CreateJob(ctx, jobs.RevisionContent{Title: "Engineer"})
+	assert.NoError(err)
+	assert.Equal(int32(3), atomic.LoadInt32(&calls))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = api.ListJobs(cancelled, nil)
+	assert.ErrorIs(err, context.Canceled)
+}
+
+func Test_Client_JobAttachments(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	api, _ := newServer(t)
+
+	if _, err := api.Authenticate(ctx, "employer@example.com", "password"); !assert.NoError(err) {
+		return
+	}
+
+	document := []byte("%PDF-1.7\n1 0 obj << /Type /Catalog >> endobj\n%%EOF\n")
+
+	presigned, err := api.PresignUpload(ctx, presign.Request{FileName: "benefits.pdf", ContentType: presign.PDF, Size: int64(len(document))})
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	request, _ := http.NewRequest(presigned.Method, presigned.URL, bytes.NewReader(document))
+
+	for name, value := range presigned.Headers {
+		request.Header.Set(name, value)
+	}
+
+	uploaded, err := http.DefaultClient.Do(request)
+
+	if assert.NoError(err) {
+		uploaded.Body.Close()
+	}
+
+	created, err := api.CreateJob(ctx, jobs.RevisionContent{Title: "Engineer", JobType: "full-time"})
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	reference := presign.UploadReference{Upload: presigned.Upload.PublicID}
+
+	_, err = api.AttachJobFile(ctx, created.PublicID, reference)
+	assert.Equal(presign.ErrNotComplete.Code, client.Code(err))
+
+	_, err = api.CompleteUpload(ctx, reference)
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	attachment, err := api.AttachJobFile(ctx, created.PublicID, reference)
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal("benefits.pdf", attachment.FileName)
+	assert.Equal(presign.PDF, attachment.ContentType)
+	assert.Equal(int64(len(document)), attachment.Size)
+
+	served, err := http.Get(attachment.URL)
+
+	if assert.NoError(err) {
+		served.Body.Close()
+		assert.Equal(http.StatusOK, served.StatusCode)
+	}
+
+	_, err = api.AttachJobFile(ctx, created.PublicID, reference)
+	assert.Equal(http.StatusConflict, client.StatusCode(err))
+
+	job, err := api.GetJob(ctx, created.PublicID)
+
+	if assert.NoError(err) && assert.Len(job.Attachments, 1) {
+		assert.Equal(attachment.PublicID, job.Attachments[0].PublicID)
+	}
+
+	// the published document is used by the attachment
+	list, err := api.ListUploads(ctx)
+	assert.NoError(err)
+
+	for _, upload := range list {
+		if upload.URL == attachment.URL {
+			assert.Equal(1, upload.RefCount)
+		}
+	}
+
+	detached, err := api.DetachJobFile(ctx, created.PublicID, attachment.PublicID)
+
+	if assert.NoError(err) {
+		assert.Equal(attachment.URL, detached.URL)
+	}
+
+	_, err = api.DetachJobFile(ctx, created.PublicID, attachment.PublicID)
+	assert.Equal(http.StatusNotFound, client.StatusCode(err))
+
+	job, err = api.GetJob(ctx, created.PublicID)
+
+	if assert.NoError(err) {
+		assert.Empty(job.Attachments)
+	}
+
+	list, err = api.ListUploads(ctx)
+	assert.NoError(err)
+
+	for _, upload := range list {
+		if upload.URL == attachment.URL {
+			assert.Equal(0, upload.RefCount)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"autumnomous-jobs-employer-api/shared/response"
+)
+
+// Error is a response the API sent with an error status. Its Problem is the
+// RFC 7807 problem detail the API returned, or one made up from the status
+// when the body was not a problem.
+type Error struct {
+	StatusCode int
+	Problem    response.Problem
+}
+
+func (e *Error) Error() string {
+
+	message := e.Problem.Title
+
+	if e.Problem.Detail != "" {
+		message += ": " + e.Problem.Detail
+	}
+
+	if e.Problem.Code != "" {
+		message += " (" + e.Problem.Code + ")"
+	}
+
+	return "client: " + message
+}
+
+// Code returns the stable problem code of err, such as job_not_found, or
+// empty when err is not an *Error
+func Code(err error) string {
+
+	var apiError *Error
+
+	if errors.As(err, &apiError) {
+		return apiError.Problem.Code
+	}
+
+	return ""
+}
+
+// StatusCode returns the HTTP status of err, or 0 when err is not an *Error
+func StatusCode(err error) int {
+
+	var apiError *Error
+
+	if errors.As(err, &apiError) {
+		return apiError.StatusCode
+	}
+
+	return 0
+}
+
+func readError(r *http.Response) error {
+
+	defer r.Body.Close()
+
+	body, _ := ioutil.ReadAll(r.Body)
+
+	apiError := &Error{StatusCode: r.StatusCode}
+
+	if err := json.Unmarshal(body, &apiError.Problem); err != nil || apiError.Problem.Status == 0 {
+		apiError.Problem = response.Problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(r.StatusCode),
+			Status: r.StatusCode,
+			Detail: strings.TrimSpace(string(body)),
+		}
+	}
+
+	return apiError
+}
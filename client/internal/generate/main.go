@@ -0,0 +1,269 @@
+// Command generate writes client/operations.go, a method of the client for
+// each operation the route package documents with an ID. Run it with go
+// generate in the client package after changing route/openapi.go.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"autumnomous-jobs-employer-api/route"
+	"autumnomous-jobs-employer-api/shared/services/mergepatch"
+	"autumnomous-jobs-employer-api/shared/services/openapi"
+)
+
+func main() {
+
+	source, err := Generate(route.Operations())
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile("operations.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Generate returns the formatted source of the client methods of operations
+func Generate(operations []openapi.Operation) ([]byte, error) {
+
+	g := &generator{imports: map[string]string{}, names: map[string]string{}}
+
+	for _, operation := range operations {
+		if operation.ID != "" {
+			g.operation(operation)
+		}
+	}
+
+	var source bytes.Buffer
+
+	source.WriteString("// Code generated by go generate from route.Operations; DO NOT EDIT.\n\n")
+	source.WriteString("package client\n\nimport (\n")
+
+	standard := []string{"context", "net/url"}
+
+	if strings.Contains(g.body.String(), "io.Reader") {
+		standard = append(standard, "io")
+	}
+
+	if strings.Contains(g.body.String(), "strconv.") {
+		standard = append(standard, "strconv")
+	}
+
+	sort.Strings(standard)
+
+	for _, importPath := range standard {
+		fmt.Fprintf(&source, "\t%q\n", importPath)
+	}
+
+	var paths []string
+
+	for importPath := range g.imports {
+		paths = append(paths, importPath)
+	}
+
+	sort.Strings(paths)
+	source.WriteString("\n")
+
+	for _, importPath := range paths {
+		if name := g.imports[importPath]; name != path.Base(importPath) {
+			fmt.Fprintf(&source, "\t%s %q\n", name, importPath)
+		} else {
+			fmt.Fprintf(&source, "\t%q\n", importPath)
+		}
+	}
+
+	source.WriteString(")\n")
+	source.Write(g.body.Bytes())
+
+	return format.Source(source.Bytes())
+}
+
+type generator struct {
+	body    bytes.Buffer
+	imports map[string]string // package names by import path
+	names   map[string]string // import paths by package name
+}
+
+func (g *generator) operation(operation openapi.Operation) {
+
+	name := exported(operation.ID)
+	w := &g.body
+
+	params := []string{"ctx context.Context"}
+
+	// the path, with its parameters as arguments
+	pathExpression := `"`
+
+	for i, segment := range strings.Split(operation.Path, "/") {
+
+		if i > 0 {
+			pathExpression += "/"
+		}
+
+		if strings.HasPrefix(segment, ":") {
+			params = append(params, segment[1:]+" string")
+			pathExpression += `" + url.PathEscape(` + segment[1:] + `) + "`
+			continue
+		}
+
+		pathExpression += segment
+	}
+
+	pathExpression = strings.TrimSuffix(pathExpression+`"`, ` + ""`)
+
+	if len(operation.Query) > 0 {
+		params = append(params, "query *"+name+"Query")
+		g.query(name, operation.Query)
+	}
+
+	request := fmt.Sprintf("method: %q, path: %s, security: %q", operation.Method, pathExpression, operation.Security)
+
+	if len(operation.Query) > 0 {
+		request += ", query: query.values()"
+	}
+
+	switch body := operation.Request.(type) {
+	case nil:
+	case openapi.Form:
+		var files []string
+
+		for _, field := range body {
+			params = append(params, field+"Name string", field+" io.Reader")
+			files = append(files, fmt.Sprintf("%q: {name: %sName, body: %s}", field, field, field))
+		}
+
+		request += ", form: map[string]*formFile{" + strings.Join(files, ", ") + "}"
+	default:
+		if operation.MediaType == mergepatch.ContentType {
+			// any value marshalling to a merge patch, such as a map
+			params = append(params, "patch interface{}")
+			request += fmt.Sprintf(", body: patch, mediaType: %q", operation.MediaType)
+		} else {
+			params = append(params, "body "+g.typeName(reflect.TypeOf(body)))
+			request += ", body: body"
+		}
+	}
+
+	openapiPath, _ := openapi.Path(operation.Path)
+	fmt.Fprintf(w, "\n// %s calls %s %s to %s\n", name, operation.Method, openapiPath, strings.ToLower(operation.Summary[:1])+operation.Summary[1:])
+
+	if operation.Response == nil {
+		fmt.Fprintf(w, "func (c *Client) %s(%s) error {\n", name, strings.Join(params, ", "))
+		fmt.Fprintf(w, "\treturn c.do(ctx, &request{%s}, nil)\n}\n", request)
+		return
+	}
+
+	result := reflect.TypeOf(operation.Response)
+	returned := g.typeName(result)
+
+	if result.Kind() == reflect.Struct {
+		returned = "*" + returned
+	}
+
+	fmt.Fprintf(w, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(params, ", "), returned)
+
+	if result.Kind() == reflect.Struct {
+		fmt.Fprintf(w, "\tresult := &%s{}\n\n", g.typeName(result))
+		fmt.Fprintf(w, "\tif err := c.do(ctx, &request{%s}, result); err != nil {\n\t\treturn nil, err\n\t}\n\n\treturn result, nil\n}\n", request)
+		return
+	}
+
+	fmt.Fprintf(w, "\tvar result %s\n\n", returned)
+	fmt.Fprintf(w, "\terr := c.do(ctx, &request{%s}, &result)\n\n\treturn result, err\n}\n", request)
+}
+
+// query writes the type of the query parameters of an operation
+func (g *generator) query(name string, parameters []openapi.Parameter) {
+
+	w := &g.body
+
+	fmt.Fprintf(w, "\n// %sQuery holds the query parameters of %s. Zero values are left out.\n", name, name)
+	fmt.Fprintf(w, "type %sQuery struct {\n", name)
+
+	for _, parameter := range parameters {
+
+		kind := "string"
+
+		switch parameter.Type {
+		case "integer":
+			kind = "int"
+		case "boolean":
+			kind = "bool"
+		}
+
+		if parameter.Description != "" {
+			fmt.Fprintf(w, "\t// %s\n", parameter.Description)
+		}
+
+		fmt.Fprintf(w, "\t%s %s\n", exported(parameter.Name), kind)
+	}
+
+	fmt.Fprintf(w, "}\n\nfunc (query *%sQuery) values() url.Values {\n\n\tvalues := url.Values{}\n\n\tif query == nil {\n\t\treturn values\n\t}\n", name)
+
+	for _, parameter := range parameters {
+
+		field := "query." + exported(parameter.Name)
+
+		switch parameter.Type {
+		case "integer":
+			fmt.Fprintf(w, "\n\tif %s != 0 {\n\t\tvalues.Set(%q, strconv.Itoa(%s))\n\t}\n", field, parameter.Name, field)
+		case "boolean":
+			fmt.Fprintf(w, "\n\tif %s {\n\t\tvalues.Set(%q, \"true\")\n\t}\n", field, parameter.Name)
+		default:
+			fmt.Fprintf(w, "\n\tif %s != \"\" {\n\t\tvalues.Set(%q, %s)\n\t}\n", field, parameter.Name, field)
+		}
+	}
+
+	fmt.Fprintf(w, "\n\treturn values\n}\n")
+}
+
+// typeName returns the Go expression of t, importing its package
+func (g *generator) typeName(t reflect.Type) string {
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + g.typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + g.typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + g.typeName(t.Key()) + "]" + g.typeName(t.Elem())
+	}
+
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+
+	return g.packageName(t.PkgPath()) + "." + t.Name()
+}
+
+func (g *generator) packageName(importPath string) string {
+
+	if name, ok := g.imports[importPath]; ok {
+		return name
+	}
+
+	// the packages of this API are named after their directory
+	name := path.Base(importPath)
+
+	for i := 2; g.names[name] != ""; i++ {
+		name = fmt.Sprintf("%s%d", path.Base(importPath), i)
+	}
+
+	g.imports[importPath] = name
+	g.names[name] = importPath
+
+	return name
+}
+
+func exported(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"autumnomous-jobs-employer-api/route"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Generate_UpToDate(t *testing.T) {
+	assert := assert.New(t)
+
+	source, err := Generate(route.Operations())
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	generated, err := ioutil.ReadFile("../../operations.go")
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal(string(source), string(generated), "client/operations.go is out of date, run go generate ./client")
+}
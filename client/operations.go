@@ -0,0 +1,587 @@
+// Code generated by go generate from route.Operations; DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+
+	"autumnomous-jobs-employer-api/controller/v1/admin"
+	"autumnomous-jobs-employer-api/controller/v1/employers"
+	"autumnomous-jobs-employer-api/controller/v1/utilities"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/presign"
+	"autumnomous-jobs-employer-api/shared/services/zipcode"
+)
+
+// UploadImage calls POST /upload/image to upload a PNG, JPEG, GIF or WebP image, stored as logo and thumbnail variants
+func (c *Client) UploadImage(ctx context.Context, fileName string, file io.Reader) (*utilities.UploadResponse, error) {
+	result := &utilities.UploadResponse{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/upload/image", security: "apiKey", form: map[string]*formFile{"file": {name: fileName, body: file}}}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PresignUpload calls POST /upload/presign to declare a file and get a presigned URL to PUT it to, straight to storage
+func (c *Client) PresignUpload(ctx context.Context, body presign.Request) (*presign.Presigned, error) {
+	result := &presign.Presigned{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/upload/presign", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CompleteUpload calls POST /upload/complete to check the file PUT to a presigned URL, so the upload can be used
+func (c *Client) CompleteUpload(ctx context.Context, body presign.UploadReference) (*uploads.Upload, error) {
+	result := &uploads.Upload{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/upload/complete", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SignUp calls POST /employer/signup to create an account, emailing a temporary password
+func (c *Client) SignUp(ctx context.Context, body employers.SignUpCredentials) (string, error) {
+	var result string
+
+	err := c.do(ctx, &request{method: "POST", path: "/employer/signup", security: "apiKey", body: body}, &result)
+
+	return result, err
+}
+
+// Login calls POST /employer/login to log in
+func (c *Client) Login(ctx context.Context, body employers.LoginCredentials) (*employers.LoginResponse, error) {
+	result := &employers.LoginResponse{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/employer/login", security: "apiKey", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdatePassword calls POST /employer/update-password to change the password
+func (c *Client) UpdatePassword(ctx context.Context, body employers.UpdatePasswordCredentials) (*response.CoreResponse, error) {
+	result := &response.CoreResponse{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/employer/update-password", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdatePaymentMethod calls POST /employer/update-payment-method to set the payment method
+func (c *Client) UpdatePaymentMethod(ctx context.Context, body employers.UpdatePaymentMethodData) error {
+	return c.do(ctx, &request{method: "POST", path: "/employer/update-payment-method", security: "token", body: body}, nil)
+}
+
+// UpdatePaymentDetails calls POST /employer/update-payment-details to set the payment details
+func (c *Client) UpdatePaymentDetails(ctx context.Context, body employers.UpdatePaymentDetailsData) error {
+	return c.do(ctx, &request{method: "POST", path: "/employer/update-payment-details", security: "token", body: body}, nil)
+}
+
+// GetActiveJobPackages calls GET /employer/get/jobpackages/active to list the job packages on sale
+func (c *Client) GetActiveJobPackages(ctx context.Context) ([]*jobpackages.JobPackage, error) {
+	var result []*jobpackages.JobPackage
+
+	err := c.do(ctx, &request{method: "GET", path: "/employer/get/jobpackages/active", security: "token"}, &result)
+
+	return result, err
+}
+
+// AutocompleteLocation calls POST /employer/get/location/autocomplete to suggest cities
+func (c *Client) AutocompleteLocation(ctx context.Context, body employers.AutocompleteLocationData) ([]zipcode.CityAutoCompleteResponse, error) {
+	var result []zipcode.CityAutoCompleteResponse
+
+	err := c.do(ctx, &request{method: "POST", path: "/employer/get/location/autocomplete", security: "token", body: body}, &result)
+
+	return result, err
+}
+
+// CreateWebhook calls POST /employer/create/webhook to subscribe a URL to events
+func (c *Client) CreateWebhook(ctx context.Context, body employers.CreateWebhookDetails) (*webhooks.Webhook, error) {
+	result := &webhooks.Webhook{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/employer/create/webhook", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetWebhooks calls GET /employer/get/webhooks to list the webhooks and the events they can subscribe to
+func (c *Client) GetWebhooks(ctx context.Context) (*employers.WebhooksResponse, error) {
+	result := &employers.WebhooksResponse{}
+
+	if err := c.do(ctx, &request{method: "GET", path: "/employer/get/webhooks", security: "token"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteWebhook calls DELETE /employer/delete/webhook to delete a webhook
+func (c *Client) DeleteWebhook(ctx context.Context, body employers.WebhookDetails) (*response.CoreResponse, error) {
+	result := &response.CoreResponse{}
+
+	if err := c.do(ctx, &request{method: "DELETE", path: "/employer/delete/webhook", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetWebhookDeliveriesQuery holds the query parameters of GetWebhookDeliveries. Zero values are left out.
+type GetWebhookDeliveriesQuery struct {
+	// The publicid of the webhook
+	Webhook string
+	// At most this many results, from 1 to 500
+	Limit int
+}
+
+func (query *GetWebhookDeliveriesQuery) values() url.Values {
+
+	values := url.Values{}
+
+	if query == nil {
+		return values
+	}
+
+	if query.Webhook != "" {
+		values.Set("webhook", query.Webhook)
+	}
+
+	if query.Limit != 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	return values
+}
+
+// GetWebhookDeliveries calls GET /employer/get/webhook/deliveries to list the deliveries of a webhook
+func (c *Client) GetWebhookDeliveries(ctx context.Context, query *GetWebhookDeliveriesQuery) (*employers.DeliveriesResponse, error) {
+	result := &employers.DeliveriesResponse{}
+
+	if err := c.do(ctx, &request{method: "GET", path: "/employer/get/webhook/deliveries", security: "token", query: query.values()}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RedeliverWebhook calls POST /employer/redeliver/webhook to send a delivery again
+func (c *Client) RedeliverWebhook(ctx context.Context, body employers.WebhookDetails) (*webhooks.Delivery, error) {
+	result := &webhooks.Delivery{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/employer/redeliver/webhook", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PingWebhook calls POST /employer/ping/webhook to send a ping event to a webhook now
+func (c *Client) PingWebhook(ctx context.Context, body employers.WebhookDetails) (*webhooks.Delivery, error) {
+	result := &webhooks.Delivery{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/employer/ping/webhook", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetAuditQuery holds the query parameters of GetAudit. Zero values are left out.
+type GetAuditQuery struct {
+	// The publicid of the employer who made the change
+	Actor      string
+	Action     string
+	Targettype string
+	// The publicid of the changed resource
+	Target string
+	// At most this many results, from 1 to 500
+	Limit int
+	// An RFC 3339 time
+	Since string
+	// An RFC 3339 time
+	Until string
+}
+
+func (query *GetAuditQuery) values() url.Values {
+
+	values := url.Values{}
+
+	if query == nil {
+		return values
+	}
+
+	if query.Actor != "" {
+		values.Set("actor", query.Actor)
+	}
+
+	if query.Action != "" {
+		values.Set("action", query.Action)
+	}
+
+	if query.Targettype != "" {
+		values.Set("targettype", query.Targettype)
+	}
+
+	if query.Target != "" {
+		values.Set("target", query.Target)
+	}
+
+	if query.Limit != 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	if query.Since != "" {
+		values.Set("since", query.Since)
+	}
+
+	if query.Until != "" {
+		values.Set("until", query.Until)
+	}
+
+	return values
+}
+
+// GetAudit calls GET /employer/audit to read the company's audit log
+func (c *Client) GetAudit(ctx context.Context, query *GetAuditQuery) (*employers.AuditResponse, error) {
+	result := &employers.AuditResponse{}
+
+	if err := c.do(ctx, &request{method: "GET", path: "/employer/audit", security: "token", query: query.values()}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PurchaseJobPackage calls POST /employer/buy/job-package to buy a job package
+func (c *Client) PurchaseJobPackage(ctx context.Context, body employers.PurchaseJobPackageDetails) (*response.CoreResponse, error) {
+	result := &response.CoreResponse{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/employer/buy/job-package", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetAccount calls GET /v2/me to get the account
+func (c *Client) GetAccount(ctx context.Context) (*accountmanagement.Employer, error) {
+	result := &accountmanagement.Employer{}
+
+	if err := c.do(ctx, &request{method: "GET", path: "/v2/me", security: "token"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PatchAccount calls PATCH /v2/me to apply a JSON merge patch to the account
+func (c *Client) PatchAccount(ctx context.Context, patch interface{}) (*accountmanagement.Employer, error) {
+	result := &accountmanagement.Employer{}
+
+	if err := c.do(ctx, &request{method: "PATCH", path: "/v2/me", security: "token", body: patch, mediaType: "application/merge-patch+json"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetCompany calls GET /v2/company to get the company
+func (c *Client) GetCompany(ctx context.Context) (*companies.Company, error) {
+	result := &companies.Company{}
+
+	if err := c.do(ctx, &request{method: "GET", path: "/v2/company", security: "token"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PatchCompany calls PATCH /v2/company to apply a JSON merge patch to the company
+func (c *Client) PatchCompany(ctx context.Context, patch interface{}) (*companies.Company, error) {
+	result := &companies.Company{}
+
+	if err := c.do(ctx, &request{method: "PATCH", path: "/v2/company", security: "token", body: patch, mediaType: "application/merge-patch+json"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UploadCompanyLogo calls POST /v2/company/logo to upload a PNG, JPEG, GIF or WebP image as the logo, stored as logo and thumbnail variants
+func (c *Client) UploadCompanyLogo(ctx context.Context, fileName string, file io.Reader) (*companies.Company, error) {
+	result := &companies.Company{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/v2/company/logo", security: "token", form: map[string]*formFile{"file": {name: fileName, body: file}}}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SetCompanyLogo calls PUT /v2/company/logo to make a complete upload the logo, stored as logo and thumbnail variants
+func (c *Client) SetCompanyLogo(ctx context.Context, body presign.UploadReference) (*companies.Company, error) {
+	result := &companies.Company{}
+
+	if err := c.do(ctx, &request{method: "PUT", path: "/v2/company/logo", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListJobsQuery holds the query parameters of ListJobs. Zero values are left out.
+type ListJobsQuery struct {
+	// Whether to include deleted jobs that can still be restored, false by default
+	Deleted bool
+}
+
+func (query *ListJobsQuery) values() url.Values {
+
+	values := url.Values{}
+
+	if query == nil {
+		return values
+	}
+
+	if query.Deleted {
+		values.Set("deleted", "true")
+	}
+
+	return values
+}
+
+// ListJobs calls GET /v2/jobs to list the jobs
+func (c *Client) ListJobs(ctx context.Context, query *ListJobsQuery) ([]*jobs.Job, error) {
+	var result []*jobs.Job
+
+	err := c.do(ctx, &request{method: "GET", path: "/v2/jobs", security: "token", query: query.values()}, &result)
+
+	return result, err
+}
+
+// CreateJob calls POST /v2/jobs to create a job
+func (c *Client) CreateJob(ctx context.Context, body jobs.RevisionContent) (*jobs.Job, error) {
+	result := &jobs.Job{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/v2/jobs", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetJob calls GET /v2/jobs/{id} to get a job
+func (c *Client) GetJob(ctx context.Context, id string) (*jobs.Job, error) {
+	result := &jobs.Job{}
+
+	if err := c.do(ctx, &request{method: "GET", path: "/v2/jobs/" + url.PathEscape(id), security: "token"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PatchJob calls PATCH /v2/jobs/{id} to apply a JSON merge patch to a job
+func (c *Client) PatchJob(ctx context.Context, id string, patch interface{}) (*jobs.Job, error) {
+	result := &jobs.Job{}
+
+	if err := c.do(ctx, &request{method: "PATCH", path: "/v2/jobs/" + url.PathEscape(id), security: "token", body: patch, mediaType: "application/merge-patch+json"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteJob calls DELETE /v2/jobs/{id} to delete a job
+func (c *Client) DeleteJob(ctx context.Context, id string) (*jobs.Job, error) {
+	result := &jobs.Job{}
+
+	if err := c.do(ctx, &request{method: "DELETE", path: "/v2/jobs/" + url.PathEscape(id), security: "token"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RestoreJob calls POST /v2/jobs/{id}/restore to restore a deleted job
+func (c *Client) RestoreJob(ctx context.Context, id string) (*jobs.Job, error) {
+	result := &jobs.Job{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/v2/jobs/" + url.PathEscape(id) + "/restore", security: "token"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetJobRevisions calls GET /v2/jobs/{id}/revisions to list the revisions of a job
+func (c *Client) GetJobRevisions(ctx context.Context, id string) (*employers.RevisionsResponse, error) {
+	result := &employers.RevisionsResponse{}
+
+	if err := c.do(ctx, &request{method: "GET", path: "/v2/jobs/" + url.PathEscape(id) + "/revisions", security: "token"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetJobRevisionDiffQuery holds the query parameters of GetJobRevisionDiff. Zero values are left out.
+type GetJobRevisionDiffQuery struct {
+	// The revision to compare from
+	From int
+	// The revision to compare to
+	To int
+}
+
+func (query *GetJobRevisionDiffQuery) values() url.Values {
+
+	values := url.Values{}
+
+	if query == nil {
+		return values
+	}
+
+	if query.From != 0 {
+		values.Set("from", strconv.Itoa(query.From))
+	}
+
+	if query.To != 0 {
+		values.Set("to", strconv.Itoa(query.To))
+	}
+
+	return values
+}
+
+// GetJobRevisionDiff calls GET /v2/jobs/{id}/revisions/diff to compare two revisions of a job
+func (c *Client) GetJobRevisionDiff(ctx context.Context, id string, query *GetJobRevisionDiffQuery) (*employers.RevisionDiffResponse, error) {
+	result := &employers.RevisionDiffResponse{}
+
+	if err := c.do(ctx, &request{method: "GET", path: "/v2/jobs/" + url.PathEscape(id) + "/revisions/diff", security: "token", query: query.values()}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RollbackJob calls POST /v2/jobs/{id}/revisions/rollback to restore the content of an earlier revision
+func (c *Client) RollbackJob(ctx context.Context, id string, body employers.RollbackDetails) (*jobs.Job, error) {
+	result := &jobs.Job{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/v2/jobs/" + url.PathEscape(id) + "/revisions/rollback", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AttachJobFile calls POST /v2/jobs/{id}/attachments to attach an uploaded PDF or DOCX document to a job
+func (c *Client) AttachJobFile(ctx context.Context, id string, body presign.UploadReference) (*jobs.Attachment, error) {
+	result := &jobs.Attachment{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/v2/jobs/" + url.PathEscape(id) + "/attachments", security: "token", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DetachJobFile calls DELETE /v2/jobs/{id}/attachments/{attachment} to remove an attachment from a job
+func (c *Client) DetachJobFile(ctx context.Context, id string, attachment string) (*jobs.Attachment, error) {
+	result := &jobs.Attachment{}
+
+	if err := c.do(ctx, &request{method: "DELETE", path: "/v2/jobs/" + url.PathEscape(id) + "/attachments/" + url.PathEscape(attachment), security: "token"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListUploads calls GET /v2/uploads to list the uploads, newest first
+func (c *Client) ListUploads(ctx context.Context) ([]*uploads.Upload, error) {
+	var result []*uploads.Upload
+
+	err := c.do(ctx, &request{method: "GET", path: "/v2/uploads", security: "token"}, &result)
+
+	return result, err
+}
+
+// DeleteUpload calls DELETE /v2/uploads/{id} to delete an upload nothing uses
+func (c *Client) DeleteUpload(ctx context.Context, id string) (*uploads.Upload, error) {
+	result := &uploads.Upload{}
+
+	if err := c.do(ctx, &request{method: "DELETE", path: "/v2/uploads/" + url.PathEscape(id), security: "token"}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetQueueQuery holds the query parameters of GetQueue. Zero values are left out.
+type GetQueueQuery struct {
+	// Tasks in this status, dead by default
+	Status string
+	// At most this many results, from 1 to 500
+	Limit int
+}
+
+func (query *GetQueueQuery) values() url.Values {
+
+	values := url.Values{}
+
+	if query == nil {
+		return values
+	}
+
+	if query.Status != "" {
+		values.Set("status", query.Status)
+	}
+
+	if query.Limit != 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	return values
+}
+
+// GetQueue calls GET /admin/queue to inspect the task queue
+func (c *Client) GetQueue(ctx context.Context, query *GetQueueQuery) (*admin.QueueResponse, error) {
+	result := &admin.QueueResponse{}
+
+	if err := c.do(ctx, &request{method: "GET", path: "/admin/queue", security: "adminKey", query: query.values()}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RequeueTask calls POST /admin/queue/requeue to give a dead task a fresh set of attempts
+func (c *Client) RequeueTask(ctx context.Context, body admin.RequeueDetails) (*response.CoreResponse, error) {
+	result := &response.CoreResponse{}
+
+	if err := c.do(ctx, &request{method: "POST", path: "/admin/queue/requeue", security: "adminKey", body: body}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"net/http"
+
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+)
+
+type QueueResponse struct {
+	Stats []*queue.Stats `json:"stats"`
+	Tasks []*queue.Task  `json:"tasks"`
+}
+
+type RequeueDetails struct {
+	ID int64 `json:"id" validate:"required"`
+}
+
+// GetQueue returns task counts by kind and status, and the most recently
+// updated tasks in ?status= (dead by default)
+func (h *Handler) GetQueue(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+
+	if status == "" {
+		status = queue.Dead
+	}
+
+	limit := 50
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		number, invalid := validation.Between("limit", value, 1, 500)
+
+		if invalid != nil {
+			response.SendValidationErrors(w, validation.Errors{invalid})
+			return
+		}
+
+		limit = number
+	}
+
+	stats, err := h.Queue.GetStats()
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	tasks, err := h.Queue.GetTasks(status, limit)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	// payloads can hold secrets such as temporary passwords
+	for _, task := range tasks {
+		task.Payload = nil
+	}
+
+	response.SendJSON(w, QueueResponse{Stats: stats, Tasks: tasks})
+}
+
+// RequeueTask gives a dead task a fresh set of attempts
+func (h *Handler) RequeueTask(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	var details RequeueDetails
+
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	err := h.Queue.Requeue(details.ID)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSONMessage(w, http.StatusOK, response.Success)
+}
@@ -0,0 +1,97 @@
+package admin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/controller/v1/admin"
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Admin_GetQueue(t *testing.T) {
+	assert := assert.New(t)
+
+	application, _ := testhelper.NewMemoryApp(&testhelper.Mailer{})
+
+	task, _ := application.Queue.Enqueue("test", map[string]string{"password": "secret"})
+	application.Queue.Claim(10, time.Minute)
+	application.Queue.Bury(task.ID, "rejected")
+
+	application.Queue.Enqueue("test", nil)
+
+	ts := httptest.NewServer(http.HandlerFunc(admin.NewHandler(application).GetQueue))
+	defer ts.Close()
+
+	response, err := http.Get(ts.URL)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer response.Body.Close()
+
+	var result admin.QueueResponse
+	assert.Nil(json.NewDecoder(response.Body).Decode(&result))
+
+	assert.Equal(http.StatusOK, response.StatusCode)
+	assert.Equal(2, len(result.Stats))
+
+	if assert.Equal(1, len(result.Tasks)) {
+		assert.Equal(task.ID, result.Tasks[0].ID)
+		assert.Equal("rejected", result.Tasks[0].LastError)
+		assert.Equal(0, len(result.Tasks[0].Payload))
+	}
+
+	response, err = http.Get(ts.URL + "?limit=0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func Test_Admin_RequeueTask(t *testing.T) {
+	assert := assert.New(t)
+
+	application, _ := testhelper.NewMemoryApp(&testhelper.Mailer{})
+
+	task, _ := application.Queue.Enqueue("test", nil)
+	application.Queue.Claim(10, time.Minute)
+	application.Queue.Bury(task.ID, "rejected")
+
+	ts := httptest.NewServer(http.HandlerFunc(admin.NewHandler(application).RequeueTask))
+	defer ts.Close()
+
+	requeue := func(id int64) int {
+		body, _ := json.Marshal(map[string]int64{"id": id})
+
+		response, err := http.Post(ts.URL, "application/json", bytes.NewBuffer(body))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response.Body.Close()
+
+		return response.StatusCode
+	}
+
+	assert.Equal(http.StatusOK, requeue(task.ID))
+
+	result, err := application.Queue.GetTask(task.ID)
+
+	if assert.Nil(err) {
+		assert.Equal(queue.Pending, result.Status)
+	}
+
+	// no longer dead
+	assert.Equal(http.StatusNotFound, requeue(task.ID))
+}
@@ -0,0 +1,140 @@
+package employers
+
+import (
+	"net/http"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/attachments"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/presign"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+)
+
+// AttachJobFileByID attaches the PDF or DOCX document of a complete upload
+// to a job of the employer. The document is published under a key derived
+// from its content, so the original upload is left unused. It has no v1
+// route.
+func (h *Handler) AttachJobFileByID(w http.ResponseWriter, r *http.Request, jobPublicID string) {
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var request presign.UploadReference
+
+	if errs := validation.Decode(r.Body, &request); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	upload, err := h.Uploads.GetEmployerUpload(publicID, request.Upload)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	if upload.ContentType != presign.PDF && upload.ContentType != presign.DOCX {
+		response.SendError(w, attachments.ErrNotDocument)
+		return
+	}
+
+	// fail before publishing anything for a job the employer cannot attach to
+	if job, err := h.Jobs.GetJob(jobPublicID); err != nil || job.EmployerPublicID != publicID {
+		response.SendError(w, jobs.ErrNotFound)
+		return
+	}
+
+	data, err := presign.Read(h.Storage, upload)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	url, err := presign.Publish(h.Storage, h.Uploads, publicID, upload.ContentType, data)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	var attachment *jobs.Attachment
+
+	err = h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		size, err := tx.Attachments.GetCompanyAttachmentSize(publicID)
+
+		if err != nil {
+			return err
+		}
+
+		if size+int64(len(data)) > attachments.MaxCompanySize {
+			return attachments.ErrQuotaExceeded
+		}
+
+		attachment, err = tx.Attachments.CreateAttachment(publicID, jobPublicID, upload.FileName, upload.ContentType, url, int64(len(data)))
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Uploads.Retain([]string{url}); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.JobAttach, audit.Job, jobPublicID, nil, attachment)
+	})
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSONStatus(w, http.StatusCreated, attachment)
+}
+
+// DetachJobFileByID removes an attachment from a job of the employer. Its
+// document expires unless something else uses it. It has no v1 route.
+func (h *Handler) DetachJobFileByID(w http.ResponseWriter, r *http.Request, jobPublicID, attachmentPublicID string) {
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var attachment *jobs.Attachment
+
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		var err error
+		attachment, err = tx.Attachments.DeleteAttachment(publicID, jobPublicID, attachmentPublicID)
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Uploads.Release([]string{attachment.URL}, time.Now().Add(uploads.OrphanRetention)); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.JobDetach, audit.Job, jobPublicID, attachment, nil)
+	})
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, attachment)
+}
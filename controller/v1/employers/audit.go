@@ -0,0 +1,122 @@
+package employers
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+)
+
+type AuditResponse struct {
+	Entries []*audit.Entry `json:"entries"`
+}
+
+// GetAudit returns the audit log of the employer's company, newest first.
+// Every employer of a company administers it, so each of them can read it.
+// Filter with ?actor=, ?action=, ?targettype=, ?target=, ?since= and
+// ?until= (RFC 3339) and ?limit=.
+func (h *Handler) GetAudit(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+
+	filter := &audit.Filter{
+		ActorPublicID:  query.Get("actor"),
+		Action:         query.Get("action"),
+		TargetType:     query.Get("targettype"),
+		TargetPublicID: query.Get("target"),
+		Limit:          100,
+	}
+
+	if value := query.Get("limit"); value != "" {
+		number, invalid := validation.Between("limit", value, 1, audit.MaxLimit)
+
+		if invalid != nil {
+			response.SendValidationErrors(w, validation.Errors{invalid})
+			return
+		}
+
+		filter.Limit = number
+	}
+
+	for name, bound := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+
+			if err != nil {
+				response.SendValidationErrors(w, validation.Errors{{Field: name, Code: validation.WrongType, Message: name + " must be an RFC 3339 time"}})
+				return
+			}
+
+			*bound = parsed
+		}
+	}
+
+	entries, err := h.Audit.GetEntries(publicID, filter)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, AuditResponse{Entries: entries})
+}
+
+// recordAudit logs a change actor made through r in the transaction making
+// it. before and after are the target's state, nil for creations and
+// deletions.
+func recordAudit(tx *transaction.Repositories, r *http.Request, actor, action, targetType, targetPublicID string, before, after interface{}) error {
+
+	changes, err := audit.Diff(before, after)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Audit.Record(&audit.Entry{
+		ActorPublicID:  actor,
+		Action:         action,
+		TargetType:     targetType,
+		TargetPublicID: targetPublicID,
+		Changes:        changes,
+		IP:             clientIP(r),
+		UserAgent:      r.UserAgent(),
+	})
+
+	return err
+}
+
+// clientIP is the address the request came from. Heroku's router appends
+// the connecting address to X-Forwarded-For, so only the last one is trusted.
+func clientIP(r *http.Request) string {
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		addresses := strings.Split(forwarded, ",")
+		return strings.TrimSpace(addresses[len(addresses)-1])
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -2,41 +2,43 @@ package employers
 
 import (
 	"encoding/base64"
-	"encoding/json"
-	"log"
 	"net/http"
 
-	"autumnomous-jobs-employer-api/shared/repository/employers"
 	"autumnomous-jobs-employer-api/shared/response"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
 )
 
 type LoginCredentials struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
 }
 
-func Login(w http.ResponseWriter, r *http.Request) {
+// LoginResponse carries the base64 encoded token to send as the bearer token
+type LoginResponse struct {
+	Token            string `json:"token"`
+	RegistrationStep string `json:"registrationstep"`
+}
+
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
-	decoder := json.NewDecoder(r.Body)
 	credentials := LoginCredentials{}
-	decoder.Decode(&credentials)
 
-	if credentials.Email == "" || credentials.Password == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, "Bad Request")
+	if errs := validation.Decode(r.Body, &credentials); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
-	match, registrationStep, publicID, err := AuthenticationFunction(credentials.Email, credentials.Password)
+	match, registrationStep, publicID, err := h.Employers.AuthenticateEmployerPassword(credentials.Email, credentials.Password)
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, "Internal Server Error")
+		response.SendError(w, err)
+		return
 	}
 
 	if match {
@@ -45,39 +47,17 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		tokenStr, err := jwt.GenerateToken(publicID)
 
 		if err != nil {
-			log.Println(err)
-			response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+			response.SendError(w, err)
 			return
 		}
 
 		encodedTokenStr := base64.StdEncoding.EncodeToString([]byte(tokenStr))
 
-		token := map[string]interface{}{
-			"token":            encodedTokenStr,
-			"registrationstep": registrationStep,
-		}
-		response.SendJSON(w, token)
+		response.SendJSON(w, LoginResponse{Token: encodedTokenStr, RegistrationStep: registrationStep})
 		return
 	} else {
-		response.SendJSONMessage(w, http.StatusUnauthorized, "Login failed")
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeInvalidCredentials, response.InvalidCredentials)
 		return
 	}
 
 }
-
-func AuthenticatePassword(email, password string) (bool, string, string, error) {
-
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
-
-	match, registrationStep, publicID, err := repository.AuthenticateEmployerPassword(email, password)
-
-	if err != nil {
-		log.Println(err)
-		return false, "", "", err
-	}
-
-	return match, registrationStep, publicID, nil
-
-}
-
-var AuthenticationFunction = AuthenticatePassword
@@ -3,12 +3,10 @@ package employers_test
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
-	"autumnomous-jobs-employer-api/controller/v1/employers"
 	"autumnomous-jobs-employer-api/shared/services/security/encryption"
 	"autumnomous-jobs-employer-api/shared/testhelper"
 
@@ -22,12 +20,15 @@ func init() {
 func Test_EmployerLogin_Success(t *testing.T) {
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.Login))
+	application := testhelper.NewApp(&testhelper.Mailer{})
+	application.Employers = authenticatingRepository{application.Employers}
+
+	ts := httptest.NewServer(http.HandlerFunc(newHandlerWithApp(application).Login))
 
 	defer ts.Close()
 
 	data := map[string]string{
-		"email":    fmt.Sprintf("lavernecox-%s@amazing.com", string(encryption.GeneratePassword(9))),
+		"email":    testhelper.Helper_RandomEmail("lavernecox"),
 		"password": string(encryption.GeneratePassword(9)),
 	}
 
@@ -43,14 +44,6 @@ func Test_EmployerLogin_Success(t *testing.T) {
 		t.Fatal()
 	}
 
-	employers.AuthenticationFunction = func(email, password string) (bool, string, string, error) {
-		return true, "", "", nil
-	}
-
-	defer func() {
-		employers.AuthenticationFunction = employers.AuthenticatePassword
-	}()
-
 	httpClient := &http.Client{}
 
 	response, err := httpClient.Do(request)
@@ -76,7 +69,7 @@ func Test_EmployerLogin_NoDataReceived(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.Login))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().Login))
 
 	defer ts.Close()
 
@@ -118,7 +111,7 @@ func Test_EmployerLogin_IncorrectMethod(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.Login))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().Login))
 
 	defer ts.Close()
 
@@ -165,12 +158,12 @@ func Test_EmployerLogin_IncorrectPassword(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.Login))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().Login))
 
 	data := map[string]string{
 		"firstname":         "First",
 		"lastname":          "Last",
-		"email":             fmt.Sprintf("email-%s@test.com", string(encryption.GeneratePassword(9))),
+		"email":             testhelper.Helper_RandomEmail("email"),
 		"password":          string(encryption.GeneratePassword(9)),
 		"employer-password": string(encryption.GeneratePassword(9)),
 	}
@@ -189,7 +182,7 @@ func Test_EmployerLogin_IncorrectPassword(t *testing.T) {
 
 	employer.HashedPassword = hashedPassword
 
-	requestBody, err := json.Marshal(data)
+	requestBody, err := json.Marshal(map[string]string{"email": data["email"], "password": data["password"]})
 
 	if err != nil {
 		t.Fatal()
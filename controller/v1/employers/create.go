@@ -1,55 +1,55 @@
 package employers
 
 import (
-	"encoding/json"
-	"log"
 	"net/http"
 
+	"autumnomous-jobs-employer-api/shared/repository/audit"
 	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
 	"autumnomous-jobs-employer-api/shared/response"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
 )
 
-type createJobDetails struct {
-	Title       string `json:"title"`
-	JobType     string `json:"jobtype"`
-	Category    string `json:"category"`
-	Description string `json:"description"` // make required?
-	Remote      bool   `json:"remote"`
-	VisibleDate string `json:"visibledate"`
-	PayPeriod   string `json:"payperiod"`
-	MinSalary   int64  `json:"minsalary"`
-	MaxSalary   int64  `json:"maxsalary"`
-}
-
-func CreateJob(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
-	var jobDetails createJobDetails
-
-	decoder := json.NewDecoder(r.Body)
-	decoder.Decode(&jobDetails)
+	var jobDetails jobs.RevisionContent
 
-	if jobDetails.Title == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.MissingRequiredValue)
+	if errs := validation.Decode(r.Body, &jobDetails); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
 	publicID := jwt.GetUserClaim(r)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	var job *jobs.Job
+
+	// resolved before the transaction, as the geocoder may be slow
+	if err := h.locateJob(&jobDetails, nil); err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		var err error
+
+		job, err = tx.Jobs.CreateJob(publicID, jobDetails)
 
-	// TODO: jobDetails.PostEndDatetime = jobDetails.PostStartDatetime + 30 days
+		if err != nil {
+			return err
+		}
 
-	job, err := repository.EmployerCreateJob(publicID, jobDetails.Title, jobDetails.JobType, jobDetails.Category, jobDetails.Description, jobDetails.VisibleDate, jobDetails.PayPeriod, jobDetails.Remote, jobDetails.MinSalary, jobDetails.MaxSalary)
+		return recordAudit(tx, r, publicID, audit.JobCreate, audit.Job, job.PublicID, nil, job)
+	})
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
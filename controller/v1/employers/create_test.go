@@ -10,7 +10,6 @@ import (
 	"testing"
 	"time"
 
-	"autumnomous-jobs-employer-api/controller/v1/employers"
 	"autumnomous-jobs-employer-api/shared/services/security/encryption"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
 	"autumnomous-jobs-employer-api/shared/testhelper"
@@ -22,7 +21,7 @@ func Test_Employer_CreateJob_IncorrectMethod(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.CreateJob))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().CreateJob))
 
 	defer ts.Close()
 
@@ -52,7 +51,7 @@ func Test_Employer_CreateJob_IncorrectData(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.CreateJob))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().CreateJob))
 
 	defer ts.Close()
 
@@ -95,7 +94,7 @@ func Test_Employer_CreateJob_IncorrectData(t *testing.T) {
 
 func Test_Employer_CreateJob_CorrectData(t *testing.T) {
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.CreateJob))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().CreateJob))
 
 	defer ts.Close()
 
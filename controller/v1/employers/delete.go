@@ -1,69 +1,138 @@
 package employers
 
 import (
-	"encoding/base64"
-	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
-	"strings"
+	"time"
 
+	"autumnomous-jobs-employer-api/shared/repository/audit"
 	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
 	"autumnomous-jobs-employer-api/shared/response"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+	"autumnomous-jobs-employer-api/shared/services/webhook"
 )
 
 type DeleteJobDetails struct {
-	PublicID string `json:"publicid"`
+	PublicID string `json:"publicid" validate:"required"`
 }
 
-func DeleteJob(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteJob(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodDelete {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method not allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
-	decoder := json.NewDecoder(r.Body)
 	var details DeleteJobDetails
 
-	decoder.Decode(&details)
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	h.DeleteJobByID(w, r, details.PublicID)
+}
+
+// DeleteJobByID deletes the employer's job jobPublicID. It is shared with the
+// v2 routes, which take the ID from the path.
+func (h *Handler) DeleteJobByID(w http.ResponseWriter, r *http.Request, jobPublicID string) {
 
-	if details.PublicID == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.MissingRequiredValue)
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
 		return
 	}
 
-	auth := strings.SplitN(r.Header.Get("Authorization"), " ", 2)[1]
-	authKey, err := base64.StdEncoding.DecodeString(auth)
+	var job *jobs.Job
+
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		before, err := tx.Jobs.GetJob(jobPublicID)
+
+		if err != nil {
+			return err
+		}
+
+		job, err = tx.Jobs.DeleteJob(publicID, jobPublicID)
+
+		if err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, r, publicID, audit.JobDelete, audit.Job, jobPublicID, before, nil); err != nil {
+			return err
+		}
+
+		return webhook.Publish(tx.Webhooks, tx.Queue, publicID, webhook.JobDeleted, job)
+	})
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
-	tokenClaims, err := jwt.ParseToken(string(authKey))
+	response.SendJSON(w, job)
+}
 
-	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+// RestoreJob undeletes a job deleted within the last jobs.DeleteRetention
+func (h *Handler) RestoreJob(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
-	publicID := tokenClaims.CustomClaims["user"]
+	var details DeleteJobDetails
+
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	h.RestoreJobByID(w, r, details.PublicID)
+}
+
+// RestoreJobByID is RestoreJob for the job jobPublicID, shared with the v2
+// routes
+func (h *Handler) RestoreJobByID(w http.ResponseWriter, r *http.Request, jobPublicID string) {
+
+	publicID := jwt.GetUserClaim(r)
 
 	if publicID == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
 		return
 	}
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	var job *jobs.Job
 
-	job, err := repository.DeleteJob(publicID, details.PublicID)
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		var err error
+
+		job, err = tx.Jobs.RestoreJob(publicID, jobPublicID, time.Now().Add(-jobs.DeleteRetention))
+
+		if err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, r, publicID, audit.JobRestore, audit.Job, job.PublicID, nil, job); err != nil {
+			return err
+		}
+
+		return webhook.Publish(tx.Webhooks, tx.Queue, publicID, webhook.JobRestored, job)
+	})
+
+	if errors.Is(err, jobs.ErrNotFound) {
+		response.SendProblem(w, http.StatusNotFound, jobs.ErrNotFound.Code, fmt.Sprintf("No deleted job to restore, jobs can be restored for %d days", int(jobs.DeleteRetention.Hours()/24)))
+		return
+	}
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
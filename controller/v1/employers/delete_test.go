@@ -8,7 +8,6 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"autumnomous-jobs-employer-api/controller/v1/employers"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
 	"autumnomous-jobs-employer-api/shared/testhelper"
 
@@ -17,7 +16,7 @@ import (
 
 func Test_Employer_DeleteJob_IncorrectMethods(t *testing.T) {
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.DeleteJob))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().DeleteJob))
 
 	defer ts.Close()
 
@@ -45,7 +44,7 @@ func Test_Employer_DeleteJob_IncorrectMethods(t *testing.T) {
 
 func Test_Employer_DeleteJob_NoData(t *testing.T) {
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.DeleteJob))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().DeleteJob))
 
 	defer ts.Close()
 
@@ -78,7 +77,7 @@ func Test_Employer_DeleteJob_NoData(t *testing.T) {
 func Test_Employer_DeleteJob_Correct(t *testing.T) {
 
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.DeleteJob))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().DeleteJob))
 
 	defer ts.Close()
 
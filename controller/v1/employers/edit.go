@@ -1,70 +1,154 @@
 package employers
 
 import (
-	"encoding/base64"
-	"encoding/json"
-	"log"
 	"net/http"
-	"strings"
 
+	"autumnomous-jobs-employer-api/shared/repository/audit"
 	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
 	"autumnomous-jobs-employer-api/shared/response"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
 )
 
-type editJobDetails struct {
-	Title       string `json:"title"`
-	JobType     string `json:"jobtype"`
-	Category    string `json:"category"`
-	Description string `json:"description"` // make required?
-	VisibleDate string `json:"visibledate"`
-	Remote      bool   `json:"remote"`
-	PublicID    string `json:"publicid"`
-	MinSalary   int64  `json:"minsalary"`
-	MaxSalary   int64  `json:"maxsalary"`
-	PayPeriod   string `json:"payperiod"`
+// EditJobDetails has the rules of jobs.RevisionContent, but as empty fields
+// are left unchanged only the job is required
+type EditJobDetails struct {
+	Title       string  `json:"title" validate:"max=200"`
+	JobType     string  `json:"jobtype" validate:"oneof=full-time part-time contract temporary internship"`
+	Category    string  `json:"category" validate:"max=100"`
+	Description string  `json:"description" validate:"max=20000"`
+	VisibleDate string  `json:"visibledate"`
+	Remote      bool    `json:"remote"`
+	PublicID    string  `json:"publicid" validate:"required"`
+	MinSalary   int64   `json:"minsalary" validate:"min=0,ltefield=MaxSalary"`
+	MaxSalary   int64   `json:"maxsalary" validate:"min=0"`
+	PayPeriod   string  `json:"payperiod" validate:"oneof=hourly daily weekly monthly yearly"`
+	Location    string  `json:"location" validate:"max=200"`
+	ZipCode     string  `json:"zipcode" validate:"max=10"`
+	Latitude    float64 `json:"latitude" validate:"min=-90,max=90"`
+	Longitude   float64 `json:"longitude" validate:"min=-180,max=180"`
 }
 
-func EditJob(w http.ResponseWriter, r *http.Request) {
+// EditJob saves the non-empty fields of a job. With an If-Match header it
+// only saves over the revision in the ETag, and otherwise responds 412 with
+// the current job so the client can merge.
+func (h *Handler) EditJob(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
-	var details editJobDetails
+	revision, err := ifMatch(r)
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.Decode(&details)
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
 
-	auth := strings.SplitN(r.Header.Get("Authorization"), " ", 2)[1]
-	authKey, err := base64.StdEncoding.DecodeString(auth)
+	var details EditJobDetails
 
-	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
-	tokenClaims, err := jwt.ParseToken(string(authKey))
+	publicID := jwt.GetUserClaim(r)
 
-	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
 		return
 	}
 
-	publicID := tokenClaims.CustomClaims["user"]
+	var job *jobs.Job
+
+	for attempt := 1; ; attempt++ {
+
+		var before *jobs.Job
+		var content jobs.RevisionContent
+
+		before, content, err = h.editedJob(publicID, &details, revision)
+
+		if err == nil {
+			err = h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+				var err error
+
+				// the edit was merged into before, so nothing may change in between
+				job, err = tx.Jobs.ReplaceJob(publicID, details.PublicID, content, before.Revision)
+
+				if err != nil {
+					return err
+				}
+
+				return recordAudit(tx, r, publicID, audit.JobEdit, audit.Job, details.PublicID, before, job)
+			})
+		}
+
+		if err != jobs.ErrStale || revision != 0 || attempt == patchAttempts {
+			break
+		}
+	}
+
+	if err == jobs.ErrStale {
+		current, err := h.Jobs.GetJob(details.PublicID)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+		if err != nil {
+			response.SendError(w, err)
+			return
+		}
 
-	job, err := repository.EditJob(publicID, details.PublicID, details.Title, details.JobType, details.Category, details.Description, details.VisibleDate, details.PayPeriod, details.Remote, details.MinSalary, details.MaxSalary)
+		sendVersioned(w, http.StatusPreconditionFailed, current.Revision, current)
+		return
+	}
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
-	response.SendJSON(w, job)
+	sendVersioned(w, http.StatusOK, job.Revision, job)
+}
+
+// editedJob reads the job and returns it with the content it has once the
+// non-empty fields of details are merged in and its location is resolved. The
+// merged content is validated, as a field left out of the request may
+// disagree with one in it.
+func (h *Handler) editedJob(publicID string, details *EditJobDetails, revision int) (*jobs.Job, jobs.RevisionContent, error) {
+
+	before, err := h.Jobs.GetJob(details.PublicID)
+
+	if err != nil {
+		return nil, jobs.RevisionContent{}, err
+	}
+
+	if before.EmployerPublicID != publicID {
+		return nil, jobs.RevisionContent{}, jobs.ErrNotFound
+	}
+
+	if revision != 0 && before.Revision != revision {
+		return nil, jobs.RevisionContent{}, jobs.ErrStale
+	}
+
+	content := before.Content()
+	content.Merge(jobs.RevisionContent{Title: details.Title, JobType: details.JobType, Category: details.Category, Description: details.Description,
+		VisibleDate: details.VisibleDate, PayPeriod: details.PayPeriod, MinSalary: details.MinSalary, MaxSalary: details.MaxSalary})
+	content.Remote = details.Remote
+
+	// a location replaces the stored one as a whole, so a new zip code is not
+	// checked against the old coordinates
+	if details.Location != "" || details.ZipCode != "" || details.Latitude != 0 || details.Longitude != 0 {
+		content.Location, content.ZipCode, content.Latitude, content.Longitude = details.Location, details.ZipCode, details.Latitude, details.Longitude
+	}
+
+	if errs := validation.Validate(&content); errs != nil {
+		return nil, jobs.RevisionContent{}, errs
+	}
+
+	if err := h.locateJob(&content, before); err != nil {
+		return nil, jobs.RevisionContent{}, err
+	}
+
+	return before, content, nil
 }
@@ -2,7 +2,7 @@ package employers_test
 
 // func Test_Employer_EditJob_IncorrectMethod(t *testing.T) {
 // 	assert := assert.New(t)
-// 	ts := httptest.NewServer(http.HandlerFunc(employers.EditJob))
+// 	ts := httptest.NewServer(http.HandlerFunc(newHandler().EditJob))
 
 // 	defer ts.Close()
 
@@ -29,7 +29,7 @@ package employers_test
 
 // func Test_Employer_EditJob_Correct_NoData(t *testing.T) {
 // 	assert := assert.New(t)
-// 	ts := httptest.NewServer(http.HandlerFunc(employers.EditJob))
+// 	ts := httptest.NewServer(http.HandlerFunc(newHandler().EditJob))
 
 // 	defer ts.Close()
 
@@ -78,7 +78,7 @@ package employers_test
 
 // func Test_Employer_EditJob_Correct_Data(t *testing.T) {
 // 	assert := assert.New(t)
-// 	ts := httptest.NewServer(http.HandlerFunc(employers.EditJob))
+// 	ts := httptest.NewServer(http.HandlerFunc(newHandler().EditJob))
 
 // 	defer ts.Close()
 
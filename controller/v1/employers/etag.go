@@ -0,0 +1,46 @@
+package employers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/response"
+)
+
+var errBadIfMatch = domain.NewInvalid("invalid_if_match", "If-Match must be a single ETag returned by this API, or *")
+
+// etag is the entity tag of a job or company at version
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatch returns the version required by the request's If-Match header. It
+// is 0, meaning any version, when the header is missing or *.
+func ifMatch(r *http.Request) (int, error) {
+
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	if len(value) < 3 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, errBadIfMatch
+	}
+
+	version, err := strconv.Atoi(value[1 : len(value)-1])
+
+	if err != nil || version < 1 {
+		return 0, errBadIfMatch
+	}
+
+	return version, nil
+}
+
+// sendVersioned sends a job or company with its ETag
+func sendVersioned(w http.ResponseWriter, status int, version int, i interface{}) {
+	w.Header().Set("ETag", etag(version))
+	response.SendJSONStatus(w, status, i)
+}
@@ -1,17 +1,12 @@
 package employers
 
 import (
-	"encoding/json"
-	"log"
 	"net/http"
-	"os"
 
-	"autumnomous-jobs-employer-api/shared/repository/employers"
-	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
 	"autumnomous-jobs-employer-api/shared/repository/jobs"
 	"autumnomous-jobs-employer-api/shared/response"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
-	"autumnomous-jobs-employer-api/shared/services/zipcode"
+	"autumnomous-jobs-employer-api/shared/services/validation"
 )
 
 type JobsResponse struct {
@@ -19,30 +14,46 @@ type JobsResponse struct {
 }
 
 type AutocompleteLocationData struct {
-	Characters string `json:"chars"`
+	Characters string `json:"chars" validate:"required,max=100"`
 }
 
-func GetJobs(w http.ResponseWriter, r *http.Request) {
+type GetJobDetails struct {
+	PublicID string `json:"publicid" validate:"required"`
+}
+
+func (h *Handler) GetJobs(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodGet {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method not allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
 	publicID := jwt.GetUserClaim(r)
 
 	if publicID == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
 		return
 	}
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	includeDeleted := false
+
+	if value := r.URL.Query().Get("deleted"); value != "" {
+		boolean, invalid := validation.Boolean("deleted", value)
+
+		if invalid != nil {
+			response.SendValidationErrors(w, validation.Errors{invalid})
+			return
+		}
+
+		includeDeleted = boolean
+	}
 
-	jobs, err := repository.GetEmployerJobs(publicID)
+	repository := h.Jobs
+
+	jobs, err := repository.GetEmployerJobs(publicID, includeDeleted)
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
@@ -50,62 +61,67 @@ func GetJobs(w http.ResponseWriter, r *http.Request) {
 
 }
 
-func GetJob(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method not allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	var details GetJobDetails
+
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
-	// tokenClaims, err := jwt.GetStrClaims(r)
+	h.GetJobByID(w, r, details.PublicID)
+}
 
-	// if err != nil {
-	// 	log.Println(err)
-	// 	response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
-	// 	return
-	// }
+// GetJobByID returns one of the employer's jobs with its ETag. It is shared
+// with the v2 routes, which take the ID from the path.
+func (h *Handler) GetJobByID(w http.ResponseWriter, r *http.Request, jobPublicID string) {
 
-	// publicID := tokenClaims["user"]
+	publicID := jwt.GetUserClaim(r)
 
-	// if publicID == "" {
-	// 	response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
-	// 	return
-	// }
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
 
-	var details map[string]string
-	decoder := json.NewDecoder(r.Body)
-	decoder.Decode(&details)
+	job, err := h.Jobs.GetJob(jobPublicID)
 
-	if details["publicid"] == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+	if err == nil && job.EmployerPublicID != publicID {
+		err = jobs.ErrNotFound
+	}
+
+	if err != nil {
+		response.SendError(w, err)
 		return
 	}
-	repository := jobs.NewJobRegistry().GetJobRepository()
 
-	job, err := repository.GetJob(details["publicid"])
+	job.Attachments, err = h.Attachments.GetJobAttachments(jobPublicID)
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
-	response.SendJSON(w, job)
+	sendVersioned(w, http.StatusOK, job.Revision, job)
 }
 
-func GetActiveJobPackages(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetActiveJobPackages(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method not allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
-	repository := jobpackages.NewJobPackageRegistry().GetJobPackageRepository()
+	repository := h.JobPackages
 
 	packages, err := repository.GetActiveJobPackages()
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
@@ -113,77 +129,69 @@ func GetActiveJobPackages(w http.ResponseWriter, r *http.Request) {
 
 }
 
-func GetEmployer(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetEmployer(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodGet {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method not allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
 	publicID := jwt.GetUserClaim(r)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := h.Employers
 
 	employer, err := repository.GetEmployer(publicID)
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
 	response.SendJSON(w, employer)
 }
 
-func GetEmployerCompany(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetEmployerCompany(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodGet {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method not allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
 	publicID := jwt.GetUserClaim(r)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := h.Employers
 
 	company, err := repository.GetEmployerCompany(publicID)
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
-	response.SendJSON(w, company)
+	sendVersioned(w, http.StatusOK, company.Version, company)
 
 }
 
-func GetAutocompleteLocationData(w http.ResponseWriter, r *http.Request) {
+// GetAutocompleteLocationData suggests cities for the characters typed so far
+func (h *Handler) GetAutocompleteLocationData(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method not allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
 	var details AutocompleteLocationData
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.Decode(&details)
-
-	if details.Characters == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.MissingRequiredValue)
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
-	gateway := zipcode.NewZipCodeGateway(os.Getenv("ZIPCODESERVICES_API_KEY"))
-
-	data, err := gateway.GetAutoComplete(details.Characters)
+	data, err := h.Geocoder.GetAutoComplete(details.Characters)
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
 	response.SendJSON(w, data)
-
 }
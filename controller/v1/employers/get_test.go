@@ -9,7 +9,6 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"autumnomous-jobs-employer-api/controller/v1/employers"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
 	"autumnomous-jobs-employer-api/shared/testhelper"
 
@@ -23,7 +22,7 @@ func init() {
 func Test_Employer_GetJobs_IncorrectPublicID(t *testing.T) {
 
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.GetJobs))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().GetJobs))
 
 	defer ts.Close()
 
@@ -49,14 +48,14 @@ func Test_Employer_GetJobs_IncorrectPublicID(t *testing.T) {
 	response, err := httpClient.Do(request)
 
 	assert.Nil(err)
-	assert.Equal(int(http.StatusBadRequest), response.StatusCode)
+	assert.Equal(int(http.StatusUnauthorized), response.StatusCode)
 
 }
 
 func Test_Employer_GetJobs_IncorrectMethod(t *testing.T) {
 
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.GetJobs))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().GetJobs))
 
 	defer ts.Close()
 
@@ -83,7 +82,7 @@ func Test_Employer_GetJobs_Correct(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.GetJobs))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().GetJobs))
 
 	defer ts.Close()
 
@@ -135,7 +134,7 @@ func Test_Employer_GetJobs_Correct(t *testing.T) {
 func Test_Employer_GetJobPackages_Correct(t *testing.T) {
 
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.GetActiveJobPackages))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().GetActiveJobPackages))
 
 	defer ts.Close()
 
@@ -176,7 +175,7 @@ func Test_Employer_GetJobPackages_Correct(t *testing.T) {
 func Test_Employer_GetCompany_Correct(t *testing.T) {
 
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.GetEmployerCompany))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().GetEmployerCompany))
 
 	defer ts.Close()
 
@@ -223,7 +222,7 @@ func Test_Employer_GetCompany_Correct(t *testing.T) {
 
 func Test_Employer_GetAutocompleteLocationData_Correct(t *testing.T) {
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.GetAutocompleteLocationData))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().GetAutocompleteLocationData))
 
 	defer ts.Close()
 
@@ -269,7 +268,7 @@ func Test_Employer_GetAutocompleteLocationData_Correct(t *testing.T) {
 
 func Test_Employer_GetAutocompleteLocationData_IncorrectMethod(t *testing.T) {
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.GetAutocompleteLocationData))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().GetAutocompleteLocationData))
 
 	defer ts.Close()
 
@@ -0,0 +1,13 @@
+package employers
+
+import "autumnomous-jobs-employer-api/app"
+
+// Handler serves the v1 employer endpoints using the application's repositories and services
+type Handler struct {
+	*app.App
+}
+
+// NewHandler returns a Handler backed by application
+func NewHandler(application *app.App) *Handler {
+	return &Handler{App: application}
+}
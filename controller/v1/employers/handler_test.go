@@ -0,0 +1,27 @@
+package employers_test
+
+import (
+	"autumnomous-jobs-employer-api/app"
+	"autumnomous-jobs-employer-api/controller/v1/employers"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+// newHandler returns a Handler over the test database that records email instead of sending it
+func newHandler() *employers.Handler {
+	return employers.NewHandler(testhelper.NewApp(&testhelper.Mailer{}))
+}
+
+// newHandlerWithApp returns a Handler over application, which tests may fill with fakes
+func newHandlerWithApp(application *app.App) *employers.Handler {
+	return employers.NewHandler(application)
+}
+
+// authenticatingRepository accepts any email and password
+type authenticatingRepository struct {
+	accountmanagement.EmployerRepository
+}
+
+func (authenticatingRepository) AuthenticateEmployerPassword(email, password string) (bool, string, string, error) {
+	return true, "", "", nil
+}
@@ -0,0 +1,94 @@
+package employers
+
+import (
+	"log"
+
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/services/zipcode"
+)
+
+// locate resolves the location fields of place through the geocoder, and
+// returns them completed with where they are. previous is the place before
+// the save, and is kept when the location did not change. Inputs that
+// cannot be found or disagree are returned as validation.Errors. Without a
+// geocoder, or when it fails, the location is saved as given, unresolved.
+func (h *Handler) locate(place, previous zipcode.Place) (zipcode.Place, error) {
+
+	query := zipcode.Query{Location: place.Location, ZipCode: place.ZipCode, Latitude: place.Latitude, Longitude: place.Longitude}
+
+	if previous.City != "" && query == (zipcode.Query{Location: previous.Location, ZipCode: previous.ZipCode, Latitude: previous.Latitude, Longitude: previous.Longitude}) {
+		return previous, nil
+	}
+
+	place.City, place.State, place.County, place.Country = "", "", "", ""
+
+	if h.Geocoder == nil {
+		return place, nil
+	}
+
+	resolved, errs, err := zipcode.Resolve(h.Geocoder, query)
+
+	if err != nil {
+		log.Println("location not resolved:", err)
+		return place, nil
+	}
+
+	if errs != nil {
+		return place, errs
+	}
+
+	if resolved == nil {
+		return place, nil
+	}
+
+	return *resolved, nil
+}
+
+// locateCompany resolves the location of profile, which was before
+func (h *Handler) locateCompany(profile *companies.Profile, before *companies.Company) error {
+
+	previous := before.Profile()
+
+	place, err := h.locate(companyPlace(profile), companyPlace(&previous))
+
+	if err != nil {
+		return err
+	}
+
+	profile.Location, profile.Zipcode, profile.Latitude, profile.Longitude = place.Location, place.ZipCode, place.Latitude, place.Longitude
+	profile.City, profile.State, profile.County, profile.Country = place.City, place.State, place.County, place.Country
+
+	return nil
+}
+
+// locateJob resolves the location of content. before is nil for a new job.
+func (h *Handler) locateJob(content *jobs.RevisionContent, before *jobs.Job) error {
+
+	var previous jobs.RevisionContent
+
+	if before != nil {
+		previous = before.Content()
+	}
+
+	place, err := h.locate(jobPlace(content), jobPlace(&previous))
+
+	if err != nil {
+		return err
+	}
+
+	content.Location, content.ZipCode, content.Latitude, content.Longitude = place.Location, place.ZipCode, place.Latitude, place.Longitude
+	content.City, content.State, content.County, content.Country = place.City, place.State, place.County, place.Country
+
+	return nil
+}
+
+func companyPlace(profile *companies.Profile) zipcode.Place {
+	return zipcode.Place{Location: profile.Location, ZipCode: profile.Zipcode, Latitude: profile.Latitude, Longitude: profile.Longitude,
+		City: profile.City, State: profile.State, County: profile.County, Country: profile.Country}
+}
+
+func jobPlace(content *jobs.RevisionContent) zipcode.Place {
+	return zipcode.Place{Location: content.Location, ZipCode: content.ZipCode, Latitude: content.Latitude, Longitude: content.Longitude,
+		City: content.City, State: content.State, County: content.County, Country: content.Country}
+}
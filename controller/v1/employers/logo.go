@@ -0,0 +1,162 @@
+package employers
+
+import (
+	"net/http"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/imaging"
+	"autumnomous-jobs-employer-api/shared/services/presign"
+	"autumnomous-jobs-employer-api/shared/services/scan"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+)
+
+// UploadCompanyLogo stores the variants of a multipart image upload and
+// makes them the company's logo, responding with the company and its ETag.
+// It has no v1 route.
+func (h *Handler) UploadCompanyLogo(w http.ResponseWriter, r *http.Request) {
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	data, err := imaging.ReadFormFile(w, r, "file")
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	if err := scan.Check(r.Context(), h.Scanner, h.Storage, h.Uploads, publicID, "", data); err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	h.setCompanyLogo(w, r, publicID, data)
+}
+
+// SetCompanyLogo makes a complete upload the company's logo, stored as the
+// variants of a multipart upload would be, responding with the company and
+// its ETag. It has no v1 route.
+func (h *Handler) SetCompanyLogo(w http.ResponseWriter, r *http.Request) {
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var request presign.UploadReference
+
+	if errs := validation.Decode(r.Body, &request); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	upload, err := h.Uploads.GetEmployerUpload(publicID, request.Upload)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	data, err := presign.Read(h.Storage, upload)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	h.setCompanyLogo(w, r, publicID, data)
+}
+
+// setCompanyLogo stores the variants of the image in data as the company's
+// logo. The original upload, when the image came from one, is left unused.
+func (h *Handler) setCompanyLogo(w http.ResponseWriter, r *http.Request, publicID string, data []byte) {
+
+	images, err := imaging.Process(data, imaging.LogoVariants)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	// fail before storing anything for an employer without a company
+	if _, err := h.Employers.GetEmployerCompany(publicID); err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	urls, err := imaging.Store(h.Storage, h.Uploads, publicID, images)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	var company *companies.Company
+
+	err = h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		before, err := tx.Employers.GetEmployerCompany(publicID)
+
+		if err != nil {
+			return err
+		}
+
+		company, err = tx.Employers.SetEmployerCompanyLogo(publicID, urls[imaging.Logo.Name], urls)
+
+		if err != nil {
+			return err
+		}
+
+		if err := retainLogo(tx, before, company); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.CompanyUpdate, audit.Company, company.PublicID, before, company)
+	})
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	sendVersioned(w, http.StatusOK, company.Version, company)
+}
+
+// retainLogo moves the company's uses of uploads from the images of its logo
+// before a change to those after it
+func retainLogo(tx *transaction.Repositories, before, after *companies.Company) error {
+
+	// retained first, so images kept by the change never go unused
+	if err := tx.Uploads.Retain(logoURLs(after)); err != nil {
+		return err
+	}
+
+	return tx.Uploads.Release(logoURLs(before), time.Now().Add(uploads.OrphanRetention))
+}
+
+func logoURLs(company *companies.Company) []string {
+
+	if company == nil {
+		return nil
+	}
+
+	urls := []string{company.Logo}
+
+	for _, url := range company.LogoVariants {
+		urls = append(urls, url)
+	}
+
+	return urls
+}
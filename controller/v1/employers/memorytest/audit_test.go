@@ -0,0 +1,99 @@
+package memorytest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autumnomous-jobs-employer-api/controller/v1/employers"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Employer_GetAudit_RecordsJobChanges(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]string{"title": "Welder"})
+	assert.Equal(http.StatusOK, result.Code)
+
+	var job jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+
+	result = sendAuthorized(t, handler.EditJob, http.MethodPost, token, map[string]interface{}{"publicid": job.PublicID, "title": "Senior Welder", "minsalary": 40000})
+	assert.Equal(http.StatusOK, result.Code)
+
+	request := httptest.NewRequest(http.MethodGet, "/?target="+job.PublicID, nil)
+	request.Header.Set("Authorization", token)
+	recorder := httptest.NewRecorder()
+
+	handler.GetAudit(recorder, request)
+
+	assert.Equal(http.StatusOK, recorder.Code)
+
+	var listed employers.AuditResponse
+	assert.Nil(json.NewDecoder(recorder.Body).Decode(&listed))
+
+	if assert.Len(listed.Entries, 2) {
+		edit := listed.Entries[0]
+
+		assert.Equal(audit.JobEdit, edit.Action)
+		assert.Equal(audit.Job, edit.TargetType)
+		assert.Equal("192.0.2.1", edit.IP)
+		assert.NotEmpty(edit.CompanyPublicID)
+
+		var changes map[string]audit.Change
+		assert.Nil(json.Unmarshal(edit.Changes, &changes))
+		assert.Equal("Welder", changes["title"].Before)
+		assert.Equal("Senior Welder", changes["title"].After)
+		assert.Equal(float64(40000), changes["minsalary"].After)
+		assert.NotContains(changes, "category")
+
+		assert.Equal(audit.JobCreate, listed.Entries[1].Action)
+	}
+}
+
+func Test_Employer_GetAudit_Filters(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]string{"title": "Welder"})
+	sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]string{"title": "Fitter"})
+
+	tests := map[string]struct {
+		query  string
+		status int
+		count  int
+	}{
+		"All":         {"", http.StatusOK, 2},
+		"Action":      {"?action=job.edit", http.StatusOK, 0},
+		"Limit":       {"?limit=1", http.StatusOK, 1},
+		"BadLimit":    {"?limit=0", http.StatusBadRequest, 0},
+		"BadSince":    {"?since=yesterday", http.StatusBadRequest, 0},
+		"FutureSince": {"?since=2999-01-01T00:00:00Z", http.StatusOK, 0},
+		"OtherActor":  {"?actor=someone-else", http.StatusOK, 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/"+test.query, nil)
+			request.Header.Set("Authorization", token)
+			recorder := httptest.NewRecorder()
+
+			handler.GetAudit(recorder, request)
+
+			assert.Equal(test.status, recorder.Code)
+
+			if test.status == http.StatusOK {
+				var listed employers.AuditResponse
+				assert.Nil(json.NewDecoder(recorder.Body).Decode(&listed))
+				assert.Len(listed.Entries, test.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package memorytest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Employer_CreateJob_FieldErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]interface{}{"jobtype": "gig", "payperiod": "yearly", "minsalary": 50000, "maxsalary": 40000})
+	assert.Equal(http.StatusBadRequest, result.Code)
+
+	var body response.Problem
+	assert.Nil(json.NewDecoder(result.Body).Decode(&body))
+	assert.Equal(response.ProblemContentType, result.Header().Get("Content-Type"))
+	assert.Equal(response.CodeValidation, body.Code)
+
+	fields := map[string]string{}
+
+	for _, err := range body.Errors {
+		fields[err.Field] = err.Code
+	}
+
+	assert.Equal(map[string]string{"title": validation.Required, "jobtype": validation.OneOf, "minsalary": validation.Range}, fields)
+
+	result = sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]interface{}{"title": "Welder", "jobtitle": "Welder"})
+	assert.Equal(http.StatusBadRequest, result.Code)
+
+	body = response.Problem{}
+	assert.Nil(json.NewDecoder(result.Body).Decode(&body))
+
+	if assert.Len(body.Errors, 1) {
+		assert.Equal("jobtitle", body.Errors[0].Field)
+		assert.Equal(validation.Unknown, body.Errors[0].Code)
+	}
+}
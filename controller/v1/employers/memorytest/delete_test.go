@@ -0,0 +1,48 @@
+package memorytest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Employer_RestoreJob(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, store, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]string{"title": "Welder"})
+
+	var job jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+
+	result = sendAuthorized(t, handler.RestoreJob, http.MethodPost, token, map[string]string{"publicid": job.PublicID})
+	assert.Equal(http.StatusNotFound, result.Code)
+
+	result = sendAuthorized(t, handler.DeleteJob, http.MethodDelete, token, map[string]string{"publicid": job.PublicID})
+	assert.Equal(http.StatusOK, result.Code)
+
+	_, err := store.JobRepository().GetJob(job.PublicID)
+	assert.NotNil(err)
+
+	result = sendAuthorized(t, handler.RestoreJob, http.MethodPost, token, map[string]string{"publicid": job.PublicID})
+	assert.Equal(http.StatusOK, result.Code)
+
+	restored, err := store.JobRepository().GetJob(job.PublicID)
+
+	if assert.Nil(err) {
+		assert.Equal("Welder", restored.Title)
+	}
+
+	entries, err := store.AuditRepository().GetEntries(job.EmployerPublicID, &audit.Filter{TargetPublicID: job.PublicID})
+
+	if assert.Nil(err) && assert.Len(entries, 3) {
+		assert.Equal(audit.JobRestore, entries[0].Action)
+		assert.Equal(audit.JobDelete, entries[1].Action)
+	}
+}
@@ -0,0 +1,104 @@
+package memorytest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+	"autumnomous-jobs-employer-api/shared/services/zipcode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Employer_EditJob_ValidatesStoredFields(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, store, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]interface{}{"title": "Welder", "minsalary": 30000, "maxsalary": 40000})
+
+	var job jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+
+	// maxsalary is left out, but the stored one is still below minsalary
+	result = sendAuthorized(t, handler.EditJob, http.MethodPost, token, map[string]interface{}{"publicid": job.PublicID, "minsalary": 50000})
+	assert.Equal(http.StatusBadRequest, result.Code)
+
+	var problem response.Problem
+	assert.Nil(json.NewDecoder(result.Body).Decode(&problem))
+
+	if assert.Len(problem.Errors, 1) {
+		assert.Equal("minsalary", problem.Errors[0].Field)
+		assert.Equal(validation.Range, problem.Errors[0].Code)
+	}
+
+	stored, err := store.JobRepository().GetJob(job.PublicID)
+
+	if assert.Nil(err) {
+		assert.Equal(int64(30000), stored.MinSalary)
+	}
+
+	result = sendAuthorized(t, handler.EditJob, http.MethodPost, token, map[string]interface{}{"publicid": job.PublicID, "minsalary": 35000})
+	assert.Equal(http.StatusOK, result.Code)
+}
+
+func Test_Employer_EditJob_MalformedAuthorization(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, _ := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.EditJob, http.MethodPost, "Bearer", map[string]interface{}{"publicid": "job"})
+	assert.Equal(http.StatusUnauthorized, result.Code)
+	assert.Equal(response.ProblemContentType, result.Header().Get("Content-Type"))
+}
+
+func Test_Employer_EditJob_Location(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	geocoder, err := zipcode.ReadOffline(strings.NewReader(`zip,city,state,county,latitude,longitude
+15222,Pittsburgh,PA,Allegheny,40.4495,-79.9931
+85004,Phoenix,AZ,Maricopa,33.4513,-112.0686
+`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.Geocoder = geocoder
+
+	result := sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]interface{}{"title": "Welder", "zipcode": "15222"})
+
+	var job jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+	assert.Equal("Pittsburgh", job.City)
+
+	// a new zip code replaces the stored location, coordinates included
+	result = sendAuthorized(t, handler.EditJob, http.MethodPost, token, map[string]interface{}{"publicid": job.PublicID, "zipcode": "85004"})
+	assert.Equal(http.StatusOK, result.Code)
+
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+	assert.Equal("Phoenix", job.City)
+	assert.Equal(33.4513, job.Latitude)
+
+	// and other edits keep it
+	result = sendAuthorized(t, handler.EditJob, http.MethodPost, token, map[string]interface{}{"publicid": job.PublicID, "title": "Senior Welder"})
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+	assert.Equal("Phoenix", job.City)
+
+	result = sendAuthorized(t, handler.EditJob, http.MethodPost, token, map[string]interface{}{"publicid": job.PublicID, "zipcode": "99999"})
+	assert.Equal(http.StatusBadRequest, result.Code)
+
+	var problem response.Problem
+	assert.Nil(json.NewDecoder(result.Body).Decode(&problem))
+
+	if assert.Len(problem.Errors, 1) {
+		assert.Equal("zipcode", problem.Errors[0].Field)
+		assert.Equal(validation.Unresolved, problem.Errors[0].Code)
+	}
+}
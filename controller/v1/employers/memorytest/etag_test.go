@@ -0,0 +1,88 @@
+package memorytest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sendIfMatch(t *testing.T, handler http.HandlerFunc, token, ifMatch string, body interface{}) *httptest.ResponseRecorder {
+
+	requestBody, err := json.Marshal(body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+	request.Header.Set("Authorization", token)
+	request.Header.Set("If-Match", ifMatch)
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+
+	return recorder
+}
+
+func Test_Employer_EditJob_IfMatch(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]string{"title": "Welder"})
+
+	var job jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+
+	result = sendAuthorized(t, handler.GetJob, http.MethodPost, token, map[string]string{"publicid": job.PublicID})
+	assert.Equal(`"1"`, result.Header().Get("ETag"))
+
+	result = sendIfMatch(t, handler.EditJob, token, `"1"`, map[string]string{"publicid": job.PublicID, "title": "Senior Welder"})
+	assert.Equal(http.StatusOK, result.Code)
+	assert.Equal(`"2"`, result.Header().Get("ETag"))
+
+	// a teammate still holding revision 1 is told about the newer one
+	result = sendIfMatch(t, handler.EditJob, token, `"1"`, map[string]string{"publicid": job.PublicID, "title": "Lead Welder"})
+	assert.Equal(http.StatusPreconditionFailed, result.Code)
+	assert.Equal(`"2"`, result.Header().Get("ETag"))
+
+	var current jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&current))
+	assert.Equal("Senior Welder", current.Title)
+
+	result = sendIfMatch(t, handler.EditJob, token, `W/"2"`, map[string]string{"publicid": job.PublicID, "title": "Lead Welder"})
+	assert.Equal(http.StatusBadRequest, result.Code)
+
+	result = sendIfMatch(t, handler.EditJob, token, "*", map[string]string{"publicid": job.PublicID, "title": "Lead Welder"})
+	assert.Equal(http.StatusOK, result.Code)
+	assert.Equal(`"3"`, result.Header().Get("ETag"))
+}
+
+func Test_Employer_UpdateCompany_IfMatch(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.GetEmployerCompany, http.MethodGet, token, nil)
+	etag := result.Header().Get("ETag")
+	assert.Equal(`"1"`, etag)
+
+	result = sendIfMatch(t, handler.UpdateCompany, token, etag, map[string]string{"name": "First"})
+	assert.Equal(http.StatusOK, result.Code)
+	assert.Equal(`"2"`, result.Header().Get("ETag"))
+
+	result = sendIfMatch(t, handler.UpdateCompany, token, etag, map[string]string{"name": "Second"})
+	assert.Equal(http.StatusPreconditionFailed, result.Code)
+
+	var current companies.Company
+	assert.Nil(json.NewDecoder(result.Body).Decode(&current))
+	assert.Equal("First", current.Name)
+	assert.Equal(2, current.Version)
+}
@@ -0,0 +1,57 @@
+package memorytest_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Employer_GetJob_NotFound(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.GetJob, http.MethodPost, token, map[string]string{"publicid": "missing"})
+	assert.Equal(http.StatusNotFound, result.Code)
+	assert.Equal(response.ProblemContentType, result.Header().Get("Content-Type"))
+
+	var problem response.Problem
+	assert.Nil(json.NewDecoder(result.Body).Decode(&problem))
+	assert.Equal(jobs.ErrNotFound.Code, problem.Code)
+}
+
+func Test_Employer_GetJob_OtherEmployer(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, store, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]string{"title": "Welder"})
+
+	var job jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+
+	other, err := store.EmployerRepository().CreateEmployer("Other", "Last", "other@example.com", "password")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherToken, err := jwt.GenerateToken(other.PublicID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result = sendAuthorized(t, handler.GetJob, http.MethodPost, "Bearer "+base64.StdEncoding.EncodeToString([]byte(otherToken)), map[string]string{"publicid": job.PublicID})
+	assert.Equal(http.StatusNotFound, result.Code)
+
+	result = sendAuthorized(t, handler.GetJob, http.MethodPost, token, map[string]string{"publicid": job.PublicID})
+	assert.Equal(http.StatusOK, result.Code)
+}
@@ -0,0 +1,68 @@
+package memorytest_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autumnomous-jobs-employer-api/controller/v1/employers"
+	"autumnomous-jobs-employer-api/shared/repository/memory"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func init() {
+	jwt.SetSigningKey("v1-test")
+}
+
+// newMemoryHandler returns a Handler over an in-memory app, with a token for
+// an employer whose company is set up
+func newMemoryHandler(t *testing.T) (*employers.Handler, *memory.Store, string) {
+
+	application, store := testhelper.NewMemoryApp(&testhelper.Mailer{})
+
+	employer, err := store.EmployerRepository().CreateEmployer("First", "Last", "employer@example.com", "password")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	company, err := store.CompanyRepository().GetOrCreateCompany("example.com", "Company", "", "", "", "", "", "", "", "", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.EmployerRepository().SetEmployerCompany(employer.PublicID, company.PublicID); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.GenerateToken(employer.PublicID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return employers.NewHandler(application), store, "Bearer " + base64.StdEncoding.EncodeToString([]byte(token))
+}
+
+func sendAuthorized(t *testing.T, handler http.HandlerFunc, method, token string, body interface{}) *httptest.ResponseRecorder {
+
+	requestBody, err := json.Marshal(body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(method, "/", bytes.NewBuffer(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", token)
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+
+	return recorder
+}
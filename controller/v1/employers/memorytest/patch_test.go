@@ -0,0 +1,126 @@
+package memorytest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/mergepatch"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sendPatch(handler http.HandlerFunc, token, target, patch string, headers map[string]string) *httptest.ResponseRecorder {
+
+	request := httptest.NewRequest(http.MethodPatch, target, bytes.NewBufferString(patch))
+	request.Header.Set("Authorization", token)
+	request.Header.Set("Content-Type", mergepatch.ContentType)
+
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+
+	return recorder
+}
+
+func Test_Employer_PatchAccount(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, store, token := newMemoryHandler(t)
+
+	result := sendPatch(handler.PatchAccount, token, "/", `{"phonenumber":"555-0100","facebook":"fb","twitter":"tw"}`, nil)
+	assert.Equal(http.StatusOK, result.Code)
+
+	result = sendPatch(handler.PatchAccount, token, "/", `{"phonenumber":null,"twitter":"tw2"}`, nil)
+	assert.Equal(http.StatusOK, result.Code)
+
+	var employer accountmanagement.Employer
+	assert.Nil(json.NewDecoder(result.Body).Decode(&employer))
+	assert.Equal("", employer.PhoneNumber)
+	assert.Equal("fb", employer.Facebook, "omitted fields are unchanged")
+	assert.Equal("tw2", employer.Twitter)
+	assert.Equal("First", employer.FirstName)
+
+	stored, err := store.EmployerRepository().GetEmployer(employer.PublicID)
+	assert.Nil(err)
+	assert.Equal("", stored.PhoneNumber)
+
+	result = sendPatch(handler.PatchAccount, token, "/", `{"email":null,"twitter":"tw3"}`, nil)
+	assert.Equal(http.StatusBadRequest, result.Code)
+
+	var invalid response.Problem
+	assert.Nil(json.NewDecoder(result.Body).Decode(&invalid))
+
+	if assert.Len(invalid.Errors, 1) {
+		assert.Equal("email", invalid.Errors[0].Field)
+		assert.Equal(validation.Required, invalid.Errors[0].Code)
+	}
+
+	assert.Equal(http.StatusBadRequest, sendPatch(handler.PatchAccount, token, "/", `{"colour":"red"}`, nil).Code)
+	assert.Equal(http.StatusBadRequest, sendPatch(handler.PatchAccount, token, "/", `["email"]`, nil).Code)
+	assert.Equal(http.StatusBadRequest, sendPatch(handler.PatchAccount, token, "/", `{"role":7}`, nil).Code)
+	assert.Equal(http.StatusUnsupportedMediaType, sendPatch(handler.PatchAccount, token, "/", `{}`, map[string]string{"Content-Type": "text/plain"}).Code)
+}
+
+func Test_Employer_PatchCompany(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	result := sendPatch(handler.PatchCompany, token, "/", `{"logo":"logo.png","longitude":-79.9}`, nil)
+	assert.Equal(http.StatusOK, result.Code)
+	assert.Equal(`"2"`, result.Header().Get("ETag"))
+
+	result = sendPatch(handler.PatchCompany, token, "/", `{"logo":null,"longitude":0}`, map[string]string{"If-Match": `"2"`})
+	assert.Equal(http.StatusOK, result.Code)
+
+	var company companies.Company
+	assert.Nil(json.NewDecoder(result.Body).Decode(&company))
+	assert.Equal("", company.Logo)
+	assert.Equal(float64(0), company.Longitude)
+	assert.Equal("Company", company.Name)
+
+	result = sendPatch(handler.PatchCompany, token, "/", `{"logo":"stale.png"}`, map[string]string{"If-Match": `"2"`})
+	assert.Equal(http.StatusPreconditionFailed, result.Code)
+	assert.Equal(`"3"`, result.Header().Get("ETag"))
+
+	assert.Equal(http.StatusBadRequest, sendPatch(handler.PatchCompany, token, "/", `{"name":null}`, nil).Code)
+}
+
+func Test_Employer_PatchJob(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]interface{}{"title": "Welder", "category": "trades", "minsalary": 40000, "remote": true})
+
+	var job jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+
+	result = sendPatch(handler.PatchJob, token, "/?job="+job.PublicID, `{"minsalary":0,"category":null,"description":"Weld things"}`, nil)
+	assert.Equal(http.StatusOK, result.Code)
+
+	var patched jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&patched))
+	assert.Equal(int64(0), patched.MinSalary)
+	assert.Equal("", patched.Category)
+	assert.Equal("Weld things", patched.Description)
+	assert.Equal("Welder", patched.Title)
+	assert.True(patched.Remote, "omitted fields are unchanged")
+	assert.Equal(`"2"`, result.Header().Get("ETag"))
+
+	assert.Equal(http.StatusBadRequest, sendPatch(handler.PatchJob, token, "/?job="+job.PublicID, `{"title":null}`, nil).Code)
+	assert.Equal(http.StatusPreconditionFailed, sendPatch(handler.PatchJob, token, "/?job="+job.PublicID, `{"remote":false}`, map[string]string{"If-Match": `"1"`}).Code)
+	assert.Equal(http.StatusNotFound, sendPatch(handler.PatchJob, token, "/?job=missing", `{}`, nil).Code)
+	assert.Equal(http.StatusMethodNotAllowed, sendAuthorized(t, handler.PatchJob, http.MethodPost, token, nil).Code)
+}
@@ -0,0 +1,97 @@
+package memorytest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autumnomous-jobs-employer-api/controller/v1/employers"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/response"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Employer_JobRevisions(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateJob, http.MethodPost, token, map[string]string{"title": "Welder", "payperiod": "yearly"})
+
+	var job jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+
+	sendAuthorized(t, handler.EditJob, http.MethodPost, token, map[string]interface{}{"publicid": job.PublicID, "title": "Senior Welder", "minsalary": 40000})
+
+	get := func(handlerFunc http.HandlerFunc, query string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/"+query, nil)
+		request.Header.Set("Authorization", token)
+		recorder := httptest.NewRecorder()
+
+		handlerFunc(recorder, request)
+
+		return recorder
+	}
+
+	recorder := get(handler.GetJobRevisions, "?job="+job.PublicID)
+	assert.Equal(http.StatusOK, recorder.Code)
+
+	var listed employers.RevisionsResponse
+	assert.Nil(json.NewDecoder(recorder.Body).Decode(&listed))
+
+	if assert.Len(listed.Revisions, 2) {
+		assert.Equal(2, listed.Revisions[0].Number)
+		assert.Equal("Senior Welder", listed.Revisions[0].Content.Title)
+	}
+
+	recorder = get(handler.GetJobRevisionDiff, fmt.Sprintf("?job=%s&from=1&to=2", job.PublicID))
+	assert.Equal(http.StatusOK, recorder.Code)
+
+	var diff employers.RevisionDiffResponse
+	assert.Nil(json.NewDecoder(recorder.Body).Decode(&diff))
+
+	var changes map[string]audit.Change
+	assert.Nil(json.Unmarshal(diff.Changes, &changes))
+	assert.Equal("Welder", changes["title"].Before)
+	assert.Equal("Senior Welder", changes["title"].After)
+	assert.NotContains(changes, "payperiod")
+
+	recorder = get(handler.GetJobRevisionDiff, fmt.Sprintf("?job=%s&from=1&to=9", job.PublicID))
+	assert.Equal(http.StatusNotFound, recorder.Code)
+
+	var problem response.Problem
+	assert.Nil(json.NewDecoder(recorder.Body).Decode(&problem))
+	assert.Equal(jobs.ErrRevisionNotFound.Code, problem.Code)
+
+	assert.Equal(http.StatusBadRequest, get(handler.GetJobRevisionDiff, "?job="+job.PublicID).Code)
+
+	recorder = get(handler.GetJobRevisions, "?job=missing")
+	assert.Equal(http.StatusNotFound, recorder.Code)
+	assert.Equal(response.ProblemContentType, recorder.Header().Get("Content-Type"))
+
+	problem = response.Problem{}
+	assert.Nil(json.NewDecoder(recorder.Body).Decode(&problem))
+	assert.Equal(jobs.ErrNotFound.Code, problem.Code)
+	assert.Equal(http.StatusNotFound, problem.Status)
+
+	result = sendAuthorized(t, handler.RollbackJob, http.MethodPost, token, employers.RollbackJobDetails{PublicID: job.PublicID, Revision: 1})
+	assert.Equal(http.StatusOK, result.Code)
+
+	var rolledBack jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&rolledBack))
+	assert.Equal("Welder", rolledBack.Title)
+	assert.Equal(3, rolledBack.Revision)
+
+	result = sendAuthorized(t, handler.RollbackJob, http.MethodPost, token, employers.RollbackJobDetails{PublicID: job.PublicID, Revision: 7})
+	assert.Equal(http.StatusNotFound, result.Code)
+
+	recorder = get(handler.GetAudit, "?action="+audit.JobRollback)
+
+	var entries employers.AuditResponse
+	assert.Nil(json.NewDecoder(recorder.Body).Decode(&entries))
+	assert.Len(entries.Entries, 1)
+}
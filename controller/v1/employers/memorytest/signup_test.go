@@ -0,0 +1,132 @@
+package memorytest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autumnomous-jobs-employer-api/controller/v1/employers"
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/services/messaging/email"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Employer_SignUp_CorrectData(t *testing.T) {
+
+	assert := assert.New(t)
+
+	mailer := &testhelper.Mailer{}
+	application, _ := testhelper.NewMemoryApp(mailer)
+
+	ts := httptest.NewServer(http.HandlerFunc(employers.NewHandler(application).SignUp))
+
+	defer ts.Close()
+
+	address := testhelper.Helper_RandomEmail("email")
+
+	response := signUp(t, ts.URL, address)
+
+	assert.Equal(http.StatusOK, response.StatusCode)
+
+	// nothing is sent until the relay runs
+	assert.Equal(0, len(mailer.Messages))
+
+	pending, err := application.Queue.GetTasks(queue.Pending, 10)
+
+	assert.Nil(err)
+
+	if assert.Equal(1, len(pending)) {
+		assert.Equal(email.Kind, pending[0].Kind)
+	}
+
+	performed, err := application.Worker().Work(context.Background(), 10)
+
+	assert.Nil(err)
+	assert.Equal(1, performed)
+
+	if assert.Equal(1, len(mailer.Messages)) {
+		assert.Equal(address, mailer.Messages[0].To)
+	}
+
+	pending, err = application.Queue.GetTasks(queue.Pending, 10)
+
+	assert.Nil(err)
+	assert.Equal(0, len(pending))
+}
+
+func Test_Employer_SignUp_RollsBack(t *testing.T) {
+
+	assert := assert.New(t)
+
+	application, _ := testhelper.NewMemoryApp(&testhelper.Mailer{})
+	unit := application.UnitOfWork
+
+	// the welcome email cannot be queued, after the account and company were written
+	application.UnitOfWork = failingQueueUnit{unit}
+
+	ts := httptest.NewServer(http.HandlerFunc(employers.NewHandler(application).SignUp))
+
+	defer ts.Close()
+
+	address := testhelper.Helper_RandomEmail("email")
+
+	response := signUp(t, ts.URL, address)
+
+	assert.Equal(http.StatusInternalServerError, response.StatusCode)
+
+	// no half-created account is left behind to block a retry
+	application.UnitOfWork = unit
+
+	response = signUp(t, ts.URL, address)
+
+	assert.Equal(http.StatusOK, response.StatusCode)
+}
+
+func signUp(t *testing.T, url, address string) *http.Response {
+
+	data, err := json.Marshal(map[string]string{
+		"firstname": "First",
+		"lastname":  "Last",
+		"email":     address,
+	})
+
+	if err != nil {
+		t.Fatal()
+	}
+
+	response, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response.Body.Close()
+
+	return response
+}
+
+type failingQueueUnit struct {
+	transaction.UnitOfWork
+}
+
+func (unit failingQueueUnit) Do(ctx context.Context, fn func(repositories *transaction.Repositories) error) error {
+	return unit.UnitOfWork.Do(ctx, func(repositories *transaction.Repositories) error {
+		repositories.Queue = failingQueue{repositories.Queue}
+		return fn(repositories)
+	})
+}
+
+type failingQueue struct {
+	queue.QueueRepository
+}
+
+func (failingQueue) Enqueue(kind string, payload interface{}) (*queue.Task, error) {
+	return nil, errors.New("queue unavailable")
+}
@@ -0,0 +1,126 @@
+package memorytest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autumnomous-jobs-employer-api/controller/v1/employers"
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+	"autumnomous-jobs-employer-api/shared/services/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Employer_CreateWebhook_IncorrectData(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	tests := map[string]employers.CreateWebhookDetails{
+		"NoURL":        {Events: []string{webhook.JobLive}},
+		"NoEvents":     {URL: "https://example.com/hooks"},
+		"RelativeURL":  {URL: "/hooks", Events: []string{webhook.JobLive}},
+		"UnknownEvent": {URL: "https://example.com/hooks", Events: []string{"job.sold"}},
+	}
+
+	for name, details := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := sendAuthorized(t, handler.CreateWebhook, http.MethodPost, token, details)
+			assert.Equal(http.StatusBadRequest, result.Code)
+		})
+	}
+}
+
+func Test_Employer_CreateWebhook_CorrectData(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, _, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateWebhook, http.MethodPost, token, employers.CreateWebhookDetails{
+		URL:    "https://example.com/hooks",
+		Events: []string{webhook.JobLive, webhook.ApplicationCreated},
+	})
+
+	assert.Equal(http.StatusOK, result.Code)
+
+	var created webhooks.Webhook
+	assert.Nil(json.NewDecoder(result.Body).Decode(&created))
+	assert.NotEmpty(created.PublicID)
+	assert.Contains(created.Secret, "whsec_")
+
+	result = sendAuthorized(t, handler.GetWebhooks, http.MethodGet, token, nil)
+
+	assert.Equal(http.StatusOK, result.Code)
+
+	var listed employers.WebhooksResponse
+	assert.Nil(json.NewDecoder(result.Body).Decode(&listed))
+
+	if assert.Len(listed.Webhooks, 1) {
+		assert.Equal(created.PublicID, listed.Webhooks[0].PublicID)
+		assert.Empty(listed.Webhooks[0].Secret)
+	}
+}
+
+func Test_Employer_PingWebhook(t *testing.T) {
+	assert := assert.New(t)
+
+	var event string
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event = r.Header.Get("X-Webhook-Event")
+	}))
+
+	defer receiver.Close()
+
+	handler, _, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateWebhook, http.MethodPost, token, employers.CreateWebhookDetails{URL: receiver.URL, Events: []string{webhook.JobLive}})
+
+	var created webhooks.Webhook
+	assert.Nil(json.NewDecoder(result.Body).Decode(&created))
+
+	result = sendAuthorized(t, handler.PingWebhook, http.MethodPost, token, employers.WebhookDetails{PublicID: created.PublicID})
+
+	assert.Equal(http.StatusOK, result.Code)
+	assert.Equal(webhook.Ping, event)
+
+	var delivery webhooks.Delivery
+	assert.Nil(json.NewDecoder(result.Body).Decode(&delivery))
+	assert.Equal(webhooks.Delivered, delivery.Status)
+
+	result = sendAuthorized(t, handler.PingWebhook, http.MethodPost, token, employers.WebhookDetails{PublicID: "missing"})
+	assert.Equal(http.StatusNotFound, result.Code)
+}
+
+func Test_Employer_RedeliverWebhook(t *testing.T) {
+	assert := assert.New(t)
+
+	handler, store, token := newMemoryHandler(t)
+
+	result := sendAuthorized(t, handler.CreateWebhook, http.MethodPost, token, employers.CreateWebhookDetails{URL: "https://example.com/hooks", Events: []string{webhook.JobLive}})
+
+	var created webhooks.Webhook
+	assert.Nil(json.NewDecoder(result.Body).Decode(&created))
+
+	original, err := store.WebhookRepository().CreateDelivery(created.PublicID, webhook.JobLive, json.RawMessage(`{"event":"job.live"}`))
+	assert.Nil(err)
+
+	result = sendAuthorized(t, handler.RedeliverWebhook, http.MethodPost, token, employers.WebhookDetails{PublicID: original.PublicID})
+
+	assert.Equal(http.StatusOK, result.Code)
+
+	var delivery webhooks.Delivery
+	assert.Nil(json.NewDecoder(result.Body).Decode(&delivery))
+	assert.NotEqual(original.PublicID, delivery.PublicID)
+	assert.JSONEq(string(original.Payload), string(delivery.Payload))
+
+	tasks, err := store.QueueRepository().GetTasks(queue.Pending, 10)
+	assert.Nil(err)
+
+	if assert.Len(tasks, 1) {
+		assert.Equal(webhook.DeliverKind, tasks[0].Kind)
+	}
+}
@@ -0,0 +1,359 @@
+package employers
+
+import (
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/mergepatch"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+)
+
+// patchAttempts is how many times a PATCH without If-Match is applied when
+// another write keeps changing the resource between reading and saving it
+const patchAttempts = 3
+
+// readMergePatch reads an RFC 7396 merge patch. application/json is accepted
+// as well, for clients that cannot set the media type.
+func readMergePatch(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+
+	if r.Method != http.MethodPatch {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return nil, false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if err != nil || mediaType != mergepatch.ContentType && mediaType != "application/json" {
+		response.SendProblem(w, http.StatusUnsupportedMediaType, response.CodeUnsupportedMediaType, "Send a JSON merge patch as "+mergepatch.ContentType)
+		return nil, false
+	}
+
+	patch, err := ioutil.ReadAll(r.Body)
+
+	if err != nil {
+		response.SendProblem(w, http.StatusBadRequest, response.CodeBadRequest, "The request body could not be read")
+		return nil, false
+	}
+
+	return patch, true
+}
+
+// applyPatch merges patch into value and validates the result. Failures are
+// returned as validation.Errors, so a field can't be patched into a state a
+// full update would refuse.
+func applyPatch(value interface{}, patch []byte) error {
+
+	err := mergepatch.Patch(value, patch)
+
+	if err == mergepatch.ErrNotObject {
+		return validation.Errors{{Code: validation.Malformed, Message: err.Error()}}
+	}
+
+	if err != nil {
+		return validation.DecodeErrors(err)
+	}
+
+	if errs := validation.Validate(value); errs != nil {
+		return errs
+	}
+
+	return nil
+}
+
+// sendPatchError responds to the errors shared by the PATCH handlers, and
+// reports whether err was one of them
+func sendPatchError(w http.ResponseWriter, err error) bool {
+
+	var invalid validation.Errors
+
+	switch {
+	case errors.As(err, &invalid):
+		response.SendValidationErrors(w, invalid)
+	case err != nil:
+		response.SendError(w, err)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// PatchAccount applies a JSON merge patch to the employer's account. Omitted
+// fields are unchanged and null clears a field.
+func (h *Handler) PatchAccount(w http.ResponseWriter, r *http.Request) {
+
+	patch, ok := readMergePatch(w, r)
+
+	if !ok {
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var employer *accountmanagement.Employer
+
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		before, err := tx.Employers.GetEmployer(publicID)
+
+		if err != nil {
+			return err
+		}
+
+		account := before.Account()
+
+		if err := applyPatch(&account, patch); err != nil {
+			return err
+		}
+
+		employer, err = tx.Employers.ReplaceEmployerAccount(publicID, account)
+
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.EmployerAccount, audit.Employer, publicID, before, employer)
+	})
+
+	if sendPatchError(w, err) {
+		return
+	}
+
+	response.SendJSON(w, employer)
+}
+
+// PatchCompany applies a JSON merge patch to the employer's company. Omitted
+// fields are unchanged and null clears a field. With an If-Match header it
+// only saves over the version in the ETag, and otherwise responds 412 with
+// the current company.
+func (h *Handler) PatchCompany(w http.ResponseWriter, r *http.Request) {
+
+	patch, ok := readMergePatch(w, r)
+
+	if !ok {
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	version, err := ifMatch(r)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	var company *companies.Company
+
+	for attempt := 1; ; attempt++ {
+
+		var before *companies.Company
+		var profile companies.Profile
+
+		before, profile, err = h.patchedCompany(publicID, patch, version)
+
+		if err == nil {
+			err = h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+				var err error
+
+				// the patch was applied to before, so nothing may change in between
+				company, err = tx.Employers.ReplaceEmployerCompany(publicID, profile, before.Version)
+
+				if err != nil {
+					return err
+				}
+
+				if err := retainLogo(tx, before, company); err != nil {
+					return err
+				}
+
+				return recordAudit(tx, r, publicID, audit.CompanyUpdate, audit.Company, company.PublicID, before, company)
+			})
+		}
+
+		if err != companies.ErrStale || version != 0 || attempt == patchAttempts {
+			break
+		}
+	}
+
+	if err == companies.ErrStale {
+		current, err := h.Employers.GetEmployerCompany(publicID)
+
+		if err != nil {
+			response.SendError(w, err)
+			return
+		}
+
+		sendVersioned(w, http.StatusPreconditionFailed, current.Version, current)
+		return
+	}
+
+	if sendPatchError(w, err) {
+		return
+	}
+
+	sendVersioned(w, http.StatusOK, company.Version, company)
+}
+
+// PatchJob applies a JSON merge patch to the job ?job=. Omitted fields are
+// unchanged and null clears a field. With an If-Match header it only saves
+// over the revision in the ETag, and otherwise responds 412 with the current
+// job.
+func (h *Handler) PatchJob(w http.ResponseWriter, r *http.Request) {
+	h.PatchJobByID(w, r, r.URL.Query().Get("job"))
+}
+
+// PatchJobByID is PatchJob for the job jobPublicID, shared with the v2 routes
+func (h *Handler) PatchJobByID(w http.ResponseWriter, r *http.Request, jobPublicID string) {
+
+	patch, ok := readMergePatch(w, r)
+
+	if !ok {
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	if jobPublicID == "" {
+		response.SendValidationErrors(w, validation.Errors{{Field: "job", Code: validation.Required, Message: "job is required"}})
+		return
+	}
+
+	revision, err := ifMatch(r)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	var job *jobs.Job
+
+	for attempt := 1; ; attempt++ {
+
+		var before *jobs.Job
+		var content jobs.RevisionContent
+
+		before, content, err = h.patchedJob(publicID, jobPublicID, patch, revision)
+
+		if err == nil {
+			err = h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+				var err error
+
+				// the patch was applied to before, so nothing may change in between
+				job, err = tx.Jobs.ReplaceJob(publicID, jobPublicID, content, before.Revision)
+
+				if err != nil {
+					return err
+				}
+
+				return recordAudit(tx, r, publicID, audit.JobEdit, audit.Job, jobPublicID, before, job)
+			})
+		}
+
+		if err != jobs.ErrStale || revision != 0 || attempt == patchAttempts {
+			break
+		}
+	}
+
+	if err == jobs.ErrStale {
+		current, err := h.Jobs.GetJob(jobPublicID)
+
+		if err != nil {
+			response.SendError(w, err)
+			return
+		}
+
+		sendVersioned(w, http.StatusPreconditionFailed, current.Revision, current)
+		return
+	}
+
+	if sendPatchError(w, err) {
+		return
+	}
+
+	sendVersioned(w, http.StatusOK, job.Revision, job)
+}
+
+// patchedCompany reads the employer's company and returns it with its
+// profile after patch. The location is resolved here, before any
+// transaction, as the geocoder may be slow.
+func (h *Handler) patchedCompany(publicID string, patch []byte, version int) (*companies.Company, companies.Profile, error) {
+
+	before, err := h.Employers.GetEmployerCompany(publicID)
+
+	if err != nil {
+		return nil, companies.Profile{}, err
+	}
+
+	if version != 0 && before.Version != version {
+		return nil, companies.Profile{}, companies.ErrStale
+	}
+
+	profile := before.Profile()
+
+	if err := applyPatch(&profile, patch); err != nil {
+		return nil, companies.Profile{}, err
+	}
+
+	if err := h.locateCompany(&profile, before); err != nil {
+		return nil, companies.Profile{}, err
+	}
+
+	return before, profile, nil
+}
+
+// patchedJob is patchedCompany for the employer's job jobPublicID
+func (h *Handler) patchedJob(publicID, jobPublicID string, patch []byte, revision int) (*jobs.Job, jobs.RevisionContent, error) {
+
+	before, err := h.Jobs.GetJob(jobPublicID)
+
+	if err != nil {
+		return nil, jobs.RevisionContent{}, err
+	}
+
+	if before.EmployerPublicID != publicID {
+		return nil, jobs.RevisionContent{}, jobs.ErrNotFound
+	}
+
+	if revision != 0 && before.Revision != revision {
+		return nil, jobs.RevisionContent{}, jobs.ErrStale
+	}
+
+	content := before.Content()
+
+	if err := applyPatch(&content, patch); err != nil {
+		return nil, jobs.RevisionContent{}, err
+	}
+
+	if err := h.locateJob(&content, before); err != nil {
+		return nil, jobs.RevisionContent{}, err
+	}
+
+	return before, content, nil
+}
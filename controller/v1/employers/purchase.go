@@ -1,67 +1,69 @@
 package employers
 
 import (
-	"encoding/json"
-	"log"
 	"net/http"
 
-	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
-	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
 	"autumnomous-jobs-employer-api/shared/response"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
 )
 
-type purchaseJobPackageDetails struct {
-	JobPackage string `json:"jobpackage"`
+type PurchaseJobPackageDetails struct {
+	JobPackage string `json:"jobpackage" validate:"required"`
 }
 
-func PurchaseJobPackage(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) PurchaseJobPackage(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
-	var jobDetails purchaseJobPackageDetails
+	var jobDetails PurchaseJobPackageDetails
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.Decode(&jobDetails)
-
-	if jobDetails.JobPackage == "" {
-
-		response.SendJSONMessage(w, http.StatusBadRequest, response.MissingRequiredValue)
+	if errs := validation.Decode(r.Body, &jobDetails); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
 	publicID := jwt.GetUserClaim(r)
 
-	repository := jobpackages.NewJobPackageRegistry().GetJobPackageRepository()
+	repository := h.JobPackages
 
 	// TODO: stripe payment
 
 	jobPackage, err := repository.GetJobPackage(jobDetails.JobPackage)
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
-	jobRepository := jobs.NewJobRegistry().GetJobRepository()
+	err = h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		var created []string
+
+		for i := 0; i < jobPackage.NumberOfJobs; i++ {
 
-	for i := 0; i < jobPackage.NumberOfJobs; i++ {
-		// TODO: jobDetails.PostEndDatetime = jobDetails.PostStartDatetime + 30 days
+			job, err := tx.Jobs.EmployerCreateJob(publicID, "Edit", "", "", "", "", "", false, 0, 0)
 
-		_, err := jobRepository.EmployerCreateJob(publicID, "Edit", "", "", "", "", "", false, 0, 0)
+			if err != nil {
+				return err
+			}
 
-		if err != nil {
-			log.Println(err)
-			response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
-			return
+			created = append(created, job.PublicID)
 		}
 
+		return recordAudit(tx, r, publicID, audit.JobPackagePurchase, audit.JobPackage, jobPackage.TypeID, nil, map[string]interface{}{"jobpackage": jobPackage.TypeID, "jobs": created})
+	})
+
+	if err != nil {
+		response.SendError(w, err)
+		return
 	}
 
-	response.SendJSONMessage(w, http.StatusOK, "success")
+	response.SendJSONMessage(w, http.StatusOK, response.Success)
 
 }
@@ -1,7 +1,6 @@
 package employers_test
 
 import (
-	"autumnomous-jobs-employer-api/controller/v1/employers"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
 	"autumnomous-jobs-employer-api/shared/testhelper"
 	"bytes"
@@ -18,7 +17,7 @@ func Test_Employer_PurchaseJobPackage_IncorrectMethod(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.PurchaseJobPackage))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().PurchaseJobPackage))
 
 	defer ts.Close()
 
@@ -48,7 +47,7 @@ func Test_Employer_PurchaseJobPackage_IncorrectData(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.PurchaseJobPackage))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().PurchaseJobPackage))
 
 	defer ts.Close()
 
@@ -85,7 +84,7 @@ func Test_Employer_PurchaseJobPackage_IncorrectData(t *testing.T) {
 
 func Test_Employer_PurchaseJobPackage_CorrectData(t *testing.T) {
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.PurchaseJobPackage))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().PurchaseJobPackage))
 
 	defer ts.Close()
 
@@ -0,0 +1,223 @@
+package employers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+)
+
+type RollbackJobDetails struct {
+	PublicID string `json:"publicid" validate:"required"`
+	Revision int    `json:"revision" validate:"required,min=1"`
+}
+
+// RollbackDetails names the revision a v2 rollback restores
+type RollbackDetails struct {
+	Revision int `json:"revision" validate:"required,min=1"`
+}
+
+type RevisionsResponse struct {
+	Revisions []*jobs.Revision `json:"revisions"`
+}
+
+// RevisionDiffResponse maps each field that differs between two revisions to
+// its value in From and To
+type RevisionDiffResponse struct {
+	From    int             `json:"from"`
+	To      int             `json:"to"`
+	Changes json.RawMessage `json:"changes"`
+}
+
+// GetJobRevisions lists the revisions of ?job=, newest first
+func (h *Handler) GetJobRevisions(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	jobPublicID := r.URL.Query().Get("job")
+
+	if jobPublicID == "" {
+		response.SendValidationErrors(w, validation.Errors{{Field: "job", Code: validation.Required, Message: "job is required"}})
+		return
+	}
+
+	h.GetJobRevisionsByID(w, r, jobPublicID)
+}
+
+// GetJobRevisionsByID is GetJobRevisions for the job jobPublicID, shared with
+// the v2 routes
+func (h *Handler) GetJobRevisionsByID(w http.ResponseWriter, r *http.Request, jobPublicID string) {
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	revisions, err := h.Jobs.GetJobRevisions(publicID, jobPublicID)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, RevisionsResponse{Revisions: revisions})
+}
+
+// GetJobRevisionDiff returns the fields of ?job= that changed between
+// revisions ?from= and ?to=
+func (h *Handler) GetJobRevisionDiff(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	jobPublicID := r.URL.Query().Get("job")
+
+	if jobPublicID == "" {
+		response.SendValidationErrors(w, validation.Errors{{Field: "job", Code: validation.Required, Message: "job is required"}})
+		return
+	}
+
+	h.GetJobRevisionDiffByID(w, r, jobPublicID)
+}
+
+// GetJobRevisionDiffByID is GetJobRevisionDiff for the job jobPublicID,
+// shared with the v2 routes
+func (h *Handler) GetJobRevisionDiffByID(w http.ResponseWriter, r *http.Request, jobPublicID string) {
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	from, fromErr := strconv.Atoi(query.Get("from"))
+	to, toErr := strconv.Atoi(query.Get("to"))
+
+	var errs validation.Errors
+
+	if fromErr != nil {
+		errs = append(errs, &validation.FieldError{Field: "from", Code: validation.WrongType, Message: "from must be a revision number"})
+	}
+
+	if toErr != nil {
+		errs = append(errs, &validation.FieldError{Field: "to", Code: validation.WrongType, Message: "to must be a revision number"})
+	}
+
+	if errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	var revisions [2]*jobs.Revision
+
+	for i, number := range []int{from, to} {
+		revision, err := h.Jobs.GetJobRevision(publicID, jobPublicID, number)
+
+		if err != nil {
+			response.SendError(w, err)
+			return
+		}
+
+		revisions[i] = revision
+	}
+
+	changes, err := audit.Diff(revisions[0].Content, revisions[1].Content)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, RevisionDiffResponse{From: from, To: to, Changes: changes})
+}
+
+// RollbackJob restores the content of an earlier revision of a job. The
+// rollback is saved as a new revision.
+func (h *Handler) RollbackJob(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var details RollbackJobDetails
+
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	h.rollbackJob(w, r, publicID, details.PublicID, details.Revision)
+}
+
+// RollbackJobByID is RollbackJob for the job jobPublicID, shared with the v2
+// routes
+func (h *Handler) RollbackJobByID(w http.ResponseWriter, r *http.Request, jobPublicID string) {
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var details RollbackDetails
+
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	h.rollbackJob(w, r, publicID, jobPublicID, details.Revision)
+}
+
+func (h *Handler) rollbackJob(w http.ResponseWriter, r *http.Request, publicID, jobPublicID string, revision int) {
+
+	var job *jobs.Job
+
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		before, err := tx.Jobs.GetJob(jobPublicID)
+
+		if err != nil {
+			return err
+		}
+
+		job, err = tx.Jobs.RollbackJob(publicID, jobPublicID, revision)
+
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.JobRollback, audit.Job, job.PublicID, before, job)
+	})
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, job)
+}
@@ -1,115 +1,86 @@
 package employers
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"log"
 	"net/http"
-	"os"
 	"strings"
-	"time"
 
-	"autumnomous-jobs-employer-api/shared/repository/companies"
-	employers "autumnomous-jobs-employer-api/shared/repository/employers"
-	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
 	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/messaging/email"
 	"autumnomous-jobs-employer-api/shared/services/security/encryption"
-
-	mailgun "github.com/mailgun/mailgun-go/v4"
+	"autumnomous-jobs-employer-api/shared/services/validation"
 )
 
 type SignUpCredentials struct {
-	FirstName string `json:"firstname"`
-	LastName  string `json:"lastname"`
-	Email     string `json:"email"`
+	FirstName string `json:"firstname" validate:"required,max=100"`
+	LastName  string `json:"lastname" validate:"required,max=100"`
+	Email     string `json:"email" validate:"required,email,max=254"`
 }
 
-func SignUp(w http.ResponseWriter, r *http.Request) {
+// SignUp creates an employer account and queues an email with their
+// temporary password
+func (h *Handler) SignUp(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
 	var credentials SignUpCredentials
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&credentials)
-
-	if err != nil {
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
-		return
-	}
 
-	if credentials.FirstName == "" || credentials.LastName == "" || credentials.Email == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.MissingRequiredValue)
+	if errs := validation.Decode(r.Body, &credentials); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
-
 	password := encryption.GeneratePassword(9)
 	hashedPassword, err := encryption.HashPassword([]byte(password))
 
 	if err != nil {
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
-	employer, err := repository.CreateEmployer(credentials.FirstName, credentials.LastName, credentials.Email, string(hashedPassword))
+	companyDomain := strings.SplitN(credentials.Email, "@", 2)[1]
 
-	if err != nil {
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
-		return
-	}
+	// The account, its company and the welcome email are written together;
+	// the email is sent by the worker once this has committed
+	err = h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
 
-	companyRepository := companies.NewCompanyRegistry().GetCompanyRepository()
+		employer, err := tx.Employers.CreateEmployer(credentials.FirstName, credentials.LastName, credentials.Email, string(hashedPassword))
 
-	companyDomain := strings.SplitN(credentials.Email, "@", 2)[1]
+		if err != nil {
+			return err
+		}
 
-	company, err := companyRepository.GetOrCreateCompany(companyDomain, "", "", "", "", "", "", "", "", "", "")
+		company, err := tx.Companies.GetOrCreateCompany(companyDomain, "", "", "", "", "", "", "", "", "", "")
 
-	if err != nil {
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
-		return
-	}
+		if err != nil {
+			return err
+		}
 
-	err = repository.SetEmployerCompany(employer.PublicID, company.PublicID)
+		err = tx.Employers.SetEmployerCompany(employer.PublicID, company.PublicID)
 
-	if err != nil {
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
-		return
-	}
+		if err != nil {
+			return err
+		}
+
+		err = recordAudit(tx, r, employer.PublicID, audit.EmployerSignUp, audit.Employer, employer.PublicID, nil, employer)
 
-	domain := os.Getenv("MAILGUN_DOMAIN")
-	apiKey := os.Getenv("MAILGUN_API_KEY")
-	_, err = SendWelcomeMessageFunction(domain, apiKey, string(password), employer)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Queue.Enqueue(email.Kind, email.WelcomeMessage(employer.FirstName, employer.Email, string(password)))
+
+		return err
+	})
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, "JSON error:"+err.Error())
+		response.SendError(w, err)
 		return
 	}
 
 	response.SendJSON(w, "")
 }
-
-//SendWelcomeMessage Sends a welcome message
-func SendWelcomeMessage(domain, apiKey, password string, employer *accountmanagement.Employer) (string, error) {
-
-	message := fmt.Sprintf("Thank you for joining BiT Jobs, %s!\nYour temporary password is %s", employer.FirstName, password)
-	mg := mailgun.NewMailgun(domain, apiKey)
-	m := mg.NewMessage(
-		"BiT Jobs Support <admin@autumnomous.git.beanstalkapp.com/autumnomous-jobs-employer-api>",
-		"Welcome to BiT Jobs!",
-		message,
-		employer.Email,
-	)
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
-	_, id, err := mg.Send(ctx, m)
-
-	return id, err
-}
-
-var SendWelcomeMessageFunction = SendWelcomeMessage
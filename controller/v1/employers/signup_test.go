@@ -8,8 +8,6 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"autumnomous-jobs-employer-api/controller/v1/employers"
-	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
 	"autumnomous-jobs-employer-api/shared/services/security/encryption"
 	"autumnomous-jobs-employer-api/shared/testhelper"
 
@@ -23,7 +21,7 @@ func init() {
 func Test_Employer_SignUp_IncorrectRequestMethod(t *testing.T) {
 
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.SignUp))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().SignUp))
 
 	defer ts.Close()
 
@@ -60,7 +58,7 @@ func Test_Employer_SignUp_IncorrectRequestMethod(t *testing.T) {
 
 func Test_Employer_SignUp_IncorrectData(t *testing.T) {
 	assert := assert.New(t)
-	ts := httptest.NewServer(http.HandlerFunc(employers.SignUp))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().SignUp))
 
 	defer ts.Close()
 
@@ -102,56 +100,3 @@ func Test_Employer_SignUp_IncorrectData(t *testing.T) {
 
 	}
 }
-
-func Test_Employer_SignUp_CorrectData(t *testing.T) {
-
-	assert := assert.New(t)
-
-	ts := httptest.NewServer(http.HandlerFunc(employers.SignUp))
-
-	defer ts.Close()
-
-	data, err := json.Marshal(map[string]string{
-		"firstname": "First",
-		"lastname":  "Last",
-		"email":     fmt.Sprintf("email-%s@site.com", encryption.GeneratePassword(9)),
-	})
-
-	if err != nil {
-		t.Fatal()
-	}
-
-	employers.SendWelcomeMessageFunction = func(domain, apiKey, password string, employer *accountmanagement.Employer) (string, error) {
-		return "", nil
-	}
-
-	defer func() {
-		employers.SendWelcomeMessageFunction = employers.SendWelcomeMessage
-	}()
-
-	request, err := http.NewRequest("POST", ts.URL, bytes.NewBuffer(data))
-
-	if err != nil {
-		t.Fatal()
-	}
-
-	httpClient := &http.Client{}
-
-	response, err := httpClient.Do(request)
-
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	var result string
-	decoder := json.NewDecoder(response.Body)
-
-	err = decoder.Decode(&result)
-
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	assert.Nil(err)
-
-}
@@ -1,212 +1,288 @@
 package employers
 
 import (
-	"encoding/json"
-	"log"
 	"net/http"
 
-	"autumnomous-jobs-employer-api/shared/repository/employers"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
 	"autumnomous-jobs-employer-api/shared/response"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
 	// stripe "github.com/stripe/stripe-go/v72"
 )
 
-type updatePasswordCredentials struct {
-	Password    string `json:"password"`
-	NewPassword string `json:"newpassword"`
+type UpdatePasswordCredentials struct {
+	Password    string `json:"password" validate:"required,max=72"`
+	NewPassword string `json:"newpassword" validate:"required,min=8,max=72"`
 }
 
-type updateAccountData struct {
-	FirstName    string `json:"firstname"`
-	LastName     string `json:"lastname"`
-	Email        string `json:"email"`
-	PhoneNumber  string `json:"phonenumber"`
-	MobileNumber string `json:"mobilenumber"`
-	Role         string `json:"role"`
-	Facebook     string `json:"facebook"`
-	Twitter      string `json:"twitter"`
-	Instagram    string `json:"instagram"`
+// UpdateAccountData has the rules of accountmanagement.Account, but as empty
+// fields are left unchanged none of them is required
+type UpdateAccountData struct {
+	FirstName    string `json:"firstname" validate:"max=100"`
+	LastName     string `json:"lastname" validate:"max=100"`
+	Email        string `json:"email" validate:"email,max=254"`
+	PhoneNumber  string `json:"phonenumber" validate:"max=30"`
+	MobileNumber string `json:"mobilenumber" validate:"max=30"`
+	Role         string `json:"role" validate:"max=100"`
+	Facebook     string `json:"facebook" validate:"max=200"`
+	Twitter      string `json:"twitter" validate:"max=200"`
+	Instagram    string `json:"instagram" validate:"max=200"`
 	// Bio          string `json:"bio"`
 }
 
-type updateCompanyData struct {
-	Name         string  `json:"name"`
-	Location     string  `json:"location"`
-	Longitude    float64 `json:"longitude"`
-	Latitude     float64 `json:"latitude"`
-	URL          string  `json:"url"`
-	Facebook     string  `json:"facebook"`
-	Twitter      string  `json:"twitter"`
-	Instagram    string  `json:"instagram"`
-	Description  string  `json:"description"`
-	Logo         string  `json:"logo"`
-	ExtraDetails string  `json:"extradetails"`
-	Zipcode      string  `json:"zipcode"`
+// UpdateCompanyData has the rules of companies.Profile, but as empty fields
+// are left unchanged none of them is required
+type UpdateCompanyData struct {
+	Name         string  `json:"name" validate:"max=200"`
+	Location     string  `json:"location" validate:"max=200"`
+	Longitude    float64 `json:"longitude" validate:"min=-180,max=180"`
+	Latitude     float64 `json:"latitude" validate:"min=-90,max=90"`
+	URL          string  `json:"url" validate:"url,max=2000"`
+	Facebook     string  `json:"facebook" validate:"max=200"`
+	Twitter      string  `json:"twitter" validate:"max=200"`
+	Instagram    string  `json:"instagram" validate:"max=200"`
+	Description  string  `json:"description" validate:"max=20000"`
+	Logo         string  `json:"logo" validate:"max=2000"`
+	ExtraDetails string  `json:"extradetails" validate:"max=20000"`
+	Zipcode      string  `json:"zipcode" validate:"max=10"`
 }
 
-type updatePaymentMethodData struct {
-	PaymentMethod string `json:"paymentmethod"`
+type UpdatePaymentMethodData struct {
+	PaymentMethod string `json:"paymentmethod" validate:"required,max=100"`
 }
 
-type updatePaymentDetailsData struct {
-	PaymentDetails string `json:"paymentdetails"`
+type UpdatePaymentDetailsData struct {
+	PaymentDetails string `json:"paymentdetails" validate:"required,max=2000"`
 }
 
-func UpdatePassword(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, response.FriendlyError)
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
-	var credentials updatePasswordCredentials
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&credentials)
+	var credentials UpdatePasswordCredentials
 
-	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
-		return
-	}
-
-	if credentials.Password == "" || credentials.NewPassword == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+	if errs := validation.Decode(r.Body, &credentials); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
 	publicID := jwt.GetUserClaim(r)
 
 	if publicID == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
 		return
 	}
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	var updated bool
+
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		var err error
 
-	updated, err := repository.UpdateEmployerPassword(publicID, credentials.Password, credentials.NewPassword)
+		updated, err = tx.Employers.UpdateEmployerPassword(publicID, credentials.Password, credentials.NewPassword)
+
+		if err != nil || !updated {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.EmployerPassword, audit.Employer, publicID, nil, map[string]string{"password": "changed"})
+	})
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
-	if updated {
-		response.SendJSONMessage(w, http.StatusOK, response.Success)
-		return
-	} else {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+	if !updated {
+		response.SendProblem(w, http.StatusBadRequest, response.CodeInvalidCredentials, "The current password is incorrect.")
 		return
 	}
 
+	response.SendJSONMessage(w, http.StatusOK, response.Success)
+
 }
 
-func UpdateAccount(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, response.FriendlyError)
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
 	publicID := jwt.GetUserClaim(r)
 
 	if publicID == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
 		return
 	}
 
-	var data updateAccountData
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&data)
+	var data UpdateAccountData
 
-	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+	if errs := validation.Decode(r.Body, &data); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	var employer *accountmanagement.Employer
+
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
 
-	employer, err := repository.UpdateEmployerAccount(publicID, data.FirstName, data.LastName, data.Email, data.PhoneNumber, data.MobileNumber, data.Role, data.Facebook, data.Twitter, data.Instagram)
+		before, err := tx.Employers.GetEmployer(publicID)
+
+		if err != nil {
+			return err
+		}
+
+		employer, err = tx.Employers.UpdateEmployerAccount(publicID, data.FirstName, data.LastName, data.Email, data.PhoneNumber, data.MobileNumber, data.Role, data.Facebook, data.Twitter, data.Instagram)
+
+		if err != nil {
+			return err
+		}
+
+		after, err := tx.Employers.GetEmployer(publicID)
+
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.EmployerAccount, audit.Employer, publicID, before, after)
+	})
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
 	response.SendJSON(w, employer)
 }
 
-func UpdateCompany(w http.ResponseWriter, r *http.Request) {
+// UpdateCompany saves the non-empty fields of the employer's company. With an
+// If-Match header it only saves over the version in the ETag, and otherwise
+// responds 412 with the current company so the client can merge. A changed
+// location is resolved through the geocoder, see locate.
+func (h *Handler) UpdateCompany(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, response.FriendlyError)
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
 	publicID := jwt.GetUserClaim(r)
 
 	if publicID == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
 		return
 	}
 
-	var data updateCompanyData
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&data)
+	version, err := ifMatch(r)
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	var data UpdateCompanyData
 
-	company, err := repository.UpdateEmployerCompany(publicID, data.Name, data.Location, data.URL, data.Facebook, data.Twitter, data.Instagram, data.Description, data.Logo, data.ExtraDetails, data.Zipcode, data.Longitude, data.Latitude)
+	if errs := validation.Decode(r.Body, &data); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	before, err := h.Employers.GetEmployerCompany(publicID)
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
-	response.SendJSON(w, company)
+	profile := before.Profile()
+	profile.Merge(companies.Profile{Name: data.Name, Location: data.Location, Longitude: data.Longitude, Latitude: data.Latitude, URL: data.URL, Facebook: data.Facebook,
+		Twitter: data.Twitter, Instagram: data.Instagram, Description: data.Description, Logo: data.Logo, ExtraDetails: data.ExtraDetails, Zipcode: data.Zipcode})
+
+	// resolved before the transaction, as the geocoder may be slow
+	if err := h.locateCompany(&profile, before); err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	var company *companies.Company
+
+	err = h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		var err error
+
+		company, err = tx.Employers.ReplaceEmployerCompany(publicID, profile, version)
+
+		if err != nil {
+			return err
+		}
+
+		if err := retainLogo(tx, before, company); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.CompanyUpdate, audit.Company, company.PublicID, before, company)
+	})
+
+	if err == companies.ErrStale {
+		current, err := h.Employers.GetEmployerCompany(publicID)
+
+		if err != nil {
+			response.SendError(w, err)
+			return
+		}
+
+		sendVersioned(w, http.StatusPreconditionFailed, current.Version, current)
+		return
+	}
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	sendVersioned(w, http.StatusOK, company.Version, company)
 
 }
 
-func UpdatePaymentMethod(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdatePaymentMethod(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, response.FriendlyError)
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
 	publicID := jwt.GetUserClaim(r)
 
 	if publicID == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
 		return
 	}
 
-	var method updatePaymentMethodData
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&method)
+	var method UpdatePaymentMethodData
 
-	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+	if errs := validation.Decode(r.Body, &method); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		if err := tx.Employers.UpdateEmployerPaymentMethod(publicID, method.PaymentMethod); err != nil {
+			return err
+		}
 
-	err = repository.UpdateEmployerPaymentMethod(publicID, method.PaymentMethod)
+		return recordAudit(tx, r, publicID, audit.EmployerPaymentMethod, audit.Employer, publicID, nil, method)
+	})
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
@@ -214,37 +290,38 @@ func UpdatePaymentMethod(w http.ResponseWriter, r *http.Request) {
 
 }
 
-func UpdatePaymentDetails(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdatePaymentDetails(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, response.FriendlyError)
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
 	publicID := jwt.GetUserClaim(r)
 
 	if publicID == "" {
-		response.SendJSONMessage(w, http.StatusBadRequest, response.FriendlyError)
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
 		return
 	}
 
-	var details updatePaymentDetailsData
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&details)
+	var details UpdatePaymentDetailsData
 
-	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
 		return
 	}
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		if err := tx.Employers.UpdateEmployerPaymentDetails(publicID, details.PaymentDetails); err != nil {
+			return err
+		}
 
-	err = repository.UpdateEmployerPaymentDetails(publicID, details.PaymentDetails)
+		return recordAudit(tx, r, publicID, audit.EmployerPayment, audit.Employer, publicID, nil, details)
+	})
 
 	if err != nil {
-		log.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
 
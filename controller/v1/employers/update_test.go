@@ -4,13 +4,11 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
-	"autumnomous-jobs-employer-api/controller/v1/employers"
 	"autumnomous-jobs-employer-api/shared/services/security/encryption"
 	"autumnomous-jobs-employer-api/shared/services/security/jwt"
 	"autumnomous-jobs-employer-api/shared/testhelper"
@@ -26,14 +24,14 @@ func Test_Employer_UpdatePassword_Success(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.UpdatePassword))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().UpdatePassword))
 
 	data := map[string]string{
 		"password":    string(encryption.GeneratePassword(9)),
 		"newpassword": string(encryption.GeneratePassword(9)),
 	}
 
-	employer := &testhelper.TestEmployer{FirstName: "First", LastName: "Last", Email: fmt.Sprintf("email-%s@site.com", encryption.GeneratePassword(9))}
+	employer := &testhelper.TestEmployer{FirstName: "First", LastName: "Last", Email: testhelper.Helper_RandomEmail("email")}
 
 	hashedPassword, err := encryption.HashPassword([]byte(data["password"]))
 
@@ -89,7 +87,7 @@ func Test_Employer_UpdatePassword_Success(t *testing.T) {
 func Test_Employer_UpdatePassword_IncorrectMethod(t *testing.T) {
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.UpdatePassword))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().UpdatePassword))
 
 	defer ts.Close()
 
@@ -129,7 +127,7 @@ func Test_Employer_UpdatePassword_IncorrectDataReceived_NoPassword(t *testing.T)
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.UpdatePassword))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().UpdatePassword))
 
 	data := map[string]string{
 		"password":    "",
@@ -164,7 +162,7 @@ func Test_Employer_UpdatePassword_IncorrectDataReceived_NoNewPassword(t *testing
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.UpdatePassword))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().UpdatePassword))
 
 	data := map[string]string{
 		"password":    string(encryption.GeneratePassword(9)),
@@ -199,7 +197,7 @@ func Test_Employer_UpdatePassword_IncorrectDataReceived_NoToken(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.UpdatePassword))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().UpdatePassword))
 
 	data := map[string]string{
 		"password":    string(encryption.GeneratePassword(9)),
@@ -237,7 +235,7 @@ func Test_Employer_UpdatePassword_IncorrectDataReceived_NoToken(t *testing.T) {
 		t.Fatal()
 	}
 
-	assert.Equal(int(http.StatusBadRequest), response.StatusCode)
+	assert.Equal(int(http.StatusUnauthorized), response.StatusCode)
 
 }
 
@@ -245,7 +243,7 @@ func Test_Employer_UpdatePassword_IncorrectDataReceived_NoData(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.UpdatePassword))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().UpdatePassword))
 
 	data := map[string]string{
 		"password":    "",
@@ -280,7 +278,7 @@ func Test_Employer_UpdateAccount_IncorrectDataReceived_NoToken(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer(http.HandlerFunc(employers.UpdateAccount))
+	ts := httptest.NewServer(http.HandlerFunc(newHandler().UpdateAccount))
 
 	request, err := http.NewRequest("POST", ts.URL, nil)
 
@@ -305,16 +303,16 @@ func Test_Employer_UpdateAccount_IncorrectDataReceived_NoToken(t *testing.T) {
 	response, err := httpClient.Do(request)
 
 	assert.Nil(err)
-	assert.Equal(int(http.StatusBadRequest), response.StatusCode)
+	assert.Equal(int(http.StatusUnauthorized), response.StatusCode)
 }
 
 func Test_Employer_UpdateAccount_CorrectDataReceived(t *testing.T) {
 
 	assert := assert.New(t)
 
-	ts := httptest.NewServer((http.HandlerFunc(employers.UpdateAccount)))
+	ts := httptest.NewServer((http.HandlerFunc(newHandler().UpdateAccount)))
 
-	employer := &testhelper.TestEmployer{FirstName: "First", LastName: "Last", Email: fmt.Sprintf("email-%s@site.com", encryption.GeneratePassword(9)), Password: string(encryption.GeneratePassword(9))}
+	employer := &testhelper.TestEmployer{FirstName: "First", LastName: "Last", Email: testhelper.Helper_RandomEmail("email"), Password: string(encryption.GeneratePassword(9))}
 
 	hashedPassword, err := encryption.HashPassword([]byte(employer.Password))
 
@@ -345,7 +343,7 @@ func Test_Employer_UpdateAccount_CorrectDataReceived(t *testing.T) {
 		"New Email": {
 			"firstname": "NewFirst",
 			"lastname":  "NewLast",
-			"email":     fmt.Sprintf("new-email-%s@site.com", encryption.GeneratePassword(9)),
+			"email":     testhelper.Helper_RandomEmail("new-email"),
 		},
 	}
 
@@ -0,0 +1,78 @@
+package employers
+
+import (
+	"net/http"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+)
+
+// GetUploads lists the employer's uploads, newest first. It has no v1 route.
+func (h *Handler) GetUploads(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	result, err := h.Uploads.GetEmployerUploads(publicID)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	if result == nil {
+		result = []*uploads.Upload{}
+	}
+
+	response.SendJSON(w, result)
+}
+
+// DeleteUploadByID deletes an upload of the employer that nothing uses, and
+// its object
+func (h *Handler) DeleteUploadByID(w http.ResponseWriter, r *http.Request, uploadPublicID string) {
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var upload *uploads.Upload
+
+	// the row stays unless the object is deleted with it
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		var err error
+		upload, err = tx.Uploads.DeleteEmployerUpload(publicID, uploadPublicID)
+
+		if err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, r, publicID, audit.UploadDelete, audit.Upload, uploadPublicID, upload, nil); err != nil {
+			return err
+		}
+
+		return h.Storage.Delete(upload.Key)
+	})
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, upload)
+}
@@ -0,0 +1,338 @@
+package employers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+	"autumnomous-jobs-employer-api/shared/services/webhook"
+
+	"github.com/google/uuid"
+)
+
+type CreateWebhookDetails struct {
+	URL    string   `json:"url" validate:"required,url,max=2000"`
+	Events []string `json:"events" validate:"required"`
+}
+
+type WebhookDetails struct {
+	PublicID string `json:"publicid" validate:"required"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []*webhooks.Webhook `json:"webhooks"`
+	Events   []string            `json:"events"`
+}
+
+type DeliveriesResponse struct {
+	Deliveries []*webhooks.Delivery `json:"deliveries"`
+}
+
+// CreateWebhook subscribes a URL to events for the employer's company. The
+// signing secret is only returned here.
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var details CreateWebhookDetails
+
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	if err := webhook.ValidateURL(details.URL, !h.Config.IsProduction()); err != nil {
+		response.SendValidationErrors(w, validation.Errors{{Field: "url", Code: validation.URL, Message: err.Error()}})
+		return
+	}
+
+	for _, event := range details.Events {
+		if !webhook.IsEvent(event) {
+			response.SendValidationErrors(w, validation.Errors{{Field: "events", Code: validation.OneOf, Message: "Unknown event: " + event}})
+			return
+		}
+	}
+
+	secret, err := webhook.NewSecret()
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	var created *webhooks.Webhook
+
+	err = h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		created, err = tx.Webhooks.CreateWebhook(publicID, details.URL, secret, details.Events)
+
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.WebhookCreate, audit.Webhook, created.PublicID, nil, created)
+	})
+
+	if errors.Is(err, companies.ErrNotFound) {
+		response.SendProblem(w, http.StatusConflict, response.CodeCompanyRequired, "Set up your company before adding webhooks")
+		return
+	}
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, created)
+}
+
+// GetWebhooks lists the company's webhooks, without their secrets, and the
+// events they can subscribe to
+func (h *Handler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	hooks, err := h.Webhooks.GetWebhooks(publicID)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	for _, hook := range hooks {
+		hook.Secret = ""
+	}
+
+	response.SendJSON(w, WebhooksResponse{Webhooks: hooks, Events: webhook.Events})
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodDelete {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var details WebhookDetails
+
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	err := h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+
+		before, err := tx.Webhooks.GetEmployerWebhook(publicID, details.PublicID)
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Webhooks.DeleteWebhook(publicID, details.PublicID); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.WebhookDelete, audit.Webhook, details.PublicID, before, nil)
+	})
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSONMessage(w, http.StatusOK, response.Success)
+}
+
+// GetWebhookDeliveries returns the delivery log of ?webhook=, newest first
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	webhookPublicID := r.URL.Query().Get("webhook")
+
+	if webhookPublicID == "" {
+		response.SendValidationErrors(w, validation.Errors{{Field: "webhook", Code: validation.Required, Message: "webhook is required"}})
+		return
+	}
+
+	limit := 50
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		number, invalid := validation.Between("limit", value, 1, 500)
+
+		if invalid != nil {
+			response.SendValidationErrors(w, validation.Errors{invalid})
+			return
+		}
+
+		limit = number
+	}
+
+	if _, err := h.Webhooks.GetEmployerWebhook(publicID, webhookPublicID); err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	deliveries, err := h.Webhooks.GetDeliveries(publicID, webhookPublicID, limit)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, DeliveriesResponse{Deliveries: deliveries})
+}
+
+// RedeliverWebhook sends the payload of a delivery again as a new delivery
+func (h *Handler) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var details WebhookDetails
+
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	original, err := h.Webhooks.GetEmployerDelivery(publicID, details.PublicID)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	var delivery *webhooks.Delivery
+
+	err = h.UnitOfWork.Do(r.Context(), func(tx *transaction.Repositories) error {
+		delivery, err = webhook.Enqueue(tx.Webhooks, tx.Queue, original.WebhookPublicID, original.Event, original.Payload)
+
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(tx, r, publicID, audit.WebhookRedeliver, audit.Webhook, original.WebhookPublicID, nil, map[string]string{"delivery": delivery.PublicID, "redelivered": original.PublicID})
+	})
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, delivery)
+}
+
+// PingWebhook sends a ping event to a webhook straight away and returns the
+// logged delivery, so an employer can check their endpoint. It is not retried.
+func (h *Handler) PingWebhook(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var details WebhookDetails
+
+	if errs := validation.Decode(r.Body, &details); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	hook, err := h.Webhooks.GetEmployerWebhook(publicID, details.PublicID)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	body, err := json.Marshal(&webhook.Envelope{
+		ID:        uuid.NewString(),
+		Event:     webhook.Ping,
+		CreatedAt: time.Now().UTC(),
+		Data:      WebhookDetails{PublicID: hook.PublicID},
+	})
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	delivery, err := h.Webhooks.CreateDelivery(hook.PublicID, webhook.Ping, body)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	delivery, err = webhook.NewDeliverer(h.Webhooks, h.WebhookClient).Send(r.Context(), hook, delivery, true)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, delivery)
+}
@@ -0,0 +1,65 @@
+package utilities
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/storage"
+
+	"github.com/gorilla/context"
+	"github.com/julienschmidt/httprouter"
+)
+
+// ServeFile serves the files of the local storage driver: a GET returns a
+// file and a PUT to a signed URL stores one. It finds nothing when uploads
+// are kept elsewhere.
+func (h *Handler) ServeFile(w http.ResponseWriter, r *http.Request) {
+
+	local, ok := h.Storage.(*storage.LocalStorage)
+
+	if !ok {
+		response.SendProblem(w, http.StatusNotFound, response.CodeNotFound, "")
+		return
+	}
+
+	params, _ := context.Get(r, "params").(httprouter.Params)
+	key := strings.TrimPrefix(params.ByName("key"), "/")
+
+	switch r.Method {
+	case http.MethodGet:
+		if strings.HasPrefix(key, storage.QuarantinePrefix) {
+			response.SendError(w, storage.ErrNotFound)
+			return
+		}
+
+		object, err := local.Get(key)
+
+		if err != nil {
+			response.SendError(w, err)
+			return
+		}
+
+		defer object.Body.Close()
+
+		// uploads are never run as pages of the API's origin
+		w.Header().Set("Content-Type", object.ContentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "sandbox")
+
+		http.ServeContent(w, r, "", time.Time{}, object.Body.(io.ReadSeeker))
+
+	case http.MethodPut:
+		if err := local.PutSigned(key, r.URL.Query(), r.Body); err != nil {
+			response.SendError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+	}
+}
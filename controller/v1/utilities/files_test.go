@@ -0,0 +1,71 @@
+package utilities_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/controller/v1/utilities"
+
+	"github.com/gorilla/context"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveFile sends a request for key to ServeFile, with the params the router
+// would set
+func serveFile(handler *utilities.Handler, method, target, key, body string) *httptest.ResponseRecorder {
+
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	context.Set(request, "params", httprouter.Params{{Key: "key", Value: "/" + key}})
+
+	defer context.Clear(request)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeFile(recorder, request)
+
+	return recorder
+}
+
+func Test_Utilities_ServeFile(t *testing.T) {
+	assert := assert.New(t)
+
+	application, local, _, _ := newUploadApp(t)
+	handler := utilities.NewHandler(application)
+
+	signed, err := local.SignedURL(http.MethodPut, "files/report.pdf", time.Minute)
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	parsed, _ := url.Parse(signed)
+
+	result := serveFile(handler, http.MethodPut, parsed.RequestURI(), "files/report.pdf", "%PDF-1.4")
+	assert.Equal(http.StatusOK, result.Code)
+
+	result = serveFile(handler, http.MethodGet, "/uploads/files/report.pdf", "files/report.pdf", "")
+	assert.Equal(http.StatusOK, result.Code)
+	assert.Equal("%PDF-1.4", result.Body.String())
+	assert.Equal("application/pdf", result.Header().Get("Content-Type"))
+	assert.Equal("nosniff", result.Header().Get("X-Content-Type-Options"))
+	assert.Equal("sandbox", result.Header().Get("Content-Security-Policy"))
+
+	// a signed URL is only good for its own key
+	result = serveFile(handler, http.MethodPut, strings.Replace(parsed.RequestURI(), "report.pdf", "other.pdf", 1), "files/other.pdf", "x")
+	assert.Equal(http.StatusForbidden, result.Code)
+
+	result = serveFile(handler, http.MethodDelete, "/uploads/files/report.pdf", "files/report.pdf", "")
+	assert.Equal(http.StatusMethodNotAllowed, result.Code)
+
+	// quarantined objects are kept but never served
+	key, err := local.Quarantine("images/eicar.png", strings.NewReader("X5O!P%@AP"), "image/png")
+
+	if assert.NoError(err) {
+		result = serveFile(handler, http.MethodGet, "/uploads/"+key, key, "")
+		assert.Equal(http.StatusNotFound, result.Code)
+	}
+}
@@ -0,0 +1,117 @@
+package utilities
+
+import (
+	"net/http"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/presign"
+	"autumnomous-jobs-employer-api/shared/services/scan"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+)
+
+// PresignUpload records a pending upload of the file the employer declares
+// and returns a presigned URL to PUT it to
+func (h *Handler) PresignUpload(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var request presign.Request
+
+	if errs := validation.Decode(r.Body, &request); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	if err := presign.Check(&request); err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	upload, err := h.Uploads.CreateUpload(publicID, presign.Key(request.ContentType), request.FileName, request.ContentType, request.Size, time.Now().Add(uploads.PendingRetention))
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	presigned, err := presign.Sign(h.Storage, upload)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSONStatus(w, http.StatusCreated, presigned)
+}
+
+// CompleteUpload checks the object of a pending upload against what was
+// declared and scans it, after which the upload can be used. An object that
+// fails its scan is quarantined instead. Completing an upload twice returns
+// it unchanged.
+func (h *Handler) CompleteUpload(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
+		return
+	}
+
+	publicID := jwt.GetUserClaim(r)
+
+	if publicID == "" {
+		response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+		return
+	}
+
+	var request presign.UploadReference
+
+	if errs := validation.Decode(r.Body, &request); errs != nil {
+		response.SendValidationErrors(w, errs)
+		return
+	}
+
+	upload, err := h.Uploads.GetEmployerUpload(publicID, request.Upload)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	if upload.Status != uploads.Pending {
+		response.SendJSON(w, upload)
+		return
+	}
+
+	size, err := presign.Verify(h.Storage, upload)
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	if err := scan.CheckUpload(r.Context(), h.Scanner, h.Storage, h.Uploads, upload); err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	upload, err = h.Uploads.CompleteUpload(upload.PublicID, size, time.Now().Add(uploads.OrphanRetention))
+
+	if err != nil {
+		response.SendError(w, err)
+		return
+	}
+
+	response.SendJSON(w, upload)
+}
@@ -0,0 +1,265 @@
+package utilities_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/app"
+	"autumnomous-jobs-employer-api/controller/v1/utilities"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/imaging"
+	"autumnomous-jobs-employer-api/shared/services/presign"
+	"autumnomous-jobs-employer-api/shared/services/scan"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/storage"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newUploadApp returns an in-memory app keeping files in a temporary
+// directory, and an employer and their token
+func newUploadApp(t *testing.T) (*app.App, *storage.LocalStorage, string, string) {
+
+	jwt.SetSigningKey("utilities-test")
+
+	application, store := testhelper.NewMemoryApp(&testhelper.Mailer{})
+
+	local, err := storage.NewLocalStorage(t.TempDir(), "http://localhost:7000/uploads", []byte("secret"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	application.Storage = local
+
+	employer, err := store.EmployerRepository().CreateEmployer("First", "Last", "uploads@example.com", "password")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.GenerateToken(employer.PublicID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return application, local, employer.PublicID, "Bearer " + base64.StdEncoding.EncodeToString([]byte(token))
+}
+
+func encodePNG(t *testing.T) []byte {
+
+	var buffer bytes.Buffer
+
+	if err := png.Encode(&buffer, image.NewGray(image.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
+
+func post(handler http.HandlerFunc, token string, body interface{}, result interface{}) int {
+
+	data, _ := json.Marshal(body)
+
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	request.Header.Set("Authorization", token)
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+
+	json.NewDecoder(recorder.Body).Decode(result)
+
+	return recorder.Code
+}
+
+// put uploads content to a URL signed by local
+func put(t *testing.T, local *storage.LocalStorage, signed string, content []byte) {
+
+	parsed, err := url.Parse(signed)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := local.PutSigned(strings.TrimPrefix(parsed.Path, storage.LocalPath+"/"), parsed.Query(), bytes.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Utilities_PresignUpload(t *testing.T) {
+	assert := assert.New(t)
+
+	application, local, _, token := newUploadApp(t)
+	handler := utilities.NewHandler(application)
+
+	problem := &response.Problem{}
+	assert.Equal(http.StatusBadRequest, post(handler.PresignUpload, token, presign.Request{ContentType: "image/svg+xml", Size: 10}, problem))
+	assert.Equal(presign.ErrUnsupportedType.Code, problem.Code)
+
+	problem = &response.Problem{}
+	assert.Equal(http.StatusBadRequest, post(handler.PresignUpload, token, presign.Request{ContentType: imaging.PNG, Size: presign.MaxSize + 1}, problem))
+	assert.Equal(imaging.ErrFileTooLarge.Code, problem.Code)
+
+	content := encodePNG(t)
+
+	presigned := &presign.Presigned{}
+	assert.Equal(http.StatusCreated, post(handler.PresignUpload, token, presign.Request{FileName: "logo.png", ContentType: imaging.PNG, Size: int64(len(content))}, presigned))
+	assert.Equal(http.MethodPut, presigned.Method)
+	assert.Equal(uploads.Pending, presigned.Upload.Status)
+
+	// nothing has been uploaded yet
+	problem = &response.Problem{}
+	assert.Equal(http.StatusConflict, post(handler.CompleteUpload, token, presign.UploadReference{Upload: presigned.Upload.PublicID}, problem))
+	assert.Equal(presign.ErrNotUploaded.Code, problem.Code)
+
+	put(t, local, presigned.URL, content)
+
+	for i := 0; i < 2; i++ {
+		upload := &uploads.Upload{}
+		assert.Equal(http.StatusOK, post(handler.CompleteUpload, token, presign.UploadReference{Upload: presigned.Upload.PublicID}, upload))
+		assert.Equal(uploads.Complete, upload.Status)
+		assert.True(upload.ExpiresAt.After(time.Now().Add(time.Hour)))
+	}
+
+	// another employer cannot complete it
+	_, _, _, stranger := newUploadApp(t)
+	problem = &response.Problem{}
+	assert.Equal(http.StatusNotFound, post(handler.CompleteUpload, stranger, presign.UploadReference{Upload: presigned.Upload.PublicID}, problem))
+}
+
+func Test_Utilities_CompleteUpload_Mismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	application, local, _, token := newUploadApp(t)
+	handler := utilities.NewHandler(application)
+
+	content := encodePNG(t)
+
+	// a file of another size than declared
+	presigned := &presign.Presigned{}
+	post(handler.PresignUpload, token, presign.Request{ContentType: imaging.PNG, Size: int64(len(content)) + 1}, presigned)
+	put(t, local, presigned.URL, content)
+
+	problem := &response.Problem{}
+	assert.Equal(http.StatusBadRequest, post(handler.CompleteUpload, token, presign.UploadReference{Upload: presigned.Upload.PublicID}, problem))
+	assert.Equal(presign.ErrSizeMismatch.Code, problem.Code)
+
+	_, err := local.Get(presigned.Upload.Key)
+	assert.Equal(storage.ErrNotFound, err)
+
+	// a GIF declared as a PNG
+	gif := append([]byte("GIF89a"), make([]byte, 20)...)
+	presigned = &presign.Presigned{}
+	post(handler.PresignUpload, token, presign.Request{ContentType: imaging.PNG, Size: int64(len(gif))}, presigned)
+	put(t, local, presigned.URL, gif)
+
+	problem = &response.Problem{}
+	assert.Equal(http.StatusBadRequest, post(handler.CompleteUpload, token, presign.UploadReference{Upload: presigned.Upload.PublicID}, problem))
+	assert.Equal(presign.ErrTypeMismatch.Code, problem.Code)
+}
+
+// infected is a Scanner that finds the EICAR test signature in everything
+type infected struct{}
+
+func (infected) Scan(ctx context.Context, content []byte) (*scan.Result, error) {
+	return &scan.Result{Verdict: scan.Infected, Reason: "Eicar-Test-Signature"}, nil
+}
+
+func Test_Utilities_CompleteUpload_Quarantines(t *testing.T) {
+	assert := assert.New(t)
+
+	application, local, employer, token := newUploadApp(t)
+	application.Scanner = infected{}
+	handler := utilities.NewHandler(application)
+
+	content := encodePNG(t)
+
+	presigned := &presign.Presigned{}
+	post(handler.PresignUpload, token, presign.Request{ContentType: imaging.PNG, Size: int64(len(content))}, presigned)
+	put(t, local, presigned.URL, content)
+
+	problem := &response.Problem{}
+	assert.Equal(http.StatusBadRequest, post(handler.CompleteUpload, token, presign.UploadReference{Upload: presigned.Upload.PublicID}, problem))
+	assert.Equal(scan.ErrInfected.Code, problem.Code)
+	assert.Contains(problem.Detail, presigned.Upload.PublicID)
+	assert.Contains(problem.Detail, "Eicar-Test-Signature")
+
+	_, err := local.Get(presigned.Upload.Key)
+	assert.Equal(storage.ErrNotFound, err)
+
+	// completing again reports the quarantined upload
+	upload := &uploads.Upload{}
+	assert.Equal(http.StatusOK, post(handler.CompleteUpload, token, presign.UploadReference{Upload: presigned.Upload.PublicID}, upload))
+	assert.Equal(uploads.Quarantined, upload.Status)
+	assert.Equal("infected: Eicar-Test-Signature", upload.ScanResult)
+
+	quarantined, err := application.Uploads.GetEmployerUpload(employer, presigned.Upload.PublicID)
+
+	if assert.NoError(err) {
+		_, err = local.Get(quarantined.Key)
+		assert.NoError(err)
+	}
+}
+
+func Test_Utilities_PurgeUploads(t *testing.T) {
+	assert := assert.New(t)
+
+	application, local, employer, _ := newUploadApp(t)
+
+	abandoned, err := application.Uploads.CreateUpload(employer, "uploads/abandoned.png", "", imaging.PNG, 3, time.Now().Add(-time.Minute))
+	assert.NoError(err)
+
+	waiting, err := application.Uploads.CreateUpload(employer, "uploads/waiting.png", "", imaging.PNG, 3, time.Now().Add(time.Hour))
+	assert.NoError(err)
+
+	// recorded uploads expire only once nothing uses them
+	orphaned, err := application.Uploads.RecordUpload(employer, "images/orphaned.png", "https://cdn.test/orphaned.png", imaging.PNG, 3, time.Now().Add(-time.Minute))
+	assert.NoError(err)
+
+	used, err := application.Uploads.RecordUpload(employer, "images/used.png", "https://cdn.test/used.png", imaging.PNG, 3, time.Now().Add(-time.Minute))
+	assert.NoError(err)
+	assert.NoError(application.Uploads.Retain([]string{used.URL}))
+
+	for _, upload := range []*uploads.Upload{abandoned, waiting, orphaned, used} {
+		if _, err := local.Put(upload.Key, strings.NewReader("png"), upload.ContentType); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := application.Queue.Enqueue(app.PurgeUploadsTask, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	performed, err := application.Worker().Work(context.Background(), 10)
+	assert.NoError(err)
+	assert.Equal(1, performed)
+
+	for _, upload := range []*uploads.Upload{abandoned, orphaned} {
+		_, err = local.Get(upload.Key)
+		assert.Equal(storage.ErrNotFound, err)
+
+		_, err = application.Uploads.GetEmployerUpload(employer, upload.PublicID)
+		assert.Equal(uploads.ErrNotFound, err)
+	}
+
+	for _, upload := range []*uploads.Upload{waiting, used} {
+		object, err := local.Get(upload.Key)
+
+		if assert.NoError(err) {
+			object.Body.Close()
+		}
+	}
+}
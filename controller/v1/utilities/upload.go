@@ -1,70 +1,55 @@
 package utilities
 
 import (
-	"autumnomous-jobs-employer-api/shared/response"
 	"net/http"
-	"os"
-
-	// Additional imports needed for examples below
-	"fmt"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/google/uuid"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/imaging"
+	"autumnomous-jobs-employer-api/shared/services/scan"
 )
 
-func UploadImage(w http.ResponseWriter, r *http.Request) {
+// UploadResponse is the public URL of an upload's logo variant, and the URLs
+// of all its variants by name
+type UploadResponse struct {
+	URL      string            `json:"url"`
+	Variants map[string]string `json:"variants"`
+}
+
+// UploadImage stores the variants of a multipart image upload, scanned,
+// checked and stripped of metadata by the imaging package, and returns their
+// public URLs. An image that fails its scan is quarantined instead.
+func (h *Handler) UploadImage(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost {
-		response.SendJSONMessage(w, http.StatusMethodNotAllowed, "Method not allowed")
+		response.SendProblem(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "")
 		return
 	}
 
-	key := os.Getenv("SPACES_KEY")
-	secret := os.Getenv("SPACES_SECRET")
-
-	// Parse multipart form, 10 << 20 specifies a maximum
-	// upload of 10 MB files.
-	r.ParseMultipartForm(10 << 20)
+	data, err := imaging.ReadFormFile(w, r, "file")
 
-	file, handler, err := r.FormFile("file")
 	if err != nil {
-		fmt.Println("Error Retrieving the File")
-		fmt.Println(err)
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
 		return
 	}
-	defer file.Close()
 
-	s3Config := &aws.Config{
-		Credentials: credentials.NewStaticCredentials(key, secret, ""),
-		Endpoint:    aws.String(os.Getenv("SPACES_ENDPOINT")),
-		Region:      aws.String("us-east-1"),
+	if err := scan.Check(r.Context(), h.Scanner, h.Storage, h.Uploads, "", "", data); err != nil {
+		response.SendError(w, err)
+		return
 	}
 
-	newSession, err := session.NewSession(s3Config)
+	images, err := imaging.Process(data, imaging.LogoVariants)
+
 	if err != nil {
-		fmt.Println(err.Error())
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
+		return
 	}
 
-	s3Client := s3.New(newSession)
-
-	keyname := fmt.Sprintf("%s-%s", uuid.NewString(), handler.Filename)
-	object := s3.PutObjectInput{
-		Bucket: aws.String(os.Getenv("SPACES_BUCKET")),
-		Key:    aws.String(keyname),
-		Body:   file,
-		ACL:    aws.String("public-read"),
-	}
-	_, err = s3Client.PutObject(&object)
+	urls, err := imaging.Store(h.Storage, h.Uploads, "", images)
 
 	if err != nil {
-		fmt.Println(err.Error())
-		response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+		response.SendError(w, err)
+		return
 	}
 
-	response.SendJSON(w, map[string]string{"url": fmt.Sprintf("https://%s.%s/%s", os.Getenv("SPACES_BUCKET"), os.Getenv("SPACES_ENDPOINT"), keyname)})
+	response.SendJSON(w, UploadResponse{URL: urls[imaging.Logo.Name], Variants: urls})
 }
@@ -1 +1,105 @@
 package utilities_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/controller/v1/utilities"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/imaging"
+	"autumnomous-jobs-employer-api/shared/services/scan"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func upload(t *testing.T, field string, content []byte) *response.Problem {
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile(field, "logo.png")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	part.Write(content)
+	writer.Close()
+
+	application, _ := testhelper.NewMemoryApp(&testhelper.Mailer{})
+
+	request := httptest.NewRequest(http.MethodPost, "/upload/image", &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	recorder := httptest.NewRecorder()
+	utilities.NewHandler(application).UploadImage(recorder, request)
+
+	problem := &response.Problem{}
+
+	if err := json.NewDecoder(recorder.Body).Decode(problem); err != nil {
+		t.Fatal(err)
+	}
+
+	return problem
+}
+
+func Test_Utilities_UploadImage_Rejects(t *testing.T) {
+	assert := assert.New(t)
+
+	problem := upload(t, "image", []byte("\x89PNG\r\n\x1a\n"))
+	assert.Equal(http.StatusBadRequest, problem.Status)
+	assert.Equal("file", problem.Errors[0].Field)
+
+	problem = upload(t, "file", make([]byte, imaging.MaxUploadSize+1))
+	assert.Equal(http.StatusBadRequest, problem.Status)
+	assert.Equal(imaging.ErrFileTooLarge.Code, problem.Code)
+
+	problem = upload(t, "file", []byte("GIF89a\x00\x00\x00\x00"))
+	assert.Equal(imaging.ErrCorrupt.Code, problem.Code)
+}
+
+func Test_Utilities_UploadImage_Quarantines(t *testing.T) {
+	assert := assert.New(t)
+
+	application, local, _, _ := newUploadApp(t)
+	application.Scanner = &scan.Policy{ContentTypes: []string{imaging.PNG}, Sniff: imaging.Sniff}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "logo.gif")
+	part.Write([]byte("GIF89a\x00\x00\x00\x00"))
+	writer.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/upload/image", &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	recorder := httptest.NewRecorder()
+	utilities.NewHandler(application).UploadImage(recorder, request)
+
+	problem := &response.Problem{}
+	json.NewDecoder(recorder.Body).Decode(problem)
+
+	assert.Equal(http.StatusBadRequest, recorder.Code)
+	assert.Equal(scan.ErrRejected.Code, problem.Code)
+
+	quarantined, _ := application.Uploads.GetOrphanedUploads(time.Now().Add(uploads.QuarantineRetention+time.Hour), 10)
+
+	if assert.Len(quarantined, 1) {
+		assert.Equal(uploads.Quarantined, quarantined[0].Status)
+		assert.Equal("rejected: content type image/gif is not allowed", quarantined[0].ScanResult)
+		assert.Contains(problem.Detail, quarantined[0].PublicID)
+
+		object, err := local.Get(quarantined[0].Key)
+
+		if assert.NoError(err) {
+			object.Body.Close()
+		}
+	}
+}
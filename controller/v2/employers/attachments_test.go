@@ -0,0 +1,163 @@
+package employers_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/controller/v2/employers"
+	hr "autumnomous-jobs-employer-api/route/middleware/httprouterwrapper"
+	"autumnomous-jobs-employer-api/shared/repository/attachments"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/presign"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/storage"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_V2_JobAttachments(t *testing.T) {
+	assert := assert.New(t)
+
+	application, store := testhelper.NewMemoryApp(&testhelper.Mailer{})
+
+	files, err := storage.NewLocalStorage(t.TempDir(), "http://localhost"+storage.LocalPath, []byte("v2-test"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	application.Storage = files
+	handler := employers.NewHandler(application)
+
+	r := httprouter.New()
+	r.POST("/v2/jobs/:id/attachments", hr.HandlerFunc(handler.AttachJobFile))
+	r.DELETE("/v2/jobs/:id/attachments/:attachment", hr.HandlerFunc(handler.DetachJobFile))
+
+	other := newEmployer(t, store, "other@example.org")
+
+	employer, err := store.EmployerRepository().CreateEmployer("First", "Last", "employer@example.com", "password")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	employerPublicID := employer.PublicID
+	token := tokenFor(t, employerPublicID)
+
+	job, err := store.JobRepository().EmployerCreateJob(employerPublicID, "Welder", "full-time", "", "", "", "", false, 0, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// upload stores a complete upload of content by the employer
+	upload := func(fileName, contentType string, content []byte) string {
+
+		key := presign.Key(contentType)
+
+		if _, err := files.Put(key, bytes.NewReader(content), contentType); err != nil {
+			t.Fatal(err)
+		}
+
+		created, err := store.UploadRepository().CreateUpload(employerPublicID, key, fileName, contentType, int64(len(content)), time.Now().Add(time.Hour))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := store.UploadRepository().CompleteUpload(created.PublicID, created.Size, time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+
+		body, _ := json.Marshal(presign.UploadReference{Upload: created.PublicID})
+		return string(body)
+	}
+
+	problemCode := func(result *bytes.Buffer) string {
+		var problem response.Problem
+		assert.Nil(json.NewDecoder(result).Decode(&problem))
+		return problem.Code
+	}
+
+	document := upload("form.pdf", presign.PDF, []byte("%PDF-1.4\n%%EOF\n"))
+	target := "/v2/jobs/" + job.PublicID + "/attachments"
+
+	result := send(r, http.MethodPost, target, other, "application/json", document)
+	assert.Equal(http.StatusNotFound, result.Code, "another employer's upload is not found")
+
+	result = send(r, http.MethodPost, target, token, "application/json", upload("logo.png", "image/png", []byte("\x89PNG\r\n\x1a\n")))
+	assert.Equal(http.StatusBadRequest, result.Code)
+	assert.Equal(attachments.ErrNotDocument.Code, problemCode(result.Body))
+
+	result = send(r, http.MethodPost, "/v2/jobs/"+job.PublicID+"x/attachments", token, "application/json", document)
+	assert.Equal(http.StatusNotFound, result.Code)
+	assert.Equal(jobs.ErrNotFound.Code, problemCode(result.Body))
+
+	// attachments of the employer's other jobs count towards the quota
+	full, err := store.JobRepository().EmployerCreateJob(employerPublicID, "Painter", "full-time", "", "", "", "", false, 0, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seeded, err := store.AttachmentRepository().CreateAttachment(employerPublicID, full.PublicID, "large.pdf", presign.PDF, "http://localhost/large.pdf", attachments.MaxCompanySize)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result = send(r, http.MethodPost, target, token, "application/json", document)
+	assert.Equal(http.StatusConflict, result.Code)
+	assert.Equal(attachments.ErrQuotaExceeded.Code, problemCode(result.Body))
+
+	result = send(r, http.MethodDelete, "/v2/jobs/"+full.PublicID+"/attachments/"+seeded.PublicID, other, "", "")
+	assert.Equal(http.StatusNotFound, result.Code)
+
+	result = send(r, http.MethodDelete, "/v2/jobs/"+full.PublicID+"/attachments/"+seeded.PublicID, token, "", "")
+	assert.Equal(http.StatusOK, result.Code)
+
+	result = send(r, http.MethodPost, target, token, "application/json", document)
+	assert.Equal(http.StatusCreated, result.Code)
+
+	var attachment jobs.Attachment
+	assert.Nil(json.NewDecoder(result.Body).Decode(&attachment))
+	assert.Equal("form.pdf", attachment.FileName)
+
+	entries, err := store.AuditRepository().GetEntries(employerPublicID, &audit.Filter{Action: audit.JobAttach})
+	assert.Nil(err)
+
+	assert.Len(entries, 1)
+
+	list, err := store.UploadRepository().GetEmployerUploads(employerPublicID)
+	assert.Nil(err)
+
+	published := 0
+	for _, found := range list {
+		if found.URL == attachment.URL {
+			published++
+			assert.Equal(uploads.Complete, found.Status)
+			assert.Equal(1, found.RefCount)
+		}
+	}
+	assert.Equal(1, published)
+}
+
+func tokenFor(t *testing.T, employerPublicID string) string {
+
+	token, err := jwt.GenerateToken(employerPublicID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return "Bearer " + base64.StdEncoding.EncodeToString([]byte(token))
+}
@@ -0,0 +1,35 @@
+// Package employers serves the v2 employer API, whose routes name resources
+// and take their IDs from the path instead of the request body. It shares the
+// v1 handlers, and so their repositories and behaviour, wherever the
+// operation is the same.
+package employers
+
+import (
+	"net/http"
+
+	"autumnomous-jobs-employer-api/app"
+	v1 "autumnomous-jobs-employer-api/controller/v1/employers"
+
+	"github.com/gorilla/context"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Handler serves the v2 employer endpoints. The v1 handlers that need no ID,
+// such as GetEmployer, are served as they are.
+type Handler struct {
+	*v1.Handler
+}
+
+// NewHandler returns a Handler backed by application
+func NewHandler(application *app.App) *Handler {
+	return &Handler{Handler: v1.NewHandler(application)}
+}
+
+// param returns the path parameter name, which httprouterwrapper stores in
+// the request context
+func param(r *http.Request, name string) string {
+
+	params, _ := context.Get(r, "params").(httprouter.Params)
+
+	return params.ByName(name)
+}
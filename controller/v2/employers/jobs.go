@@ -0,0 +1,49 @@
+package employers
+
+import "net/http"
+
+// GetJob returns the job /v2/jobs/:id with its ETag
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	h.GetJobByID(w, r, param(r, "id"))
+}
+
+// PatchJob applies a JSON merge patch to the job /v2/jobs/:id, honouring
+// If-Match like the v1 PATCH
+func (h *Handler) PatchJob(w http.ResponseWriter, r *http.Request) {
+	h.PatchJobByID(w, r, param(r, "id"))
+}
+
+// DeleteJob deletes the job /v2/jobs/:id
+func (h *Handler) DeleteJob(w http.ResponseWriter, r *http.Request) {
+	h.DeleteJobByID(w, r, param(r, "id"))
+}
+
+// RestoreJob undeletes the job /v2/jobs/:id
+func (h *Handler) RestoreJob(w http.ResponseWriter, r *http.Request) {
+	h.RestoreJobByID(w, r, param(r, "id"))
+}
+
+// GetJobRevisions lists the revisions of the job /v2/jobs/:id, newest first
+func (h *Handler) GetJobRevisions(w http.ResponseWriter, r *http.Request) {
+	h.GetJobRevisionsByID(w, r, param(r, "id"))
+}
+
+// GetJobRevisionDiff compares two revisions of the job /v2/jobs/:id
+func (h *Handler) GetJobRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	h.GetJobRevisionDiffByID(w, r, param(r, "id"))
+}
+
+// RollbackJob restores an earlier revision of the job /v2/jobs/:id
+func (h *Handler) RollbackJob(w http.ResponseWriter, r *http.Request) {
+	h.RollbackJobByID(w, r, param(r, "id"))
+}
+
+// AttachJobFile attaches an uploaded document to the job /v2/jobs/:id
+func (h *Handler) AttachJobFile(w http.ResponseWriter, r *http.Request) {
+	h.AttachJobFileByID(w, r, param(r, "id"))
+}
+
+// DetachJobFile removes the attachment /v2/jobs/:id/attachments/:attachment
+func (h *Handler) DetachJobFile(w http.ResponseWriter, r *http.Request) {
+	h.DetachJobFileByID(w, r, param(r, "id"), param(r, "attachment"))
+}
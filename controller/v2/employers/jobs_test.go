@@ -0,0 +1,151 @@
+package employers_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autumnomous-jobs-employer-api/controller/v2/employers"
+	hr "autumnomous-jobs-employer-api/route/middleware/httprouterwrapper"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/memory"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/mergepatch"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	jwt.SetSigningKey("v2-test")
+}
+
+// newRouter serves the v2 job routes over an in-memory app
+func newRouter(t *testing.T) (*httprouter.Router, *memory.Store) {
+
+	application, store := testhelper.NewMemoryApp(&testhelper.Mailer{})
+	handler := employers.NewHandler(application)
+
+	r := httprouter.New()
+	r.GET("/v2/jobs", hr.HandlerFunc(handler.GetJobs))
+	r.POST("/v2/jobs", hr.HandlerFunc(handler.CreateJob))
+	r.GET("/v2/jobs/:id", hr.HandlerFunc(handler.GetJob))
+	r.PATCH("/v2/jobs/:id", hr.HandlerFunc(handler.PatchJob))
+	r.DELETE("/v2/jobs/:id", hr.HandlerFunc(handler.DeleteJob))
+	r.POST("/v2/jobs/:id/restore", hr.HandlerFunc(handler.RestoreJob))
+	r.GET("/v2/jobs/:id/revisions", hr.HandlerFunc(handler.GetJobRevisions))
+	r.GET("/v2/jobs/:id/revisions/diff", hr.HandlerFunc(handler.GetJobRevisionDiff))
+	r.POST("/v2/jobs/:id/revisions/rollback", hr.HandlerFunc(handler.RollbackJob))
+
+	return r, store
+}
+
+// newEmployer returns a token for a new employer with a company
+func newEmployer(t *testing.T, store *memory.Store, email string) string {
+
+	employer, err := store.EmployerRepository().CreateEmployer("First", "Last", email, "password")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	company, err := store.CompanyRepository().GetOrCreateCompany(email, "Company", "", "", "", "", "", "", "", "", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.EmployerRepository().SetEmployerCompany(employer.PublicID, company.PublicID); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.GenerateToken(employer.PublicID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return "Bearer " + base64.StdEncoding.EncodeToString([]byte(token))
+}
+
+func send(r http.Handler, method, target, token, contentType, body string) *httptest.ResponseRecorder {
+
+	request := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	request.Header.Set("Authorization", token)
+	request.Header.Set("Content-Type", contentType)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func Test_V2_Jobs(t *testing.T) {
+	assert := assert.New(t)
+
+	r, store := newRouter(t)
+	token := newEmployer(t, store, "employer@example.com")
+	other := newEmployer(t, store, "other@example.org")
+
+	result := send(r, http.MethodPost, "/v2/jobs", token, "application/json", `{"title":"Welder"}`)
+	assert.Equal(http.StatusOK, result.Code)
+
+	var job jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+
+	result = send(r, http.MethodGet, "/v2/jobs/"+job.PublicID, token, "", "")
+	assert.Equal(http.StatusOK, result.Code)
+	assert.Equal(`"1"`, result.Header().Get("ETag"))
+
+	result = send(r, http.MethodGet, "/v2/jobs/"+job.PublicID, other, "", "")
+	assert.Equal(http.StatusNotFound, result.Code, "another employer's job is not found")
+
+	var problem response.Problem
+	assert.Nil(json.NewDecoder(result.Body).Decode(&problem))
+	assert.Equal(jobs.ErrNotFound.Code, problem.Code)
+
+	result = send(r, http.MethodPatch, "/v2/jobs/"+job.PublicID, token, mergepatch.ContentType, `{"title":"Senior Welder"}`)
+	assert.Equal(http.StatusOK, result.Code)
+	assert.Equal(`"2"`, result.Header().Get("ETag"))
+
+	result = send(r, http.MethodGet, "/v2/jobs/"+job.PublicID+"/revisions", token, "", "")
+	assert.Equal(http.StatusOK, result.Code)
+
+	var revisions struct {
+		Revisions []*jobs.Revision `json:"revisions"`
+	}
+	assert.Nil(json.NewDecoder(result.Body).Decode(&revisions))
+	assert.Len(revisions.Revisions, 2)
+
+	result = send(r, http.MethodGet, "/v2/jobs/"+job.PublicID+"/revisions/diff?from=1&to=2", token, "", "")
+	assert.Equal(http.StatusOK, result.Code)
+
+	var diff struct {
+		Changes json.RawMessage `json:"changes"`
+	}
+	assert.Nil(json.NewDecoder(result.Body).Decode(&diff))
+	assert.JSONEq(`{"title":{"before":"Welder","after":"Senior Welder"}}`, string(diff.Changes))
+
+	result = send(r, http.MethodPost, "/v2/jobs/"+job.PublicID+"/revisions/rollback", token, "application/json", `{"revision":1}`)
+	assert.Equal(http.StatusOK, result.Code)
+
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+	assert.Equal("Welder", job.Title)
+	assert.Equal(3, job.Revision)
+
+	result = send(r, http.MethodPost, "/v2/jobs/"+job.PublicID+"/revisions/rollback", other, "application/json", `{"revision":1}`)
+	assert.Equal(http.StatusNotFound, result.Code, "another employer cannot roll the job back")
+
+	assert.Equal(http.StatusOK, send(r, http.MethodDelete, "/v2/jobs/"+job.PublicID, token, "", "").Code)
+	assert.Equal(http.StatusNotFound, send(r, http.MethodGet, "/v2/jobs/"+job.PublicID, token, "", "").Code)
+
+	assert.Equal(http.StatusOK, send(r, http.MethodPost, "/v2/jobs/"+job.PublicID+"/restore", token, "", "").Code)
+	assert.Equal(http.StatusOK, send(r, http.MethodGet, "/v2/jobs/"+job.PublicID, token, "", "").Code)
+
+	assert.Equal(http.StatusUnauthorized, send(r, http.MethodGet, "/v2/jobs/"+job.PublicID, "Bearer x", "", "").Code)
+}
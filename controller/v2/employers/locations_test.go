@@ -0,0 +1,102 @@
+package employers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"autumnomous-jobs-employer-api/controller/v2/employers"
+	hr "autumnomous-jobs-employer-api/route/middleware/httprouterwrapper"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/mergepatch"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+	"autumnomous-jobs-employer-api/shared/services/zipcode"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+const zipcodes = `zip,city,state,county,latitude,longitude
+15222,Pittsburgh,PA,Allegheny,40.4495,-79.9931
+85004,Phoenix,AZ,Maricopa,33.4513,-112.0686
+`
+
+func Test_V2_Locations(t *testing.T) {
+	assert := assert.New(t)
+
+	application, store := testhelper.NewMemoryApp(&testhelper.Mailer{})
+
+	geocoder, err := zipcode.ReadOffline(strings.NewReader(zipcodes))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	application.Geocoder = geocoder
+	handler := employers.NewHandler(application)
+
+	r := httprouter.New()
+	r.PATCH("/v2/company", hr.HandlerFunc(handler.PatchCompany))
+	r.POST("/v2/jobs", hr.HandlerFunc(handler.CreateJob))
+	r.PATCH("/v2/jobs/:id", hr.HandlerFunc(handler.PatchJob))
+
+	token := newEmployer(t, store, "employer@example.com")
+
+	// a zip code is completed with where it is
+	result := send(r, http.MethodPatch, "/v2/company", token, mergepatch.ContentType, `{"zipcode":"15222"}`)
+	assert.Equal(http.StatusOK, result.Code)
+
+	var company companies.Company
+	assert.Nil(json.NewDecoder(result.Body).Decode(&company))
+	assert.Equal("Pittsburgh, PA", company.Location)
+	assert.Equal("Pittsburgh", company.City)
+	assert.Equal("PA", company.State)
+	assert.Equal("Allegheny", company.County)
+	assert.Equal("US", company.Country)
+	assert.Equal(40.4495, company.Latitude)
+
+	// and coordinates far from it are refused
+	result = send(r, http.MethodPatch, "/v2/company", token, mergepatch.ContentType, `{"latitude":33.4513,"longitude":-112.0686}`)
+	assert.Equal(http.StatusBadRequest, result.Code)
+
+	var problem response.Problem
+	assert.Nil(json.NewDecoder(result.Body).Decode(&problem))
+
+	if assert.Len(problem.Errors, 1) {
+		assert.Equal("latitude", problem.Errors[0].Field)
+		assert.Equal(validation.Inconsistent, problem.Errors[0].Code)
+	}
+
+	// a job's coordinates find the nearest zip code
+	result = send(r, http.MethodPost, "/v2/jobs", token, "application/json", `{"title":"Welder","location":"Downtown","latitude":33.45,"longitude":-112.07}`)
+	assert.Equal(http.StatusOK, result.Code)
+
+	var job jobs.Job
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+	assert.Equal("Downtown", job.Location)
+	assert.Equal("85004", job.ZipCode)
+	assert.Equal("Phoenix", job.City)
+	assert.Equal("Maricopa", job.County)
+
+	// the server, not the client, says where a job is
+	result = send(r, http.MethodPatch, "/v2/jobs/"+job.PublicID, token, mergepatch.ContentType, `{"city":"Tucson"}`)
+	assert.Equal(http.StatusOK, result.Code)
+
+	assert.Nil(json.NewDecoder(result.Body).Decode(&job))
+	assert.Equal("Phoenix", job.City)
+
+	result = send(r, http.MethodPatch, "/v2/jobs/"+job.PublicID, token, mergepatch.ContentType, `{"zipcode":"99999","latitude":null,"longitude":null}`)
+	assert.Equal(http.StatusBadRequest, result.Code)
+
+	problem = response.Problem{}
+	assert.Nil(json.NewDecoder(result.Body).Decode(&problem))
+
+	if assert.Len(problem.Errors, 1) {
+		assert.Equal("zipcode", problem.Errors[0].Field)
+		assert.Equal(validation.Unresolved, problem.Errors[0].Code)
+	}
+}
@@ -0,0 +1,8 @@
+package employers
+
+import "net/http"
+
+// DeleteUpload deletes the upload /v2/uploads/:id
+func (h *Handler) DeleteUpload(w http.ResponseWriter, r *http.Request) {
+	h.DeleteUploadByID(w, r, param(r, "id"))
+}
@@ -15,4 +15,5 @@ require (
 	github.com/mailgun/mailgun-go/v4 v4.5.2
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
+	"time"
 
+	"autumnomous-jobs-employer-api/app"
 	"autumnomous-jobs-employer-api/route"
+	"autumnomous-jobs-employer-api/shared/config"
 	"autumnomous-jobs-employer-api/shared/database"
-
-	"github.com/joho/godotenv"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
 )
 
 func init() {
@@ -22,36 +27,154 @@ func init() {
 
 func main() {
 
-	// ENVIRONMENT if not prod call godotenv package to find .env file
-	if os.Getenv("CLIENT_ENV") != "production" {
-		loadEnv()
+	// `jobsemployer worker` runs only the background worker, for a separate
+	// worker dyno; with no arguments the API is served
+	command := "serve"
+
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	if command != "serve" && command != "worker" {
+		log.Fatalf("unknown command %q, expected serve or worker", command)
 	}
 
+	cfg, err := config.Load()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, warning := range cfg.Warnings() {
+		log.Println("Config warning:", warning)
+	}
+
+	jwt.SetSigningKey(cfg.SigningKey)
+
 	// Connect to databases
 
-	database.Connect("HEROKU_POSTGRESQL_CYAN_URL")
+	db, err := database.Connect(cfg.DatabaseURL)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := database.Migrate(db); err != nil {
+		log.Fatal(err)
+	}
+
+	application, err := app.New(cfg, db)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Cancelled on SIGTERM (Heroku dyno restarts/deploys) or SIGINT (Ctrl-C)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	if command == "worker" {
+		work(ctx, application)
+		return
+	}
+
+	serve(ctx, stop, application)
+}
+
+// serve runs the API, and the worker too unless it runs in its own process
+func serve(ctx context.Context, stop context.CancelFunc, application *app.App) {
+
+	cfg := application.Config
+	server := newServer(cfg, route.LoadRoutes(application))
+
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+
+	workerDone := make(chan struct{})
+
+	if cfg.Worker.InProcess {
+		go func() {
+			application.Worker().Run(workerCtx)
+			close(workerDone)
+		}()
+	} else {
+		close(workerDone)
+	}
+
+	serverErrors := make(chan error, 1)
+
+	go func() {
+		log.Println("Listening on", server.Addr)
+		serverErrors <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErrors:
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	case <-ctx.Done():
+		stop()
+		shutdown(server, application, cfg.Server.ShutdownTimeout, func() {
+			stopWorker()
+			<-workerDone
+		})
+	}
+}
+
+// work runs the worker until ctx is cancelled, then lets the tasks in
+// progress finish before releasing the database pool
+func work(ctx context.Context, application *app.App) {
+
+	log.Println("Worker started with concurrency", application.Config.Worker.Concurrency)
 
-	port := os.Getenv("PORT")
-	if len(port) == 0 {
-		port = "7000"
+	application.Worker().Run(ctx)
+
+	if err := application.Close(); err != nil {
+		log.Println("Database close error:", err)
 	}
 
-	log.Fatal(http.ListenAndServe(":"+port, route.LoadRoutes()))
+	log.Println("Worker stopped")
 }
 
 // *****************************************************************************
-// Application Settings
+// Server
 // *****************************************************************************
 
-func loadEnv() {
+// newServer returns an http.Server with timeouts set so slow or idle clients
+// cannot hold connections open indefinitely
+func newServer(cfg *config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+}
 
-	os.Clearenv()
+// shutdown stops accepting new connections, waits up to timeout for in-flight
+// requests to drain, stops the background workers and then releases the
+// database pool
+func shutdown(server *http.Server, application *app.App, timeout time.Duration, stopWorkers func()) {
 
-	err := godotenv.Load(".env")
+	log.Println("Shutting down, draining connections for up to", timeout)
 
-	if err != nil {
-		log.Println(err)
-		log.Fatal("Error loading .env file:", err)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Println("Graceful shutdown did not complete:", err)
+		server.Close()
+	}
+
+	stopWorkers()
+
+	if err := application.Close(); err != nil {
+		log.Println("Database close error:", err)
 	}
 
+	log.Println("Shutdown complete")
 }
@@ -4,70 +4,68 @@ import (
 	"encoding/base64"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
-	"autumnomous-jobs-employer-api/shared/repository/employers"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
 	"autumnomous-jobs-employer-api/shared/response"
 	jwt "autumnomous-jobs-employer-api/shared/services/security/jwt"
 )
 
-func ValidateJWT(h http.Handler) http.Handler {
+// ValidateJWT allows the request if it carries a valid token for an employer known to repository
+func ValidateJWT(repository accountmanagement.EmployerRepository) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return validateJWT(repository, h)
+	}
+}
+
+func validateJWT(repository accountmanagement.EmployerRepository, h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		s := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
-		if len(s) == 2 {
-
-			b, err := base64.StdEncoding.DecodeString(s[1])
-
-			if err == nil {
-				bearerToken := strings.SplitN(string(b), ":", 2)
-
-				if len(bearerToken) == 1 {
-
-					data, err := jwt.ParseToken(bearerToken[0])
-
-					if err != nil {
-						log.Println(err)
-						response.SendJSONMessage(w, http.StatusBadRequest, "Unauthorized")
-					}
-
-					if data == nil {
-
-						response.SendJSONMessage(w, http.StatusUnauthorized, "Unauthorized")
 
-					} else {
-						userId := data.CustomClaims["user"]
+		if len(s) != 2 {
+			response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid Token")
+			return
+		}
 
-						if userId == "" {
+		b, err := base64.StdEncoding.DecodeString(s[1])
 
-							response.SendJSONMessage(w, http.StatusUnauthorized, "Unauthorized")
-						} else {
+		if err != nil || strings.Contains(string(b), ":") {
+			response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid Token")
+			return
+		}
 
-							repository := employers.NewEmployerRegistry().GetEmployerRepository()
-							_, err := repository.GetEmployer(userId)
+		data, err := jwt.ParseToken(string(b))
 
-							//validate user below
+		if err != nil {
+			log.Println(err)
+			response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+			return
+		}
 
-							if err != nil {
-								response.SendJSONMessage(w, http.StatusUnauthorized, "Unauthorized")
-							} else {
+		if data == nil || data.CustomClaims["user"] == "" {
+			response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+			return
+		}
 
-								h.ServeHTTP(w, req)
-							}
-						}
-					}
-				}
-			}
-		} else {
-			response.SendJSONMessage(w, http.StatusUnauthorized, "Invalid Token")
-			//respond with invalid token
+		//validate user below
+		if _, err := repository.GetEmployer(data.CustomClaims["user"]); err != nil {
+			response.SendProblem(w, http.StatusUnauthorized, response.CodeUnauthorized, response.Unauthorized)
+			return
 		}
+
+		h.ServeHTTP(w, req)
 	})
 }
 
-//AllowAPIKey allows authentication if an API key is present
-func AllowAPIKey(h http.Handler) http.Handler {
+// AllowAPIKey allows authentication if apiKey is present
+func AllowAPIKey(apiKey string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return allowAPIKey(apiKey, h)
+	}
+}
+
+func allowAPIKey(apiKey string, h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		if r.Header.Get("Authorization") != "" {
@@ -76,22 +74,20 @@ func AllowAPIKey(h http.Handler) http.Handler {
 			authKey, err := base64.StdEncoding.DecodeString(string(auth))
 
 			if err != nil {
-				log.Println(err)
-				response.SendJSONMessage(w, http.StatusInternalServerError, response.FriendlyError)
+				response.SendProblem(w, http.StatusUnauthorized, response.CodeInvalidAPIKey, response.InvalidAPIKey)
 				return
 			}
 
-			apiKey := os.Getenv("API_KEY")
 			if strings.Compare(strings.TrimSpace(string(authKey)), apiKey) == 0 && apiKey != "" {
 				h.ServeHTTP(w, r)
 			} else {
-				response.SendJSONMessage(w, http.StatusUnauthorized, response.InvalidAPIKey)
+				response.SendProblem(w, http.StatusUnauthorized, response.CodeInvalidAPIKey, response.InvalidAPIKey)
 			}
 
 			// check for key in db
 
 		} else { // If user is not authenticated, don't allow them to access the page
-			response.SendJSONMessage(w, http.StatusUnauthorized, response.InvalidAPIKey)
+			response.SendProblem(w, http.StatusUnauthorized, response.CodeInvalidAPIKey, response.InvalidAPIKey)
 		}
 
 	})
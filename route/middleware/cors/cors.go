@@ -42,7 +42,10 @@ const (
 	methods           string = "POST, GET, OPTIONS, PUT, DELETE, HEAD, PATCH"
 
 	// If you want to expose some other headers add it here
-	headers string = "Accept, Accept-Encoding, Authorization, Content-Length, Content-Type, X-CSRF-Token"
+	headers string = "Accept, Accept-Encoding, Authorization, Content-Length, Content-Type, ETag, If-Match, X-CSRF-Token"
+
+	// Response headers browsers may read, including those announcing the v1 deprecation
+	exposed string = headers + ", Deprecation, Sunset, Link"
 )
 
 // Handler will allow cross-origin HTTP requests
@@ -59,7 +62,7 @@ func Handler(next http.Handler) http.Handler {
 		w.Header().Set(allow_headers, headers)
 		w.Header().Set(allow_methods, methods)
 		w.Header().Set(allow_credentials, credentials)
-		w.Header().Set(expose_headers, headers)
+		w.Header().Set(expose_headers, exposed)
 
 		// If this was preflight options request let's write empty ok response and return
 		if r.Method == options {
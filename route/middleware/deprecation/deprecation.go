@@ -0,0 +1,32 @@
+// Package deprecation marks the responses of deprecated routes with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers, and links each route
+// to the one replacing it.
+package deprecation
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Handler returns middleware for a route deprecated at deprecated and removed
+// at sunset. successor is the path of the route replacing it; no Link is sent
+// when it is empty, nor a Sunset header when sunset is zero.
+func Handler(deprecated, sunset time.Time, successor string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			w.Header().Set("Deprecation", "@"+strconv.FormatInt(deprecated.Unix(), 10))
+
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+
+			if successor != "" {
+				w.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package deprecation_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/route/middleware/deprecation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Deprecation_Handler(t *testing.T) {
+	assert := assert.New(t)
+
+	deprecated := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	recorder := httptest.NewRecorder()
+	deprecation.Handler(deprecated, sunset, "/v2/jobs")(ok).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/employer/get/jobs", nil))
+
+	assert.Equal("@1792368000", recorder.Header().Get("Deprecation"))
+	assert.Equal("Mon, 19 Apr 2027 00:00:00 GMT", recorder.Header().Get("Sunset"))
+	assert.Equal(`</v2/jobs>; rel="successor-version"`, recorder.Header().Get("Link"))
+
+	recorder = httptest.NewRecorder()
+	deprecation.Handler(deprecated, time.Time{}, "")(ok).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Empty(recorder.Header().Get("Sunset"))
+	assert.Empty(recorder.Header().Get("Link"))
+}
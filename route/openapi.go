@@ -0,0 +1,201 @@
+package route
+
+import (
+	"net/http"
+
+	"autumnomous-jobs-employer-api/controller/v1/admin"
+	"autumnomous-jobs-employer-api/controller/v1/employers"
+	"autumnomous-jobs-employer-api/controller/v1/utilities"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/mergepatch"
+	"autumnomous-jobs-employer-api/shared/services/openapi"
+	"autumnomous-jobs-employer-api/shared/services/presign"
+	"autumnomous-jobs-employer-api/shared/services/zipcode"
+)
+
+// Security schemes of the OpenAPI document
+const (
+	apiKeyScheme   = "apiKey"
+	tokenScheme    = "token"
+	adminKeyScheme = "adminKey"
+)
+
+var jobQuery = openapi.Parameter{Name: "job", Description: "The publicid of the job", Required: true}
+
+var fromQuery = openapi.Parameter{Name: "from", Description: "The revision to compare from", Required: true, Type: "integer"}
+
+var toQuery = openapi.Parameter{Name: "to", Description: "The revision to compare to", Required: true, Type: "integer"}
+
+var limitQuery = openapi.Parameter{Name: "limit", Description: "At most this many results, from 1 to 500", Type: "integer"}
+
+var deletedQuery = openapi.Parameter{Name: "deleted", Description: "Whether to include deleted jobs that can still be restored, false by default", Type: "boolean"}
+
+// operations documents every route in routes. Test_Route_OpenAPI fails when a
+// route is missing. The client package has a method for each operation with
+// an ID, so deprecated routes have none.
+var operations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/openapi.json", Summary: "This document", Tags: []string{"meta"}},
+	{Method: http.MethodGet, Path: "/docs", Summary: "Swagger UI for this document, outside production", Tags: []string{"meta"}},
+
+	{ID: "uploadImage", Method: http.MethodPost, Path: "/upload/image", Summary: "Upload a PNG, JPEG, GIF or WebP image, stored as logo and thumbnail variants", Tags: []string{"uploads"}, Security: apiKeyScheme,
+		Request: openapi.Form{"file"}, Response: utilities.UploadResponse{}},
+	{ID: "presignUpload", Method: http.MethodPost, Path: "/upload/presign", Summary: "Declare a file and get a presigned URL to PUT it to, straight to storage", Tags: []string{"uploads"}, Security: tokenScheme,
+		Request: presign.Request{}, Response: presign.Presigned{}, Status: http.StatusCreated},
+	{ID: "completeUpload", Method: http.MethodPost, Path: "/upload/complete", Summary: "Check the file PUT to a presigned URL, so the upload can be used", Tags: []string{"uploads"}, Security: tokenScheme,
+		Request: presign.UploadReference{}, Response: uploads.Upload{}},
+	{Method: http.MethodGet, Path: "/uploads/{key}", Summary: "A file kept by the local storage driver, when it is selected", Tags: []string{"uploads"}},
+	{Method: http.MethodPut, Path: "/uploads/{key}", Summary: "Store the body as a file of the local storage driver, at a URL it signed", Tags: []string{"uploads"},
+		Query: []openapi.Parameter{{Name: "expires", Description: "When the URL expires, in seconds since 1970", Required: true, Type: "integer"}, {Name: "signature", Description: "The signature of the URL", Required: true}}},
+
+	{ID: "signUp", Method: http.MethodPost, Path: "/employer/signup", Summary: "Create an account, emailing a temporary password", Tags: []string{"account"}, Security: apiKeyScheme,
+		Request: employers.SignUpCredentials{}, Response: ""},
+	{ID: "login", Method: http.MethodPost, Path: "/employer/login", Summary: "Log in", Tags: []string{"account"}, Security: apiKeyScheme,
+		Request: employers.LoginCredentials{}, Response: employers.LoginResponse{}},
+
+	{ID: "updatePassword", Method: http.MethodPost, Path: "/employer/update-password", Summary: "Change the password", Tags: []string{"account"}, Security: tokenScheme,
+		Request: employers.UpdatePasswordCredentials{}, Response: response.CoreResponse{}},
+	{Method: http.MethodPost, Path: "/employer/update-account", Summary: "Update the account, leaving empty fields unchanged", Tags: []string{"account"}, Security: tokenScheme,
+		Request: employers.UpdateAccountData{}, Response: accountmanagement.Employer{}, Deprecated: true},
+	{Method: http.MethodPatch, Path: "/employer/update-account", Summary: "Apply a JSON merge patch to the account", Tags: []string{"account"}, Security: tokenScheme,
+		Request: accountmanagement.Account{}, MediaType: mergepatch.ContentType, Response: accountmanagement.Employer{}, Deprecated: true},
+	{Method: http.MethodPost, Path: "/employer/update-company", Summary: "Update the company, leaving empty fields unchanged", Tags: []string{"company"}, Security: tokenScheme,
+		Request: employers.UpdateCompanyData{}, Response: companies.Company{}, Deprecated: true},
+	{Method: http.MethodPatch, Path: "/employer/update-company", Summary: "Apply a JSON merge patch to the company", Tags: []string{"company"}, Security: tokenScheme,
+		Request: companies.Profile{}, MediaType: mergepatch.ContentType, Response: companies.Company{}, Deprecated: true},
+	{ID: "updatePaymentMethod", Method: http.MethodPost, Path: "/employer/update-payment-method", Summary: "Set the payment method", Tags: []string{"account"}, Security: tokenScheme,
+		Request: employers.UpdatePaymentMethodData{}},
+	{ID: "updatePaymentDetails", Method: http.MethodPost, Path: "/employer/update-payment-details", Summary: "Set the payment details", Tags: []string{"account"}, Security: tokenScheme,
+		Request: employers.UpdatePaymentDetailsData{}},
+
+	{Method: http.MethodGet, Path: "/employer/get", Summary: "Get the account", Tags: []string{"account"}, Security: tokenScheme,
+		Response: accountmanagement.Employer{}, Deprecated: true},
+	{Method: http.MethodGet, Path: "/employer/get/company", Summary: "Get the company", Tags: []string{"company"}, Security: tokenScheme,
+		Response: companies.Company{}, Deprecated: true},
+	{Method: http.MethodPost, Path: "/employer/create/job", Summary: "Create a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Request: jobs.RevisionContent{}, Response: jobs.Job{}, Deprecated: true},
+	{Method: http.MethodPost, Path: "/employer/edit/job", Summary: "Edit a job, leaving empty fields unchanged", Tags: []string{"jobs"}, Security: tokenScheme,
+		Request: employers.EditJobDetails{}, Response: jobs.Job{}, Deprecated: true},
+	{Method: http.MethodPatch, Path: "/employer/edit/job", Summary: "Apply a JSON merge patch to a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Query: []openapi.Parameter{jobQuery}, Request: jobs.RevisionContent{}, MediaType: mergepatch.ContentType, Response: jobs.Job{}, Deprecated: true},
+	{Method: http.MethodGet, Path: "/employer/get/jobs", Summary: "List the jobs", Tags: []string{"jobs"}, Security: tokenScheme,
+		Query: []openapi.Parameter{deletedQuery}, Response: []*jobs.Job{}, Deprecated: true},
+	{Method: http.MethodPost, Path: "/employer/get/job", Summary: "Get a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Request: employers.GetJobDetails{}, Response: jobs.Job{}, Deprecated: true},
+	{Method: http.MethodDelete, Path: "/employer/delete/job", Summary: "Delete a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Request: employers.DeleteJobDetails{}, Response: jobs.Job{}, Deprecated: true},
+	{Method: http.MethodPost, Path: "/employer/restore/job", Summary: "Restore a deleted job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Request: employers.DeleteJobDetails{}, Response: jobs.Job{}, Deprecated: true},
+	{Method: http.MethodGet, Path: "/employer/get/job/revisions", Summary: "List the revisions of a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Query: []openapi.Parameter{jobQuery}, Response: employers.RevisionsResponse{}, Deprecated: true},
+	{Method: http.MethodGet, Path: "/employer/get/job/revisions/diff", Summary: "Compare two revisions of a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Query: []openapi.Parameter{jobQuery, fromQuery, toQuery}, Response: employers.RevisionDiffResponse{}, Deprecated: true},
+	{Method: http.MethodPost, Path: "/employer/rollback/job", Summary: "Restore the content of an earlier revision", Tags: []string{"jobs"}, Security: tokenScheme,
+		Request: employers.RollbackJobDetails{}, Response: jobs.Job{}, Deprecated: true},
+	{ID: "getActiveJobPackages", Method: http.MethodGet, Path: "/employer/get/jobpackages/active", Summary: "List the job packages on sale", Tags: []string{"job packages"}, Security: tokenScheme,
+		Response: []*jobpackages.JobPackage{}},
+	{ID: "autocompleteLocation", Method: http.MethodPost, Path: "/employer/get/location/autocomplete", Summary: "Suggest cities", Tags: []string{"locations"}, Security: tokenScheme,
+		Request: employers.AutocompleteLocationData{}, Response: []zipcode.CityAutoCompleteResponse{}},
+
+	{ID: "createWebhook", Method: http.MethodPost, Path: "/employer/create/webhook", Summary: "Subscribe a URL to events", Tags: []string{"webhooks"}, Security: tokenScheme,
+		Request: employers.CreateWebhookDetails{}, Response: webhooks.Webhook{}},
+	{ID: "getWebhooks", Method: http.MethodGet, Path: "/employer/get/webhooks", Summary: "List the webhooks and the events they can subscribe to", Tags: []string{"webhooks"}, Security: tokenScheme,
+		Response: employers.WebhooksResponse{}},
+	{ID: "deleteWebhook", Method: http.MethodDelete, Path: "/employer/delete/webhook", Summary: "Delete a webhook", Tags: []string{"webhooks"}, Security: tokenScheme,
+		Request: employers.WebhookDetails{}, Response: response.CoreResponse{}},
+	{ID: "getWebhookDeliveries", Method: http.MethodGet, Path: "/employer/get/webhook/deliveries", Summary: "List the deliveries of a webhook", Tags: []string{"webhooks"}, Security: tokenScheme,
+		Query: []openapi.Parameter{{Name: "webhook", Description: "The publicid of the webhook", Required: true}, limitQuery}, Response: employers.DeliveriesResponse{}},
+	{ID: "redeliverWebhook", Method: http.MethodPost, Path: "/employer/redeliver/webhook", Summary: "Send a delivery again", Tags: []string{"webhooks"}, Security: tokenScheme,
+		Request: employers.WebhookDetails{}, Response: webhooks.Delivery{}},
+	{ID: "pingWebhook", Method: http.MethodPost, Path: "/employer/ping/webhook", Summary: "Send a ping event to a webhook now", Tags: []string{"webhooks"}, Security: tokenScheme,
+		Request: employers.WebhookDetails{}, Response: webhooks.Delivery{}},
+
+	{ID: "getAudit", Method: http.MethodGet, Path: "/employer/audit", Summary: "Read the company's audit log", Tags: []string{"audit"}, Security: tokenScheme,
+		Query: []openapi.Parameter{
+			{Name: "actor", Description: "The publicid of the employer who made the change"},
+			{Name: "action"},
+			{Name: "targettype"},
+			{Name: "target", Description: "The publicid of the changed resource"},
+			limitQuery,
+			{Name: "since", Description: "An RFC 3339 time"},
+			{Name: "until", Description: "An RFC 3339 time"},
+		},
+		Response: employers.AuditResponse{}},
+
+	{ID: "purchaseJobPackage", Method: http.MethodPost, Path: "/employer/buy/job-package", Summary: "Buy a job package", Tags: []string{"job packages"}, Security: tokenScheme,
+		Request: employers.PurchaseJobPackageDetails{}, Response: response.CoreResponse{}},
+
+	{ID: "getAccount", Method: http.MethodGet, Path: "/v2/me", Summary: "Get the account", Tags: []string{"account"}, Security: tokenScheme,
+		Response: accountmanagement.Employer{}},
+	{ID: "patchAccount", Method: http.MethodPatch, Path: "/v2/me", Summary: "Apply a JSON merge patch to the account", Tags: []string{"account"}, Security: tokenScheme,
+		Request: accountmanagement.Account{}, MediaType: mergepatch.ContentType, Response: accountmanagement.Employer{}},
+	{ID: "getCompany", Method: http.MethodGet, Path: "/v2/company", Summary: "Get the company", Tags: []string{"company"}, Security: tokenScheme,
+		Response: companies.Company{}},
+	{ID: "patchCompany", Method: http.MethodPatch, Path: "/v2/company", Summary: "Apply a JSON merge patch to the company", Tags: []string{"company"}, Security: tokenScheme,
+		Request: companies.Profile{}, MediaType: mergepatch.ContentType, Response: companies.Company{}},
+	{ID: "uploadCompanyLogo", Method: http.MethodPost, Path: "/v2/company/logo", Summary: "Upload a PNG, JPEG, GIF or WebP image as the logo, stored as logo and thumbnail variants", Tags: []string{"company"}, Security: tokenScheme,
+		Request: openapi.Form{"file"}, Response: companies.Company{}},
+	{ID: "setCompanyLogo", Method: http.MethodPut, Path: "/v2/company/logo", Summary: "Make a complete upload the logo, stored as logo and thumbnail variants", Tags: []string{"company"}, Security: tokenScheme,
+		Request: presign.UploadReference{}, Response: companies.Company{}},
+	{ID: "listJobs", Method: http.MethodGet, Path: "/v2/jobs", Summary: "List the jobs", Tags: []string{"jobs"}, Security: tokenScheme,
+		Query: []openapi.Parameter{deletedQuery}, Response: []*jobs.Job{}},
+	{ID: "createJob", Method: http.MethodPost, Path: "/v2/jobs", Summary: "Create a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Request: jobs.RevisionContent{}, Response: jobs.Job{}},
+	{ID: "getJob", Method: http.MethodGet, Path: "/v2/jobs/:id", Summary: "Get a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Response: jobs.Job{}},
+	{ID: "patchJob", Method: http.MethodPatch, Path: "/v2/jobs/:id", Summary: "Apply a JSON merge patch to a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Request: jobs.RevisionContent{}, MediaType: mergepatch.ContentType, Response: jobs.Job{}},
+	{ID: "deleteJob", Method: http.MethodDelete, Path: "/v2/jobs/:id", Summary: "Delete a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Response: jobs.Job{}},
+	{ID: "restoreJob", Method: http.MethodPost, Path: "/v2/jobs/:id/restore", Summary: "Restore a deleted job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Response: jobs.Job{}},
+	{ID: "getJobRevisions", Method: http.MethodGet, Path: "/v2/jobs/:id/revisions", Summary: "List the revisions of a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Response: employers.RevisionsResponse{}},
+	{ID: "getJobRevisionDiff", Method: http.MethodGet, Path: "/v2/jobs/:id/revisions/diff", Summary: "Compare two revisions of a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Query: []openapi.Parameter{fromQuery, toQuery}, Response: employers.RevisionDiffResponse{}},
+	{ID: "rollbackJob", Method: http.MethodPost, Path: "/v2/jobs/:id/revisions/rollback", Summary: "Restore the content of an earlier revision", Tags: []string{"jobs"}, Security: tokenScheme,
+		Request: employers.RollbackDetails{}, Response: jobs.Job{}},
+	{ID: "attachJobFile", Method: http.MethodPost, Path: "/v2/jobs/:id/attachments", Summary: "Attach an uploaded PDF or DOCX document to a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Request: presign.UploadReference{}, Response: jobs.Attachment{}, Status: http.StatusCreated},
+	{ID: "detachJobFile", Method: http.MethodDelete, Path: "/v2/jobs/:id/attachments/:attachment", Summary: "Remove an attachment from a job", Tags: []string{"jobs"}, Security: tokenScheme,
+		Response: jobs.Attachment{}},
+	{ID: "listUploads", Method: http.MethodGet, Path: "/v2/uploads", Summary: "List the uploads, newest first", Tags: []string{"uploads"}, Security: tokenScheme,
+		Response: []*uploads.Upload{}},
+	{ID: "deleteUpload", Method: http.MethodDelete, Path: "/v2/uploads/:id", Summary: "Delete an upload nothing uses", Tags: []string{"uploads"}, Security: tokenScheme,
+		Response: uploads.Upload{}},
+
+	{ID: "getQueue", Method: http.MethodGet, Path: "/admin/queue", Summary: "Inspect the task queue", Tags: []string{"admin"}, Security: adminKeyScheme,
+		Query: []openapi.Parameter{{Name: "status", Description: "Tasks in this status, dead by default"}, limitQuery}, Response: admin.QueueResponse{}},
+	{ID: "requeueTask", Method: http.MethodPost, Path: "/admin/queue/requeue", Summary: "Give a dead task a fresh set of attempts", Tags: []string{"admin"}, Security: adminKeyScheme,
+		Request: admin.RequeueDetails{}, Response: response.CoreResponse{}},
+}
+
+// Operations returns the operations of the API, in the order Document adds them
+func Operations() []openapi.Operation {
+	return append([]openapi.Operation(nil), operations...)
+}
+
+// Document returns the OpenAPI document of the API
+func Document() *openapi.Document {
+
+	builder := openapi.NewBuilder(openapi.Info{
+		Title:   "Autumnomous Jobs Employer API",
+		Version: "2.0.0",
+		Description: "Errors are RFC 7807 problems whose code is stable. " +
+			"The /employer routes are deprecated in favour of /v2 where it has a replacement.",
+	}, map[string]*openapi.SecurityScheme{
+		apiKeyScheme:   {Type: "http", Scheme: "bearer", Description: "The base64 encoded API key"},
+		tokenScheme:    {Type: "http", Scheme: "bearer", Description: "The token returned by /employer/login"},
+		adminKeyScheme: {Type: "http", Scheme: "bearer", Description: "The base64 encoded admin API key"},
+	})
+
+	builder.Problem = response.Problem{}
+	builder.Add(operations...)
+
+	return builder.Document()
+}
@@ -2,61 +2,180 @@ package route
 
 import (
 	"net/http"
+	"time"
 
+	"autumnomous-jobs-employer-api/app"
+	"autumnomous-jobs-employer-api/controller/v1/admin"
 	"autumnomous-jobs-employer-api/controller/v1/employers"
 	"autumnomous-jobs-employer-api/controller/v1/utilities"
+	v2employers "autumnomous-jobs-employer-api/controller/v2/employers"
 	"autumnomous-jobs-employer-api/route/middleware/acl"
 	"autumnomous-jobs-employer-api/route/middleware/cors"
+	"autumnomous-jobs-employer-api/route/middleware/deprecation"
 	hr "autumnomous-jobs-employer-api/route/middleware/httprouterwrapper"
 	"autumnomous-jobs-employer-api/route/middleware/logrequest"
+	"autumnomous-jobs-employer-api/shared/config"
+	"autumnomous-jobs-employer-api/shared/services/openapi"
+	"autumnomous-jobs-employer-api/shared/services/storage"
 
 	"github.com/gorilla/context"
 	"github.com/julienschmidt/httprouter"
 	"github.com/justinas/alice"
 )
 
+// v1Deprecated is when the v1 routes replaced by /v2 were deprecated
+var v1Deprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
 // LoadRoutes returns the routes and middleware
-func LoadRoutes() http.Handler {
+func LoadRoutes(application *app.App) http.Handler {
 	//return routes()
-	return middleware(routes())
+	return middleware(routes(application))
 }
 
 // *****************************************************************************
 // Routes
 // *****************************************************************************
 
-func routes() *httprouter.Router {
-	r := httprouter.New()
+// Route is a method and path served by the API
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Routes lists every route, for checking each is documented
+func Routes(application *app.App) []Route {
+	return routes(application).routes
+}
+
+// router records the routes registered on it
+type router struct {
+	*httprouter.Router
+	routes []Route
+}
+
+func (r *router) Handle(method, path string, handle httprouter.Handle) {
+	r.routes = append(r.routes, Route{Method: method, Path: path})
+	r.Router.Handle(method, path, handle)
+}
 
-	r.POST("/upload/image", hr.Handler(alice.New(acl.AllowAPIKey).ThenFunc(utilities.UploadImage)))
+func (r *router) GET(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodGet, path, handle)
+}
 
-	r.POST("/employer/signup", hr.Handler(alice.New(acl.AllowAPIKey).ThenFunc(employers.SignUp)))
-	r.POST("/employer/login", hr.Handler(alice.New(acl.AllowAPIKey).ThenFunc(employers.Login)))
+func (r *router) POST(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPost, path, handle)
+}
 
-	r.POST("/employer/update-password", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.UpdatePassword)))
-	r.POST("/employer/update-account", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.UpdateAccount)))
-	r.POST("/employer/update-company", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.UpdateCompany)))
-	r.POST("/employer/update-payment-method", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.UpdatePaymentMethod)))
-	r.POST("/employer/update-payment-details", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.UpdatePaymentDetails)))
+func (r *router) PATCH(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPatch, path, handle)
+}
 
-	r.GET("/employer/get", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.GetEmployer)))
-	r.GET("/employer/get/company", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.GetEmployerCompany)))
-	r.POST("/employer/create/job", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.CreateJob)))
-	r.POST("/employer/edit/job", hr.Handler(alice.New(acl.ValidateJWT, acl.ValidateJWT).ThenFunc(employers.EditJob)))
-	r.GET("/employer/get/jobs", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.GetJobs)))
-	r.POST("/employer/get/job", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.GetJob)))
-	r.DELETE("/employer/delete/job", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.DeleteJob)))
-	r.GET("/employer/get/jobpackages/active", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.GetActiveJobPackages)))
-	r.POST("/employer/get/location/autocomplete", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.GetAutocompleteLocationData)))
+func (r *router) PUT(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPut, path, handle)
+}
+
+func (r *router) DELETE(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodDelete, path, handle)
+}
 
-	r.POST("/employer/buy/job-package", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(employers.PurchaseJobPackage)))
+func routes(application *app.App) *router {
+	r := &router{Router: httprouter.New()}
+
+	employer := employers.NewHandler(application)
+	utility := utilities.NewHandler(application)
+	operator := admin.NewHandler(application)
+	resources := v2employers.NewHandler(application)
+
+	apiKey := acl.AllowAPIKey(application.Config.APIKey)
+	validateJWT := acl.ValidateJWT(application.Employers)
+	adminKey := acl.AllowAPIKey(application.Config.AdminAPIKey)
+
+	// replacedBy marks a v1 route as deprecated in favour of a v2 route
+	replacedBy := func(successor string) alice.Constructor {
+		return deprecation.Handler(v1Deprecated, application.Config.V1Sunset, successor)
+	}
+
+	r.GET("/openapi.json", hr.HandlerFunc(openapi.Handler(Document())))
+
+	if !application.Config.IsProduction() {
+		r.GET("/docs", hr.HandlerFunc(openapi.UI("/openapi.json")))
+	}
+
+	r.POST("/upload/image", hr.Handler(alice.New(apiKey).ThenFunc(utility.UploadImage)))
+	r.POST("/upload/presign", hr.Handler(alice.New(validateJWT).ThenFunc(utility.PresignUpload)))
+	r.POST("/upload/complete", hr.Handler(alice.New(validateJWT).ThenFunc(utility.CompleteUpload)))
+
+	if application.Config.Storage.Driver == config.StorageLocal {
+		r.GET(storage.LocalPath+"/*key", hr.HandlerFunc(utility.ServeFile))
+		r.PUT(storage.LocalPath+"/*key", hr.HandlerFunc(utility.ServeFile))
+	}
+
+	r.POST("/employer/signup", hr.Handler(alice.New(apiKey).ThenFunc(employer.SignUp)))
+	r.POST("/employer/login", hr.Handler(alice.New(apiKey).ThenFunc(employer.Login)))
+
+	r.POST("/employer/update-password", hr.Handler(alice.New(validateJWT).ThenFunc(employer.UpdatePassword)))
+	r.POST("/employer/update-account", hr.Handler(alice.New(replacedBy("/v2/me"), validateJWT).ThenFunc(employer.UpdateAccount)))
+	r.POST("/employer/update-company", hr.Handler(alice.New(replacedBy("/v2/company"), validateJWT).ThenFunc(employer.UpdateCompany)))
+	r.PATCH("/employer/update-account", hr.Handler(alice.New(replacedBy("/v2/me"), validateJWT).ThenFunc(employer.PatchAccount)))
+	r.PATCH("/employer/update-company", hr.Handler(alice.New(replacedBy("/v2/company"), validateJWT).ThenFunc(employer.PatchCompany)))
+	r.POST("/employer/update-payment-method", hr.Handler(alice.New(validateJWT).ThenFunc(employer.UpdatePaymentMethod)))
+	r.POST("/employer/update-payment-details", hr.Handler(alice.New(validateJWT).ThenFunc(employer.UpdatePaymentDetails)))
+
+	r.GET("/employer/get", hr.Handler(alice.New(replacedBy("/v2/me"), validateJWT).ThenFunc(employer.GetEmployer)))
+	r.GET("/employer/get/company", hr.Handler(alice.New(replacedBy("/v2/company"), validateJWT).ThenFunc(employer.GetEmployerCompany)))
+	r.POST("/employer/create/job", hr.Handler(alice.New(replacedBy("/v2/jobs"), validateJWT).ThenFunc(employer.CreateJob)))
+	r.POST("/employer/edit/job", hr.Handler(alice.New(replacedBy("/v2/jobs"), validateJWT).ThenFunc(employer.EditJob)))
+	r.PATCH("/employer/edit/job", hr.Handler(alice.New(replacedBy("/v2/jobs"), validateJWT).ThenFunc(employer.PatchJob)))
+	r.GET("/employer/get/jobs", hr.Handler(alice.New(replacedBy("/v2/jobs"), validateJWT).ThenFunc(employer.GetJobs)))
+	r.POST("/employer/get/job", hr.Handler(alice.New(replacedBy("/v2/jobs"), validateJWT).ThenFunc(employer.GetJob)))
+	r.DELETE("/employer/delete/job", hr.Handler(alice.New(replacedBy("/v2/jobs"), validateJWT).ThenFunc(employer.DeleteJob)))
+	r.POST("/employer/restore/job", hr.Handler(alice.New(replacedBy("/v2/jobs"), validateJWT).ThenFunc(employer.RestoreJob)))
+	r.GET("/employer/get/job/revisions", hr.Handler(alice.New(replacedBy("/v2/jobs"), validateJWT).ThenFunc(employer.GetJobRevisions)))
+	r.GET("/employer/get/job/revisions/diff", hr.Handler(alice.New(replacedBy("/v2/jobs"), validateJWT).ThenFunc(employer.GetJobRevisionDiff)))
+	r.POST("/employer/rollback/job", hr.Handler(alice.New(replacedBy("/v2/jobs"), validateJWT).ThenFunc(employer.RollbackJob)))
+	r.GET("/employer/get/jobpackages/active", hr.Handler(alice.New(validateJWT).ThenFunc(employer.GetActiveJobPackages)))
+	r.POST("/employer/get/location/autocomplete", hr.Handler(alice.New(validateJWT).ThenFunc(employer.GetAutocompleteLocationData)))
+
+	r.POST("/employer/create/webhook", hr.Handler(alice.New(validateJWT).ThenFunc(employer.CreateWebhook)))
+	r.GET("/employer/get/webhooks", hr.Handler(alice.New(validateJWT).ThenFunc(employer.GetWebhooks)))
+	r.DELETE("/employer/delete/webhook", hr.Handler(alice.New(validateJWT).ThenFunc(employer.DeleteWebhook)))
+	r.GET("/employer/get/webhook/deliveries", hr.Handler(alice.New(validateJWT).ThenFunc(employer.GetWebhookDeliveries)))
+	r.POST("/employer/redeliver/webhook", hr.Handler(alice.New(validateJWT).ThenFunc(employer.RedeliverWebhook)))
+	r.POST("/employer/ping/webhook", hr.Handler(alice.New(validateJWT).ThenFunc(employer.PingWebhook)))
+
+	r.GET("/employer/audit", hr.Handler(alice.New(validateJWT).ThenFunc(employer.GetAudit)))
+
+	r.POST("/employer/buy/job-package", hr.Handler(alice.New(validateJWT).ThenFunc(employer.PurchaseJobPackage)))
+
+	r.GET("/v2/me", hr.Handler(alice.New(validateJWT).ThenFunc(resources.GetEmployer)))
+	r.PATCH("/v2/me", hr.Handler(alice.New(validateJWT).ThenFunc(resources.PatchAccount)))
+	r.GET("/v2/company", hr.Handler(alice.New(validateJWT).ThenFunc(resources.GetEmployerCompany)))
+	r.PATCH("/v2/company", hr.Handler(alice.New(validateJWT).ThenFunc(resources.PatchCompany)))
+	r.POST("/v2/company/logo", hr.Handler(alice.New(validateJWT).ThenFunc(resources.UploadCompanyLogo)))
+	r.PUT("/v2/company/logo", hr.Handler(alice.New(validateJWT).ThenFunc(resources.SetCompanyLogo)))
+	r.GET("/v2/jobs", hr.Handler(alice.New(validateJWT).ThenFunc(resources.GetJobs)))
+	r.POST("/v2/jobs", hr.Handler(alice.New(validateJWT).ThenFunc(resources.CreateJob)))
+	r.GET("/v2/jobs/:id", hr.Handler(alice.New(validateJWT).ThenFunc(resources.GetJob)))
+	r.PATCH("/v2/jobs/:id", hr.Handler(alice.New(validateJWT).ThenFunc(resources.PatchJob)))
+	r.DELETE("/v2/jobs/:id", hr.Handler(alice.New(validateJWT).ThenFunc(resources.DeleteJob)))
+	r.POST("/v2/jobs/:id/restore", hr.Handler(alice.New(validateJWT).ThenFunc(resources.RestoreJob)))
+	r.GET("/v2/jobs/:id/revisions", hr.Handler(alice.New(validateJWT).ThenFunc(resources.GetJobRevisions)))
+	r.GET("/v2/jobs/:id/revisions/diff", hr.Handler(alice.New(validateJWT).ThenFunc(resources.GetJobRevisionDiff)))
+	r.POST("/v2/jobs/:id/revisions/rollback", hr.Handler(alice.New(validateJWT).ThenFunc(resources.RollbackJob)))
+	r.POST("/v2/jobs/:id/attachments", hr.Handler(alice.New(validateJWT).ThenFunc(resources.AttachJobFile)))
+	r.DELETE("/v2/jobs/:id/attachments/:attachment", hr.Handler(alice.New(validateJWT).ThenFunc(resources.DetachJobFile)))
+	r.GET("/v2/uploads", hr.Handler(alice.New(validateJWT).ThenFunc(resources.GetUploads)))
+	r.DELETE("/v2/uploads/:id", hr.Handler(alice.New(validateJWT).ThenFunc(resources.DeleteUpload)))
+
+	r.GET("/admin/queue", hr.Handler(alice.New(adminKey).ThenFunc(operator.GetQueue)))
+	r.POST("/admin/queue/requeue", hr.Handler(alice.New(adminKey).ThenFunc(operator.RequeueTask)))
 
 	// r.POST("/get-user", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(users.GetUser)))
 
-	// r.GET("/get/client/registration", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(clients.CheckRegistration)))
-	// r.POST("/set/client/registration", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(clients.SetRegistration)))
+	// r.GET("/get/client/registration", hr.Handler(alice.New(validateJWT).ThenFunc(clients.CheckRegistration)))
+	// r.POST("/set/client/registration", hr.Handler(alice.New(validateJWT).ThenFunc(clients.SetRegistration)))
 
-	// r.GET("/customers/:id", hr.Handler(alice.New(acl.ValidateJWT).ThenFunc(clients.GetClientCustomers)))
+	// r.GET("/customers/:id", hr.Handler(alice.New(validateJWT).ThenFunc(clients.GetClientCustomers)))
 	// Enable Pprof
 	// r.GET("/debug/pprof/*pprof", hr.Handler(alice.
 	// 	New(acl.ValidateJWT).
@@ -0,0 +1,104 @@
+package route_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autumnomous-jobs-employer-api/route"
+	"autumnomous-jobs-employer-api/shared/config"
+	"autumnomous-jobs-employer-api/shared/services/openapi"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Route_V1IsDeprecated(t *testing.T) {
+	assert := assert.New(t)
+
+	jwt.SetSigningKey("route-test")
+
+	application, store := testhelper.NewMemoryApp(&testhelper.Mailer{})
+
+	employer, err := store.EmployerRepository().CreateEmployer("First", "Last", "employer@example.com", "password")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.GenerateToken(employer.PublicID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := route.LoadRoutes(application)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		request.Header.Set("Authorization", "Bearer "+base64.StdEncoding.EncodeToString([]byte(token)))
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	v1 := get("/employer/get")
+	assert.Equal(http.StatusOK, v1.Code)
+	assert.NotEmpty(v1.Header().Get("Deprecation"))
+	assert.Equal("Mon, 19 Apr 2027 00:00:00 GMT", v1.Header().Get("Sunset"))
+	assert.Equal(`</v2/me>; rel="successor-version"`, v1.Header().Get("Link"))
+
+	v2 := get("/v2/me")
+	assert.Equal(http.StatusOK, v2.Code)
+	assert.Empty(v2.Header().Get("Deprecation"))
+	assert.JSONEq(v1.Body.String(), v2.Body.String())
+}
+
+func Test_Route_OpenAPI(t *testing.T) {
+	assert := assert.New(t)
+
+	application, _ := testhelper.NewMemoryApp(&testhelper.Mailer{})
+
+	document := route.Document()
+	registered := map[string]bool{}
+
+	for _, r := range route.Routes(application) {
+		path, _ := openapi.Path(r.Path)
+		method := strings.ToLower(r.Method)
+		registered[method+" "+path] = true
+
+		assert.NotNil(document.Paths[path][method], "%s %s has no OpenAPI operation, add one to operations in route/openapi.go", r.Method, r.Path)
+	}
+
+	for path, endpoints := range document.Paths {
+		for method := range endpoints {
+			assert.True(registered[method+" "+path], "%s %s is documented but not routed", method, path)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	route.LoadRoutes(application).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(http.StatusOK, recorder.Code)
+
+	var served openapi.Document
+	assert.Nil(json.NewDecoder(recorder.Body).Decode(&served))
+	assert.Equal(openapi.Version, served.OpenAPI)
+	assert.Contains(served.Components.Schemas, "Job")
+}
+
+func Test_Route_OpenAPI_NoUIInProduction(t *testing.T) {
+	assert := assert.New(t)
+
+	application, _ := testhelper.NewMemoryApp(&testhelper.Mailer{})
+	application.Config.Environment = config.Production
+
+	for _, r := range route.Routes(application) {
+		assert.NotEqual("/docs", r.Path)
+	}
+}
@@ -0,0 +1,535 @@
+// Package config loads the typed application configuration from YAML profile
+// files, a .env file and the process environment, and validates it at startup.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Production is the profile used on Heroku
+	Production = "production"
+
+	// Development is the default profile for local work
+	Development = "development"
+
+	// Test is the profile used by the test suites
+	Test = "test"
+)
+
+const (
+	// StorageS3 keeps uploads in the S3 compatible bucket set by Spaces
+	StorageS3 = "s3"
+
+	// StorageLocal keeps uploads in a directory the API serves itself
+	StorageLocal = "local"
+)
+
+const (
+	// ScanNone publishes uploads without scanning them
+	ScanNone = "none"
+
+	// ScanPolicy checks the size and type of uploads
+	ScanPolicy = "policy"
+
+	// ScanClamAV checks the size and type of uploads, then has the ClamAV
+	// daemon at Scan.ClamAV scan them
+	ScanClamAV = "clamav"
+)
+
+// Config holds every setting the API reads at runtime
+type Config struct {
+	Environment string `yaml:"environment"`
+	Port        string `yaml:"port"`
+	DatabaseURL string `yaml:"databaseurl"`
+	APIKey      string `yaml:"apikey"`
+	SigningKey  string `yaml:"signingkey"`
+
+	// AdminAPIKey guards the operator endpoints under /admin, which are
+	// disabled when it is empty
+	AdminAPIKey string `yaml:"adminapikey"`
+
+	// V1Sunset is when the deprecated v1 routes will be removed, announced in
+	// their Sunset header. No header is sent when it is zero.
+	V1Sunset time.Time `yaml:"v1sunset"`
+
+	Server          Server          `yaml:"server"`
+	Worker          Worker          `yaml:"worker"`
+	Storage         Storage         `yaml:"storage"`
+	Scan            Scan            `yaml:"scan"`
+	Spaces          Spaces          `yaml:"spaces"`
+	Mailgun         Mailgun         `yaml:"mailgun"`
+	ZipCodeServices ZipCodeServices `yaml:"zipcodeservices"`
+}
+
+// Server holds the http.Server limits
+type Server struct {
+	ReadHeaderTimeout time.Duration `yaml:"readheadertimeout"`
+	ReadTimeout       time.Duration `yaml:"readtimeout"`
+	WriteTimeout      time.Duration `yaml:"writetimeout"`
+	IdleTimeout       time.Duration `yaml:"idletimeout"`
+	MaxHeaderBytes    int           `yaml:"maxheaderbytes"`
+	ShutdownTimeout   time.Duration `yaml:"shutdowntimeout"`
+}
+
+// Worker holds the background task worker settings
+type Worker struct {
+	Concurrency int `yaml:"concurrency"`
+
+	// InProcess runs the worker inside the API process. Turn it off when a
+	// separate `worker` process is running.
+	InProcess bool `yaml:"inprocess"`
+}
+
+// Storage selects where uploaded files are kept
+type Storage struct {
+	// Driver is StorageS3 or StorageLocal. It defaults to StorageS3 in
+	// production and StorageLocal otherwise.
+	Driver string `yaml:"driver"`
+
+	// Directory is where the local driver writes files
+	Directory string `yaml:"directory"`
+
+	// URL is the public address of the local driver's files, by default
+	// http://localhost:<port>/uploads
+	URL string `yaml:"url"`
+}
+
+// Scan selects how uploads are scanned before they are published
+type Scan struct {
+	// Driver is ScanNone, ScanPolicy or ScanClamAV, by default ScanPolicy
+	Driver string `yaml:"driver"`
+
+	// ClamAV is the address of the ClamAV daemon, as tcp://host:port or
+	// unix:///path/to/clamd.sock
+	ClamAV string `yaml:"clamav"`
+
+	// Timeout bounds one scan by the daemon
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Spaces holds the DigitalOcean Spaces (S3 compatible) credentials used for uploads
+type Spaces struct {
+	Key      string `yaml:"key"`
+	Secret   string `yaml:"secret"`
+	Endpoint string `yaml:"endpoint"`
+	Bucket   string `yaml:"bucket"`
+	Region   string `yaml:"region"`
+}
+
+// Mailgun holds the credentials used to send transactional email
+type Mailgun struct {
+	Domain string `yaml:"domain"`
+	APIKey string `yaml:"apikey"`
+}
+
+// ZipCodeServices holds the credentials for api.zipcodeservices.io and how
+// its answers are cached
+type ZipCodeServices struct {
+	APIKey string `yaml:"apikey"`
+
+	// Timeout bounds one request to the service, which is retried
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Dataset is a CSV file of US zip codes answering when the service
+	// cannot, or instead of it when there is no APIKey
+	Dataset string `yaml:"dataset"`
+
+	// CacheSize is how many answers are kept in memory, CacheTTL how long
+	// any answer is kept
+	CacheSize int           `yaml:"cachesize"`
+	CacheTTL  time.Duration `yaml:"cachettl"`
+}
+
+// Defaults returns the configuration used before any file or variable is applied
+func Defaults(profile string) *Config {
+	return &Config{
+		Environment: profile,
+		Port:        "7000",
+		V1Sunset:    time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+		Server: Server{
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       30 * time.Second, // image uploads go through ParseMultipartForm
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   25 * time.Second, // Heroku sends SIGKILL 30 seconds after SIGTERM
+		},
+		Worker: Worker{
+			Concurrency: 4,
+			InProcess:   true,
+		},
+		Storage: Storage{
+			Driver:    storageDriver(profile),
+			Directory: "uploads",
+		},
+		Scan: Scan{
+			Driver:  ScanPolicy,
+			Timeout: 30 * time.Second,
+		},
+		Spaces: Spaces{
+			Region: "us-east-1",
+		},
+		ZipCodeServices: ZipCodeServices{
+			Timeout:   5 * time.Second,
+			CacheSize: 10000,
+			CacheTTL:  30 * 24 * time.Hour,
+		},
+	}
+}
+
+func storageDriver(profile string) string {
+
+	if profile == Production {
+		return StorageS3
+	}
+
+	return StorageLocal
+}
+
+// Load reads the configuration for the profile named by CLIENT_ENV, falling
+// back to development, using .env for local overrides
+func Load() (*Config, error) {
+
+	profile := os.Getenv("CLIENT_ENV")
+
+	if profile == "" {
+		profile = Development
+	}
+
+	return LoadProfile(profile, ".env")
+}
+
+// LoadProfile builds the configuration for profile. Values are applied in
+// order, each overriding the last: defaults, config/<profile>.yaml (or the file
+// named by CONFIG_FILE), envFile when the profile is not production, and
+// finally the process environment. The result is validated before returning.
+func LoadProfile(profile, envFile string) (*Config, error) {
+
+	config := Defaults(profile)
+
+	if profile != Production && envFile != "" {
+		// godotenv never overrides variables that are already set
+		if err := godotenv.Load(envFile); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("config: reading %s: %v", envFile, err)
+		}
+	}
+
+	if err := config.loadFile(profile); err != nil {
+		return nil, err
+	}
+
+	if err := config.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (config *Config) loadFile(profile string) error {
+
+	path := os.Getenv("CONFIG_FILE")
+	explicit := path != ""
+
+	if !explicit {
+		path = filepath.Join("config", profile+".yaml")
+	}
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil
+		}
+		return fmt.Errorf("config: reading %s: %v", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("config: parsing %s: %v", path, err)
+	}
+
+	// the profile selects the file, a file cannot select another profile
+	config.Environment = profile
+
+	return nil
+}
+
+func (config *Config) loadEnv() error {
+
+	setString(&config.Port, "PORT")
+	setString(&config.DatabaseURL, "DATABASE_URL")
+	setString(&config.DatabaseURL, "HEROKU_POSTGRESQL_CYAN_URL")
+	setString(&config.APIKey, "API_KEY")
+	setString(&config.SigningKey, "KNIT_SIGNING_KEY")
+	setString(&config.AdminAPIKey, "ADMIN_API_KEY")
+
+	setString(&config.Storage.Driver, "STORAGE_DRIVER")
+	setString(&config.Storage.Directory, "STORAGE_DIRECTORY")
+	setString(&config.Storage.URL, "STORAGE_URL")
+
+	setString(&config.Scan.Driver, "SCAN_DRIVER")
+	setString(&config.Scan.ClamAV, "CLAMAV_ADDRESS")
+
+	setString(&config.Spaces.Key, "SPACES_KEY")
+	setString(&config.Spaces.Secret, "SPACES_SECRET")
+	setString(&config.Spaces.Endpoint, "SPACES_ENDPOINT")
+	setString(&config.Spaces.Bucket, "SPACES_BUCKET")
+	setString(&config.Spaces.Region, "SPACES_REGION")
+
+	setString(&config.Mailgun.Domain, "MAILGUN_DOMAIN")
+	setString(&config.Mailgun.APIKey, "MAILGUN_API_KEY")
+
+	setString(&config.ZipCodeServices.APIKey, "ZIPCODESERVICES_API_KEY")
+	setString(&config.ZipCodeServices.Dataset, "ZIPCODE_DATASET")
+
+	if err := setDate(&config.V1Sunset, "V1_SUNSET"); err != nil {
+		return err
+	}
+
+	if err := setInt(&config.Worker.Concurrency, "WORKER_CONCURRENCY"); err != nil {
+		return err
+	}
+
+	if err := setBool(&config.Worker.InProcess, "WORKER_IN_PROCESS"); err != nil {
+		return err
+	}
+
+	if err := setDuration(&config.Scan.Timeout, "SCAN_TIMEOUT"); err != nil {
+		return err
+	}
+
+	if err := setDuration(&config.ZipCodeServices.Timeout, "ZIPCODESERVICES_TIMEOUT"); err != nil {
+		return err
+	}
+
+	if err := setInt(&config.ZipCodeServices.CacheSize, "GEOCODE_CACHE_SIZE"); err != nil {
+		return err
+	}
+
+	if err := setDuration(&config.ZipCodeServices.CacheTTL, "GEOCODE_CACHE_TTL"); err != nil {
+		return err
+	}
+
+	return setDuration(&config.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT")
+}
+
+// Validate reports every missing required setting and every malformed one at
+// once. Production requires the credentials of every external service, other
+// profiles only require what the API cannot start without.
+func (config *Config) Validate() error {
+
+	var missing, invalid []string
+
+	require := func(value, name string) {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, name)
+		}
+	}
+
+	require(config.Port, "PORT")
+	require(config.DatabaseURL, "HEROKU_POSTGRESQL_CYAN_URL (or DATABASE_URL)")
+	require(config.SigningKey, "KNIT_SIGNING_KEY")
+
+	if config.Environment != Test {
+		require(config.APIKey, "API_KEY")
+	}
+
+	if config.Environment == Production {
+		if config.Storage.Driver == StorageS3 {
+			require(config.Spaces.Key, "SPACES_KEY")
+			require(config.Spaces.Secret, "SPACES_SECRET")
+			require(config.Spaces.Endpoint, "SPACES_ENDPOINT")
+			require(config.Spaces.Bucket, "SPACES_BUCKET")
+		}
+		require(config.Mailgun.Domain, "MAILGUN_DOMAIN")
+		require(config.Mailgun.APIKey, "MAILGUN_API_KEY")
+		if config.ZipCodeServices.Dataset == "" {
+			require(config.ZipCodeServices.APIKey, "ZIPCODESERVICES_API_KEY (or ZIPCODE_DATASET)")
+		}
+	}
+
+	if config.Server.ShutdownTimeout <= 0 {
+		invalid = append(invalid, fmt.Sprintf("SHUTDOWN_TIMEOUT must be positive, got %s", config.Server.ShutdownTimeout))
+	}
+
+	if config.Storage.Driver != StorageS3 && config.Storage.Driver != StorageLocal {
+		invalid = append(invalid, fmt.Sprintf("STORAGE_DRIVER must be %q or %q, got %q", StorageS3, StorageLocal, config.Storage.Driver))
+	}
+
+	if config.Storage.Driver == StorageLocal {
+		require(config.Storage.Directory, "STORAGE_DIRECTORY")
+	}
+
+	if config.Scan.Driver != ScanNone && config.Scan.Driver != ScanPolicy && config.Scan.Driver != ScanClamAV {
+		invalid = append(invalid, fmt.Sprintf("SCAN_DRIVER must be %q, %q or %q, got %q", ScanNone, ScanPolicy, ScanClamAV, config.Scan.Driver))
+	}
+
+	if config.Scan.Driver == ScanClamAV {
+		require(config.Scan.ClamAV, "CLAMAV_ADDRESS")
+	}
+
+	if config.ZipCodeServices.Timeout <= 0 {
+		invalid = append(invalid, fmt.Sprintf("ZIPCODESERVICES_TIMEOUT must be positive, got %s", config.ZipCodeServices.Timeout))
+	}
+
+	if config.ZipCodeServices.CacheSize < 0 || config.ZipCodeServices.CacheTTL < 0 {
+		invalid = append(invalid, "GEOCODE_CACHE_SIZE and GEOCODE_CACHE_TTL cannot be negative")
+	}
+
+	if config.Worker.Concurrency < 1 {
+		invalid = append(invalid, fmt.Sprintf("WORKER_CONCURRENCY must be at least 1, got %d", config.Worker.Concurrency))
+	}
+
+	if len(missing) > 0 {
+		invalid = append([]string{fmt.Sprintf("missing required settings for profile %q: %s", config.Environment, strings.Join(missing, ", "))}, invalid...)
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("config: %s", strings.Join(invalid, "; "))
+	}
+
+	return nil
+}
+
+// Warnings lists optional settings that are unset, and the features that will
+// fail without them, so they can be logged at startup
+func (config *Config) Warnings() []string {
+
+	var warnings []string
+
+	if config.Mailgun.Domain == "" || config.Mailgun.APIKey == "" {
+		warnings = append(warnings, "MAILGUN_DOMAIN/MAILGUN_API_KEY not set: welcome emails cannot be sent")
+	}
+
+	if config.Storage.Driver == StorageS3 && (config.Spaces.Key == "" || config.Spaces.Bucket == "" || config.Spaces.Endpoint == "") {
+		warnings = append(warnings, "SPACES_* not set: image uploads will fail")
+	}
+
+	if config.Storage.Driver == StorageLocal && config.IsProduction() {
+		warnings = append(warnings, "STORAGE_DRIVER is local: uploads are lost whenever the dyno restarts")
+	}
+
+	if config.Scan.Driver != ScanClamAV && config.IsProduction() {
+		warnings = append(warnings, "SCAN_DRIVER is not clamav: uploads are not scanned for malware")
+	}
+
+	if config.ZipCodeServices.APIKey == "" && config.ZipCodeServices.Dataset == "" {
+		warnings = append(warnings, "ZIPCODESERVICES_API_KEY and ZIPCODE_DATASET not set: location autocomplete will fail")
+	}
+
+	if config.AdminAPIKey == "" {
+		warnings = append(warnings, "ADMIN_API_KEY not set: admin endpoints are disabled")
+	}
+
+	return warnings
+}
+
+// StorageURL returns the public address of the local storage driver's files
+func (config *Config) StorageURL() string {
+
+	if config.Storage.URL != "" {
+		return config.Storage.URL
+	}
+
+	return "http://localhost:" + config.Port + "/uploads"
+}
+
+// IsProduction reports whether the production profile is active
+func (config *Config) IsProduction() bool {
+	return config.Environment == Production
+}
+
+func setString(field *string, name string) {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		*field = value
+	}
+}
+
+func setDuration(field *time.Duration, name string) error {
+
+	value, ok := os.LookupEnv(name)
+
+	if !ok || value == "" {
+		return nil
+	}
+
+	duration, err := time.ParseDuration(value)
+
+	if err != nil {
+		return fmt.Errorf("config: %s must be a duration such as \"20s\": %v", name, err)
+	}
+
+	*field = duration
+
+	return nil
+}
+
+func setInt(field *int, name string) error {
+
+	value, ok := os.LookupEnv(name)
+
+	if !ok || value == "" {
+		return nil
+	}
+
+	number, err := strconv.Atoi(value)
+
+	if err != nil {
+		return fmt.Errorf("config: %s must be a whole number: %v", name, err)
+	}
+
+	*field = number
+
+	return nil
+}
+
+func setBool(field *bool, name string) error {
+
+	value, ok := os.LookupEnv(name)
+
+	if !ok || value == "" {
+		return nil
+	}
+
+	flag, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return fmt.Errorf("config: %s must be true or false: %v", name, err)
+	}
+
+	*field = flag
+
+	return nil
+}
+
+func setDate(field *time.Time, name string) error {
+
+	value, ok := os.LookupEnv(name)
+
+	if !ok || value == "" {
+		return nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+
+	if err != nil {
+		return fmt.Errorf("config: %s must be a date such as \"2027-04-19\": %v", name, err)
+	}
+
+	*field = date
+
+	return nil
+}
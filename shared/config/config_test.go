@@ -0,0 +1,317 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setenv(t *testing.T, values map[string]string) {
+	for key, value := range values {
+		previous, existed := os.LookupEnv(key)
+		os.Setenv(key, value)
+
+		key := key
+		t.Cleanup(func() {
+			if existed {
+				os.Setenv(key, previous)
+			} else {
+				os.Unsetenv(key)
+			}
+		})
+	}
+}
+
+func Test_Config_LoadProfile_EnvironmentOverridesDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	setenv(t, map[string]string{
+		"PORT":             "8080",
+		"DATABASE_URL":     "postgres://localhost/test",
+		"KNIT_SIGNING_KEY": "signing-key",
+		"SHUTDOWN_TIMEOUT": "10s",
+	})
+
+	result, err := config.LoadProfile(config.Test, "")
+
+	assert.Nil(err)
+	assert.Equal("8080", result.Port)
+	assert.Equal("postgres://localhost/test", result.DatabaseURL)
+	assert.Equal("signing-key", result.SigningKey)
+	assert.Equal(10*time.Second, result.Server.ShutdownTimeout)
+	assert.Equal("us-east-1", result.Spaces.Region)
+}
+
+func Test_Config_LoadProfile_ProductionReportsEveryMissingValue(t *testing.T) {
+	assert := assert.New(t)
+
+	setenv(t, map[string]string{
+		"DATABASE_URL":     "postgres://localhost/test",
+		"KNIT_SIGNING_KEY": "signing-key",
+		"API_KEY":          "api-key",
+	})
+
+	result, err := config.LoadProfile(config.Production, "")
+
+	assert.Nil(result)
+	assert.NotNil(err)
+
+	for _, name := range []string{"SPACES_KEY", "SPACES_BUCKET", "MAILGUN_DOMAIN", "MAILGUN_API_KEY", "ZIPCODESERVICES_API_KEY"} {
+		assert.True(strings.Contains(err.Error(), name), name)
+	}
+}
+
+func Test_Config_LoadProfile_ReportsMalformedWithMissingValues(t *testing.T) {
+	assert := assert.New(t)
+
+	setenv(t, map[string]string{
+		"DATABASE_URL":       "postgres://localhost/test",
+		"STORAGE_DRIVER":     "ftp",
+		"WORKER_CONCURRENCY": "0",
+	})
+
+	_, err := config.LoadProfile(config.Test, "")
+
+	if assert.NotNil(err) {
+		for _, name := range []string{"KNIT_SIGNING_KEY", "STORAGE_DRIVER", "WORKER_CONCURRENCY"} {
+			assert.True(strings.Contains(err.Error(), name), name)
+		}
+	}
+}
+
+func Test_Config_LoadProfile_InvalidDuration(t *testing.T) {
+	assert := assert.New(t)
+
+	setenv(t, map[string]string{
+		"DATABASE_URL":     "postgres://localhost/test",
+		"KNIT_SIGNING_KEY": "signing-key",
+		"SHUTDOWN_TIMEOUT": "soon",
+	})
+
+	_, err := config.LoadProfile(config.Test, "")
+
+	assert.NotNil(err)
+	assert.True(strings.Contains(err.Error(), "SHUTDOWN_TIMEOUT"))
+}
+
+func Test_Config_LoadProfile_Worker(t *testing.T) {
+	assert := assert.New(t)
+
+	setenv(t, map[string]string{
+		"DATABASE_URL":       "postgres://localhost/test",
+		"KNIT_SIGNING_KEY":   "signing-key",
+		"WORKER_CONCURRENCY": "8",
+		"WORKER_IN_PROCESS":  "false",
+	})
+
+	result, err := config.LoadProfile(config.Test, "")
+
+	assert.Nil(err)
+	assert.Equal(8, result.Worker.Concurrency)
+	assert.False(result.Worker.InProcess)
+
+	setenv(t, map[string]string{"WORKER_CONCURRENCY": "0"})
+
+	_, err = config.LoadProfile(config.Test, "")
+
+	assert.NotNil(err)
+	assert.True(strings.Contains(err.Error(), "WORKER_CONCURRENCY"))
+}
+
+func Test_Config_LoadProfile_V1Sunset(t *testing.T) {
+	assert := assert.New(t)
+
+	setenv(t, map[string]string{
+		"DATABASE_URL":     "postgres://localhost/test",
+		"KNIT_SIGNING_KEY": "signing-key",
+		"V1_SUNSET":        "2027-06-30",
+	})
+
+	result, err := config.LoadProfile(config.Test, "")
+
+	assert.Nil(err)
+	assert.Equal(time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC), result.V1Sunset)
+
+	setenv(t, map[string]string{"V1_SUNSET": "next summer"})
+
+	_, err = config.LoadProfile(config.Test, "")
+
+	assert.NotNil(err)
+	assert.True(strings.Contains(err.Error(), "V1_SUNSET"))
+}
+
+func Test_Config_LoadProfile_YAMLFile(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "config")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "staging.yaml")
+
+	err = ioutil.WriteFile(path, []byte(`
+port: "9000"
+databaseurl: postgres://yaml/test
+signingkey: from-yaml
+apikey: yaml-api-key
+mailgun:
+  domain: mg.example.com
+server:
+  writetimeout: 45s
+`), 0600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setenv(t, map[string]string{
+		"CONFIG_FILE":      path,
+		"KNIT_SIGNING_KEY": "from-env",
+	})
+
+	result, err := config.LoadProfile("staging", "")
+
+	assert.Nil(err)
+	assert.Equal("staging", result.Environment)
+	assert.Equal("9000", result.Port)
+	assert.Equal("postgres://yaml/test", result.DatabaseURL)
+	assert.Equal("from-env", result.SigningKey)
+	assert.Equal("mg.example.com", result.Mailgun.Domain)
+	assert.Equal(45*time.Second, result.Server.WriteTimeout)
+	assert.Equal(5*time.Second, result.Server.ReadHeaderTimeout)
+}
+
+func Test_Config_LoadProfile_Storage(t *testing.T) {
+	assert := assert.New(t)
+
+	setenv(t, map[string]string{
+		"DATABASE_URL":     "postgres://localhost/test",
+		"KNIT_SIGNING_KEY": "signing-key",
+	})
+
+	result, err := config.LoadProfile(config.Test, "")
+
+	assert.Nil(err)
+	assert.Equal(config.StorageLocal, result.Storage.Driver)
+	assert.Equal("http://localhost:7000/uploads", result.StorageURL())
+	assert.Equal(config.StorageS3, config.Defaults(config.Production).Storage.Driver)
+
+	setenv(t, map[string]string{"STORAGE_DRIVER": "ftp"})
+
+	_, err = config.LoadProfile(config.Test, "")
+
+	assert.NotNil(err)
+	assert.True(strings.Contains(err.Error(), "STORAGE_DRIVER"))
+
+	// production needs no Spaces credentials when files are kept locally
+	setenv(t, map[string]string{
+		"STORAGE_DRIVER":          "local",
+		"STORAGE_URL":             "https://api.example.com/uploads",
+		"API_KEY":                 "api-key",
+		"MAILGUN_DOMAIN":          "mg.example.com",
+		"MAILGUN_API_KEY":         "mailgun-key",
+		"ZIPCODESERVICES_API_KEY": "zip-key",
+	})
+
+	result, err = config.LoadProfile(config.Production, "")
+
+	assert.Nil(err)
+	assert.Equal("https://api.example.com/uploads", result.StorageURL())
+	assert.Contains(result.Warnings(), "STORAGE_DRIVER is local: uploads are lost whenever the dyno restarts")
+}
+
+func Test_Config_LoadProfile_Scan(t *testing.T) {
+	assert := assert.New(t)
+
+	setenv(t, map[string]string{
+		"DATABASE_URL":     "postgres://localhost/test",
+		"KNIT_SIGNING_KEY": "signing-key",
+	})
+
+	result, err := config.LoadProfile(config.Test, "")
+
+	assert.Nil(err)
+	assert.Equal(config.ScanPolicy, result.Scan.Driver)
+
+	setenv(t, map[string]string{"SCAN_DRIVER": "clamav"})
+
+	_, err = config.LoadProfile(config.Test, "")
+
+	if assert.NotNil(err) {
+		assert.True(strings.Contains(err.Error(), "CLAMAV_ADDRESS"))
+	}
+
+	setenv(t, map[string]string{"CLAMAV_ADDRESS": "tcp://clamd:3310", "SCAN_TIMEOUT": "5s"})
+
+	result, err = config.LoadProfile(config.Test, "")
+
+	if assert.Nil(err) {
+		assert.Equal("tcp://clamd:3310", result.Scan.ClamAV)
+		assert.Equal(5*time.Second, result.Scan.Timeout)
+	}
+
+	setenv(t, map[string]string{"SCAN_DRIVER": "antivirus"})
+
+	_, err = config.LoadProfile(config.Test, "")
+
+	if assert.NotNil(err) {
+		assert.True(strings.Contains(err.Error(), "SCAN_DRIVER"))
+	}
+}
+
+func Test_Config_LoadProfile_ZipCodeServices(t *testing.T) {
+	assert := assert.New(t)
+
+	setenv(t, map[string]string{
+		"DATABASE_URL":     "postgres://localhost/test",
+		"KNIT_SIGNING_KEY": "signing-key",
+	})
+
+	result, err := config.LoadProfile(config.Test, "")
+
+	if assert.Nil(err) {
+		assert.Equal(5*time.Second, result.ZipCodeServices.Timeout)
+		assert.Equal(10000, result.ZipCodeServices.CacheSize)
+		assert.Equal(30*24*time.Hour, result.ZipCodeServices.CacheTTL)
+	}
+
+	setenv(t, map[string]string{
+		"ZIPCODE_DATASET":         "zipcodes.csv",
+		"ZIPCODESERVICES_TIMEOUT": "2s",
+		"GEOCODE_CACHE_SIZE":      "50",
+		"GEOCODE_CACHE_TTL":       "1h",
+	})
+
+	result, err = config.LoadProfile(config.Test, "")
+
+	if assert.Nil(err) {
+		assert.Equal("zipcodes.csv", result.ZipCodeServices.Dataset)
+		assert.Equal(2*time.Second, result.ZipCodeServices.Timeout)
+		assert.Equal(50, result.ZipCodeServices.CacheSize)
+		assert.Equal(time.Hour, result.ZipCodeServices.CacheTTL)
+
+		// the dataset answers without an API key
+		for _, warning := range result.Warnings() {
+			assert.False(strings.Contains(warning, "ZIPCODE"), warning)
+		}
+	}
+
+	setenv(t, map[string]string{"ZIPCODESERVICES_TIMEOUT": "0s"})
+
+	_, err = config.LoadProfile(config.Test, "")
+
+	if assert.NotNil(err) {
+		assert.True(strings.Contains(err.Error(), "ZIPCODESERVICES_TIMEOUT"))
+	}
+}
@@ -3,32 +3,35 @@ package database
 import (
 	"database/sql"
 	"log"
-	"os"
 
 	_ "github.com/lib/pq"
 )
 
-var DB *sql.DB
-
-// DSN returns the Data Source Name
-func postgresqldsn(dbENV string) string {
-	// Example: root:@tcp(localhost:3306)/ipaddressservices
-
-	return os.Getenv(dbENV)
-
-}
-
-// Connect to the database -- for all of them
-func Connect(dbENV string) {
-	var err error
+// Connect opens the PostgreSQL pool for dsn and checks that it is reachable
+func Connect(dsn string) (*sql.DB, error) {
 
 	// Connect to PostgreSQL
-	if DB, err = sql.Open("postgres", postgresqldsn(dbENV)); err != nil {
+	db, err := sql.Open("postgres", dsn)
+
+	if err != nil {
 		log.Println("PostGres SQL Driver Error", err)
+		return nil, err
 	}
 
 	// Check if is alive
-	if err = DB.Ping(); err != nil {
+	if err = db.Ping(); err != nil {
 		log.Println("Database Error", err)
+		return db, err
 	}
+
+	return db, nil
+}
+
+// Querier is implemented by both *sql.DB and *sql.Tx, so a repository built
+// over a transaction joins it and one built over the pool runs on its own
+type Querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Prepare(query string) (*sql.Stmt, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
 }
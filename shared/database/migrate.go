@@ -0,0 +1,93 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// The base schema (employers, companies, jobs, jobpackages) predates these
+// files; migrations only describe tables added since
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies every migration in migrations/ that has not been applied
+// yet, each in its own transaction, in file name order
+func Migrate(db *sql.DB) error {
+
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schemamigrations (name TEXT PRIMARY KEY, appliedat TIMESTAMPTZ NOT NULL DEFAULT now());`)
+
+	if err != nil {
+		return err
+	}
+
+	entries, err := migrations.ReadDir("migrations")
+
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".sql") {
+			names = append(names, entry.Name())
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := applyMigration(db, name); err != nil {
+			return fmt.Errorf("migration %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, name string) error {
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	// serialise concurrent dynos starting at the same time
+	if _, err := tx.Exec(`LOCK TABLE schemamigrations IN EXCLUSIVE MODE;`); err != nil {
+		return err
+	}
+
+	var applied bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM schemamigrations WHERE name=$1);`, name).Scan(&applied); err != nil {
+		return err
+	}
+
+	if applied {
+		return nil
+	}
+
+	statements, err := migrations.ReadFile("migrations/" + name)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(string(statements)); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO schemamigrations(name) VALUES ($1);`, name); err != nil {
+		return err
+	}
+
+	log.Println("Applied migration", name)
+
+	return tx.Commit()
+}
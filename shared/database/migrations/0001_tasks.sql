@@ -0,0 +1,21 @@
+-- Background task queue. Tasks enqueued inside a transaction are only
+-- visible to workers once it commits, which makes this the outbox as well.
+CREATE TABLE IF NOT EXISTS tasks (
+    id          BIGSERIAL PRIMARY KEY,
+    kind        TEXT NOT NULL,
+    payload     JSONB,
+    status      TEXT NOT NULL DEFAULT 'pending', -- pending, running, done, dead
+    attempts    INTEGER NOT NULL DEFAULT 0,
+    lasterror   TEXT,
+    uniquekey   TEXT,
+    runat       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    lockeduntil TIMESTAMPTZ,
+    createdat   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updatedat   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finishedat  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS tasks_due_idx ON tasks (runat) WHERE status IN ('pending', 'running');
+
+-- at most one unfinished task per key, used for periodic tasks
+CREATE UNIQUE INDEX IF NOT EXISTS tasks_uniquekey_idx ON tasks (uniquekey) WHERE status IN ('pending', 'running');
@@ -0,0 +1,8 @@
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS expiresat TIMESTAMPTZ;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS expiredat TIMESTAMPTZ;
+
+-- existing jobs get a full listing period from today rather than from a
+-- visible date that may not parse as a timestamp
+UPDATE jobs SET expiresat = now() + interval '30 days' WHERE expiresat IS NULL;
+
+CREATE INDEX IF NOT EXISTS jobs_expiresat_idx ON jobs (expiresat) WHERE expiredat IS NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS liveat TIMESTAMPTZ;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS wentliveat TIMESTAMPTZ;
+
+-- existing jobs are already live, so no job.live event is sent for them
+UPDATE jobs SET liveat = now(), wentliveat = now() WHERE liveat IS NULL;
+
+CREATE INDEX IF NOT EXISTS jobs_liveat_idx ON jobs (liveat) WHERE wentliveat IS NULL;
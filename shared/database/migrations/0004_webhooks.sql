@@ -0,0 +1,31 @@
+-- Endpoints a company has subscribed to events
+CREATE TABLE IF NOT EXISTS webhooks (
+    id        BIGSERIAL PRIMARY KEY,
+    publicid  TEXT NOT NULL UNIQUE,
+    companyid BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    url       TEXT NOT NULL,
+    secret    TEXT NOT NULL,
+    events    TEXT[] NOT NULL,
+    createdat TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_companyid_idx ON webhooks (companyid);
+
+-- One row per event sent to an endpoint, updated after every attempt
+CREATE TABLE IF NOT EXISTS webhookdeliveries (
+    id             BIGSERIAL PRIMARY KEY,
+    publicid       TEXT NOT NULL UNIQUE,
+    webhookid      BIGINT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event          TEXT NOT NULL,
+    payload        JSONB NOT NULL,
+    status         TEXT NOT NULL DEFAULT 'pending', -- pending, delivered, failed
+    attempts       INTEGER NOT NULL DEFAULT 0,
+    responsestatus INTEGER,
+    responsebody   TEXT,
+    lasterror      TEXT,
+    createdat      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    lastattemptat  TIMESTAMPTZ,
+    deliveredat    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS webhookdeliveries_webhookid_idx ON webhookdeliveries (webhookid, createdat DESC);
@@ -0,0 +1,28 @@
+-- Append-only record of every change employers make. The company is copied
+-- from the actor when the entry is written, so it survives later moves.
+CREATE TABLE IF NOT EXISTS auditlog (
+    id              BIGSERIAL PRIMARY KEY,
+    publicid        TEXT NOT NULL UNIQUE,
+    actorpublicid   TEXT NOT NULL,
+    companypublicid TEXT NOT NULL DEFAULT '',
+    action          TEXT NOT NULL,
+    targettype      TEXT NOT NULL,
+    targetpublicid  TEXT NOT NULL DEFAULT '',
+    changes         JSONB NOT NULL DEFAULT '{}',
+    ip              TEXT NOT NULL DEFAULT '',
+    useragent       TEXT NOT NULL DEFAULT '',
+    createdat       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS auditlog_company_idx ON auditlog (companypublicid, createdat DESC);
+CREATE INDEX IF NOT EXISTS auditlog_actor_idx ON auditlog (actorpublicid, createdat DESC);
+
+CREATE OR REPLACE FUNCTION auditlog_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'auditlog is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS auditlog_append_only ON auditlog;
+CREATE TRIGGER auditlog_append_only BEFORE UPDATE OR DELETE ON auditlog
+    FOR EACH ROW EXECUTE PROCEDURE auditlog_append_only();
@@ -0,0 +1,4 @@
+-- Deleted jobs are kept for a retention period so they can be restored
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS deletedat TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS jobs_deletedat_idx ON jobs (deletedat) WHERE deletedat IS NOT NULL;
@@ -0,0 +1,30 @@
+-- Every save of a job's content is kept as a numbered revision. jobs.revision
+-- is the number of the current one; it is bumped under the row lock of the
+-- update that writes the revision.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS jobrevisions (
+    id             BIGSERIAL PRIMARY KEY,
+    jobid          BIGINT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    revision       INTEGER NOT NULL,
+    title          TEXT,
+    jobtype        TEXT,
+    category       TEXT,
+    description    TEXT,
+    visibledate    TEXT,
+    remote         BOOLEAN,
+    minsalary      BIGINT,
+    maxsalary      BIGINT,
+    payperiod      TEXT,
+    editorpublicid TEXT NOT NULL DEFAULT '',
+    createdat      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (jobid, revision)
+);
+
+-- existing jobs start their history at their current content
+INSERT INTO jobrevisions(jobid, revision, title, jobtype, category, description, visibledate, remote, minsalary, maxsalary, payperiod, editorpublicid)
+SELECT jobs.id, jobs.revision, jobs.title, jobs.jobtype, jobs.category, jobs.description, jobs.visibledate::text,
+    jobs.remote, jobs.minsalary, jobs.maxsalary, jobs.payperiod, employers.publicid
+FROM jobs
+JOIN employers ON employers.id=jobs.employerid
+ON CONFLICT (jobid, revision) DO NOTHING;
@@ -0,0 +1,3 @@
+-- companies.version is bumped by every profile update and served as the
+-- company's ETag. Jobs use jobs.revision for the same purpose.
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- companies.logovariants maps the name of each stored size of the logo, such
+-- as thumbnail, to its URL. companies.logo is the URL of the logo variant.
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS logovariants JSONB NOT NULL DEFAULT '{}';
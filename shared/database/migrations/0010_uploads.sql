@@ -0,0 +1,18 @@
+-- Files employers upload straight to object storage through a presigned URL.
+-- Rows that are never completed, or never attached once complete, expire and
+-- are removed with their object.
+CREATE TABLE IF NOT EXISTS uploads (
+    id          BIGSERIAL PRIMARY KEY,
+    publicid    TEXT NOT NULL UNIQUE,
+    employerid  BIGINT NOT NULL REFERENCES employers(id) ON DELETE CASCADE,
+    key         TEXT NOT NULL UNIQUE,
+    filename    TEXT NOT NULL DEFAULT '',
+    contenttype TEXT NOT NULL,
+    size        BIGINT NOT NULL,
+    status      TEXT NOT NULL DEFAULT 'pending', -- pending, complete, attached
+    createdat   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expiresat   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS uploads_employerid_idx ON uploads (employerid);
+CREATE INDEX IF NOT EXISTS uploads_expiresat_idx ON uploads (expiresat) WHERE status <> 'attached';
@@ -0,0 +1,21 @@
+-- Every object the API stores gets an uploads row: the employer and company
+-- it was uploaded for, its public URL and the number of records using it.
+-- Rows no longer used by anything expire and are swept with their object.
+-- An upload outlives its employer and company until it is swept.
+ALTER TABLE uploads ALTER COLUMN employerid DROP NOT NULL;
+ALTER TABLE uploads DROP CONSTRAINT IF EXISTS uploads_employerid_fkey;
+ALTER TABLE uploads ADD CONSTRAINT uploads_employerid_fkey FOREIGN KEY (employerid) REFERENCES employers(id) ON DELETE SET NULL;
+
+ALTER TABLE uploads ADD COLUMN IF NOT EXISTS companyid BIGINT REFERENCES companies(id) ON DELETE SET NULL;
+ALTER TABLE uploads ADD COLUMN IF NOT EXISTS url TEXT NOT NULL DEFAULT '';
+ALTER TABLE uploads ADD COLUMN IF NOT EXISTS refcount INTEGER NOT NULL DEFAULT 0 CHECK (refcount >= 0);
+
+UPDATE uploads SET companyid=employers.companyid FROM employers WHERE employers.id=uploads.employerid;
+
+-- attached uploads were the originals of processed logos, which are not used
+-- once their variants are stored
+UPDATE uploads SET status='complete', expiresat=now() WHERE status='attached';
+
+DROP INDEX IF EXISTS uploads_expiresat_idx;
+CREATE INDEX IF NOT EXISTS uploads_orphaned_idx ON uploads (expiresat) WHERE refcount = 0;
+CREATE INDEX IF NOT EXISTS uploads_url_idx ON uploads (url) WHERE url <> '';
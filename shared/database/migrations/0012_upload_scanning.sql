@@ -0,0 +1,4 @@
+-- Uploads that fail a scan are kept in quarantine, never served, with the
+-- scanner's reason in scanresult. Like other unused uploads they are swept
+-- once they expire.
+ALTER TABLE uploads ADD COLUMN IF NOT EXISTS scanresult TEXT NOT NULL DEFAULT '';
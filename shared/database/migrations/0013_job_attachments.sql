@@ -0,0 +1,14 @@
+-- Documents attached to jobs, such as a PDF job description. Each is served
+-- from url, the public object of an upload, which every attachment of it
+-- uses once.
+CREATE TABLE IF NOT EXISTS jobattachments (
+    id          BIGSERIAL PRIMARY KEY,
+    publicid    TEXT NOT NULL UNIQUE,
+    jobid       BIGINT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    filename    TEXT NOT NULL DEFAULT '',
+    contenttype TEXT NOT NULL,
+    size        BIGINT NOT NULL,
+    url         TEXT NOT NULL,
+    createdat   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (jobid, url)
+);
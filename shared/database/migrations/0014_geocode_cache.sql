@@ -0,0 +1,10 @@
+-- Geocoder answers cached by the request that produced them, so repeated
+-- lookups and autocomplete keystrokes do not reach the remote service. Rows
+-- past expiresat are ignored and purged.
+CREATE TABLE IF NOT EXISTS geocodecache (
+    key       TEXT PRIMARY KEY,
+    value     JSONB NOT NULL,
+    expiresat TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS geocodecache_expiresat_idx ON geocodecache (expiresat);
@@ -0,0 +1,26 @@
+-- Company and job locations are resolved through the geocoder when they are
+-- saved. location, zipcode, latitude and longitude are as the employer gave
+-- them, completed by the geocoder; city, state, county and country are where
+-- they resolve to, and are empty when they could not be resolved.
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS city TEXT NOT NULL DEFAULT '';
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT '';
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS county TEXT NOT NULL DEFAULT '';
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS country TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS location TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS zipcode TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS city TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS county TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS country TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE jobrevisions ADD COLUMN IF NOT EXISTS location TEXT;
+ALTER TABLE jobrevisions ADD COLUMN IF NOT EXISTS zipcode TEXT;
+ALTER TABLE jobrevisions ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE jobrevisions ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+ALTER TABLE jobrevisions ADD COLUMN IF NOT EXISTS city TEXT;
+ALTER TABLE jobrevisions ADD COLUMN IF NOT EXISTS state TEXT;
+ALTER TABLE jobrevisions ADD COLUMN IF NOT EXISTS county TEXT;
+ALTER TABLE jobrevisions ADD COLUMN IF NOT EXISTS country TEXT;
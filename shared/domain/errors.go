@@ -0,0 +1,91 @@
+// Package domain describes the failures repositories and services report
+// for a client to act on. Each Error has a Kind, which decides its HTTP
+// status, and a Code that is stable, so clients can branch on the code
+// rather than on the message.
+package domain
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// Kind is the class of an Error
+type Kind int
+
+const (
+	// NotFound is a resource that does not exist, or that the caller may not
+	// know exists
+	NotFound Kind = iota + 1
+	// Conflict is a write that clashes with the current state, such as an
+	// edit of a stale version or a duplicate
+	Conflict
+	// Forbidden is an action the caller may not take on a resource they can
+	// see
+	Forbidden
+	// Invalid is a request that can never succeed as sent
+	Invalid
+)
+
+// Error is a failure with a stable Code
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+// ErrMissingValue is returned for a call without an argument it requires
+var ErrMissingValue = NewInvalid("missing_value", "missing required value")
+
+// NewNotFound returns a NotFound error. It wraps sql.ErrNoRows, so callers
+// written against database/sql still recognise it with errors.Is.
+func NewNotFound(code, message string) *Error {
+	return &Error{Kind: NotFound, Code: code, Message: message, Err: sql.ErrNoRows}
+}
+
+// NewConflict returns a Conflict error
+func NewConflict(code, message string) *Error {
+	return &Error{Kind: Conflict, Code: code, Message: message}
+}
+
+// NewForbidden returns a Forbidden error
+func NewForbidden(code, message string) *Error {
+	return &Error{Kind: Forbidden, Code: code, Message: message}
+}
+
+// NewInvalid returns an Invalid error
+func NewInvalid(code, message string) *Error {
+	return &Error{Kind: Invalid, Code: code, Message: message}
+}
+
+// KindOf returns the Kind of the first Error in err's chain, or 0 if there is
+// none
+func KindOf(err error) Kind {
+
+	var domainErr *Error
+
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+
+	return 0
+}
+
+// NotFoundOr returns notFound if err is sql.ErrNoRows and err otherwise, for
+// repositories to name the missing resource after a query
+func NotFoundOr(err error, notFound *Error) error {
+
+	if err == sql.ErrNoRows {
+		return notFound
+	}
+
+	return err
+}
@@ -0,0 +1,16 @@
+package attachments_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func init() {
+	testhelper.Init()
+}
+
+func Test_AttachmentRepository_Contract(t *testing.T) {
+	repositorytest.AttachmentRepository(t, testhelper.Repositories)
+}
@@ -0,0 +1,203 @@
+package attachments
+
+import (
+	"log"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// MaxCompanySize is how many bytes the attachments of a company's jobs may
+// take altogether
+const MaxCompanySize = 100 << 20
+
+var (
+	// ErrNotFound is returned for an attachment that does not exist or is not
+	// attached to the job
+	ErrNotFound = domain.NewNotFound("attachment_not_found", "attachment not found")
+
+	// ErrExists is returned for attaching a file to a job it is attached to
+	ErrExists = domain.NewConflict("attachment_exists", "the file is already attached to the job")
+
+	// ErrNotDocument is returned for attaching an upload that is not a PDF or
+	// DOCX document
+	ErrNotDocument = domain.NewInvalid("attachment_not_document", "attach a PDF or DOCX document")
+
+	// ErrQuotaExceeded is returned for an attachment that would take the
+	// company over MaxCompanySize
+	ErrQuotaExceeded = domain.NewConflict("attachment_quota_exceeded", "the attachments of the company's jobs may take at most 100 MB")
+)
+
+// AttachmentRepository records the documents attached to jobs
+type AttachmentRepository interface {
+	CreateAttachment(employerPublicID, jobPublicID, fileName, contentType, url string, size int64) (*jobs.Attachment, error)
+	DeleteAttachment(employerPublicID, jobPublicID, attachmentPublicID string) (*jobs.Attachment, error)
+	GetJobAttachments(jobPublicID string) ([]*jobs.Attachment, error)
+	GetCompanyAttachmentSize(employerPublicID string) (int64, error)
+	GetPurgeableAttachments(deletedBefore time.Time) ([]*jobs.Attachment, error)
+}
+
+// PostgresAttachmentRepository is the AttachmentRepository backed by the
+// jobattachments table
+type PostgresAttachmentRepository struct {
+	Database database.Querier
+}
+
+func NewAttachmentRepository(db database.Querier) *PostgresAttachmentRepository {
+	return &PostgresAttachmentRepository{Database: db}
+}
+
+const attachmentColumns = `jobattachments.publicid, jobattachments.filename, jobattachments.contenttype, jobattachments.size,
+	jobattachments.url, jobattachments.createdat`
+
+// CreateAttachment attaches the document at url to a job of the employer
+// that is not deleted
+func (repository *PostgresAttachmentRepository) CreateAttachment(employerPublicID, jobPublicID, fileName, contentType, url string, size int64) (*jobs.Attachment, error) {
+
+	if employerPublicID == "" || jobPublicID == "" || contentType == "" || url == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	attachment, err := scanAttachment(repository.Database.QueryRow(`
+		INSERT INTO jobattachments(publicid, jobid, filename, contenttype, size, url)
+		SELECT $1, jobs.id, $4, $5, $6, $7
+		FROM jobs
+		JOIN employers ON employers.id=jobs.employerid
+		WHERE jobs.publicid=$3 AND employers.publicid=$2 AND jobs.deletedat IS NULL
+		RETURNING `+attachmentColumns+`;`, uuid.NewString(), employerPublicID, jobPublicID, fileName, contentType, size, url))
+
+	if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+		return nil, ErrExists
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, jobs.ErrNotFound)
+	}
+
+	return attachment, nil
+}
+
+// DeleteAttachment detaches an attachment from a job of the employer that is
+// not deleted, and returns it
+func (repository *PostgresAttachmentRepository) DeleteAttachment(employerPublicID, jobPublicID, attachmentPublicID string) (*jobs.Attachment, error) {
+
+	attachment, err := scanAttachment(repository.Database.QueryRow(`
+		DELETE FROM jobattachments
+		USING jobs, employers
+		WHERE jobattachments.publicid=$3 AND jobs.id=jobattachments.jobid AND jobs.publicid=$2 AND jobs.deletedat IS NULL
+			AND employers.id=jobs.employerid AND employers.publicid=$1
+		RETURNING `+attachmentColumns+`;`, employerPublicID, jobPublicID, attachmentPublicID))
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, ErrNotFound)
+	}
+
+	return attachment, nil
+}
+
+// GetJobAttachments returns the attachments of a job, oldest first
+func (repository *PostgresAttachmentRepository) GetJobAttachments(jobPublicID string) ([]*jobs.Attachment, error) {
+
+	return repository.queryAttachments(`
+		SELECT `+attachmentColumns+`
+		FROM jobattachments
+		JOIN jobs ON jobs.id=jobattachments.jobid
+		WHERE jobs.publicid=$1
+		ORDER BY jobattachments.createdat, jobattachments.id;`, jobPublicID)
+}
+
+// GetCompanyAttachmentSize returns how many bytes the attachments of the jobs
+// of the employer's company take, deleted jobs included, or of the
+// employer's own jobs when they have no company. In a transaction it locks
+// the company until the transaction ends, so attachments added concurrently
+// are counted one after the other.
+func (repository *PostgresAttachmentRepository) GetCompanyAttachmentSize(employerPublicID string) (int64, error) {
+
+	_, err := repository.Database.Exec(`
+		SELECT id FROM companies WHERE id=(SELECT companyid FROM employers WHERE publicid=$1) FOR UPDATE;`, employerPublicID)
+
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+
+	var size int64
+
+	err = repository.Database.QueryRow(`
+		WITH owner AS (SELECT id, companyid FROM employers WHERE publicid=$1)
+		SELECT COALESCE(SUM(jobattachments.size), 0)
+		FROM jobattachments
+		JOIN jobs ON jobs.id=jobattachments.jobid
+		JOIN employers ON employers.id=jobs.employerid
+		JOIN owner ON owner.companyid=employers.companyid OR owner.id=employers.id;`, employerPublicID).Scan(&size)
+
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// GetPurgeableAttachments returns the attachments of the jobs deleted before
+// deletedBefore, which PurgeDeletedJobs removes with them
+func (repository *PostgresAttachmentRepository) GetPurgeableAttachments(deletedBefore time.Time) ([]*jobs.Attachment, error) {
+
+	return repository.queryAttachments(`
+		SELECT `+attachmentColumns+`
+		FROM jobattachments
+		JOIN jobs ON jobs.id=jobattachments.jobid
+		WHERE jobs.deletedat < $1
+		ORDER BY jobattachments.id;`, deletedBefore)
+}
+
+func (repository *PostgresAttachmentRepository) queryAttachments(query string, args ...interface{}) ([]*jobs.Attachment, error) {
+
+	rows, err := repository.Database.Query(query, args...)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var result []*jobs.Attachment
+
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		result = append(result, attachment)
+	}
+
+	return result, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAttachment(row scanner) (*jobs.Attachment, error) {
+
+	var attachment jobs.Attachment
+
+	err := row.Scan(&attachment.PublicID, &attachment.FileName, &attachment.ContentType, &attachment.Size, &attachment.URL, &attachment.CreatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
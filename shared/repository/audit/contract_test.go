@@ -0,0 +1,16 @@
+package audit_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func init() {
+	testhelper.Init()
+}
+
+func Test_AuditRepository_Contract(t *testing.T) {
+	repositorytest.AuditRepository(t, testhelper.Repositories)
+}
@@ -0,0 +1,38 @@
+package audit_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type account struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func Test_Audit_Diff(t *testing.T) {
+	assert := assert.New(t)
+
+	changes, err := audit.Diff(&account{Name: "Ida", Email: "ida@example.com", Password: "a"}, &account{Name: "Ida B.", Email: "ida@example.com", Password: "b"})
+
+	assert.Nil(err)
+	assert.JSONEq(`{"name":{"before":"Ida","after":"Ida B."},"password":{"before":"[redacted]","after":"[redacted]"}}`, string(changes))
+
+	changes, err = audit.Diff(nil, &account{Name: "Ida"})
+
+	assert.Nil(err)
+	assert.JSONEq(`{"name":{"after":"Ida"},"email":{"after":""},"password":{"after":""}}`, string(changes))
+
+	var missing *account
+	changes, err = audit.Diff(&account{Name: "Ida"}, missing)
+
+	assert.Nil(err)
+	assert.JSONEq(`{"name":{"before":"Ida"},"email":{"before":""},"password":{"before":""}}`, string(changes))
+
+	_, err = audit.Diff("not an object", nil)
+	assert.NotNil(err)
+}
@@ -0,0 +1,306 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/domain"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded by the employer endpoints
+const (
+	EmployerSignUp        = "employer.signup"
+	EmployerPassword      = "employer.password.update"
+	EmployerAccount       = "employer.account.update"
+	EmployerPaymentMethod = "employer.paymentmethod.update"
+	EmployerPayment       = "employer.paymentdetails.update"
+	CompanyUpdate         = "company.update"
+	JobCreate             = "job.create"
+	JobEdit               = "job.edit"
+	JobDelete             = "job.delete"
+	JobRestore            = "job.restore"
+	JobRollback           = "job.rollback"
+	JobAttach             = "job.attach"
+	JobDetach             = "job.detach"
+	JobPackagePurchase    = "jobpackage.purchase"
+	WebhookCreate         = "webhook.create"
+	WebhookDelete         = "webhook.delete"
+	WebhookRedeliver      = "webhook.redeliver"
+	UploadDelete          = "upload.delete"
+)
+
+// Target types
+const (
+	Employer   = "employer"
+	Company    = "company"
+	Job        = "job"
+	JobPackage = "jobpackage"
+	Webhook    = "webhook"
+	Upload     = "upload"
+)
+
+// MaxLimit caps how many entries GetEntries returns
+const MaxLimit = 500
+
+// Redacted replaces the value of sensitive fields in Changes
+const Redacted = "[redacted]"
+
+// redactedFields are recorded as changed without their values
+var redactedFields = map[string]bool{"password": true, "secret": true, "paymentdetails": true}
+
+// AuditRepository stores the append-only log of changes employers make
+type AuditRepository interface {
+	Record(entry *Entry) (*Entry, error)
+	GetEntries(employerPublicID string, filter *Filter) ([]*Entry, error)
+}
+
+// PostgresAuditRepository is the AuditRepository backed by the auditlog table
+type PostgresAuditRepository struct {
+	Database database.Querier
+}
+
+func NewAuditRepository(db database.Querier) *PostgresAuditRepository {
+	return &PostgresAuditRepository{Database: db}
+}
+
+// Entry is one change. Changes maps each changed field to its before and
+// after values.
+type Entry struct {
+	PublicID        string          `json:"publicid"`
+	ActorPublicID   string          `json:"actorpublicid"`
+	CompanyPublicID string          `json:"companypublicid"`
+	Action          string          `json:"action"`
+	TargetType      string          `json:"targettype"`
+	TargetPublicID  string          `json:"targetpublicid"`
+	Changes         json.RawMessage `json:"changes"`
+	IP              string          `json:"ip"`
+	UserAgent       string          `json:"useragent"`
+	CreatedAt       time.Time       `json:"createdat"`
+}
+
+// Change is the before and after value of one field
+type Change struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Filter narrows GetEntries. Empty fields match everything.
+type Filter struct {
+	ActorPublicID  string
+	Action         string
+	TargetType     string
+	TargetPublicID string
+	Since          time.Time
+	Until          time.Time
+	Limit          int
+}
+
+// Diff returns the fields that differ between the JSON forms of before and
+// after. Either may be nil, for creations and deletions.
+func Diff(before, after interface{}) (json.RawMessage, error) {
+
+	beforeFields, err := fields(before)
+
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := fields(after)
+
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]*Change{}
+
+	for name, value := range beforeFields {
+		if other, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, other) {
+			changes[name] = &Change{Before: value, After: other}
+		}
+	}
+
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = &Change{After: value}
+		}
+	}
+
+	for name, change := range changes {
+		if redactedFields[strings.ToLower(name)] {
+			change.Before, change.After = redact(change.Before), redact(change.After)
+		}
+	}
+
+	return json.Marshal(changes)
+}
+
+func fields(value interface{}) (map[string]interface{}, error) {
+
+	fields := map[string]interface{}{}
+
+	if value == nil || reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil() {
+		return fields, nil
+	}
+
+	encoded, err := json.Marshal(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, fmt.Errorf("audit: %T is not a JSON object", value)
+	}
+
+	return fields, nil
+}
+
+func redact(value interface{}) interface{} {
+
+	if value == nil || value == "" {
+		return value
+	}
+
+	return Redacted
+}
+
+// Record appends entry, filling in its publicid, the actor's company and the
+// time
+func (repository *PostgresAuditRepository) Record(entry *Entry) (*Entry, error) {
+
+	if entry.ActorPublicID == "" || entry.Action == "" || entry.TargetType == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	recorded := *entry
+	recorded.PublicID = uuid.NewString()
+
+	if len(recorded.Changes) == 0 {
+		recorded.Changes = json.RawMessage(`{}`)
+	}
+
+	err := repository.Database.QueryRow(`
+		INSERT INTO auditlog(publicid, actorpublicid, companypublicid, action, targettype, targetpublicid, changes, ip, useragent)
+		VALUES ($1, $2, COALESCE(NULLIF($3, ''), (SELECT companies.publicid FROM employers JOIN companies ON companies.id=employers.companyid WHERE employers.publicid=$2), ''), $4, $5, $6, $7, $8, $9)
+		RETURNING companypublicid, createdat;`,
+		recorded.PublicID, recorded.ActorPublicID, recorded.CompanyPublicID, recorded.Action, recorded.TargetType,
+		recorded.TargetPublicID, []byte(recorded.Changes), recorded.IP, recorded.UserAgent).Scan(&recorded.CompanyPublicID, &recorded.CreatedAt)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &recorded, nil
+}
+
+// GetEntries returns the entries of the employer's company, and the
+// employer's own entries made before they had one, newest first
+func (repository *PostgresAuditRepository) GetEntries(employerPublicID string, filter *Filter) ([]*Entry, error) {
+
+	if employerPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	if filter == nil {
+		filter = &Filter{}
+	}
+
+	conditions := []string{`(auditlog.companypublicid <> '' AND auditlog.companypublicid = (
+		SELECT companies.publicid FROM employers JOIN companies ON companies.id=employers.companyid WHERE employers.publicid=$1)
+		OR auditlog.actorpublicid=$1)`}
+	args := []interface{}{employerPublicID}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorPublicID != "" {
+		add("auditlog.actorpublicid=$%d", filter.ActorPublicID)
+	}
+
+	if filter.Action != "" {
+		add("auditlog.action=$%d", filter.Action)
+	}
+
+	if filter.TargetType != "" {
+		add("auditlog.targettype=$%d", filter.TargetType)
+	}
+
+	if filter.TargetPublicID != "" {
+		add("auditlog.targetpublicid=$%d", filter.TargetPublicID)
+	}
+
+	if !filter.Since.IsZero() {
+		add("auditlog.createdat >= $%d", filter.Since)
+	}
+
+	if !filter.Until.IsZero() {
+		add("auditlog.createdat < $%d", filter.Until)
+	}
+
+	args = append(args, filter.MaxEntries())
+
+	rows, err := repository.Database.Query(`
+		SELECT publicid, actorpublicid, companypublicid, action, targettype, targetpublicid, changes, ip, useragent, createdat
+		FROM auditlog
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY createdat DESC, id DESC
+		LIMIT $`+fmt.Sprint(len(args))+`;`, args...)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var entries []*Entry
+
+	for rows.Next() {
+		var entry Entry
+		var changes []byte
+
+		err := rows.Scan(&entry.PublicID, &entry.ActorPublicID, &entry.CompanyPublicID, &entry.Action, &entry.TargetType,
+			&entry.TargetPublicID, &changes, &entry.IP, &entry.UserAgent, &entry.CreatedAt)
+
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		entry.Changes = changes
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// MaxEntries is how many entries the filter returns at most
+func (filter *Filter) MaxEntries() int {
+
+	if filter.Limit < 1 || filter.Limit > MaxLimit {
+		return MaxLimit
+	}
+
+	return filter.Limit
+}
+
+// Matches reports whether entry passes the filter, for stores that cannot
+// filter in a query
+func (filter *Filter) Matches(entry *Entry) bool {
+	return (filter.ActorPublicID == "" || entry.ActorPublicID == filter.ActorPublicID) &&
+		(filter.Action == "" || entry.Action == filter.Action) &&
+		(filter.TargetType == "" || entry.TargetType == filter.TargetType) &&
+		(filter.TargetPublicID == "" || entry.TargetPublicID == filter.TargetPublicID) &&
+		(filter.Since.IsZero() || !entry.CreatedAt.Before(filter.Since)) &&
+		(filter.Until.IsZero() || entry.CreatedAt.Before(filter.Until))
+}
@@ -0,0 +1,12 @@
+package companies_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func Test_CompanyRepository_Contract(t *testing.T) {
+	repositorytest.CompanyRepository(t, testhelper.Repositories)
+}
@@ -1,12 +1,28 @@
 package companies
 
 import (
-	"database/sql"
 	"log"
+
+	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/domain"
+)
+
+// CompanyRepository manages the companies employers belong to
+type CompanyRepository interface {
+	GetOrCreateCompany(domain, name, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode string) (*Company, error)
+}
+
+var (
+	// ErrNotFound is returned for an employer without a company
+	ErrNotFound = domain.NewNotFound("company_not_found", "company not found")
+	// ErrStale is returned by a conditional update when the company's
+	// version is not the one the caller read
+	ErrStale = domain.NewConflict("company_modified", "company was changed since it was read")
 )
 
-type CompanyRepository struct {
-	Database *sql.DB
+// PostgresCompanyRepository is the CompanyRepository backed by the companies table
+type PostgresCompanyRepository struct {
+	Database database.Querier
 }
 
 type Company struct {
@@ -24,23 +40,146 @@ type Company struct {
 	ExtraDetails string  `json:"extradetails"`
 	PublicID     string  `json:"publicid"`
 	Zipcode      string  `json:"zipcode"`
+	City         string  `json:"city"`
+	State        string  `json:"state"`
+	County       string  `json:"county"`
+	Country      string  `json:"country"`
+	Version      int     `json:"version"`
+
+	// LogoVariants maps the name of each size the logo is stored at to its
+	// URL. It is set with the logo by an upload, and cleared when the logo
+	// is changed any other way.
+	LogoVariants map[string]string `json:"logovariants,omitempty"`
+}
+
+// Profile is the part of a company its employers edit
+type Profile struct {
+	Name         string  `json:"name" validate:"required,max=200"`
+	Location     string  `json:"location" validate:"max=200"`
+	Longitude    float64 `json:"longitude" validate:"min=-180,max=180"`
+	Latitude     float64 `json:"latitude" validate:"min=-90,max=90"`
+	URL          string  `json:"url" validate:"url,max=2000"`
+	Facebook     string  `json:"facebook" validate:"max=200"`
+	Twitter      string  `json:"twitter" validate:"max=200"`
+	Instagram    string  `json:"instagram" validate:"max=200"`
+	Description  string  `json:"description" validate:"max=20000"`
+	Logo         string  `json:"logo" validate:"max=2000"`
+	ExtraDetails string  `json:"extradetails" validate:"max=20000"`
+	Zipcode      string  `json:"zipcode" validate:"max=10"`
+
+	// City, State, County and Country are where the other location fields
+	// resolve to. They are set by the server when the profile is saved.
+	City    string `json:"city" validate:"max=100"`
+	State   string `json:"state" validate:"max=100"`
+	County  string `json:"county" validate:"max=100"`
+	Country string `json:"country" validate:"max=100"`
 }
 
-func NewCompanyRepository(db *sql.DB) *CompanyRepository {
-	return &CompanyRepository{Database: db}
+// Profile returns the editable part of company
+func (company *Company) Profile() Profile {
+	return Profile{
+		Name:         company.Name,
+		Location:     company.Location,
+		Longitude:    company.Longitude,
+		Latitude:     company.Latitude,
+		URL:          company.URL,
+		Facebook:     company.Facebook,
+		Twitter:      company.Twitter,
+		Instagram:    company.Instagram,
+		Description:  company.Description,
+		Logo:         company.Logo,
+		ExtraDetails: company.ExtraDetails,
+		Zipcode:      company.Zipcode,
+		City:         company.City,
+		State:        company.State,
+		County:       company.County,
+		Country:      company.Country,
+	}
 }
 
-func (repository *CompanyRepository) GetOrCreateCompany(domain, name, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode string) (*Company, error) {
+// Merge copies the non-empty fields of changes into profile
+func (profile *Profile) Merge(changes Profile) {
+
+	if changes.Name != "" {
+		profile.Name = changes.Name
+	}
+
+	if changes.Location != "" {
+		profile.Location = changes.Location
+	}
+
+	if changes.Longitude != 0 {
+		profile.Longitude = changes.Longitude
+	}
+
+	if changes.Latitude != 0 {
+		profile.Latitude = changes.Latitude
+	}
+
+	if changes.URL != "" {
+		profile.URL = changes.URL
+	}
+
+	if changes.Facebook != "" {
+		profile.Facebook = changes.Facebook
+	}
+
+	if changes.Twitter != "" {
+		profile.Twitter = changes.Twitter
+	}
+
+	if changes.Instagram != "" {
+		profile.Instagram = changes.Instagram
+	}
+
+	if changes.Description != "" {
+		profile.Description = changes.Description
+	}
+
+	if changes.Logo != "" {
+		profile.Logo = changes.Logo
+	}
+
+	if changes.ExtraDetails != "" {
+		profile.ExtraDetails = changes.ExtraDetails
+	}
+
+	if changes.Zipcode != "" {
+		profile.Zipcode = changes.Zipcode
+	}
+
+	if changes.City != "" {
+		profile.City = changes.City
+	}
+
+	if changes.State != "" {
+		profile.State = changes.State
+	}
+
+	if changes.County != "" {
+		profile.County = changes.County
+	}
+
+	if changes.Country != "" {
+		profile.Country = changes.Country
+	}
+}
+
+func NewCompanyRepository(db database.Querier) *PostgresCompanyRepository {
+	return &PostgresCompanyRepository{Database: db}
+}
+
+func (repository *PostgresCompanyRepository) GetOrCreateCompany(domain, name, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode string) (*Company, error) {
 	var company Company
 
-	stmt, err := repository.Database.Prepare(`SELECT name, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode, publicid FROM companies WHERE domain=$1;`)
+	stmt, err := repository.Database.Prepare(`SELECT name, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode, publicid, version FROM companies WHERE domain=$1;`)
 
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	err = stmt.QueryRow(domain).Scan(&company.Name, &company.Location, &company.URL, &company.Facebook, &company.Twitter, &company.Instagram, &company.Description, &company.Logo, &company.ExtraDetails, &company.Zipcode, &company.PublicID)
+	err = stmt.QueryRow(domain).Scan(&company.Name, &company.Location, &company.URL, &company.Facebook, &company.Twitter, &company.Instagram, &company.Description, &company.Logo, &company.ExtraDetails, &company.Zipcode, &company.PublicID, &company.Version)
 
 	if err != nil {
 
@@ -48,14 +187,14 @@ func (repository *CompanyRepository) GetOrCreateCompany(domain, name, location,
 			stmt, err := repository.Database.Prepare(`
 				INSERT INTO companies(name, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode, domain) VALUES
 				($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-				RETURNING publicid;`)
+				RETURNING publicid, version;`)
 
 			if err != nil {
 				log.Println(err)
 				return nil, err
 			}
 
-			err = stmt.QueryRow(name, location, url, facebook, twitter, instagram, description, logo, extradetails, domain).Scan(&company.PublicID)
+			err = stmt.QueryRow(name, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode, domain).Scan(&company.PublicID, &company.Version)
 
 			if err != nil {
 				log.Println(err)
@@ -81,5 +220,7 @@ func (repository *CompanyRepository) GetOrCreateCompany(domain, name, location,
 
 	}
 
+	company.Domain = domain
+
 	return &company, nil
 }
@@ -30,9 +30,9 @@ func Test_CompanyRepository_GetOrCreateCompany_Create_Correct(t *testing.T) {
 		"extradetails": "",
 	}
 
-	repository := companies.NewCompanyRegistry().GetCompanyRepository()
+	repository := companies.NewCompanyRepository(testhelper.DB)
 
-	result, err := repository.GetOrCreateCompany(data["domain"], data["name"], data["location"], data["url"], data["facebook"], data["twitter"], data["instagram"], data["description"], data["logo"], data["extradetails"])
+	result, err := repository.GetOrCreateCompany(data["domain"], data["name"], data["location"], data["url"], data["facebook"], data["twitter"], data["instagram"], data["description"], data["logo"], data["extradetails"], "")
 
 	assert.Nil(err)
 	assert.NotNil(result)
@@ -45,9 +45,9 @@ func Test_CompanyRepository_GetOrCreateCompany_Get_Correct(t *testing.T) {
 
 	company := testhelper.Helper_RandomCompany(t)
 
-	repository := companies.NewCompanyRegistry().GetCompanyRepository()
+	repository := companies.NewCompanyRepository(testhelper.DB)
 
-	result, err := repository.GetOrCreateCompany(company.Domain, "", "", "", "", "", "", "", "", "")
+	result, err := repository.GetOrCreateCompany(company.Domain, "", "", "", "", "", "", "", "", "", "")
 
 	assert.Nil(err)
 	assert.NotNil(result)
@@ -2,17 +2,52 @@ package accountmanagement
 
 import (
 	"database/sql"
-	"errors"
+	"encoding/json"
 	"log"
 
+	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/domain"
 	"autumnomous-jobs-employer-api/shared/repository/companies"
 	"autumnomous-jobs-employer-api/shared/services/security/encryption"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
-type EmployerRepository struct {
-	Database *sql.DB
+// EmployerRepository manages employer accounts and their registration progress
+type EmployerRepository interface {
+	CreateEmployer(firstName, lastName, email, password string) (*Employer, error)
+	GetEmployer(userID string) (*Employer, error)
+	AuthenticateEmployerPassword(email, password string) (bool, string, string, error)
+	UpdateEmployerPassword(publicID, password, newPassword string) (bool, error)
+	UpdateEmployerAccount(publicID, firstName, lastName, email, phoneNumber, mobileNumber, role, facebook, twitter, instagram string) (*Employer, error)
+	// UpdateEmployerAccount and UpdateEmployerCompany leave empty fields
+	// unchanged. The Replace methods write every field.
+	//
+	// UpdateEmployerCompany writes unconditionally when version is 0, and
+	// otherwise returns companies.ErrStale unless the company is at version
+	UpdateEmployerCompany(employerPublicID, companyName, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode string, longitude, latitude float64, version int) (*companies.Company, error)
+	ReplaceEmployerAccount(publicID string, account Account) (*Employer, error)
+	ReplaceEmployerCompany(employerPublicID string, profile companies.Profile, version int) (*companies.Company, error)
+	// SetEmployerCompanyLogo sets the company's logo and the URLs of its
+	// variants. ReplaceEmployerCompany clears the variants when it changes
+	// the logo.
+	SetEmployerCompanyLogo(employerPublicID, logo string, variants map[string]string) (*companies.Company, error)
+	UpdateEmployerPaymentMethod(employerPublicID, paymentMethod string) error
+	UpdateEmployerPaymentDetails(employerPublicID, paymentDetails string) error
+	SetEmployerCompany(employerPublicID, companyPublicID string) error
+	GetEmployerCompany(employerPublicID string) (*companies.Company, error)
+}
+
+var (
+	// ErrNotFound is returned for an employer that does not exist
+	ErrNotFound = domain.NewNotFound("employer_not_found", "employer not found")
+	// ErrEmailTaken is returned when another employer has the email address
+	ErrEmailTaken = domain.NewConflict("email_taken", "an employer with this email already exists")
+)
+
+// PostgresEmployerRepository is the EmployerRepository backed by the employers table
+type PostgresEmployerRepository struct {
+	Database database.Querier
 }
 
 type Employer struct {
@@ -27,11 +62,79 @@ type Employer struct {
 	Instagram        string `json:"instagram"`
 	TotalPostsBought int    `json:"totalpostsbought"`
 	RegistrationStep string `json:"registrationstep"`
-	Password         string
+	Password         string `json:"-"`
 	CompanyPublicID  string `json:"companypublicid"`
 	PublicID         string `json:"publicid"`
 }
 
+// Account is the part of an employer they edit themselves
+type Account struct {
+	FirstName    string `json:"firstname" validate:"max=100"`
+	LastName     string `json:"lastname" validate:"max=100"`
+	Email        string `json:"email" validate:"required,email,max=254"`
+	PhoneNumber  string `json:"phonenumber" validate:"max=30"`
+	MobileNumber string `json:"mobilenumber" validate:"max=30"`
+	Role         string `json:"role" validate:"max=100"`
+	Facebook     string `json:"facebook" validate:"max=200"`
+	Twitter      string `json:"twitter" validate:"max=200"`
+	Instagram    string `json:"instagram" validate:"max=200"`
+}
+
+// Account returns the editable part of employer
+func (employer *Employer) Account() Account {
+	return Account{
+		FirstName:    employer.FirstName,
+		LastName:     employer.LastName,
+		Email:        employer.Email,
+		PhoneNumber:  employer.PhoneNumber,
+		MobileNumber: employer.MobileNumber,
+		Role:         employer.Role,
+		Facebook:     employer.Facebook,
+		Twitter:      employer.Twitter,
+		Instagram:    employer.Instagram,
+	}
+}
+
+// Merge copies the non-empty fields of changes into account
+func (account *Account) Merge(changes Account) {
+
+	if changes.FirstName != "" {
+		account.FirstName = changes.FirstName
+	}
+
+	if changes.LastName != "" {
+		account.LastName = changes.LastName
+	}
+
+	if changes.Email != "" {
+		account.Email = changes.Email
+	}
+
+	if changes.PhoneNumber != "" {
+		account.PhoneNumber = changes.PhoneNumber
+	}
+
+	if changes.MobileNumber != "" {
+		account.MobileNumber = changes.MobileNumber
+	}
+
+	if changes.Role != "" {
+		account.Role = changes.Role
+	}
+
+	if changes.Facebook != "" {
+		account.Facebook = changes.Facebook
+	}
+
+	if changes.Twitter != "" {
+		account.Twitter = changes.Twitter
+	}
+
+	if changes.Instagram != "" {
+		account.Instagram = changes.Instagram
+	}
+}
+
 // RegistrationStep represents which stage in the registration process the user is in
 type RegistrationStep int64
 
@@ -59,14 +162,14 @@ func (rs RegistrationStep) String() string {
 	return [...]string{"change-password", "personal-information", "company-details", "payment-method", "payment-details", "registration-complete"}[rs]
 }
 
-func NewEmployerRepository(db *sql.DB) *EmployerRepository {
-	return &EmployerRepository{Database: db}
+func NewEmployerRepository(db database.Querier) *PostgresEmployerRepository {
+	return &PostgresEmployerRepository{Database: db}
 }
 
-func (repository *EmployerRepository) CreateEmployer(firstName, lastName, email, password string) (*Employer, error) {
+func (repository *PostgresEmployerRepository) CreateEmployer(firstName, lastName, email, password string) (*Employer, error) {
 
 	if firstName == "" || lastName == "" || email == "" || password == "" {
-		return nil, errors.New("data cannot be empty")
+		return nil, domain.ErrMissingValue
 	}
 
 	employer := &Employer{FirstName: firstName, LastName: lastName, Email: email}
@@ -80,6 +183,10 @@ func (repository *EmployerRepository) CreateEmployer(firstName, lastName, email,
 
 	err = stmt.QueryRow(email, firstName, lastName, password).Scan(&employer.PublicID)
 
+	if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+		return nil, ErrEmailTaken
+	}
+
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -88,10 +195,10 @@ func (repository *EmployerRepository) CreateEmployer(firstName, lastName, email,
 	return employer, nil
 }
 
-func (repository *EmployerRepository) GetEmployer(userID string) (*Employer, error) {
+func (repository *PostgresEmployerRepository) GetEmployer(userID string) (*Employer, error) {
 
 	if userID == "" {
-		return nil, errors.New("missing required value")
+		return nil, domain.ErrMissingValue
 	}
 	var employer Employer
 
@@ -112,7 +219,7 @@ func (repository *EmployerRepository) GetEmployer(userID string) (*Employer, err
 
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return nil, domain.NotFoundOr(err, ErrNotFound)
 	}
 
 	if emp_mobile_number.Valid {
@@ -143,7 +250,7 @@ func (repository *EmployerRepository) GetEmployer(userID string) (*Employer, err
 	return &employer, nil
 }
 
-func (repository *EmployerRepository) AuthenticateEmployerPassword(email, password string) (bool, string, string, error) {
+func (repository *PostgresEmployerRepository) AuthenticateEmployerPassword(email, password string) (bool, string, string, error) {
 
 	if email == "" || password == "" {
 		return false, "", "", nil
@@ -177,7 +284,7 @@ func (repository *EmployerRepository) AuthenticateEmployerPassword(email, passwo
 	return false, "", "", nil
 }
 
-func (repository *EmployerRepository) UpdateEmployerPassword(publicID, password, newPassword string) (bool, error) {
+func (repository *PostgresEmployerRepository) UpdateEmployerPassword(publicID, password, newPassword string) (bool, error) {
 
 	if publicID == "" || password == "" || newPassword == "" {
 		return false, nil
@@ -249,189 +356,153 @@ func (repository *EmployerRepository) UpdateEmployerPassword(publicID, password,
 	}
 }
 
-func (repository *EmployerRepository) UpdateEmployerAccount(publicID, firstName, lastName, email, phoneNumber, mobileNumber, role, facebook, twitter, instagram string) (*Employer, error) {
-
-	Employer := &Employer{}
-
-	stmt, err := repository.Database.Prepare(`SELECT firstname, lastname, email FROM employers WHERE publicid=$1;`)
-
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
+func (repository *PostgresEmployerRepository) UpdateEmployerAccount(publicID, firstName, lastName, email, phoneNumber, mobileNumber, role, facebook, twitter, instagram string) (*Employer, error) {
 
-	err = stmt.QueryRow(publicID).Scan(&Employer.FirstName, &Employer.LastName, &Employer.Email)
+	employer, err := repository.GetEmployer(publicID)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	if firstName != "" {
-		Employer.FirstName = firstName
-	}
+	account := employer.Account()
+	account.Merge(Account{FirstName: firstName, LastName: lastName, Email: email, PhoneNumber: phoneNumber, MobileNumber: mobileNumber, Role: role, Facebook: facebook, Twitter: twitter, Instagram: instagram})
 
-	if lastName != "" {
-		Employer.LastName = lastName
-	}
+	return repository.ReplaceEmployerAccount(publicID, account)
+}
 
-	if email != "" {
-		Employer.Email = email
-	}
+// ReplaceEmployerAccount writes every field of account, so empty fields are
+// cleared
+func (repository *PostgresEmployerRepository) ReplaceEmployerAccount(publicID string, account Account) (*Employer, error) {
 
-	if phoneNumber != "" {
-		Employer.PhoneNumber = phoneNumber
+	if publicID == "" || account.Email == "" {
+		return nil, domain.ErrMissingValue
 	}
 
-	if mobileNumber != "" {
-		Employer.MobileNumber = mobileNumber
-	}
+	result, err := repository.Database.Exec(`UPDATE employers SET firstname=$1, lastname=$2, email=$3, phonenumber=$4, mobilenumber=$5, role=$6, facebook=$7, twitter=$8, instagram=$9 WHERE publicid=$10;`,
+		account.FirstName, account.LastName, account.Email, account.PhoneNumber, account.MobileNumber, account.Role, account.Facebook, account.Twitter, account.Instagram, publicID)
 
-	if role != "" {
-		Employer.Role = role
+	if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+		return nil, ErrEmailTaken
 	}
 
-	Employer.Facebook = facebook
-	Employer.Twitter = twitter
-	Employer.Instagram = instagram
-	Employer.PublicID = publicID
-	stmt, err = repository.Database.Prepare(`UPDATE employers SET firstname=$1, lastname=$2, email=$3, phonenumber=$4, mobilenumber=$5, role=$6, facebook=$7, twitter=$8, instagram=$9 WHERE publicid=$10;`)
-
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	_, err = stmt.Exec(Employer.FirstName, Employer.LastName, Employer.Email, Employer.PhoneNumber, Employer.MobileNumber, Employer.Role, facebook, twitter, instagram, Employer.PublicID)
-
-	if err != nil {
-		log.Println(err)
+	if updated, err := result.RowsAffected(); err != nil {
 		return nil, err
+	} else if updated == 0 {
+		return nil, ErrNotFound
 	}
 
-	emp, _ := repository.GetEmployer(publicID)
-
-	if emp.RegistrationStep == PersonalInformation.String() {
-		stmt, _ = repository.Database.Prepare(`UPDATE employers SET registrationstep='company-details' WHERE publicid=$1;`)
-
-		stmt.Exec(publicID)
+	_, err = repository.Database.Exec(`UPDATE employers SET registrationstep=$1 WHERE publicid=$2 AND registrationstep=$3;`, CompanyDetails.String(), publicID, PersonalInformation.String())
 
+	if err != nil {
+		log.Println(err)
+		return nil, err
 	}
 
-	return Employer, nil
+	return repository.GetEmployer(publicID)
 }
 
-func (repository *EmployerRepository) UpdateEmployerCompany(employerPublicID, companyName, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode string, longitude, latitude float64) (*companies.Company, error) {
+func (repository *PostgresEmployerRepository) UpdateEmployerCompany(employerPublicID, companyName, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode string, longitude, latitude float64, version int) (*companies.Company, error) {
 
-	var company companies.Company
-	var companyLongitude, companyLatitude sql.NullFloat64
-	stmt, err := repository.Database.Prepare(`
-		SELECT companies.name, companies.location, companies.longitude, companies.latitude, companies.url, 
-			companies.facebook, companies.twitter, companies.instagram, 
-			companies.logo, companies.description, companies.extradetails,
-			companies.domain, companies.publicid, companies.zipcode
-		FROM companies 
-		JOIN employers ON employers.companyid = companies.id 
-		WHERE employers.publicid=$1;`)
+	company, err := repository.GetEmployerCompany(employerPublicID)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	err = stmt.QueryRow(employerPublicID).Scan(&company.Name, &company.Location, &companyLongitude, &companyLatitude, &company.URL, &company.Facebook, &company.Twitter, &company.Instagram, &company.Description, &company.Logo, &company.ExtraDetails, &company.Domain, &company.PublicID, &company.Zipcode)
+	profile := company.Profile()
+	profile.Merge(companies.Profile{Name: companyName, Location: location, Longitude: longitude, Latitude: latitude, URL: url, Facebook: facebook, Twitter: twitter,
+		Instagram: instagram, Description: description, Logo: logo, ExtraDetails: extradetails, Zipcode: zipcode})
 
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
+	return repository.ReplaceEmployerCompany(employerPublicID, profile, version)
+}
 
-	if companyLongitude.Valid {
-		company.Longitude = companyLongitude.Float64
-	}
+// ReplaceEmployerCompany writes every field of profile, so empty fields are
+// cleared. It writes unconditionally when version is 0, and otherwise
+// returns companies.ErrStale unless the company is at version.
+func (repository *PostgresEmployerRepository) ReplaceEmployerCompany(employerPublicID string, profile companies.Profile, version int) (*companies.Company, error) {
 
-	if companyLatitude.Valid {
-		company.Latitude = companyLatitude.Float64
+	if employerPublicID == "" {
+		return nil, domain.ErrMissingValue
 	}
 
-	if companyName != "" {
-		company.Name = companyName
-	}
+	var companyPublicID string
 
-	if location != "" {
-		company.Location = location
-	}
+	err := repository.Database.QueryRow(`
+		UPDATE companies SET name=$1, location=$2, url=$3, facebook=$4, twitter=$5, instagram=$6, description=$7, logo=$8, extradetails=$9, longitude=$10, latitude=$11, zipcode=$12,
+			city=$15, state=$16, county=$17, country=$18, version=version+1,
+			logovariants=CASE WHEN logo=$8 THEN logovariants ELSE '{}' END
+		WHERE id=(SELECT companyid FROM employers WHERE publicid=$13) AND ($14 = 0 OR version=$14)
+		RETURNING publicid;`,
+		profile.Name, profile.Location, profile.URL, profile.Facebook, profile.Twitter, profile.Instagram, profile.Description, profile.Logo, profile.ExtraDetails,
+		profile.Longitude, profile.Latitude, profile.Zipcode, employerPublicID, version, profile.City, profile.State, profile.County, profile.Country).Scan(&companyPublicID)
 
-	if longitude != 0 {
-		company.Longitude = longitude
-	}
+	if err == sql.ErrNoRows && version != 0 {
 
-	if latitude != 0 {
-		company.Latitude = latitude
-	}
-
-	if url != "" {
-		company.URL = url
+		// the company exists, so another update won the race since it was read
+		if _, err := repository.GetEmployerCompany(employerPublicID); err == nil {
+			return nil, companies.ErrStale
+		}
 	}
 
-	if facebook != "" {
-		company.Facebook = facebook
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, companies.ErrNotFound)
 	}
 
-	if twitter != "" {
-		company.Twitter = twitter
-	}
+	_, err = repository.Database.Exec(`UPDATE employers SET registrationstep=$1 WHERE publicid=$2 AND registrationstep=$3;`, PaymentMethod.String(), employerPublicID, CompanyDetails.String())
 
-	if instagram != "" {
-		company.Instagram = instagram
+	if err != nil {
+		log.Println(err)
+		return nil, err
 	}
 
-	if description != "" {
-		company.Description = description
-	}
+	return repository.GetEmployerCompany(employerPublicID)
+}
 
-	if logo != "" {
-		company.Logo = logo
-	}
+func (repository *PostgresEmployerRepository) SetEmployerCompanyLogo(employerPublicID, logo string, variants map[string]string) (*companies.Company, error) {
 
-	if extradetails != "" {
-		company.ExtraDetails = extradetails
+	if employerPublicID == "" {
+		return nil, domain.ErrMissingValue
 	}
 
-	if zipcode != "" {
-		company.Zipcode = zipcode
+	if variants == nil {
+		variants = map[string]string{}
 	}
 
-	stmt, err = repository.Database.Prepare(`UPDATE companies SET name=$1, location=$2, url=$3, facebook=$4, twitter=$5, instagram=$6, description=$7, logo=$8, extradetails=$9, longitude=$10, latitude=$11, zipcode=$12 WHERE publicid=$13;`)
+	encoded, err := json.Marshal(variants)
 
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	_, err = stmt.Exec(company.Name, company.Location, company.URL, company.Facebook, company.Twitter, company.Instagram, company.Description, company.Logo, company.ExtraDetails, company.Longitude, company.Latitude, company.Zipcode, company.PublicID)
+	result, err := repository.Database.Exec(`
+		UPDATE companies SET logo=$1, logovariants=$2, version=version+1
+		WHERE id=(SELECT companyid FROM employers WHERE publicid=$3);`, logo, encoded, employerPublicID)
 
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	emp, _ := repository.GetEmployer(employerPublicID)
-
-	if emp.RegistrationStep == CompanyDetails.String() {
-		stmt, _ = repository.Database.Prepare(`UPDATE employers SET registrationstep='payment-method' WHERE publicid=$1;`)
-
-		stmt.Exec(employerPublicID)
-
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return nil, companies.ErrNotFound
 	}
 
-	return &company, nil
-
+	return repository.GetEmployerCompany(employerPublicID)
 }
 
-func (repository *EmployerRepository) UpdateEmployerPaymentMethod(employerPublicID, paymentMethod string) error {
+func (repository *PostgresEmployerRepository) UpdateEmployerPaymentMethod(employerPublicID, paymentMethod string) error {
+
+	emp, err := repository.GetEmployer(employerPublicID)
 
-	emp, _ := repository.GetEmployer(employerPublicID)
+	if err != nil {
+		return err
+	}
 
 	if emp.RegistrationStep == PaymentMethod.String() {
 		stmt, err := repository.Database.Prepare(`UPDATE employers SET registrationstep='payment-details' WHERE publicid=$1;`)
@@ -448,9 +519,13 @@ func (repository *EmployerRepository) UpdateEmployerPaymentMethod(employerPublic
 	return nil
 }
 
-func (repository *EmployerRepository) UpdateEmployerPaymentDetails(employerPublicID, paymentDetails string) error {
+func (repository *PostgresEmployerRepository) UpdateEmployerPaymentDetails(employerPublicID, paymentDetails string) error {
+
+	emp, err := repository.GetEmployer(employerPublicID)
 
-	emp, _ := repository.GetEmployer(employerPublicID)
+	if err != nil {
+		return err
+	}
 
 	if emp.RegistrationStep == PaymentDetails.String() {
 		stmt, err := repository.Database.Prepare(`UPDATE employers SET registrationstep='registration-complete' WHERE publicid=$1;`)
@@ -468,37 +543,51 @@ func (repository *EmployerRepository) UpdateEmployerPaymentDetails(employerPubli
 
 }
 
-func (repository *EmployerRepository) SetEmployerCompany(employerPublicID, companyPublicID string) error {
+func (repository *PostgresEmployerRepository) SetEmployerCompany(employerPublicID, companyPublicID string) error {
 
 	if employerPublicID == "" || companyPublicID == "" {
-		return errors.New("missing required value")
+		return domain.ErrMissingValue
 	}
 
-	stmt, err := repository.Database.Prepare(`UPDATE employers SET companyid=(SELECT id FROM companies WHERE publicid=$1) WHERE publicid=$2;`)
+	stmt, err := repository.Database.Prepare(`UPDATE employers SET companyid=companies.id FROM companies WHERE companies.publicid=$1 AND employers.publicid=$2;`)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = stmt.Exec(companyPublicID, employerPublicID)
+	result, err := stmt.Exec(companyPublicID, employerPublicID)
 
 	if err != nil {
 		return err
 	}
 
+	updated, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	// either the employer or the company does not exist
+	if updated == 0 {
+		return ErrNotFound
+	}
+
 	return nil
 }
 
-func (repository *EmployerRepository) GetEmployerCompany(employerPublicID string) (*companies.Company, error) {
+func (repository *PostgresEmployerRepository) GetEmployerCompany(employerPublicID string) (*companies.Company, error) {
 
 	if employerPublicID == "" {
-		return nil, errors.New("missing required value")
+		return nil, domain.ErrMissingValue
 	}
 	var company companies.Company
+	var companyLongitude, companyLatitude sql.NullFloat64
+	var companyZipcode sql.NullString
+	var logoVariants []byte
 	stmt, err := repository.Database.Prepare(`
 				SELECT 
 					name, domain, location, longitude, latitude, url, facebook, twitter, instagram,
-					description, logo, extradetails, publicid
+					description, logo, extradetails, publicid, zipcode, city, state, county, country, version, logovariants
 				FROM companies 
 				WHERE id = (SELECT companyid FROM employers WHERE publicid=$1);`)
 
@@ -507,12 +596,25 @@ func (repository *EmployerRepository) GetEmployerCompany(employerPublicID string
 		return nil, err
 	}
 
-	err = stmt.QueryRow(employerPublicID).Scan(&company.Name, &company.Domain, &company.Location, &company.Longitude, &company.Latitude, &company.URL, &company.Facebook, &company.Twitter, &company.Instagram, &company.Description, &company.Logo, &company.ExtraDetails, &company.PublicID)
+	err = stmt.QueryRow(employerPublicID).Scan(&company.Name, &company.Domain, &company.Location, &companyLongitude, &companyLatitude, &company.URL, &company.Facebook, &company.Twitter, &company.Instagram, &company.Description, &company.Logo, &company.ExtraDetails, &company.PublicID, &companyZipcode,
+		&company.City, &company.State, &company.County, &company.Country, &company.Version, &logoVariants)
 
 	if err != nil {
 		log.Println(err)
+		return nil, domain.NotFoundOr(err, companies.ErrNotFound)
+	}
+
+	if err := json.Unmarshal(logoVariants, &company.LogoVariants); err != nil {
 		return nil, err
 	}
 
+	if len(company.LogoVariants) == 0 {
+		company.LogoVariants = nil
+	}
+
+	company.Longitude = companyLongitude.Float64
+	company.Latitude = companyLatitude.Float64
+	company.Zipcode = companyZipcode.String
+
 	return &company, nil
 }
@@ -5,8 +5,6 @@ import (
 	"log"
 	"testing"
 
-	"autumnomous-jobs-employer-api/shared/database"
-	employers "autumnomous-jobs-employer-api/shared/repository/employers"
 	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
 	"autumnomous-jobs-employer-api/shared/services/security/encryption"
 	"autumnomous-jobs-employer-api/shared/testhelper"
@@ -22,8 +20,8 @@ func Test_NewEmployerRepository(t *testing.T) {
 
 	assert := assert.New(t)
 
-	result := accountmanagement.NewEmployerRepository(database.DB)
-	assert.Equal(database.DB, result.Database)
+	result := accountmanagement.NewEmployerRepository(testhelper.DB)
+	assert.Equal(testhelper.DB, result.Database)
 }
 
 func Test_EmployerRepository_CreateEmployer(t *testing.T) {
@@ -36,7 +34,7 @@ func Test_EmployerRepository_CreateEmployer(t *testing.T) {
 		"password":  string(encryption.GeneratePassword(9)),
 	}
 
-	repository := accountmanagement.NewEmployerRepository(database.DB)
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	result, err := repository.CreateEmployer(data["firstname"], data["lastname"], data["email"], data["password"])
 
@@ -80,7 +78,7 @@ func Test_EmployerRepository_CreateEmployer_Fail_EmptyData(t *testing.T) {
 
 	for _, test := range tests {
 
-		repository := accountmanagement.NewEmployerRepository(database.DB)
+		repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 		result, err := repository.CreateEmployer(test["firstname"], test["lastname"], test["email"], test["password"])
 
@@ -96,7 +94,7 @@ func Test_EmployerRepository_GetEmployer(t *testing.T) {
 
 	employer := testhelper.Helper_RandomEmployer(t)
 
-	repository := accountmanagement.NewEmployerRepository(database.DB)
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	result, err := repository.GetEmployer(employer.PublicID)
 
@@ -111,7 +109,7 @@ func Test_EmployerRepository_GetEmployer(t *testing.T) {
 func Test_EmployerRepository_GetEmployer_Fail_EmptyData(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := accountmanagement.NewEmployerRepository(database.DB)
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	result, err := repository.GetEmployer("")
 
@@ -124,7 +122,7 @@ func Test_EmployerRepository_AuthenticateEmployerPassword_NoDataReceived(t *test
 
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	data := map[string]string{
 		"email":    "",
@@ -142,7 +140,7 @@ func Test_EmployerRepository_AuthenicateEmployerPassword_CorrectDataReceived(t *
 
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	data := map[string]string{
 		"firstname": "First",
@@ -176,7 +174,7 @@ func Test_EmployerRepository_AuthenicateEmployerPassword_CorrectDataReceived(t *
 func Test_EmployerRepository_AuthenticateEmployerPassword_IncorrectDataReceived_Email(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	data := map[string]string{
 		"firstname":      "First",
@@ -213,7 +211,7 @@ func Test_EmployerRepository_AuthenticateEmployerPassword_IncorrectDataReceived_
 func Test_EmployerRepository_AuthenticateEmployerPassword_IncorrectDataReceived_Password(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	data := map[string]string{
 		"firstname":         "First",
@@ -250,7 +248,7 @@ func Test_EmployerRepository_AuthenticateEmployerPassword_IncorrectDataReceived_
 func Test_EmployerRepository_UpdateEmployerPassword_NoDataReceived(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	data := map[string]string{
 		"password":    "",
@@ -266,7 +264,7 @@ func Test_EmployerRepository_UpdateEmployerPassword_NoDataReceived(t *testing.T)
 
 func Test_EmployerRepository_UpdateEmployerPassword_IncorrectPublicID(t *testing.T) {
 	assert := assert.New(t)
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	data := map[string]string{
 		"password":    string(encryption.GeneratePassword(9)),
@@ -284,7 +282,7 @@ func Test_EmployerRepository_UpdateEmployerPassword_CorrectData(t *testing.T) {
 
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	Employer := &testhelper.TestEmployer{
 		FirstName: "First",
@@ -319,7 +317,7 @@ func Test_EmployerRepository_UpdateEmployerAccount_CorrectData(t *testing.T) {
 
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	Employer := &testhelper.TestEmployer{
 		FirstName: "First",
@@ -355,7 +353,7 @@ func Test_EmployerRepository_UpdateEmployerAccount_CorrectData(t *testing.T) {
 func Test_EmployerRepository_SetEmployerCompany_Correct(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	employer := testhelper.Helper_RandomEmployer(t)
 	company := testhelper.Helper_RandomCompany(t)
@@ -372,7 +370,7 @@ func Test_EmployerRepository_SetEmployerCompany_Correct(t *testing.T) {
 func Test_EmployerRepository_SetEmployerCompany_Incorrect_NoEmployerPublicID(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	err := repository.SetEmployerCompany("", "notvalid")
 
@@ -383,7 +381,7 @@ func Test_EmployerRepository_SetEmployerCompany_Incorrect_NoEmployerPublicID(t *
 func Test_EmployerRepository_SetEmployerCompany_Incorrect_NoCompanyPublicID(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	err := repository.SetEmployerCompany("notvalid", "")
 
@@ -395,7 +393,7 @@ func Test_EmployerRepository_GetEmployerCompany_Incorrect_NoEmployerPublicID(t *
 
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	_, err := repository.GetEmployerCompany("")
 
@@ -407,7 +405,7 @@ func Test_EmployerRepository_GetEmployerCompany_Correct(t *testing.T) {
 
 	assert := assert.New(t)
 
-	repository := employers.NewEmployerRegistry().GetEmployerRepository()
+	repository := accountmanagement.NewEmployerRepository(testhelper.DB)
 
 	employer := testhelper.Helper_RandomEmployer(t)
 	company := testhelper.Helper_RandomCompany(t)
@@ -0,0 +1,12 @@
+package accountmanagement_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func Test_EmployerRepository_Contract(t *testing.T) {
+	repositorytest.EmployerRepository(t, testhelper.Repositories)
+}
@@ -0,0 +1,16 @@
+package geocodes_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func init() {
+	testhelper.Init()
+}
+
+func Test_GeocodeRepository_Contract(t *testing.T) {
+	repositorytest.GeocodeRepository(t, testhelper.Repositories)
+}
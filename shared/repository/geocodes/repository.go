@@ -0,0 +1,94 @@
+package geocodes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/domain"
+)
+
+// ErrNotFound is returned for a key that is not cached or has expired
+var ErrNotFound = domain.NewNotFound("geocode_not_found", "geocode not cached")
+
+// GeocodeRepository caches geocoder answers by key until they expire
+type GeocodeRepository interface {
+	GetGeocode(key string, now time.Time) (*Geocode, error)
+	PutGeocode(key string, value json.RawMessage, expiresAt time.Time) error
+	PurgeExpiredGeocodes(now time.Time) (int64, error)
+}
+
+// Geocode is a cached geocoder answer, as JSON
+type Geocode struct {
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt time.Time       `json:"expiresat"`
+}
+
+// PostgresGeocodeRepository is the GeocodeRepository backed by the
+// geocodecache table
+type PostgresGeocodeRepository struct {
+	Database database.Querier
+}
+
+func NewGeocodeRepository(db database.Querier) *PostgresGeocodeRepository {
+	return &PostgresGeocodeRepository{Database: db}
+}
+
+// GetGeocode returns the answer cached under key, unless it expired before now
+func (repository *PostgresGeocodeRepository) GetGeocode(key string, now time.Time) (*Geocode, error) {
+
+	var geocode Geocode
+	var value []byte
+
+	err := repository.Database.QueryRow(`
+		SELECT key, value, expiresat FROM geocodecache WHERE key=$1 AND expiresat > $2;`, key, now).Scan(&geocode.Key, &value, &geocode.ExpiresAt)
+
+	// misses are expected, only failures are logged
+	if err != nil && err != sql.ErrNoRows {
+		log.Println(err)
+	}
+
+	if err != nil {
+		return nil, domain.NotFoundOr(err, ErrNotFound)
+	}
+
+	geocode.Value = value
+
+	return &geocode, nil
+}
+
+// PutGeocode caches value under key until expiresAt, replacing any answer
+// cached under it before
+func (repository *PostgresGeocodeRepository) PutGeocode(key string, value json.RawMessage, expiresAt time.Time) error {
+
+	if key == "" || len(value) == 0 {
+		return domain.ErrMissingValue
+	}
+
+	_, err := repository.Database.Exec(`
+		INSERT INTO geocodecache(key, value, expiresat) VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value=EXCLUDED.value, expiresat=EXCLUDED.expiresat;`, key, []byte(value), expiresAt)
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// PurgeExpiredGeocodes removes the answers that expired before now
+func (repository *PostgresGeocodeRepository) PurgeExpiredGeocodes(now time.Time) (int64, error) {
+
+	result, err := repository.Database.Exec(`DELETE FROM geocodecache WHERE expiresat <= $1;`, now)
+
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,12 @@
+package jobpackages_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func Test_JobPackageRepository_Contract(t *testing.T) {
+	repositorytest.JobPackageRepository(t, testhelper.Repositories)
+}
@@ -1,12 +1,24 @@
 package jobpackages
 
 import (
-	"database/sql"
 	"log"
+
+	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/domain"
 )
 
-type JobPackageRepository struct {
-	Database *sql.DB
+// JobPackageRepository reads the job packages employers can buy
+type JobPackageRepository interface {
+	GetActiveJobPackages() ([]*JobPackage, error)
+	GetJobPackage(typeID string) (*JobPackage, error)
+}
+
+// ErrNotFound is returned for a job package that does not exist
+var ErrNotFound = domain.NewNotFound("job_package_not_found", "job package not found")
+
+// PostgresJobPackageRepository is the JobPackageRepository backed by the jobpackages table
+type PostgresJobPackageRepository struct {
+	Database database.Querier
 }
 
 type JobPackage struct {
@@ -19,11 +31,11 @@ type JobPackage struct {
 	Price        float64 `json:"price"`
 }
 
-func NewJobPackageRepository(db *sql.DB) *JobPackageRepository {
-	return &JobPackageRepository{Database: db}
+func NewJobPackageRepository(db database.Querier) *PostgresJobPackageRepository {
+	return &PostgresJobPackageRepository{Database: db}
 }
 
-func (repository *JobPackageRepository) GetActiveJobPackages() ([]*JobPackage, error) {
+func (repository *PostgresJobPackageRepository) GetActiveJobPackages() ([]*JobPackage, error) {
 
 	var packages []*JobPackage
 
@@ -61,7 +73,7 @@ func (repository *JobPackageRepository) GetActiveJobPackages() ([]*JobPackage, e
 	return packages, nil
 }
 
-func (repository *JobPackageRepository) GetJobPackage(typeID string) (*JobPackage, error) {
+func (repository *PostgresJobPackageRepository) GetJobPackage(typeID string) (*JobPackage, error) {
 
 	var pack JobPackage
 	stmt, err := repository.Database.Prepare(`
@@ -78,7 +90,7 @@ func (repository *JobPackageRepository) GetJobPackage(typeID string) (*JobPackag
 
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return nil, domain.NotFoundOr(err, ErrNotFound)
 	}
 
 	return &pack, nil
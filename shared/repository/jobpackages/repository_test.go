@@ -15,7 +15,7 @@ func init() {
 func Test_EmployerRepository_GetActiveJobPackages_Correct(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobpackages.NewJobPackageRegistry().GetJobPackageRepository()
+	repository := jobpackages.NewJobPackageRepository(testhelper.DB)
 
 	testhelper.Helper_RandomJobPackage(t)
 
@@ -30,7 +30,7 @@ func Test_EmployerRepository_GetActiveJobPackages_Correct(t *testing.T) {
 func Test_EmployerRepository_GetJobPackage_Correct(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobpackages.NewJobPackageRegistry().GetJobPackageRepository()
+	repository := jobpackages.NewJobPackageRepository(testhelper.DB)
 
 	jobpackage := testhelper.Helper_RandomJobPackage(t)
 
@@ -0,0 +1,12 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func Test_JobRepository_Contract(t *testing.T) {
+	repositorytest.JobRepository(t, testhelper.Repositories)
+}
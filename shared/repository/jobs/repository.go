@@ -1,59 +1,311 @@
 package jobs
 
 import (
+	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/domain"
 	"autumnomous-jobs-employer-api/shared/services/utils"
 	"database/sql"
-	"errors"
 	"log"
 	"strings"
+	"time"
 )
 
-type JobRepository struct {
-	Database *sql.DB
+// JobRepository manages the job postings owned by employers
+type JobRepository interface {
+	EmployerCreateJob(employerPublicID, jobTitle, jobType, category, jobDescription, visibleDate, payPeriod string, remote bool, minSalary, maxSalary int64) (*Job, error)
+	// CreateJob is EmployerCreateJob with every field of content
+	CreateJob(employerPublicID string, content RevisionContent) (*Job, error)
+	GetJob(jobPublicID string) (*Job, error)
+	// GetEmployerJobs leaves out deleted jobs unless includeDeleted, which
+	// returns them with DeletedAt set
+	GetEmployerJobs(employerPublicID string, includeDeleted bool) ([]*Job, error)
+	DeleteJob(employerPublicID, jobPublicID string) (*Job, error)
+	// EditJob leaves empty fields unchanged and ReplaceJob clears them. Both
+	// save unconditionally when revision is 0, and otherwise return ErrStale
+	// unless the job is at revision.
+	EditJob(employerPublicID, jobPublicID, jobTitle, jobType, category, jobDescription, visibleDate, payPeriod string, remote bool, minSalary, maxSalary int64, revision int) (*Job, error)
+	ReplaceJob(employerPublicID, jobPublicID string, content RevisionContent, revision int) (*Job, error)
+	GetJobRevisions(employerPublicID, jobPublicID string) ([]*Revision, error)
+	GetJobRevision(employerPublicID, jobPublicID string, number int) (*Revision, error)
+	RollbackJob(employerPublicID, jobPublicID string, number int) (*Job, error)
+	RestoreJob(employerPublicID, jobPublicID string, deletedSince time.Time) (*Job, error)
+	PurgeDeletedJobs(deletedBefore time.Time) (int64, error)
+	GoLive(now time.Time) ([]*Job, error)
+	ExpireJobs(now time.Time) ([]*Job, error)
 }
 
-func NewJobRepository(db *sql.DB) *JobRepository {
-	return &JobRepository{Database: db}
+var (
+	// ErrNotFound is returned for a job that does not exist, was deleted or
+	// belongs to another employer
+	ErrNotFound = domain.NewNotFound("job_not_found", "job not found")
+	// ErrRevisionNotFound is returned for a revision the job does not have
+	ErrRevisionNotFound = domain.NewNotFound("job_revision_not_found", "job revision not found")
+	// ErrStale is returned by a conditional edit when the job's revision is
+	// not the one the caller read
+	ErrStale = domain.NewConflict("job_modified", "job was changed since it was read")
+)
+
+// ListingPeriod is how long a job is listed after its visible date
+const ListingPeriod = 30 * 24 * time.Hour
+
+// DeleteRetention is how long a deleted job can be restored before it is
+// purged
+const DeleteRetention = 30 * 24 * time.Hour
+
+// LiveAt is when a job with visibleDate starts being listed. A missing or
+// unreadable visible date means now.
+func LiveAt(visibleDate string, now time.Time) time.Time {
+
+	start, err := time.Parse(time.RFC3339, visibleDate)
+
+	if err != nil {
+		return now
+	}
+
+	return start
+}
+
+// ExpiresAt is when a job with visibleDate stops being listed
+func ExpiresAt(visibleDate string, now time.Time) time.Time {
+	return LiveAt(visibleDate, now).Add(ListingPeriod)
+}
+
+// PostgresJobRepository is the JobRepository backed by the jobs table
+type PostgresJobRepository struct {
+	Database database.Querier
+}
+
+func NewJobRepository(db database.Querier) *PostgresJobRepository {
+	return &PostgresJobRepository{Database: db}
 }
 
 type Job struct {
-	PublicID         string `json:"publicid"`
-	Title            string `json:"title"`
-	JobType          string `json:"jobtype"`
-	Category         string `json:"category"`
-	Description      string `json:"description"` // make required?
-	EmployerPublicID string `json:"employerpublicid"`
-	Remote           bool   `json:"remote"`
-	VisibleDate      string `json:"visibledate"`
-	MinSalary        int64  `json:"minsalary"`
-	MaxSalary        int64  `json:"maxsalary"`
-	PayPeriod        string `json:"payperiod"`
+	PublicID         string  `json:"publicid"`
+	Title            string  `json:"title"`
+	JobType          string  `json:"jobtype"`
+	Category         string  `json:"category"`
+	Description      string  `json:"description"` // make required?
+	EmployerPublicID string  `json:"employerpublicid"`
+	Remote           bool    `json:"remote"`
+	VisibleDate      string  `json:"visibledate"`
+	MinSalary        int64   `json:"minsalary"`
+	MaxSalary        int64   `json:"maxsalary"`
+	PayPeriod        string  `json:"payperiod"`
+	Location         string  `json:"location"`
+	ZipCode          string  `json:"zipcode"`
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	City             string  `json:"city"`
+	State            string  `json:"state"`
+	County           string  `json:"county"`
+	Country          string  `json:"country"`
+	ExpiresAt        string  `json:"expiresat"`
+	Expired          bool    `json:"expired"`
+	DeletedAt        string  `json:"deletedat,omitempty"`
+	Revision         int     `json:"revision"`
+
+	// Attachments are only listed for a single job
+	Attachments []*Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a document attached to a job, served at URL
+type Attachment struct {
+	PublicID    string    `json:"publicid"`
+	FileName    string    `json:"filename"`
+	ContentType string    `json:"contenttype"`
+	Size        int64     `json:"size"`
+	URL         string    `json:"url"`
+	CreatedAt   time.Time `json:"createdat"`
 }
 
-func (repository *JobRepository) EmployerCreateJob(employerPublicID, jobTitle, jobType, category, jobDescription, visibleDate, payPeriod string, remote bool, minSalary, maxSalary int64) (*Job, error) {
+// Revision is a job's content as saved by its creation, an edit or a
+// rollback. Numbers start at 1 and increase with every save.
+type Revision struct {
+	Number         int             `json:"number"`
+	EditorPublicID string          `json:"editorpublicid"`
+	CreatedAt      time.Time       `json:"createdat"`
+	Content        RevisionContent `json:"content"`
+}
+
+// RevisionContent is the part of a job its revisions record
+type RevisionContent struct {
+	Title       string  `json:"title" validate:"required,max=200"`
+	JobType     string  `json:"jobtype" validate:"oneof=full-time part-time contract temporary internship"`
+	Category    string  `json:"category" validate:"max=100"`
+	Description string  `json:"description" validate:"max=20000"`
+	VisibleDate string  `json:"visibledate"`
+	Remote      bool    `json:"remote"`
+	MinSalary   int64   `json:"minsalary" validate:"min=0,ltefield=MaxSalary"`
+	MaxSalary   int64   `json:"maxsalary" validate:"min=0"`
+	PayPeriod   string  `json:"payperiod" validate:"oneof=hourly daily weekly monthly yearly"`
+	Location    string  `json:"location" validate:"max=200"`
+	ZipCode     string  `json:"zipcode" validate:"max=10"`
+	Latitude    float64 `json:"latitude" validate:"min=-90,max=90"`
+	Longitude   float64 `json:"longitude" validate:"min=-180,max=180"`
+
+	// City, State, County and Country are where the other location fields
+	// resolve to. They are set by the server when the job is saved.
+	City    string `json:"city" validate:"max=100"`
+	State   string `json:"state" validate:"max=100"`
+	County  string `json:"county" validate:"max=100"`
+	Country string `json:"country" validate:"max=100"`
+}
+
+// Merge copies the non-empty fields of changes into content. Remote is
+// left to the caller, as false cannot be told apart from unset.
+func (content *RevisionContent) Merge(changes RevisionContent) {
+
+	if changes.Title != "" {
+		content.Title = changes.Title
+	}
+
+	if changes.JobType != "" {
+		content.JobType = changes.JobType
+	}
+
+	if changes.Category != "" {
+		content.Category = changes.Category
+	}
+
+	if changes.Description != "" {
+		content.Description = changes.Description
+	}
+
+	if changes.VisibleDate != "" {
+		content.VisibleDate = changes.VisibleDate
+	}
+
+	if changes.MinSalary != 0 {
+		content.MinSalary = changes.MinSalary
+	}
+
+	if changes.MaxSalary != 0 {
+		content.MaxSalary = changes.MaxSalary
+	}
+
+	if changes.PayPeriod != "" {
+		content.PayPeriod = changes.PayPeriod
+	}
+
+	if changes.Location != "" {
+		content.Location = changes.Location
+	}
 
-	if jobTitle == "" {
-		return nil, errors.New("data cannot be empty")
+	if changes.ZipCode != "" {
+		content.ZipCode = changes.ZipCode
+	}
+
+	if changes.Latitude != 0 {
+		content.Latitude = changes.Latitude
+	}
+
+	if changes.Longitude != 0 {
+		content.Longitude = changes.Longitude
+	}
+
+	if changes.City != "" {
+		content.City = changes.City
+	}
+
+	if changes.State != "" {
+		content.State = changes.State
+	}
+
+	if changes.County != "" {
+		content.County = changes.County
+	}
+
+	if changes.Country != "" {
+		content.Country = changes.Country
+	}
+}
+
+// ApplyContent replaces the revisioned part of job with content
+func (job *Job) ApplyContent(content RevisionContent) {
+	job.Title = content.Title
+	job.JobType = content.JobType
+	job.Category = content.Category
+	job.Description = content.Description
+	job.VisibleDate = content.VisibleDate
+	job.Remote = content.Remote
+	job.MinSalary = content.MinSalary
+	job.MaxSalary = content.MaxSalary
+	job.PayPeriod = content.PayPeriod
+	job.Location = content.Location
+	job.ZipCode = content.ZipCode
+	job.Latitude = content.Latitude
+	job.Longitude = content.Longitude
+	job.City = content.City
+	job.State = content.State
+	job.County = content.County
+	job.Country = content.Country
+}
+
+// Content returns the revisioned part of job
+func (job *Job) Content() RevisionContent {
+	return RevisionContent{
+		Title:       job.Title,
+		JobType:     job.JobType,
+		Category:    job.Category,
+		Description: job.Description,
+		VisibleDate: job.VisibleDate,
+		Remote:      job.Remote,
+		MinSalary:   job.MinSalary,
+		MaxSalary:   job.MaxSalary,
+		PayPeriod:   job.PayPeriod,
+		Location:    job.Location,
+		ZipCode:     job.ZipCode,
+		Latitude:    job.Latitude,
+		Longitude:   job.Longitude,
+		City:        job.City,
+		State:       job.State,
+		County:      job.County,
+		Country:     job.Country,
+	}
+}
+
+func (repository *PostgresJobRepository) EmployerCreateJob(employerPublicID, jobTitle, jobType, category, jobDescription, visibleDate, payPeriod string, remote bool, minSalary, maxSalary int64) (*Job, error) {
+	return repository.CreateJob(employerPublicID, RevisionContent{Title: jobTitle, JobType: jobType, Category: category, Description: jobDescription,
+		VisibleDate: visibleDate, PayPeriod: payPeriod, Remote: remote, MinSalary: minSalary, MaxSalary: maxSalary})
+}
+
+// CreateJob creates a job with content as its first revision
+func (repository *PostgresJobRepository) CreateJob(employerPublicID string, content RevisionContent) (*Job, error) {
+
+	if content.Title == "" {
+		return nil, domain.ErrMissingValue
 	}
 
 	var job Job
 	var slug string
 
-	slug = strings.ReplaceAll(jobTitle, " ", "-")
+	slug = strings.ReplaceAll(content.Title, " ", "-")
 	slug = strings.ToLower(slug)
 
 	stmt, err := repository.Database.Prepare(`
-		INSERT INTO 
-		jobs(title, jobtype, category, description,visibledate, remote, employerid, slug, minsalary, maxsalary, payperiod) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9 (SELECT id FROM employers WHERE publicid=$10), $11) 
-		RETURNING publicid;`)
+		WITH created AS (
+			INSERT INTO 
+			jobs(title, jobtype, category, description,visibledate, remote, employerid, slug, minsalary, maxsalary, payperiod, liveat, expiresat,
+				location, zipcode, latitude, longitude, city, state, county, country) 
+			VALUES ($1, $2, $3, $4, $5, $6, (SELECT id FROM employers WHERE publicid=$7), $8, $9, $10, $11, $12, $13, $15, $16, $17, $18, $19, $20, $21, $22) 
+			RETURNING id, publicid, revision, title, jobtype, category, description, visibledate, remote, minsalary, maxsalary, payperiod,
+				location, zipcode, latitude, longitude, city, state, county, country
+		), revision AS (
+			INSERT INTO jobrevisions(jobid, revision, title, jobtype, category, description, visibledate, remote, minsalary, maxsalary, payperiod, editorpublicid,
+				location, zipcode, latitude, longitude, city, state, county, country)
+			SELECT id, revision, title, jobtype, category, description, NULLIF($14::text, ''), remote, minsalary, maxsalary, payperiod, $7,
+				location, zipcode, latitude, longitude, city, state, county, country FROM created
+		)
+		SELECT publicid FROM created;`)
 
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	err = stmt.QueryRow(jobTitle, jobType, category, jobDescription, utils.NewNullString(visibleDate), remote, employerPublicID, slug, minSalary, maxSalary, payPeriod).Scan(&job.PublicID)
+	err = stmt.QueryRow(content.Title, content.JobType, content.Category, content.Description, utils.NewNullString(content.VisibleDate), content.Remote, employerPublicID, slug,
+		content.MinSalary, content.MaxSalary, content.PayPeriod, LiveAt(content.VisibleDate, time.Now()), ExpiresAt(content.VisibleDate, time.Now()), content.VisibleDate,
+		content.Location, content.ZipCode, content.Latitude, content.Longitude, content.City, content.State, content.County, content.Country).Scan(&job.PublicID)
 
 	if err != nil {
 		log.Println(err)
@@ -64,20 +316,22 @@ func (repository *JobRepository) EmployerCreateJob(employerPublicID, jobTitle, j
 
 }
 
-func (repository *JobRepository) GetJob(jobPublicID string) (*Job, error) {
+func (repository *PostgresJobRepository) GetJob(jobPublicID string) (*Job, error) {
 
 	if jobPublicID == "" {
-		return nil, errors.New("missing required value")
+		return nil, domain.ErrMissingValue
 	}
 	var job Job
 	var visibleDate, payPeriod sql.NullString
 	var minSalary, maxSalary sql.NullInt64
+	var expiresAt sql.NullTime
 
 	stmt, err := repository.Database.Prepare(`
-		SELECT jobs.title, jobs.jobtype, jobs.category, jobs.description, jobs.visibledate, jobs.remote, jobs.minsalary, jobs.maxsalary, jobs.payperiod, employers.publicid
+		SELECT jobs.title, jobs.jobtype, jobs.category, jobs.description, jobs.visibledate, jobs.remote, jobs.minsalary, jobs.maxsalary, jobs.payperiod, employers.publicid,
+			jobs.expiresat, jobs.expiredat IS NOT NULL, jobs.revision, ` + locationColumns + `
 		FROM jobs
 		JOIN employers ON employers.id=jobs.employerid
-		WHERE jobs.publicid=$1;`,
+		WHERE jobs.publicid=$1 AND jobs.deletedat IS NULL;`,
 	)
 
 	if err != nil {
@@ -85,11 +339,12 @@ func (repository *JobRepository) GetJob(jobPublicID string) (*Job, error) {
 		return nil, err
 	}
 
-	err = stmt.QueryRow(jobPublicID).Scan(&job.Title, &job.JobType, &job.Category, &job.Description, &visibleDate, &job.Remote, &minSalary, &maxSalary, &payPeriod, &job.EmployerPublicID)
+	err = stmt.QueryRow(jobPublicID).Scan(&job.Title, &job.JobType, &job.Category, &job.Description, &visibleDate, &job.Remote, &minSalary, &maxSalary, &payPeriod, &job.EmployerPublicID, &expiresAt, &job.Expired, &job.Revision,
+		&job.Location, &job.ZipCode, &job.Latitude, &job.Longitude, &job.City, &job.State, &job.County, &job.Country)
 
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return nil, domain.NotFoundOr(err, ErrNotFound)
 	}
 
 	job.PublicID = jobPublicID
@@ -110,30 +365,35 @@ func (repository *JobRepository) GetJob(jobPublicID string) (*Job, error) {
 		job.MaxSalary = maxSalary.Int64
 	}
 
+	if expiresAt.Valid {
+		job.ExpiresAt = expiresAt.Time.Format(time.RFC3339)
+	}
+
 	return &job, nil
 }
 
-func (repository *JobRepository) GetEmployerJobs(employerPublicID string) ([]*Job, error) {
+func (repository *PostgresJobRepository) GetEmployerJobs(employerPublicID string, includeDeleted bool) ([]*Job, error) {
 
 	if employerPublicID == "" {
-		return nil, errors.New("missing required value")
+		return nil, domain.ErrMissingValue
 	}
 
 	var jobs []*Job
 
 	stmt, err := repository.Database.Prepare(`
 			SELECT jobs.title, jobs.jobtype, jobs.category, jobs.description, 
-				jobs.visibledate, jobs.remote, jobs.minsalary, jobs.maxsalary, jobs.payperiod, jobs.publicid
+				jobs.visibledate, jobs.remote, jobs.minsalary, jobs.maxsalary, jobs.payperiod, jobs.publicid,
+				jobs.expiresat, jobs.expiredat IS NOT NULL, jobs.revision, jobs.deletedat, ` + locationColumns + `
 			FROM jobs
 			JOIN employers ON employers.id=jobs.employerid
-			WHERE jobs.employerid=(SELECT id FROM employers WHERE publicid=$1);`)
+			WHERE jobs.employerid=(SELECT id FROM employers WHERE publicid=$1) AND ($2 OR jobs.deletedat IS NULL);`)
 
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	rows, err := stmt.Query(employerPublicID)
+	rows, err := stmt.Query(employerPublicID, includeDeleted)
 
 	if err != nil {
 		log.Println(err)
@@ -145,8 +405,10 @@ func (repository *JobRepository) GetEmployerJobs(employerPublicID string) ([]*Jo
 		job := &Job{}
 		var visibleDate, payPeriod sql.NullString
 		var minSalary, maxSalary sql.NullInt64
+		var expiresAt, deletedAt sql.NullTime
 
-		err := rows.Scan(&job.Title, &job.JobType, &job.Category, &job.Description, &visibleDate, &job.Remote, &minSalary, &maxSalary, &payPeriod, &job.PublicID)
+		err := rows.Scan(&job.Title, &job.JobType, &job.Category, &job.Description, &visibleDate, &job.Remote, &minSalary, &maxSalary, &payPeriod, &job.PublicID, &expiresAt, &job.Expired, &job.Revision, &deletedAt,
+			&job.Location, &job.ZipCode, &job.Latitude, &job.Longitude, &job.City, &job.State, &job.County, &job.Country)
 
 		if err != nil {
 			log.Println(err)
@@ -170,45 +432,120 @@ func (repository *JobRepository) GetEmployerJobs(employerPublicID string) ([]*Jo
 			job.MaxSalary = maxSalary.Int64
 		}
 
+		if expiresAt.Valid {
+			job.ExpiresAt = expiresAt.Time.Format(time.RFC3339)
+		}
+
+		if deletedAt.Valid {
+			job.DeletedAt = deletedAt.Time.Format(time.RFC3339)
+		}
+
 		jobs = append(jobs, job)
 	}
 
 	return jobs, nil
 }
 
-func (repository *JobRepository) DeleteJob(employerPublicID, jobPublicID string) (*Job, error) {
+func (repository *PostgresJobRepository) DeleteJob(employerPublicID, jobPublicID string) (*Job, error) {
 
 	if employerPublicID == "" || jobPublicID == "" {
-		return nil, errors.New("missing required value")
+		return nil, domain.ErrMissingValue
 	}
 
-	var job Job
+	job := Job{EmployerPublicID: employerPublicID}
+	var deletedAt time.Time
 
-	stmt, err := repository.Database.Prepare(`DELETE FROM jobs WHERE publicid=$1 AND employerid=(SELECT id FROM employers WHERE publicid=$2) RETURNING title, jobtype, category, description;`)
+	stmt, err := repository.Database.Prepare(`
+		UPDATE jobs SET deletedat=now()
+		WHERE publicid=$1 AND employerid=(SELECT id FROM employers WHERE publicid=$2) AND deletedat IS NULL
+		RETURNING publicid, title, jobtype, category, description, deletedat;`)
 
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	err = stmt.QueryRow(jobPublicID, employerPublicID).Scan(&job.Title, &job.JobType, &job.Category, &job.Description)
+	err = stmt.QueryRow(jobPublicID, employerPublicID).Scan(&job.PublicID, &job.Title, &job.JobType, &job.Category, &job.Description, &deletedAt)
 
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return nil, domain.NotFoundOr(err, ErrNotFound)
 	}
 
+	job.DeletedAt = deletedAt.Format(time.RFC3339)
+
 	return &job, nil
 }
 
-func (repository *JobRepository) EditJob(employerPublicID, jobPublicID, jobTitle, jobType, category, jobDescription, visibleDate, payPeriod string, remote bool, minSalary, maxSalary int64) (*Job, error) {
+// RestoreJob undeletes a job the employer deleted after deletedSince
+func (repository *PostgresJobRepository) RestoreJob(employerPublicID, jobPublicID string, deletedSince time.Time) (*Job, error) {
 
 	if employerPublicID == "" || jobPublicID == "" {
-		return nil, errors.New("missing required value")
+		return nil, domain.ErrMissingValue
 	}
 
-	var slug string
-	// var postEndDatetime time.Time
+	result, err := repository.Database.Exec(`
+		UPDATE jobs SET deletedat=NULL
+		WHERE publicid=$1 AND employerid=(SELECT id FROM employers WHERE publicid=$2) AND deletedat > $3;`, jobPublicID, employerPublicID, deletedSince)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	restored, err := result.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if restored == 0 {
+		return nil, ErrNotFound
+	}
+
+	return repository.GetJob(jobPublicID)
+}
+
+// PurgeDeletedJobs permanently removes jobs deleted before deletedBefore
+func (repository *PostgresJobRepository) PurgeDeletedJobs(deletedBefore time.Time) (int64, error) {
+
+	result, err := repository.Database.Exec(`DELETE FROM jobs WHERE deletedat < $1;`, deletedBefore)
+
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (repository *PostgresJobRepository) EditJob(employerPublicID, jobPublicID, jobTitle, jobType, category, jobDescription, visibleDate, payPeriod string, remote bool, minSalary, maxSalary int64, revision int) (*Job, error) {
+
+	if employerPublicID == "" || jobPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	job, err := repository.GetJob(jobPublicID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	content := job.Content()
+	content.Merge(RevisionContent{Title: jobTitle, JobType: jobType, Category: category, Description: jobDescription, VisibleDate: visibleDate,
+		PayPeriod: payPeriod, MinSalary: minSalary, MaxSalary: maxSalary})
+	content.Remote = remote
+
+	return repository.ReplaceJob(employerPublicID, jobPublicID, content, revision)
+}
+
+// ReplaceJob saves every field of content as a new revision, so empty fields
+// are cleared
+func (repository *PostgresJobRepository) ReplaceJob(employerPublicID, jobPublicID string, content RevisionContent, revision int) (*Job, error) {
+
+	if employerPublicID == "" || jobPublicID == "" || content.Title == "" {
+		return nil, domain.ErrMissingValue
+	}
 
 	job, err := repository.GetJob(jobPublicID)
 
@@ -216,48 +553,241 @@ func (repository *JobRepository) EditJob(employerPublicID, jobPublicID, jobTitle
 		return nil, err
 	}
 
-	job.Remote = remote
+	if job.EmployerPublicID != employerPublicID {
+		return nil, ErrNotFound
+	}
+
+	if revision != 0 && job.Revision != revision {
+		return nil, ErrStale
+	}
+
+	var liveAt sql.NullTime
 
-	if jobTitle != "" {
-		job.Title = jobTitle
-		slug = strings.ReplaceAll(jobTitle, " ", "-")
-		slug = strings.ToLower(slug)
+	if content.VisibleDate != "" && content.VisibleDate != job.VisibleDate {
+		job.ExpiresAt = ExpiresAt(content.VisibleDate, time.Now()).Format(time.RFC3339)
+		liveAt = sql.NullTime{Time: LiveAt(content.VisibleDate, time.Now()), Valid: true}
+	}
+
+	job.ApplyContent(content)
+
+	err = repository.saveJob(employerPublicID, job, liveAt, revision)
+
+	// another edit won the race since the job was read
+	if err == sql.ErrNoRows && revision != 0 {
+		return nil, ErrStale
+	}
+
+	if err != nil {
+		return nil, domain.NotFoundOr(err, ErrNotFound)
+	}
+
+	return job, nil
+}
+
+// saveJob writes job's content and records it as a new revision by editor,
+// updating job.Revision. Unless revision is 0 the job must still be at that
+// revision. It returns sql.ErrNoRows when the job belongs to another
+// employer, was deleted or has moved on from revision.
+func (repository *PostgresJobRepository) saveJob(editorPublicID string, job *Job, liveAt sql.NullTime, revision int) error {
+
+	slug := strings.ToLower(strings.ReplaceAll(job.Title, " ", "-"))
+
+	err := repository.Database.QueryRow(`
+		WITH updated AS (
+			UPDATE jobs SET title=$1, jobtype=$2, category=$3, description=$4, visibledate=$5, slug=$6, remote=$7 , minsalary=$8, maxsalary=$9, payperiod=$10,
+				location=$17, zipcode=$18, latitude=$19, longitude=$20, city=$21, state=$22, county=$23, country=$24,
+				expiresat=COALESCE($13, expiresat), liveat=COALESCE($14, liveat), revision=revision+1
+			WHERE publicid=$11 AND employerid=(SELECT id FROM employers WHERE publicid=$12) AND deletedat IS NULL AND ($16 = 0 OR revision=$16)
+			RETURNING id, revision, title, jobtype, category, description, visibledate, remote, minsalary, maxsalary, payperiod,
+				location, zipcode, latitude, longitude, city, state, county, country
+		)
+		INSERT INTO jobrevisions(jobid, revision, title, jobtype, category, description, visibledate, remote, minsalary, maxsalary, payperiod, editorpublicid,
+			location, zipcode, latitude, longitude, city, state, county, country)
+		SELECT id, revision, title, jobtype, category, description, NULLIF($15::text, ''), remote, minsalary, maxsalary, payperiod, $12,
+			location, zipcode, latitude, longitude, city, state, county, country FROM updated
+		RETURNING revision;`,
+		job.Title, job.JobType, job.Category, job.Description, utils.NewNullString(job.VisibleDate), slug, job.Remote, job.MinSalary, job.MaxSalary, job.PayPeriod,
+		job.PublicID, editorPublicID, utils.NewNullString(job.ExpiresAt), liveAt, job.VisibleDate, revision,
+		job.Location, job.ZipCode, job.Latitude, job.Longitude, job.City, job.State, job.County, job.Country).Scan(&job.Revision)
+
+	if err != nil && err != sql.ErrNoRows {
+		log.Println(err)
 	}
 
-	if jobType != "" {
-		job.JobType = jobType
+	return err
+}
+
+// GetJobRevisions returns every revision of the employer's job, newest first
+func (repository *PostgresJobRepository) GetJobRevisions(employerPublicID, jobPublicID string) ([]*Revision, error) {
+
+	if employerPublicID == "" || jobPublicID == "" {
+		return nil, domain.ErrMissingValue
 	}
 
-	if category != "" {
-		job.Category = category
+	rows, err := repository.Database.Query(`
+		SELECT `+revisionColumns+`
+		FROM jobrevisions
+		JOIN jobs ON jobs.id=jobrevisions.jobid
+		JOIN employers ON employers.id=jobs.employerid
+		WHERE jobs.publicid=$1 AND employers.publicid=$2 AND jobs.deletedat IS NULL
+		ORDER BY jobrevisions.revision DESC;`, jobPublicID, employerPublicID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
 	}
 
-	if jobDescription != "" {
-		job.Description = jobDescription
+	defer rows.Close()
+
+	var revisions []*Revision
+
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
 	}
 
-	if visibleDate != "" {
-		job.VisibleDate = visibleDate
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	if minSalary != 0 {
-		job.MinSalary = minSalary
+	// every job has at least the revision written when it was created
+	if len(revisions) == 0 {
+		return nil, ErrNotFound
 	}
 
-	if maxSalary != 0 {
-		job.MaxSalary = maxSalary
+	return revisions, nil
+}
+
+// GetJobRevision returns one revision of the employer's job
+func (repository *PostgresJobRepository) GetJobRevision(employerPublicID, jobPublicID string, number int) (*Revision, error) {
+
+	if employerPublicID == "" || jobPublicID == "" {
+		return nil, domain.ErrMissingValue
 	}
 
-	stmt, err := repository.Database.Prepare(`UPDATE jobs SET title=$1, jobtype=$2, category=$3, description=$4, visibledate=$5, slug=$6, remote=$7 , minsalary=$8, maxsalary=$9, payperiod=$10 WHERE publicid=$11 AND employerid=(SELECT id FROM employers WHERE publicid=$12);`)
+	revision, err := scanRevision(repository.Database.QueryRow(`
+		SELECT `+revisionColumns+`
+		FROM jobrevisions
+		JOIN jobs ON jobs.id=jobrevisions.jobid
+		JOIN employers ON employers.id=jobs.employerid
+		WHERE jobs.publicid=$1 AND employers.publicid=$2 AND jobs.deletedat IS NULL AND jobrevisions.revision=$3;`, jobPublicID, employerPublicID, number))
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, ErrRevisionNotFound)
+	}
+
+	return revision, nil
+}
+
+// RollbackJob restores the content of an earlier revision. The rollback is
+// saved as a new revision, so history is never rewritten.
+func (repository *PostgresJobRepository) RollbackJob(employerPublicID, jobPublicID string, number int) (*Job, error) {
+
+	revision, err := repository.GetJobRevision(employerPublicID, jobPublicID, number)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.ReplaceJob(employerPublicID, jobPublicID, revision.Content, 0)
+}
+
+const revisionColumns = `jobrevisions.revision, jobrevisions.editorpublicid, jobrevisions.createdat,
+	COALESCE(jobrevisions.title, ''), COALESCE(jobrevisions.jobtype, ''), COALESCE(jobrevisions.category, ''),
+	COALESCE(jobrevisions.description, ''), COALESCE(jobrevisions.visibledate, ''), COALESCE(jobrevisions.remote, false),
+	COALESCE(jobrevisions.minsalary, 0), COALESCE(jobrevisions.maxsalary, 0), COALESCE(jobrevisions.payperiod, ''),
+	COALESCE(jobrevisions.location, ''), COALESCE(jobrevisions.zipcode, ''), COALESCE(jobrevisions.latitude, 0), COALESCE(jobrevisions.longitude, 0),
+	COALESCE(jobrevisions.city, ''), COALESCE(jobrevisions.state, ''), COALESCE(jobrevisions.county, ''), COALESCE(jobrevisions.country, '')`
+
+// locationColumns are the location of a job, in the order of its fields
+const locationColumns = `jobs.location, jobs.zipcode, jobs.latitude, jobs.longitude, jobs.city, jobs.state, jobs.county, jobs.country`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRevision(row scanner) (*Revision, error) {
+
+	var revision Revision
+	content := &revision.Content
+
+	err := row.Scan(&revision.Number, &revision.EditorPublicID, &revision.CreatedAt,
+		&content.Title, &content.JobType, &content.Category, &content.Description, &content.VisibleDate,
+		&content.Remote, &content.MinSalary, &content.MaxSalary, &content.PayPeriod,
+		&content.Location, &content.ZipCode, &content.Latitude, &content.Longitude, &content.City, &content.State, &content.County, &content.Country)
 
 	if err != nil {
 		return nil, err
 	}
-	_, err = stmt.Exec(job.Title, job.JobType, job.Category, job.Description, job.VisibleDate, slug, job.Remote, job.MinSalary, job.MaxSalary, job.PayPeriod, job.PublicID, employerPublicID)
+
+	return &revision, nil
+}
+
+// GoLive marks every job whose visible date has passed by now as live and
+// returns them. A job is only returned by the call that made it live.
+func (repository *PostgresJobRepository) GoLive(now time.Time) ([]*Job, error) {
+
+	rows, err := repository.Database.Query(`
+		UPDATE jobs SET wentliveat=$1
+		FROM employers
+		WHERE employers.id=jobs.employerid AND jobs.wentliveat IS NULL AND jobs.expiredat IS NULL AND jobs.deletedat IS NULL AND jobs.liveat <= $1
+		RETURNING jobs.publicid, jobs.title, employers.publicid, jobs.expiresat;`, now)
 
 	if err != nil {
+		log.Println(err)
 		return nil, err
 	}
 
-	return job, nil
+	return scanStateChanges(rows, false)
+}
+
+// ExpireJobs marks every job whose listing period ended by now as expired and
+// returns them. A job is only returned by the call that expired it.
+func (repository *PostgresJobRepository) ExpireJobs(now time.Time) ([]*Job, error) {
+
+	rows, err := repository.Database.Query(`
+		UPDATE jobs SET expiredat=$1
+		FROM employers
+		WHERE employers.id=jobs.employerid AND jobs.expiredat IS NULL AND jobs.deletedat IS NULL AND jobs.expiresat <= $1
+		RETURNING jobs.publicid, jobs.title, employers.publicid, jobs.expiresat;`, now)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return scanStateChanges(rows, true)
+}
+
+// scanStateChanges reads the jobs returned by GoLive and ExpireJobs
+func scanStateChanges(rows *sql.Rows, expired bool) ([]*Job, error) {
+
+	defer rows.Close()
+
+	var changed []*Job
+
+	for rows.Next() {
+		job := &Job{Expired: expired}
+		var expiresAt sql.NullTime
+
+		if err := rows.Scan(&job.PublicID, &job.Title, &job.EmployerPublicID, &expiresAt); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		if expiresAt.Valid {
+			job.ExpiresAt = expiresAt.Time.Format(time.RFC3339)
+		}
+
+		changed = append(changed, job)
+	}
+
+	return changed, rows.Err()
 }
@@ -19,7 +19,7 @@ func init() {
 func Test_EmployerRepository_EmployerCreateJob_IncorrectData(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
 	Employer := &testhelper.TestEmployer{
 		FirstName: "First",
@@ -59,7 +59,7 @@ func Test_EmployerRepository_EmployerCreateJob_IncorrectData(t *testing.T) {
 func Test_EmployerRepository_EmployerCreateJob_CorrectData(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
 	Employer := testhelper.Helper_RandomEmployer(t)
 
@@ -85,7 +85,7 @@ func Test_EmployerRepository_GetJob_IncorrectData(t *testing.T) {
 
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
 	job, err := repository.GetJob("")
 
@@ -97,7 +97,7 @@ func Test_EmployerRepository_GetJob_CorrectData(t *testing.T) {
 
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
 	employer := testhelper.Helper_RandomEmployer(t)
 
@@ -114,8 +114,8 @@ func Test_EmployerRepository_GetJob_CorrectData(t *testing.T) {
 func Test_EmployerRepository_GetEmployerJobs_IncorrectData(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
-	jobs, err := repository.GetEmployerJobs("")
+	repository := jobs.NewJobRepository(testhelper.DB)
+	jobs, err := repository.GetEmployerJobs("", false)
 
 	assert.Nil(jobs)
 	assert.NotNil(err)
@@ -124,14 +124,14 @@ func Test_EmployerRepository_GetEmployerJobs_IncorrectData(t *testing.T) {
 func Test_EmployerRepository_GetEmployerJobs_CorrectData(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
 	employer := testhelper.Helper_RandomEmployer(t)
 	testhelper.Helper_RandomJob(employer, t)
 	testhelper.Helper_RandomJob(employer, t)
 	testhelper.Helper_RandomJob(employer, t)
 
-	jobs, err := repository.GetEmployerJobs(employer.PublicID)
+	jobs, err := repository.GetEmployerJobs(employer.PublicID, false)
 
 	assert.Equal(len(jobs), 3)
 	assert.Nil(err)
@@ -140,7 +140,7 @@ func Test_EmployerRepository_GetEmployerJobs_CorrectData(t *testing.T) {
 func Test_EmployerRepository_DeleteJob_IncorrectData_MissingJobPublicID(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
 	employer := testhelper.Helper_RandomEmployer(t)
 
@@ -155,7 +155,7 @@ func Test_EmployerRepository_DeleteJob_IncorrectData_MissingJobPublicID(t *testi
 func Test_EmployerRepository_DeleteJob_IncorrectData_MissingEmployerPublicID(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
 	employer := testhelper.Helper_RandomEmployer(t)
 
@@ -170,7 +170,7 @@ func Test_EmployerRepository_DeleteJob_IncorrectData_MissingEmployerPublicID(t *
 func Test_EmployerRepository_DeleteJob_Correct(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
 	employer := testhelper.Helper_RandomEmployer(t)
 
@@ -185,11 +185,11 @@ func Test_EmployerRepository_DeleteJob_Correct(t *testing.T) {
 func Test_EmployerRepository_EditJob_MissingJobPublicID(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
 	employer := testhelper.Helper_RandomEmployer(t)
 
-	job, err := repository.EditJob(employer.PublicID, "", "", "", "", "", "", "", true, 0, 0)
+	job, err := repository.EditJob(employer.PublicID, "", "", "", "", "", "", "", true, 0, 0, 0)
 
 	assert.Nil(job)
 	assert.NotNil(err)
@@ -199,9 +199,9 @@ func Test_EmployerRepository_EditJob_MissingJobPublicID(t *testing.T) {
 func Test_EmployerRepository_EditJob_MissingEmployerPublicID(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
-	job, err := repository.EditJob("", "", "", "", "", "", "", "", true, 0, 0)
+	job, err := repository.EditJob("", "", "", "", "", "", "", "", true, 0, 0, 0)
 
 	assert.Nil(job)
 	assert.NotNil(err)
@@ -211,13 +211,13 @@ func Test_EmployerRepository_EditJob_MissingEmployerPublicID(t *testing.T) {
 func Test_EmployerRepository_EditJob_Correct(t *testing.T) {
 	assert := assert.New(t)
 
-	repository := jobs.NewJobRegistry().GetJobRepository()
+	repository := jobs.NewJobRepository(testhelper.DB)
 
 	employer := testhelper.Helper_RandomEmployer(t)
 
 	job := testhelper.Helper_RandomJob(employer, t)
 
-	result, err := repository.EditJob(employer.PublicID, job.PublicID, "A Job", "full-time", "full-stack", "this is a job", "2021-09-04", "yearly", true, 1000, 10000)
+	result, err := repository.EditJob(employer.PublicID, job.PublicID, "A Job", "full-time", "full-stack", "this is a job", "2021-09-04", "yearly", true, 1000, 10000, 0)
 
 	assert.NotNil(result)
 	assert.Equal(result.Title, "A Job")
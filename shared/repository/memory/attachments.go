@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/attachments"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+)
+
+// AttachmentRepository is the in-memory attachments.AttachmentRepository
+type AttachmentRepository struct {
+	store *Store
+}
+
+var _ attachments.AttachmentRepository = (*AttachmentRepository)(nil)
+
+type attachmentRow struct {
+	jobPublicID string
+	attachment  jobs.Attachment
+}
+
+func (repository *AttachmentRepository) CreateAttachment(employerPublicID, jobPublicID, fileName, contentType, url string, size int64) (*jobs.Attachment, error) {
+
+	if employerPublicID == "" || jobPublicID == "" || contentType == "" || url == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	job, ok := repository.store.job(jobPublicID)
+
+	if !ok || job.EmployerPublicID != employerPublicID {
+		return nil, jobs.ErrNotFound
+	}
+
+	for _, row := range repository.store.attachments {
+		if row.jobPublicID == jobPublicID && row.attachment.URL == url {
+			return nil, attachments.ErrExists
+		}
+	}
+
+	row := &attachmentRow{
+		jobPublicID: jobPublicID,
+		attachment: jobs.Attachment{
+			PublicID:    newPublicID(),
+			FileName:    fileName,
+			ContentType: contentType,
+			Size:        size,
+			URL:         url,
+			CreatedAt:   time.Now(),
+		},
+	}
+
+	repository.store.attachments = append(repository.store.attachments, row)
+
+	result := row.attachment
+	return &result, nil
+}
+
+func (repository *AttachmentRepository) DeleteAttachment(employerPublicID, jobPublicID, attachmentPublicID string) (*jobs.Attachment, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	job, ok := repository.store.job(jobPublicID)
+
+	if !ok || job.EmployerPublicID != employerPublicID {
+		return nil, attachments.ErrNotFound
+	}
+
+	for i, row := range repository.store.attachments {
+		if row.jobPublicID == jobPublicID && row.attachment.PublicID == attachmentPublicID {
+			repository.store.attachments = append(repository.store.attachments[:i:i], repository.store.attachments[i+1:]...)
+
+			result := row.attachment
+			return &result, nil
+		}
+	}
+
+	return nil, attachments.ErrNotFound
+}
+
+func (repository *AttachmentRepository) GetJobAttachments(jobPublicID string) ([]*jobs.Attachment, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var result []*jobs.Attachment
+
+	for _, row := range repository.store.attachments {
+		if row.jobPublicID == jobPublicID {
+			attachment := row.attachment
+			result = append(result, &attachment)
+		}
+	}
+
+	return result, nil
+}
+
+func (repository *AttachmentRepository) GetCompanyAttachmentSize(employerPublicID string) (int64, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	owner, ok := repository.store.employers[employerPublicID]
+
+	if !ok {
+		return 0, nil
+	}
+
+	var size int64
+
+	for _, row := range repository.store.attachments {
+		job, ok := repository.store.jobs[row.jobPublicID]
+
+		if !ok {
+			continue
+		}
+
+		employer, ok := repository.store.employers[job.EmployerPublicID]
+
+		if job.EmployerPublicID == employerPublicID || ok && owner.companyPublicID != "" && employer.companyPublicID == owner.companyPublicID {
+			size += row.attachment.Size
+		}
+	}
+
+	return size, nil
+}
+
+func (repository *AttachmentRepository) GetPurgeableAttachments(deletedBefore time.Time) ([]*jobs.Attachment, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var result []*jobs.Attachment
+
+	for _, row := range repository.store.attachments {
+		if deletedAt, deleted := repository.store.jobDeletedAt[row.jobPublicID]; deleted && deletedAt.Before(deletedBefore) {
+			attachment := row.attachment
+			result = append(result, &attachment)
+		}
+	}
+
+	return result, nil
+}
+
+// removeAttachments drops the attachments of a purged job. It must be called
+// with mu held.
+func (store *Store) removeAttachments(jobPublicID string) {
+
+	var kept []*attachmentRow
+
+	for _, row := range store.attachments {
+		if row.jobPublicID != jobPublicID {
+			kept = append(kept, row)
+		}
+	}
+
+	store.attachments = kept
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"encoding/json"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+)
+
+// AuditRepository is the in-memory audit.AuditRepository
+type AuditRepository struct {
+	store *Store
+}
+
+var _ audit.AuditRepository = (*AuditRepository)(nil)
+
+func (repository *AuditRepository) Record(entry *audit.Entry) (*audit.Entry, error) {
+
+	if entry.ActorPublicID == "" || entry.Action == "" || entry.TargetType == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	recorded := *entry
+	recorded.PublicID = newPublicID()
+	recorded.CreatedAt = time.Now()
+
+	if recorded.CompanyPublicID == "" {
+		recorded.CompanyPublicID, _ = repository.store.companyOf(recorded.ActorPublicID)
+	}
+
+	if len(recorded.Changes) == 0 {
+		recorded.Changes = json.RawMessage(`{}`)
+	}
+
+	repository.store.auditLog = append(repository.store.auditLog, &recorded)
+
+	result := recorded
+	return &result, nil
+}
+
+func (repository *AuditRepository) GetEntries(employerPublicID string, filter *audit.Filter) ([]*audit.Entry, error) {
+
+	if employerPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	if filter == nil {
+		filter = &audit.Filter{}
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	companyPublicID, _ := repository.store.companyOf(employerPublicID)
+
+	var entries []*audit.Entry
+
+	// newest first; entries are appended in creation order
+	for i := len(repository.store.auditLog) - 1; i >= 0 && len(entries) < filter.MaxEntries(); i-- {
+		entry := repository.store.auditLog[i]
+
+		visible := entry.ActorPublicID == employerPublicID || companyPublicID != "" && entry.CompanyPublicID == companyPublicID
+
+		if visible && filter.Matches(entry) {
+			result := *entry
+			entries = append(entries, &result)
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+)
+
+// CompanyRepository is the in-memory companies.CompanyRepository
+type CompanyRepository struct {
+	store *Store
+}
+
+var _ companies.CompanyRepository = (*CompanyRepository)(nil)
+
+func (repository *CompanyRepository) GetOrCreateCompany(domain, name, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode string) (*companies.Company, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	for _, company := range repository.store.companies {
+		if company.Domain == domain {
+			result := *company
+			return &result, nil
+		}
+	}
+
+	company := &companies.Company{
+		Name:         name,
+		Domain:       domain,
+		Location:     location,
+		URL:          url,
+		Facebook:     facebook,
+		Twitter:      twitter,
+		Instagram:    instagram,
+		Description:  description,
+		Logo:         logo,
+		ExtraDetails: extradetails,
+		Zipcode:      zipcode,
+		PublicID:     newPublicID(),
+		Version:      1,
+	}
+
+	repository.store.companies[company.PublicID] = company
+
+	result := *company
+	return &result, nil
+}
@@ -0,0 +1,377 @@
+package memory
+
+import (
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/services/security/encryption"
+)
+
+// EmployerRepository is the in-memory accountmanagement.EmployerRepository
+type EmployerRepository struct {
+	store *Store
+}
+
+var _ accountmanagement.EmployerRepository = (*EmployerRepository)(nil)
+
+func (repository *EmployerRepository) CreateEmployer(firstName, lastName, email, password string) (*accountmanagement.Employer, error) {
+
+	if firstName == "" || lastName == "" || email == "" || password == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	for _, row := range repository.store.employers {
+		if row.employer.Email == email {
+			return nil, accountmanagement.ErrEmailTaken
+		}
+	}
+
+	row := &employerRow{
+		employer: accountmanagement.Employer{
+			FirstName:        firstName,
+			LastName:         lastName,
+			Email:            email,
+			RegistrationStep: accountmanagement.ChangePassword.String(),
+			PublicID:         newPublicID(),
+		},
+		password: password,
+	}
+
+	repository.store.employers[row.employer.PublicID] = row
+
+	return &accountmanagement.Employer{FirstName: firstName, LastName: lastName, Email: email, PublicID: row.employer.PublicID}, nil
+}
+
+func (repository *EmployerRepository) GetEmployer(userID string) (*accountmanagement.Employer, error) {
+
+	if userID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.employers[userID]
+
+	if !ok {
+		return nil, accountmanagement.ErrNotFound
+	}
+
+	employer := row.employer
+	return &employer, nil
+}
+
+func (repository *EmployerRepository) AuthenticateEmployerPassword(email, password string) (bool, string, string, error) {
+
+	if email == "" || password == "" {
+		return false, "", "", nil
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	for _, row := range repository.store.employers {
+		if row.employer.Email != email {
+			continue
+		}
+
+		if encryption.CompareHashes([]byte(row.password), []byte(password)) {
+			return true, row.employer.RegistrationStep, row.employer.PublicID, nil
+		}
+
+		return false, "", "", nil
+	}
+
+	return false, "", "", nil
+}
+
+func (repository *EmployerRepository) UpdateEmployerPassword(publicID, password, newPassword string) (bool, error) {
+
+	if publicID == "" || password == "" || newPassword == "" {
+		return false, nil
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.employers[publicID]
+
+	if !ok || !encryption.CompareHashes([]byte(row.password), []byte(password)) {
+		return false, nil
+	}
+
+	hashedNewPassword, err := encryption.HashPassword([]byte(newPassword))
+
+	if err != nil {
+		return false, err
+	}
+
+	row.password = string(hashedNewPassword)
+	row.advance(accountmanagement.ChangePassword)
+
+	return true, nil
+}
+
+func (repository *EmployerRepository) UpdateEmployerAccount(publicID, firstName, lastName, email, phoneNumber, mobileNumber, role, facebook, twitter, instagram string) (*accountmanagement.Employer, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.employers[publicID]
+
+	if !ok {
+		return nil, accountmanagement.ErrNotFound
+	}
+
+	account := row.employer.Account()
+	account.Merge(accountmanagement.Account{FirstName: firstName, LastName: lastName, Email: email, PhoneNumber: phoneNumber, MobileNumber: mobileNumber, Role: role, Facebook: facebook, Twitter: twitter, Instagram: instagram})
+
+	return row.replaceAccount(account), nil
+}
+
+func (repository *EmployerRepository) ReplaceEmployerAccount(publicID string, account accountmanagement.Account) (*accountmanagement.Employer, error) {
+
+	if publicID == "" || account.Email == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.employers[publicID]
+
+	if !ok {
+		return nil, accountmanagement.ErrNotFound
+	}
+
+	return row.replaceAccount(account), nil
+}
+
+func (repository *EmployerRepository) UpdateEmployerCompany(employerPublicID, companyName, location, url, facebook, twitter, instagram, description, logo, extradetails, zipcode string, longitude, latitude float64, version int) (*companies.Company, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, company, err := repository.store.employerCompany(employerPublicID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	profile := company.Profile()
+	profile.Merge(companies.Profile{Name: companyName, Location: location, Longitude: longitude, Latitude: latitude, URL: url, Facebook: facebook, Twitter: twitter,
+		Instagram: instagram, Description: description, Logo: logo, ExtraDetails: extradetails, Zipcode: zipcode})
+
+	return replaceCompany(row, company, profile, version)
+}
+
+func (repository *EmployerRepository) ReplaceEmployerCompany(employerPublicID string, profile companies.Profile, version int) (*companies.Company, error) {
+
+	if employerPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, company, err := repository.store.employerCompany(employerPublicID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return replaceCompany(row, company, profile, version)
+}
+
+func (repository *EmployerRepository) SetEmployerCompanyLogo(employerPublicID, logo string, variants map[string]string) (*companies.Company, error) {
+
+	if employerPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	_, company, err := repository.store.employerCompany(employerPublicID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	company.Logo = logo
+	company.LogoVariants = nil
+
+	if len(variants) > 0 {
+		company.LogoVariants = map[string]string{}
+
+		for name, url := range variants {
+			company.LogoVariants[name] = url
+		}
+	}
+
+	company.Version++
+
+	result := *company
+	return &result, nil
+}
+
+func (repository *EmployerRepository) UpdateEmployerPaymentMethod(employerPublicID, paymentMethod string) error {
+	return repository.advance(employerPublicID, accountmanagement.PaymentMethod)
+}
+
+func (repository *EmployerRepository) UpdateEmployerPaymentDetails(employerPublicID, paymentDetails string) error {
+	return repository.advance(employerPublicID, accountmanagement.PaymentDetails)
+}
+
+func (repository *EmployerRepository) SetEmployerCompany(employerPublicID, companyPublicID string) error {
+
+	if employerPublicID == "" || companyPublicID == "" {
+		return domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.employers[employerPublicID]
+
+	if !ok {
+		return accountmanagement.ErrNotFound
+	}
+
+	if _, ok := repository.store.companies[companyPublicID]; !ok {
+		return accountmanagement.ErrNotFound
+	}
+
+	row.companyPublicID = companyPublicID
+
+	return nil
+}
+
+func (repository *EmployerRepository) GetEmployerCompany(employerPublicID string) (*companies.Company, error) {
+
+	if employerPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	_, company, err := repository.store.employerCompany(employerPublicID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := *company
+	return &result, nil
+}
+
+func (repository *EmployerRepository) advance(employerPublicID string, from accountmanagement.RegistrationStep) error {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.employers[employerPublicID]
+
+	if !ok {
+		return accountmanagement.ErrNotFound
+	}
+
+	row.advance(from)
+
+	return nil
+}
+
+// advance moves the employer to the step after from, if from is the current step
+func (row *employerRow) advance(from accountmanagement.RegistrationStep) {
+	if row.employer.RegistrationStep == from.String() && from < accountmanagement.RegistrationComplete {
+		row.employer.RegistrationStep = (from + 1).String()
+	}
+}
+
+// replaceAccount must be called with mu held
+func (row *employerRow) replaceAccount(account accountmanagement.Account) *accountmanagement.Employer {
+
+	employer := &row.employer
+
+	employer.FirstName = account.FirstName
+	employer.LastName = account.LastName
+	employer.Email = account.Email
+	employer.PhoneNumber = account.PhoneNumber
+	employer.MobileNumber = account.MobileNumber
+	employer.Role = account.Role
+	employer.Facebook = account.Facebook
+	employer.Twitter = account.Twitter
+	employer.Instagram = account.Instagram
+
+	row.advance(accountmanagement.PersonalInformation)
+
+	return &accountmanagement.Employer{
+		FirstName:    employer.FirstName,
+		LastName:     employer.LastName,
+		Email:        employer.Email,
+		PhoneNumber:  employer.PhoneNumber,
+		MobileNumber: employer.MobileNumber,
+		Role:         employer.Role,
+		Facebook:     employer.Facebook,
+		Twitter:      employer.Twitter,
+		Instagram:    employer.Instagram,
+		PublicID:     employer.PublicID,
+	}
+}
+
+// replaceCompany must be called with mu held
+func replaceCompany(row *employerRow, company *companies.Company, profile companies.Profile, version int) (*companies.Company, error) {
+
+	if version != 0 && company.Version != version {
+		return nil, companies.ErrStale
+	}
+
+	company.Name = profile.Name
+	company.Location = profile.Location
+	company.Longitude = profile.Longitude
+	company.Latitude = profile.Latitude
+	company.URL = profile.URL
+	company.Facebook = profile.Facebook
+	company.Twitter = profile.Twitter
+	company.Instagram = profile.Instagram
+	company.Description = profile.Description
+	if company.Logo != profile.Logo {
+		company.LogoVariants = nil
+	}
+
+	company.Logo = profile.Logo
+	company.ExtraDetails = profile.ExtraDetails
+	company.Zipcode = profile.Zipcode
+	company.City = profile.City
+	company.State = profile.State
+	company.County = profile.County
+	company.Country = profile.Country
+	company.Version++
+
+	row.advance(accountmanagement.CompanyDetails)
+
+	result := *company
+	return &result, nil
+}
+
+// employerCompany must be called with mu held
+func (store *Store) employerCompany(employerPublicID string) (*employerRow, *companies.Company, error) {
+
+	row, ok := store.employers[employerPublicID]
+
+	if !ok {
+		return nil, nil, companies.ErrNotFound
+	}
+
+	company, ok := store.companies[row.companyPublicID]
+
+	if !ok {
+		return nil, nil, companies.ErrNotFound
+	}
+
+	return row, company, nil
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"encoding/json"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/geocodes"
+)
+
+// GeocodeRepository is the in-memory geocodes.GeocodeRepository
+type GeocodeRepository struct {
+	store *Store
+}
+
+var _ geocodes.GeocodeRepository = (*GeocodeRepository)(nil)
+
+func (repository *GeocodeRepository) GetGeocode(key string, now time.Time) (*geocodes.Geocode, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	geocode, ok := repository.store.geocodes[key]
+
+	if !ok || !geocode.ExpiresAt.After(now) {
+		return nil, geocodes.ErrNotFound
+	}
+
+	result := *geocode
+	result.Value = append(json.RawMessage(nil), geocode.Value...)
+
+	return &result, nil
+}
+
+func (repository *GeocodeRepository) PutGeocode(key string, value json.RawMessage, expiresAt time.Time) error {
+
+	if key == "" || len(value) == 0 {
+		return domain.ErrMissingValue
+	}
+
+	if !json.Valid(value) {
+		return domain.NewInvalid("invalid_json", "geocode value is not JSON")
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	repository.store.geocodes[key] = &geocodes.Geocode{
+		Key:       key,
+		Value:     append(json.RawMessage(nil), value...),
+		ExpiresAt: expiresAt,
+	}
+
+	return nil
+}
+
+func (repository *GeocodeRepository) PurgeExpiredGeocodes(now time.Time) (int64, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var purged int64
+
+	for key, geocode := range repository.store.geocodes {
+		if !geocode.ExpiresAt.After(now) {
+			delete(repository.store.geocodes, key)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"sort"
+
+	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
+)
+
+// JobPackageRepository is the in-memory jobpackages.JobPackageRepository
+type JobPackageRepository struct {
+	store *Store
+}
+
+var _ jobpackages.JobPackageRepository = (*JobPackageRepository)(nil)
+
+func (repository *JobPackageRepository) GetActiveJobPackages() ([]*jobpackages.JobPackage, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var packages []*jobpackages.JobPackage
+
+	for _, pack := range repository.store.jobPackages {
+		if pack.IsActive {
+			result := *pack
+			packages = append(packages, &result)
+		}
+	}
+
+	sort.Slice(packages, func(i, j int) bool { return packages[i].ID < packages[j].ID })
+
+	return packages, nil
+}
+
+func (repository *JobPackageRepository) GetJobPackage(typeID string) (*jobpackages.JobPackage, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	pack, ok := repository.store.jobPackages[typeID]
+
+	if !ok {
+		return nil, jobpackages.ErrNotFound
+	}
+
+	result := *pack
+	return &result, nil
+}
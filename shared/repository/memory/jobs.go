@@ -0,0 +1,370 @@
+package memory
+
+import (
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+)
+
+// JobRepository is the in-memory jobs.JobRepository
+type JobRepository struct {
+	store *Store
+}
+
+var _ jobs.JobRepository = (*JobRepository)(nil)
+
+func (repository *JobRepository) EmployerCreateJob(employerPublicID, jobTitle, jobType, category, jobDescription, visibleDate, payPeriod string, remote bool, minSalary, maxSalary int64) (*jobs.Job, error) {
+	return repository.CreateJob(employerPublicID, jobs.RevisionContent{Title: jobTitle, JobType: jobType, Category: category, Description: jobDescription,
+		VisibleDate: visibleDate, PayPeriod: payPeriod, Remote: remote, MinSalary: minSalary, MaxSalary: maxSalary})
+}
+
+func (repository *JobRepository) CreateJob(employerPublicID string, content jobs.RevisionContent) (*jobs.Job, error) {
+
+	if content.Title == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	if _, ok := repository.store.employers[employerPublicID]; !ok {
+		return nil, accountmanagement.ErrNotFound
+	}
+
+	row := &jobs.Job{
+		PublicID:         newPublicID(),
+		EmployerPublicID: employerPublicID,
+		ExpiresAt:        jobs.ExpiresAt(content.VisibleDate, time.Now()).Format(time.RFC3339),
+		Revision:         1,
+	}
+
+	row.ApplyContent(content)
+
+	repository.store.jobs[row.PublicID] = row
+	repository.store.jobOrder = append(repository.store.jobOrder, row.PublicID)
+	repository.store.jobLiveAt[row.PublicID] = jobs.LiveAt(content.VisibleDate, time.Now())
+	repository.store.addRevision(row, employerPublicID)
+
+	job := *row
+	return &job, nil
+}
+
+func (repository *JobRepository) GetJob(jobPublicID string) (*jobs.Job, error) {
+
+	if jobPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.job(jobPublicID)
+
+	if !ok {
+		return nil, jobs.ErrNotFound
+	}
+
+	job := *row
+	return &job, nil
+}
+
+func (repository *JobRepository) GetEmployerJobs(employerPublicID string, includeDeleted bool) ([]*jobs.Job, error) {
+
+	if employerPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var employerJobs []*jobs.Job
+
+	for _, publicID := range repository.store.jobOrder {
+		row, ok := repository.store.jobs[publicID]
+
+		if !ok || row.EmployerPublicID != employerPublicID {
+			continue
+		}
+
+		job := *row
+
+		if deletedAt, deleted := repository.store.jobDeletedAt[publicID]; deleted {
+			if !includeDeleted {
+				continue
+			}
+			job.DeletedAt = deletedAt.Format(time.RFC3339)
+		}
+
+		employerJobs = append(employerJobs, &job)
+	}
+
+	return employerJobs, nil
+}
+
+func (repository *JobRepository) DeleteJob(employerPublicID, jobPublicID string) (*jobs.Job, error) {
+
+	if employerPublicID == "" || jobPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.job(jobPublicID)
+
+	if !ok || row.EmployerPublicID != employerPublicID {
+		return nil, jobs.ErrNotFound
+	}
+
+	deletedAt := time.Now()
+	repository.store.jobDeletedAt[jobPublicID] = deletedAt
+
+	return &jobs.Job{PublicID: row.PublicID, EmployerPublicID: row.EmployerPublicID, Title: row.Title, JobType: row.JobType, Category: row.Category, Description: row.Description, DeletedAt: deletedAt.Format(time.RFC3339)}, nil
+}
+
+func (repository *JobRepository) RestoreJob(employerPublicID, jobPublicID string, deletedSince time.Time) (*jobs.Job, error) {
+
+	if employerPublicID == "" || jobPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, exists := repository.store.jobs[jobPublicID]
+	deletedAt, deleted := repository.store.jobDeletedAt[jobPublicID]
+
+	if !exists || !deleted || row.EmployerPublicID != employerPublicID || !deletedAt.After(deletedSince) {
+		return nil, jobs.ErrNotFound
+	}
+
+	delete(repository.store.jobDeletedAt, jobPublicID)
+
+	job := *row
+	return &job, nil
+}
+
+func (repository *JobRepository) PurgeDeletedJobs(deletedBefore time.Time) (int64, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var purged int64
+
+	for publicID, deletedAt := range repository.store.jobDeletedAt {
+		if deletedAt.Before(deletedBefore) {
+			delete(repository.store.jobs, publicID)
+			delete(repository.store.jobLiveAt, publicID)
+			delete(repository.store.jobsLive, publicID)
+			delete(repository.store.jobDeletedAt, publicID)
+			delete(repository.store.jobRevisions, publicID)
+			repository.store.removeAttachments(publicID)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (repository *JobRepository) EditJob(employerPublicID, jobPublicID, jobTitle, jobType, category, jobDescription, visibleDate, payPeriod string, remote bool, minSalary, maxSalary int64, revision int) (*jobs.Job, error) {
+
+	if employerPublicID == "" || jobPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.job(jobPublicID)
+
+	if !ok {
+		return nil, jobs.ErrNotFound
+	}
+
+	content := row.Content()
+	content.Merge(jobs.RevisionContent{Title: jobTitle, JobType: jobType, Category: category, Description: jobDescription, VisibleDate: visibleDate,
+		PayPeriod: payPeriod, MinSalary: minSalary, MaxSalary: maxSalary})
+	content.Remote = remote
+
+	return repository.store.replaceJob(employerPublicID, jobPublicID, content, revision)
+}
+
+func (repository *JobRepository) ReplaceJob(employerPublicID, jobPublicID string, content jobs.RevisionContent, revision int) (*jobs.Job, error) {
+
+	if employerPublicID == "" || jobPublicID == "" || content.Title == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	return repository.store.replaceJob(employerPublicID, jobPublicID, content, revision)
+}
+
+func (repository *JobRepository) GetJobRevisions(employerPublicID, jobPublicID string) ([]*jobs.Revision, error) {
+
+	if employerPublicID == "" || jobPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.job(jobPublicID)
+
+	if !ok || row.EmployerPublicID != employerPublicID {
+		return nil, jobs.ErrNotFound
+	}
+
+	stored := repository.store.jobRevisions[jobPublicID]
+	revisions := make([]*jobs.Revision, 0, len(stored))
+
+	for i := len(stored) - 1; i >= 0; i-- {
+		revision := *stored[i]
+		revisions = append(revisions, &revision)
+	}
+
+	return revisions, nil
+}
+
+func (repository *JobRepository) GetJobRevision(employerPublicID, jobPublicID string, number int) (*jobs.Revision, error) {
+
+	if employerPublicID == "" || jobPublicID == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	row, ok := repository.store.job(jobPublicID)
+
+	if !ok || row.EmployerPublicID != employerPublicID {
+		return nil, jobs.ErrRevisionNotFound
+	}
+
+	for _, stored := range repository.store.jobRevisions[jobPublicID] {
+		if stored.Number == number {
+			revision := *stored
+			return &revision, nil
+		}
+	}
+
+	return nil, jobs.ErrRevisionNotFound
+}
+
+func (repository *JobRepository) RollbackJob(employerPublicID, jobPublicID string, number int) (*jobs.Job, error) {
+
+	revision, err := repository.GetJobRevision(employerPublicID, jobPublicID, number)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.ReplaceJob(employerPublicID, jobPublicID, revision.Content, 0)
+}
+
+func (repository *JobRepository) GoLive(now time.Time) ([]*jobs.Job, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var live []*jobs.Job
+
+	for _, publicID := range repository.store.jobOrder {
+		row, ok := repository.store.job(publicID)
+
+		if !ok || row.Expired || repository.store.jobsLive[publicID] || repository.store.jobLiveAt[publicID].After(now) {
+			continue
+		}
+
+		repository.store.jobsLive[publicID] = true
+
+		live = append(live, &jobs.Job{PublicID: row.PublicID, Title: row.Title, EmployerPublicID: row.EmployerPublicID, ExpiresAt: row.ExpiresAt})
+	}
+
+	return live, nil
+}
+
+func (repository *JobRepository) ExpireJobs(now time.Time) ([]*jobs.Job, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var expired []*jobs.Job
+
+	for _, publicID := range repository.store.jobOrder {
+		row, ok := repository.store.job(publicID)
+
+		if !ok || row.Expired {
+			continue
+		}
+
+		expiresAt, err := time.Parse(time.RFC3339, row.ExpiresAt)
+
+		if err != nil || expiresAt.After(now) {
+			continue
+		}
+
+		row.Expired = true
+
+		expired = append(expired, &jobs.Job{PublicID: row.PublicID, Title: row.Title, EmployerPublicID: row.EmployerPublicID, ExpiresAt: row.ExpiresAt, Expired: true})
+	}
+
+	return expired, nil
+}
+
+// replaceJob must be called with mu held
+func (store *Store) replaceJob(employerPublicID, jobPublicID string, content jobs.RevisionContent, revision int) (*jobs.Job, error) {
+
+	row, ok := store.job(jobPublicID)
+
+	if !ok || row.EmployerPublicID != employerPublicID {
+		return nil, jobs.ErrNotFound
+	}
+
+	if revision != 0 && row.Revision != revision {
+		return nil, jobs.ErrStale
+	}
+
+	if content.VisibleDate != "" && content.VisibleDate != row.VisibleDate {
+		row.ExpiresAt = jobs.ExpiresAt(content.VisibleDate, time.Now()).Format(time.RFC3339)
+		store.jobLiveAt[jobPublicID] = jobs.LiveAt(content.VisibleDate, time.Now())
+	}
+
+	row.ApplyContent(content)
+	row.Revision++
+	store.addRevision(row, employerPublicID)
+
+	job := *row
+	return &job, nil
+}
+
+// addRevision records the current content of row as its revision row.Revision.
+// It must be called with mu held.
+func (store *Store) addRevision(row *jobs.Job, editorPublicID string) {
+	store.jobRevisions[row.PublicID] = append(store.jobRevisions[row.PublicID], &jobs.Revision{
+		Number:         row.Revision,
+		EditorPublicID: editorPublicID,
+		CreatedAt:      time.Now(),
+		Content:        row.Content(),
+	})
+}
+
+// job returns the row of a job that has not been deleted. It must be called
+// with mu held.
+func (store *Store) job(publicID string) (*jobs.Job, bool) {
+
+	row, ok := store.jobs[publicID]
+
+	if !ok {
+		return nil, false
+	}
+
+	if _, deleted := store.jobDeletedAt[publicID]; deleted {
+		return nil, false
+	}
+
+	return row, true
+}
@@ -0,0 +1,35 @@
+package memory_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
+	"autumnomous-jobs-employer-api/shared/repository/memory"
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+)
+
+func newRepositories(t *testing.T) *repositorytest.Repositories {
+
+	store := memory.NewStore()
+
+	return &repositorytest.Repositories{
+		Employers:   store.EmployerRepository(),
+		Companies:   store.CompanyRepository(),
+		Jobs:        store.JobRepository(),
+		JobPackages: store.JobPackageRepository(),
+		Queue:       store.QueueRepository(),
+		Webhooks:    store.WebhookRepository(),
+		Audit:       store.AuditRepository(),
+		Uploads:     store.UploadRepository(),
+		Attachments: store.AttachmentRepository(),
+		Geocodes:    store.GeocodeRepository(),
+		UnitOfWork:  store.UnitOfWork(),
+		AddJobPackage: func(t *testing.T, pack *jobpackages.JobPackage) *jobpackages.JobPackage {
+			return store.AddJobPackage(pack)
+		},
+	}
+}
+
+func Test_Memory_Repositories_Contract(t *testing.T) {
+	repositorytest.Run(t, newRepositories)
+}
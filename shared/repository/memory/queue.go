@@ -0,0 +1,288 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+)
+
+// QueueRepository is the in-memory queue.QueueRepository
+type QueueRepository struct {
+	store *Store
+}
+
+var _ queue.QueueRepository = (*QueueRepository)(nil)
+
+type taskRow struct {
+	task        queue.Task
+	lockedUntil time.Time
+	updatedAt   time.Time
+}
+
+func (repository *QueueRepository) Enqueue(kind string, payload interface{}) (*queue.Task, error) {
+	return repository.Schedule(kind, payload, time.Time{}, "")
+}
+
+func (repository *QueueRepository) Schedule(kind string, payload interface{}, runAt time.Time, uniqueKey string) (*queue.Task, error) {
+
+	if kind == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	body, err := json.Marshal(payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	now := time.Now()
+
+	if runAt.IsZero() {
+		runAt = now
+	}
+
+	if uniqueKey != "" {
+		for _, row := range repository.store.tasks {
+			if row.task.UniqueKey == uniqueKey && unfinished(row) {
+				return nil, queue.ErrDuplicate
+			}
+		}
+	}
+
+	repository.store.taskID++
+
+	row := &taskRow{
+		task: queue.Task{
+			ID:        repository.store.taskID,
+			Kind:      kind,
+			Payload:   body,
+			Status:    queue.Pending,
+			UniqueKey: uniqueKey,
+			RunAt:     runAt,
+			CreatedAt: now,
+		},
+		updatedAt: now,
+	}
+
+	repository.store.tasks = append(repository.store.tasks, row)
+
+	return row.copy(), nil
+}
+
+func (repository *QueueRepository) Claim(limit int, lease time.Duration) ([]*queue.Task, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	now := time.Now()
+
+	var due []*taskRow
+
+	for _, row := range repository.store.tasks {
+		pending := row.task.Status == queue.Pending && !row.task.RunAt.After(now)
+		abandoned := row.task.Status == queue.Running && row.lockedUntil.Before(now)
+
+		if pending || abandoned {
+			due = append(due, row)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].task.RunAt.Before(due[j].task.RunAt) })
+
+	var tasks []*queue.Task
+
+	for _, row := range due {
+		if len(tasks) == limit {
+			break
+		}
+
+		row.task.Status = queue.Running
+		row.task.Attempts++
+		row.lockedUntil = now.Add(lease)
+		row.updatedAt = now
+
+		tasks = append(tasks, row.copy())
+	}
+
+	return tasks, nil
+}
+
+func (repository *QueueRepository) Complete(id int64) error {
+	return repository.update(id, queue.Running, func(row *taskRow, now time.Time) {
+		row.task.Status = queue.Done
+		row.task.Payload = nil
+		row.task.FinishedAt = &now
+	})
+}
+
+func (repository *QueueRepository) Retry(id int64, lastError string, runAt time.Time) error {
+	return repository.update(id, queue.Running, func(row *taskRow, now time.Time) {
+		row.task.Status = queue.Pending
+		row.task.LastError = lastError
+		row.task.RunAt = runAt
+	})
+}
+
+func (repository *QueueRepository) Bury(id int64, lastError string) error {
+	return repository.update(id, queue.Running, func(row *taskRow, now time.Time) {
+		row.task.Status = queue.Dead
+		row.task.LastError = lastError
+		row.task.FinishedAt = &now
+	})
+}
+
+func (repository *QueueRepository) Requeue(id int64) error {
+	return repository.update(id, queue.Dead, func(row *taskRow, now time.Time) {
+		row.task.Status = queue.Pending
+		row.task.Attempts = 0
+		row.task.RunAt = now
+		row.task.FinishedAt = nil
+	})
+}
+
+func (repository *QueueRepository) GetTask(id int64) (*queue.Task, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	for _, row := range repository.store.tasks {
+		if row.task.ID == id {
+			return row.copy(), nil
+		}
+	}
+
+	return nil, queue.ErrNotFound
+}
+
+func (repository *QueueRepository) GetTasks(status string, limit int) ([]*queue.Task, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var rows []*taskRow
+
+	for _, row := range repository.store.tasks {
+		if row.task.Status == status {
+			rows = append(rows, row)
+		}
+	}
+
+	// most recently updated first, newest id breaking ties
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].updatedAt.Equal(rows[j].updatedAt) {
+			return rows[i].task.ID > rows[j].task.ID
+		}
+		return rows[i].updatedAt.After(rows[j].updatedAt)
+	})
+
+	var tasks []*queue.Task
+
+	for _, row := range rows {
+		if len(tasks) == limit {
+			break
+		}
+
+		tasks = append(tasks, row.copy())
+	}
+
+	return tasks, nil
+}
+
+func (repository *QueueRepository) GetStats() ([]*queue.Stats, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	byKey := map[[2]string]*queue.Stats{}
+	var stats []*queue.Stats
+
+	for _, row := range repository.store.tasks {
+		key := [2]string{row.task.Kind, row.task.Status}
+		entry, ok := byKey[key]
+
+		if !ok {
+			entry = &queue.Stats{Kind: row.task.Kind, Status: row.task.Status, Oldest: row.task.RunAt}
+			byKey[key] = entry
+			stats = append(stats, entry)
+		}
+
+		entry.Count++
+
+		if row.task.RunAt.Before(entry.Oldest) {
+			entry.Oldest = row.task.RunAt
+		}
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Kind == stats[j].Kind {
+			return stats[i].Status < stats[j].Status
+		}
+		return stats[i].Kind < stats[j].Kind
+	})
+
+	return stats, nil
+}
+
+func (repository *QueueRepository) DeleteFinished(before time.Time) (int64, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var kept []*taskRow
+	var deleted int64
+
+	for _, row := range repository.store.tasks {
+		if row.task.Status == queue.Done && row.task.FinishedAt.Before(before) {
+			deleted++
+			continue
+		}
+
+		kept = append(kept, row)
+	}
+
+	repository.store.tasks = kept
+
+	return deleted, nil
+}
+
+func (repository *QueueRepository) update(id int64, status string, change func(row *taskRow, now time.Time)) error {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	for _, row := range repository.store.tasks {
+		if row.task.ID == id && row.task.Status == status {
+			now := time.Now()
+
+			change(row, now)
+			row.lockedUntil = time.Time{}
+			row.updatedAt = now
+
+			return nil
+		}
+	}
+
+	return queue.ErrNotFound
+}
+
+func unfinished(row *taskRow) bool {
+	return row.task.Status == queue.Pending || row.task.Status == queue.Running
+}
+
+func (row *taskRow) copy() *queue.Task {
+
+	task := row.task
+
+	if row.task.FinishedAt != nil {
+		finishedAt := *row.task.FinishedAt
+		task.FinishedAt = &finishedAt
+	}
+
+	return &task
+}
@@ -0,0 +1,141 @@
+// Package memory implements the repositories over in-process maps, for tests
+// that should not need a database
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/geocodes"
+	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+
+	"github.com/google/uuid"
+)
+
+// Store holds the rows shared by the in-memory repositories, the same way
+// the Postgres repositories share one database
+type Store struct {
+	mu sync.Mutex
+	tx sync.Mutex // held for the duration of a UnitOfWork
+
+	employers    map[string]*employerRow // by publicid
+	companies    map[string]*companies.Company
+	jobs         map[string]*jobs.Job
+	jobOrder     []string
+	jobLiveAt    map[string]time.Time
+	jobsLive     map[string]bool
+	jobDeletedAt map[string]time.Time
+	jobRevisions map[string][]*jobs.Revision        // oldest first, never modified once appended
+	jobPackages  map[string]*jobpackages.JobPackage // by typeid
+	packageID    int
+	tasks        []*taskRow
+	taskID       int64
+	webhooks     []*webhooks.Webhook
+	deliveries   []*webhooks.Delivery
+	auditLog     []*audit.Entry // never modified once appended
+	uploads      []*uploads.Upload
+	attachments  []*attachmentRow             // never modified once appended
+	geocodes     map[string]*geocodes.Geocode // a cache outside any unit of work, not snapshotted
+}
+
+type employerRow struct {
+	employer        accountmanagement.Employer
+	password        string
+	companyPublicID string
+}
+
+// NewStore returns an empty Store
+func NewStore() *Store {
+	return &Store{
+		employers:    map[string]*employerRow{},
+		companies:    map[string]*companies.Company{},
+		jobs:         map[string]*jobs.Job{},
+		jobLiveAt:    map[string]time.Time{},
+		jobsLive:     map[string]bool{},
+		jobDeletedAt: map[string]time.Time{},
+		jobRevisions: map[string][]*jobs.Revision{},
+		jobPackages:  map[string]*jobpackages.JobPackage{},
+		geocodes:     map[string]*geocodes.Geocode{},
+	}
+}
+
+// EmployerRepository returns the EmployerRepository over store
+func (store *Store) EmployerRepository() *EmployerRepository {
+	return &EmployerRepository{store: store}
+}
+
+// CompanyRepository returns the CompanyRepository over store
+func (store *Store) CompanyRepository() *CompanyRepository {
+	return &CompanyRepository{store: store}
+}
+
+// JobRepository returns the JobRepository over store
+func (store *Store) JobRepository() *JobRepository {
+	return &JobRepository{store: store}
+}
+
+// JobPackageRepository returns the JobPackageRepository over store
+func (store *Store) JobPackageRepository() *JobPackageRepository {
+	return &JobPackageRepository{store: store}
+}
+
+// QueueRepository returns the QueueRepository over store
+func (store *Store) QueueRepository() *QueueRepository {
+	return &QueueRepository{store: store}
+}
+
+// WebhookRepository returns the WebhookRepository over store
+func (store *Store) WebhookRepository() *WebhookRepository {
+	return &WebhookRepository{store: store}
+}
+
+// AuditRepository returns the AuditRepository over store
+func (store *Store) AuditRepository() *AuditRepository {
+	return &AuditRepository{store: store}
+}
+
+// UploadRepository returns the UploadRepository over store
+func (store *Store) UploadRepository() *UploadRepository {
+	return &UploadRepository{store: store}
+}
+
+// AttachmentRepository returns the AttachmentRepository over store
+func (store *Store) AttachmentRepository() *AttachmentRepository {
+	return &AttachmentRepository{store: store}
+}
+
+// GeocodeRepository returns the GeocodeRepository over store
+func (store *Store) GeocodeRepository() *GeocodeRepository {
+	return &GeocodeRepository{store: store}
+}
+
+// UnitOfWork returns the UnitOfWork over store
+func (store *Store) UnitOfWork() *UnitOfWork {
+	return &UnitOfWork{store: store}
+}
+
+// AddJobPackage inserts pack, the equivalent of seeding the jobpackages table
+func (store *Store) AddJobPackage(pack *jobpackages.JobPackage) *jobpackages.JobPackage {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.packageID++
+
+	stored := *pack
+	stored.ID = store.packageID
+	store.jobPackages[stored.TypeID] = &stored
+
+	result := stored
+	return &result
+}
+
+func newPublicID() string {
+	return uuid.NewString()
+}
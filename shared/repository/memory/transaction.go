@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+)
+
+// UnitOfWork is the in-memory transaction.UnitOfWork. Units run one at a
+// time, and the store is restored to its previous contents if fn fails.
+// There is no isolation: a rollback also undoes writes made outside the unit
+// while it ran, which is fine for tests that drive one request at a time.
+type UnitOfWork struct {
+	store *Store
+}
+
+var _ transaction.UnitOfWork = (*UnitOfWork)(nil)
+
+func (unit *UnitOfWork) Do(ctx context.Context, fn func(repositories *transaction.Repositories) error) error {
+
+	unit.store.tx.Lock()
+	defer unit.store.tx.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	saved := unit.store.snapshot()
+
+	err := fn(&transaction.Repositories{
+		Employers:   unit.store.EmployerRepository(),
+		Companies:   unit.store.CompanyRepository(),
+		Jobs:        unit.store.JobRepository(),
+		Queue:       unit.store.QueueRepository(),
+		Webhooks:    unit.store.WebhookRepository(),
+		Audit:       unit.store.AuditRepository(),
+		Uploads:     unit.store.UploadRepository(),
+		Attachments: unit.store.AttachmentRepository(),
+	})
+
+	if err != nil {
+		unit.store.restore(saved)
+	}
+
+	return err
+}
+
+// snapshot copies every row, so later changes to the store do not alter it
+func (store *Store) snapshot() *Store {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	saved := NewStore()
+
+	for key, row := range store.employers {
+		copied := *row
+		saved.employers[key] = &copied
+	}
+
+	for key, company := range store.companies {
+		copied := *company
+		saved.companies[key] = &copied
+	}
+
+	for key, job := range store.jobs {
+		copied := *job
+		saved.jobs[key] = &copied
+	}
+
+	for key, liveAt := range store.jobLiveAt {
+		saved.jobLiveAt[key] = liveAt
+	}
+
+	for key, live := range store.jobsLive {
+		saved.jobsLive[key] = live
+	}
+
+	for key, deletedAt := range store.jobDeletedAt {
+		saved.jobDeletedAt[key] = deletedAt
+	}
+
+	for key, revisions := range store.jobRevisions {
+		saved.jobRevisions[key] = append([]*jobs.Revision(nil), revisions...)
+	}
+
+	for key, pack := range store.jobPackages {
+		copied := *pack
+		saved.jobPackages[key] = &copied
+	}
+
+	for _, row := range store.tasks {
+		copied := *row
+		copied.task = *row.copy()
+		saved.tasks = append(saved.tasks, &copied)
+	}
+
+	for _, webhook := range store.webhooks {
+		saved.webhooks = append(saved.webhooks, copyWebhook(webhook))
+	}
+
+	for _, delivery := range store.deliveries {
+		saved.deliveries = append(saved.deliveries, copyDelivery(delivery))
+	}
+
+	for _, upload := range store.uploads {
+		copied := *upload
+		saved.uploads = append(saved.uploads, &copied)
+	}
+
+	saved.auditLog = append([]*audit.Entry(nil), store.auditLog...)
+	saved.attachments = append([]*attachmentRow(nil), store.attachments...)
+	saved.jobOrder = append([]string(nil), store.jobOrder...)
+	saved.packageID = store.packageID
+	saved.taskID = store.taskID
+
+	return saved
+}
+
+func (store *Store) restore(saved *Store) {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.employers = saved.employers
+	store.companies = saved.companies
+	store.jobs = saved.jobs
+	store.jobOrder = saved.jobOrder
+	store.jobLiveAt = saved.jobLiveAt
+	store.jobsLive = saved.jobsLive
+	store.jobDeletedAt = saved.jobDeletedAt
+	store.jobRevisions = saved.jobRevisions
+	store.jobPackages = saved.jobPackages
+	store.packageID = saved.packageID
+	store.tasks = saved.tasks
+	store.webhooks = saved.webhooks
+	store.deliveries = saved.deliveries
+	store.auditLog = saved.auditLog
+	store.uploads = saved.uploads
+	store.attachments = saved.attachments
+	store.taskID = saved.taskID
+}
@@ -0,0 +1,332 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+)
+
+// UploadRepository is the in-memory uploads.UploadRepository
+type UploadRepository struct {
+	store *Store
+}
+
+var _ uploads.UploadRepository = (*UploadRepository)(nil)
+
+func (repository *UploadRepository) CreateUpload(employerPublicID, key, fileName, contentType string, size int64, expiresAt time.Time) (*uploads.Upload, error) {
+
+	if employerPublicID == "" || key == "" || contentType == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	employer, ok := repository.store.employers[employerPublicID]
+
+	if !ok {
+		return nil, accountmanagement.ErrNotFound
+	}
+
+	upload := &uploads.Upload{
+		PublicID:         newPublicID(),
+		EmployerPublicID: employerPublicID,
+		CompanyPublicID:  employer.companyPublicID,
+		Key:              key,
+		FileName:         fileName,
+		ContentType:      contentType,
+		Size:             size,
+		Status:           uploads.Pending,
+		CreatedAt:        time.Now(),
+		ExpiresAt:        expiresAt,
+	}
+
+	repository.store.uploads = append(repository.store.uploads, upload)
+
+	result := *upload
+	return &result, nil
+}
+
+func (repository *UploadRepository) RecordUpload(employerPublicID, key, url, contentType string, size int64, expiresAt time.Time) (*uploads.Upload, error) {
+
+	if key == "" || contentType == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var companyPublicID string
+
+	if employerPublicID != "" {
+		employer, ok := repository.store.employers[employerPublicID]
+
+		if !ok {
+			return nil, accountmanagement.ErrNotFound
+		}
+
+		companyPublicID = employer.companyPublicID
+	}
+
+	for _, upload := range repository.store.uploads {
+		if upload.Key == key {
+			upload.URL = url
+
+			if expiresAt.After(upload.ExpiresAt) {
+				upload.ExpiresAt = expiresAt
+			}
+
+			result := *upload
+			return &result, nil
+		}
+	}
+
+	upload := &uploads.Upload{
+		PublicID:         newPublicID(),
+		EmployerPublicID: employerPublicID,
+		CompanyPublicID:  companyPublicID,
+		Key:              key,
+		URL:              url,
+		ContentType:      contentType,
+		Size:             size,
+		Status:           uploads.Complete,
+		CreatedAt:        time.Now(),
+		ExpiresAt:        expiresAt,
+	}
+
+	repository.store.uploads = append(repository.store.uploads, upload)
+
+	result := *upload
+	return &result, nil
+}
+
+func (repository *UploadRepository) GetEmployerUpload(employerPublicID, uploadPublicID string) (*uploads.Upload, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	upload, ok := repository.store.upload(uploadPublicID)
+
+	if !ok || employerPublicID == "" || upload.EmployerPublicID != employerPublicID {
+		return nil, uploads.ErrNotFound
+	}
+
+	result := *upload
+	return &result, nil
+}
+
+func (repository *UploadRepository) GetEmployerUploads(employerPublicID string) ([]*uploads.Upload, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var result []*uploads.Upload
+
+	// newest first, and uploads are appended in creation order
+	for i := len(repository.store.uploads) - 1; i >= 0; i-- {
+		upload := repository.store.uploads[i]
+
+		if employerPublicID != "" && upload.EmployerPublicID == employerPublicID {
+			copied := *upload
+			result = append(result, &copied)
+		}
+	}
+
+	return result, nil
+}
+
+func (repository *UploadRepository) CompleteUpload(uploadPublicID string, size int64, expiresAt time.Time) (*uploads.Upload, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	upload, ok := repository.store.upload(uploadPublicID)
+
+	if !ok || upload.Status != uploads.Pending {
+		return nil, uploads.ErrNotFound
+	}
+
+	upload.Status = uploads.Complete
+	upload.Size = size
+	upload.ExpiresAt = expiresAt
+
+	result := *upload
+	return &result, nil
+}
+
+func (repository *UploadRepository) QuarantineUpload(uploadPublicID, key, scanResult string, expiresAt time.Time) (*uploads.Upload, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	upload, ok := repository.store.upload(uploadPublicID)
+
+	if !ok || upload.Status != uploads.Pending {
+		return nil, uploads.ErrNotFound
+	}
+
+	upload.Status = uploads.Quarantined
+	upload.Key = key
+	upload.ScanResult = scanResult
+	upload.ExpiresAt = expiresAt
+
+	result := *upload
+	return &result, nil
+}
+
+func (repository *UploadRepository) RecordQuarantine(employerPublicID, key, fileName, contentType string, size int64, scanResult string, expiresAt time.Time) (*uploads.Upload, error) {
+
+	if key == "" || contentType == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var companyPublicID string
+
+	if employerPublicID != "" {
+		employer, ok := repository.store.employers[employerPublicID]
+
+		if !ok {
+			return nil, accountmanagement.ErrNotFound
+		}
+
+		companyPublicID = employer.companyPublicID
+	}
+
+	upload := &uploads.Upload{
+		PublicID:         newPublicID(),
+		EmployerPublicID: employerPublicID,
+		CompanyPublicID:  companyPublicID,
+		Key:              key,
+		FileName:         fileName,
+		ContentType:      contentType,
+		Size:             size,
+		Status:           uploads.Quarantined,
+		ScanResult:       scanResult,
+		CreatedAt:        time.Now(),
+		ExpiresAt:        expiresAt,
+	}
+
+	repository.store.uploads = append(repository.store.uploads, upload)
+
+	result := *upload
+	return &result, nil
+}
+
+func (repository *UploadRepository) Retain(urls []string) error {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	for _, url := range uploads.Distinct(urls) {
+		for _, upload := range repository.store.uploads {
+			if upload.URL == url {
+				upload.RefCount++
+			}
+		}
+	}
+
+	return nil
+}
+
+func (repository *UploadRepository) Release(urls []string, expiresAt time.Time) error {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	for _, url := range uploads.Distinct(urls) {
+		for _, upload := range repository.store.uploads {
+			if upload.URL != url || upload.RefCount == 0 {
+				continue
+			}
+
+			upload.RefCount--
+
+			if upload.RefCount == 0 {
+				upload.ExpiresAt = expiresAt
+			}
+		}
+	}
+
+	return nil
+}
+
+func (repository *UploadRepository) DeleteEmployerUpload(employerPublicID, uploadPublicID string) (*uploads.Upload, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	for i, upload := range repository.store.uploads {
+		if upload.PublicID != uploadPublicID || employerPublicID == "" || upload.EmployerPublicID != employerPublicID {
+			continue
+		}
+
+		if upload.RefCount > 0 {
+			return nil, uploads.ErrInUse
+		}
+
+		repository.store.uploads = append(repository.store.uploads[:i:i], repository.store.uploads[i+1:]...)
+
+		result := *upload
+		return &result, nil
+	}
+
+	return nil, uploads.ErrNotFound
+}
+
+func (repository *UploadRepository) GetOrphanedUploads(now time.Time, limit int) ([]*uploads.Upload, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	var result []*uploads.Upload
+
+	for _, upload := range repository.store.uploads {
+		if upload.RefCount == 0 && upload.ExpiresAt.Before(now) {
+			copied := *upload
+			result = append(result, &copied)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ExpiresAt.Before(result[j].ExpiresAt)
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+func (repository *UploadRepository) DeleteOrphanedUpload(uploadPublicID string, now time.Time) error {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	for i, upload := range repository.store.uploads {
+		if upload.PublicID == uploadPublicID && upload.RefCount == 0 && upload.ExpiresAt.Before(now) {
+			repository.store.uploads = append(repository.store.uploads[:i:i], repository.store.uploads[i+1:]...)
+			return nil
+		}
+	}
+
+	return uploads.ErrNotFound
+}
+
+// upload must be called with mu held
+func (store *Store) upload(uploadPublicID string) (*uploads.Upload, bool) {
+
+	for _, upload := range store.uploads {
+		if upload.PublicID == uploadPublicID {
+			return upload, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,339 @@
+package memory
+
+import (
+	"encoding/json"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+)
+
+// WebhookRepository is the in-memory webhooks.WebhookRepository
+type WebhookRepository struct {
+	store *Store
+}
+
+var _ webhooks.WebhookRepository = (*WebhookRepository)(nil)
+
+func (repository *WebhookRepository) CreateWebhook(employerPublicID, url, secret string, events []string) (*webhooks.Webhook, error) {
+
+	if employerPublicID == "" || url == "" || secret == "" || len(events) == 0 {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	companyPublicID, ok := repository.store.companyOf(employerPublicID)
+
+	if !ok {
+		return nil, companies.ErrNotFound
+	}
+
+	webhook := &webhooks.Webhook{
+		PublicID:        newPublicID(),
+		CompanyPublicID: companyPublicID,
+		URL:             url,
+		Secret:          secret,
+		Events:          append([]string(nil), events...),
+		CreatedAt:       time.Now(),
+	}
+
+	repository.store.webhooks = append(repository.store.webhooks, webhook)
+
+	return copyWebhook(webhook), nil
+}
+
+func (repository *WebhookRepository) GetWebhooks(employerPublicID string) ([]*webhooks.Webhook, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	companyPublicID, _ := repository.store.companyOf(employerPublicID)
+
+	var result []*webhooks.Webhook
+
+	for _, webhook := range repository.store.webhooks {
+		if companyPublicID != "" && webhook.CompanyPublicID == companyPublicID {
+			result = append(result, copyWebhook(webhook))
+		}
+	}
+
+	return result, nil
+}
+
+func (repository *WebhookRepository) GetEmployerWebhook(employerPublicID, webhookPublicID string) (*webhooks.Webhook, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	webhook, ok := repository.store.employerWebhook(employerPublicID, webhookPublicID)
+
+	if !ok {
+		return nil, webhooks.ErrNotFound
+	}
+
+	return copyWebhook(webhook), nil
+}
+
+func (repository *WebhookRepository) GetWebhook(webhookPublicID string) (*webhooks.Webhook, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	webhook, ok := repository.store.webhook(webhookPublicID)
+
+	if !ok {
+		return nil, webhooks.ErrNotFound
+	}
+
+	return copyWebhook(webhook), nil
+}
+
+func (repository *WebhookRepository) DeleteWebhook(employerPublicID, webhookPublicID string) error {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	if _, ok := repository.store.employerWebhook(employerPublicID, webhookPublicID); !ok {
+		return webhooks.ErrNotFound
+	}
+
+	var kept []*webhooks.Webhook
+
+	for _, webhook := range repository.store.webhooks {
+		if webhook.PublicID != webhookPublicID {
+			kept = append(kept, webhook)
+		}
+	}
+
+	repository.store.webhooks = kept
+
+	var deliveries []*webhooks.Delivery
+
+	for _, delivery := range repository.store.deliveries {
+		if delivery.WebhookPublicID != webhookPublicID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	repository.store.deliveries = deliveries
+
+	return nil
+}
+
+func (repository *WebhookRepository) GetSubscribedWebhooks(employerPublicID, event string) ([]*webhooks.Webhook, error) {
+
+	all, err := repository.GetWebhooks(employerPublicID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var subscribed []*webhooks.Webhook
+
+	for _, webhook := range all {
+		for _, candidate := range webhook.Events {
+			if candidate == event {
+				subscribed = append(subscribed, webhook)
+				break
+			}
+		}
+	}
+
+	return subscribed, nil
+}
+
+func (repository *WebhookRepository) CreateDelivery(webhookPublicID, event string, payload json.RawMessage) (*webhooks.Delivery, error) {
+
+	if webhookPublicID == "" || event == "" || len(payload) == 0 {
+		return nil, domain.ErrMissingValue
+	}
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	if _, ok := repository.store.webhook(webhookPublicID); !ok {
+		return nil, webhooks.ErrNotFound
+	}
+
+	delivery := &webhooks.Delivery{
+		PublicID:        newPublicID(),
+		WebhookPublicID: webhookPublicID,
+		Event:           event,
+		Payload:         append(json.RawMessage(nil), payload...),
+		Status:          webhooks.Pending,
+		CreatedAt:       time.Now(),
+	}
+
+	repository.store.deliveries = append(repository.store.deliveries, delivery)
+
+	return copyDelivery(delivery), nil
+}
+
+func (repository *WebhookRepository) GetEmployerDelivery(employerPublicID, deliveryPublicID string) (*webhooks.Delivery, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	delivery, ok := repository.store.delivery(deliveryPublicID)
+
+	if !ok {
+		return nil, webhooks.ErrDeliveryNotFound
+	}
+
+	if _, ok := repository.store.employerWebhook(employerPublicID, delivery.WebhookPublicID); !ok {
+		return nil, webhooks.ErrDeliveryNotFound
+	}
+
+	return copyDelivery(delivery), nil
+}
+
+func (repository *WebhookRepository) GetDelivery(deliveryPublicID string) (*webhooks.Delivery, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	delivery, ok := repository.store.delivery(deliveryPublicID)
+
+	if !ok {
+		return nil, webhooks.ErrDeliveryNotFound
+	}
+
+	return copyDelivery(delivery), nil
+}
+
+func (repository *WebhookRepository) GetDeliveries(employerPublicID, webhookPublicID string, limit int) ([]*webhooks.Delivery, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	if _, ok := repository.store.employerWebhook(employerPublicID, webhookPublicID); !ok {
+		return nil, nil
+	}
+
+	var deliveries []*webhooks.Delivery
+
+	for _, delivery := range repository.store.deliveries {
+		if delivery.WebhookPublicID == webhookPublicID {
+			deliveries = append(deliveries, copyDelivery(delivery))
+		}
+	}
+
+	// newest first; deliveries are appended in creation order
+	for i, j := 0, len(deliveries)-1; i < j; i, j = i+1, j-1 {
+		deliveries[i], deliveries[j] = deliveries[j], deliveries[i]
+	}
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (repository *WebhookRepository) RecordAttempt(deliveryPublicID string, attempt *webhooks.Attempt) (*webhooks.Delivery, error) {
+
+	repository.store.mu.Lock()
+	defer repository.store.mu.Unlock()
+
+	delivery, ok := repository.store.delivery(deliveryPublicID)
+
+	if !ok {
+		return nil, webhooks.ErrDeliveryNotFound
+	}
+
+	at := attempt.At
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &at
+	delivery.Status = attempt.Status
+	delivery.ResponseStatus = attempt.ResponseStatus
+	delivery.ResponseBody = attempt.ResponseBody
+	delivery.LastError = attempt.Error
+
+	if attempt.Status == webhooks.Delivered {
+		delivery.DeliveredAt = &at
+	}
+
+	return copyDelivery(delivery), nil
+}
+
+// companyOf must be called with mu held
+func (store *Store) companyOf(employerPublicID string) (string, bool) {
+
+	row, ok := store.employers[employerPublicID]
+
+	if !ok || row.companyPublicID == "" {
+		return "", false
+	}
+
+	return row.companyPublicID, true
+}
+
+// webhook must be called with mu held
+func (store *Store) webhook(webhookPublicID string) (*webhooks.Webhook, bool) {
+
+	for _, webhook := range store.webhooks {
+		if webhook.PublicID == webhookPublicID {
+			return webhook, true
+		}
+	}
+
+	return nil, false
+}
+
+// employerWebhook must be called with mu held
+func (store *Store) employerWebhook(employerPublicID, webhookPublicID string) (*webhooks.Webhook, bool) {
+
+	companyPublicID, ok := store.companyOf(employerPublicID)
+
+	if !ok {
+		return nil, false
+	}
+
+	webhook, ok := store.webhook(webhookPublicID)
+
+	if !ok || webhook.CompanyPublicID != companyPublicID {
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+// delivery must be called with mu held
+func (store *Store) delivery(deliveryPublicID string) (*webhooks.Delivery, bool) {
+
+	for _, delivery := range store.deliveries {
+		if delivery.PublicID == deliveryPublicID {
+			return delivery, true
+		}
+	}
+
+	return nil, false
+}
+
+func copyWebhook(webhook *webhooks.Webhook) *webhooks.Webhook {
+	result := *webhook
+	result.Events = append([]string(nil), webhook.Events...)
+	return &result
+}
+
+func copyDelivery(delivery *webhooks.Delivery) *webhooks.Delivery {
+
+	result := *delivery
+	result.Payload = append(json.RawMessage(nil), delivery.Payload...)
+
+	if delivery.LastAttemptAt != nil {
+		at := *delivery.LastAttemptAt
+		result.LastAttemptAt = &at
+	}
+
+	if delivery.DeliveredAt != nil {
+		at := *delivery.DeliveredAt
+		result.DeliveredAt = &at
+	}
+
+	return &result
+}
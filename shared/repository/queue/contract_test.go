@@ -0,0 +1,16 @@
+package queue_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func init() {
+	testhelper.Init()
+}
+
+func Test_QueueRepository_Contract(t *testing.T) {
+	repositorytest.QueueRepository(t, testhelper.Repositories)
+}
@@ -0,0 +1,297 @@
+package queue
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/services/utils"
+
+	"github.com/lib/pq"
+)
+
+// Task statuses
+const (
+	Pending = "pending"
+	Running = "running"
+	Done    = "done"
+	Dead    = "dead"
+)
+
+var (
+	// ErrNotFound is returned for a task that does not exist, or is not in
+	// the status an update needs
+	ErrNotFound = domain.NewNotFound("task_not_found", "task not found")
+	// ErrDuplicate is returned by Schedule when an unfinished task already has the unique key
+	ErrDuplicate = domain.NewConflict("task_duplicate", "an unfinished task with this key already exists")
+)
+
+// QueueRepository stores background tasks. A task enqueued through a
+// repository joined to a transaction is only claimed once that transaction
+// commits, so Enqueue doubles as a transactional outbox.
+type QueueRepository interface {
+	Enqueue(kind string, payload interface{}) (*Task, error)
+	Schedule(kind string, payload interface{}, runAt time.Time, uniqueKey string) (*Task, error)
+	Claim(limit int, lease time.Duration) ([]*Task, error)
+	Complete(id int64) error
+	Retry(id int64, lastError string, runAt time.Time) error
+	Bury(id int64, lastError string) error
+	Requeue(id int64) error
+	GetTask(id int64) (*Task, error)
+	GetTasks(status string, limit int) ([]*Task, error)
+	GetStats() ([]*Stats, error)
+	DeleteFinished(before time.Time) (int64, error)
+}
+
+// PostgresQueueRepository is the QueueRepository backed by the tasks table
+type PostgresQueueRepository struct {
+	Database database.Querier
+}
+
+// Task is a unit of background work. Attempts counts claims, including the
+// current one.
+type Task struct {
+	ID         int64           `json:"id"`
+	Kind       string          `json:"kind"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Status     string          `json:"status"`
+	Attempts   int             `json:"attempts"`
+	LastError  string          `json:"lasterror,omitempty"`
+	UniqueKey  string          `json:"uniquekey,omitempty"`
+	RunAt      time.Time       `json:"runat"`
+	CreatedAt  time.Time       `json:"createdat"`
+	FinishedAt *time.Time      `json:"finishedat,omitempty"`
+}
+
+// Stats counts the tasks of one kind in one status
+type Stats struct {
+	Kind   string    `json:"kind"`
+	Status string    `json:"status"`
+	Count  int       `json:"count"`
+	Oldest time.Time `json:"oldest"` // earliest runat
+}
+
+func NewQueueRepository(db database.Querier) *PostgresQueueRepository {
+	return &PostgresQueueRepository{Database: db}
+}
+
+const taskColumns = `id, kind, payload, status, attempts, COALESCE(lasterror, ''), COALESCE(uniquekey, ''), runat, createdat, finishedat`
+
+func (repository *PostgresQueueRepository) Enqueue(kind string, payload interface{}) (*Task, error) {
+	return repository.Schedule(kind, payload, time.Time{}, "")
+}
+
+// Schedule enqueues a task that is not claimed before runAt. The zero time
+// means now. A non-empty uniqueKey fails with ErrDuplicate while another
+// pending or running task has the same key.
+func (repository *PostgresQueueRepository) Schedule(kind string, payload interface{}, runAt time.Time, uniqueKey string) (*Task, error) {
+
+	if kind == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	body, err := json.Marshal(payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if runAt.IsZero() {
+		runAt = time.Now()
+	}
+
+	task, err := scanTask(repository.Database.QueryRow(`
+		INSERT INTO tasks(kind, payload, runat, uniquekey) VALUES ($1, $2, $3, $4)
+		RETURNING `+taskColumns+`;`, kind, string(body), runAt, utils.NewNullString(uniqueKey)))
+
+	if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+		return nil, ErrDuplicate
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// Claim leases up to limit due tasks and marks them running. Rows locked by
+// another worker are skipped, and a running task whose lease has expired,
+// because its worker died, is claimed again.
+func (repository *PostgresQueueRepository) Claim(limit int, lease time.Duration) ([]*Task, error) {
+
+	rows, err := repository.Database.Query(`
+		UPDATE tasks SET status = 'running', attempts = attempts + 1, lockeduntil = now() + $2 * interval '1 millisecond', updatedat = now()
+		WHERE id IN (
+			SELECT id FROM tasks
+			WHERE (status = 'pending' AND runat <= now()) OR (status = 'running' AND lockeduntil < now())
+			ORDER BY runat, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING `+taskColumns+`;`, limit, lease.Milliseconds())
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return scanTasks(rows)
+}
+
+// Complete marks the task done and drops its payload, which may hold secrets
+// such as a temporary password
+func (repository *PostgresQueueRepository) Complete(id int64) error {
+	return repository.update(`UPDATE tasks SET status = 'done', payload = NULL, lockeduntil = NULL, finishedat = now(), updatedat = now() WHERE id = $1 AND status = 'running';`, id)
+}
+
+// Retry releases a running task to be claimed again at runAt
+func (repository *PostgresQueueRepository) Retry(id int64, lastError string, runAt time.Time) error {
+	return repository.update(`UPDATE tasks SET status = 'pending', lasterror = $2, runat = $3, lockeduntil = NULL, updatedat = now() WHERE id = $1 AND status = 'running';`, id, lastError, runAt)
+}
+
+// Bury moves a running task to the dead letters, where it stays until requeued
+func (repository *PostgresQueueRepository) Bury(id int64, lastError string) error {
+	return repository.update(`UPDATE tasks SET status = 'dead', lasterror = $2, lockeduntil = NULL, finishedat = now(), updatedat = now() WHERE id = $1 AND status = 'running';`, id, lastError)
+}
+
+// Requeue gives a dead task a fresh set of attempts, starting now
+func (repository *PostgresQueueRepository) Requeue(id int64) error {
+	return repository.update(`UPDATE tasks SET status = 'pending', attempts = 0, runat = now(), finishedat = NULL, updatedat = now() WHERE id = $1 AND status = 'dead';`, id)
+}
+
+func (repository *PostgresQueueRepository) GetTask(id int64) (*Task, error) {
+
+	task, err := scanTask(repository.Database.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = $1;`, id))
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, ErrNotFound)
+	}
+
+	return task, nil
+}
+
+// GetTasks returns up to limit tasks in status, most recently updated first
+func (repository *PostgresQueueRepository) GetTasks(status string, limit int) ([]*Task, error) {
+
+	rows, err := repository.Database.Query(`SELECT `+taskColumns+` FROM tasks WHERE status = $1 ORDER BY updatedat DESC, id DESC LIMIT $2;`, status, limit)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return scanTasks(rows)
+}
+
+func (repository *PostgresQueueRepository) GetStats() ([]*Stats, error) {
+
+	rows, err := repository.Database.Query(`SELECT kind, status, count(*), min(runat) FROM tasks GROUP BY kind, status ORDER BY kind, status;`)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var stats []*Stats
+
+	for rows.Next() {
+		var row Stats
+
+		if err := rows.Scan(&row.Kind, &row.Status, &row.Count, &row.Oldest); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		stats = append(stats, &row)
+	}
+
+	return stats, rows.Err()
+}
+
+// DeleteFinished removes done tasks that finished before the cutoff. Dead
+// tasks are kept until an operator requeues or deletes them.
+func (repository *PostgresQueueRepository) DeleteFinished(before time.Time) (int64, error) {
+
+	result, err := repository.Database.Exec(`DELETE FROM tasks WHERE status = 'done' AND finishedat < $1;`, before)
+
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (repository *PostgresQueueRepository) update(query string, args ...interface{}) error {
+
+	result, err := repository.Database.Exec(query, args...)
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTask(row scanner) (*Task, error) {
+
+	var task Task
+	var payload []byte
+	var finishedAt sql.NullTime
+
+	err := row.Scan(&task.ID, &task.Kind, &payload, &task.Status, &task.Attempts, &task.LastError, &task.UniqueKey, &task.RunAt, &task.CreatedAt, &finishedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	task.Payload = payload
+
+	if finishedAt.Valid {
+		task.FinishedAt = &finishedAt.Time
+	}
+
+	return &task, nil
+}
+
+func scanTasks(rows *sql.Rows) ([]*Task, error) {
+
+	defer rows.Close()
+
+	var tasks []*Task
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
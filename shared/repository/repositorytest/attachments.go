@@ -0,0 +1,168 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/attachments"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// AttachmentRepository is the contract for attachments.AttachmentRepository
+func AttachmentRepository(t *testing.T, factory Factory) {
+
+	repositories := factory(t)
+	repository := repositories.Attachments
+
+	attach := func(t *testing.T, employerPublicID, jobPublicID string, size int64) *jobs.Attachment {
+		attachment, err := repository.CreateAttachment(employerPublicID, jobPublicID, "benefits.pdf", "application/pdf", "https://cdn.example.com/files/"+randomString()+".pdf", size)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return attachment
+	}
+
+	t.Run("CreateAttachment", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		attachment := attach(t, employer.PublicID, job.PublicID, 2048)
+
+		assert.NotEqual("", attachment.PublicID)
+		assert.Equal("benefits.pdf", attachment.FileName)
+		assert.Equal("application/pdf", attachment.ContentType)
+		assert.Equal(int64(2048), attachment.Size)
+		assert.False(attachment.CreatedAt.IsZero())
+
+		_, err := repository.CreateAttachment(employer.PublicID, job.PublicID, "copy.pdf", "application/pdf", attachment.URL, 2048)
+		assert.Equal(attachments.ErrExists, err)
+
+		// the same file may be attached to another job
+		other := createJob(t, repositories, employer.PublicID)
+		_, err = repository.CreateAttachment(employer.PublicID, other.PublicID, "benefits.pdf", "application/pdf", attachment.URL, 2048)
+		assert.Nil(err)
+
+		// only to jobs of the employer
+		_, err = repository.CreateAttachment(createEmployer(t, repositories).PublicID, job.PublicID, "benefits.pdf", "application/pdf", "https://cdn.example.com/files/"+randomString()+".pdf", 1)
+		assert.Equal(jobs.ErrNotFound, err)
+
+		_, err = repository.CreateAttachment(employer.PublicID, job.PublicID, "benefits.pdf", "application/pdf", "", 1)
+		assert.NotNil(err)
+
+		result, err := repository.GetJobAttachments(job.PublicID)
+		assert.Nil(err)
+
+		if assert.Len(result, 1) {
+			assert.Equal(attachment.PublicID, result[0].PublicID)
+			assert.Equal(attachment.URL, result[0].URL)
+		}
+	})
+
+	t.Run("DeleteAttachment", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		first := attach(t, employer.PublicID, job.PublicID, 1)
+		second := attach(t, employer.PublicID, job.PublicID, 1)
+
+		_, err := repository.DeleteAttachment(createEmployer(t, repositories).PublicID, job.PublicID, first.PublicID)
+		assert.Equal(attachments.ErrNotFound, err)
+
+		_, err = repository.DeleteAttachment(employer.PublicID, createJob(t, repositories, employer.PublicID).PublicID, first.PublicID)
+		assert.Equal(attachments.ErrNotFound, err)
+
+		deleted, err := repository.DeleteAttachment(employer.PublicID, job.PublicID, first.PublicID)
+		assert.Nil(err)
+		assert.Equal(first.URL, deleted.URL)
+
+		_, err = repository.DeleteAttachment(employer.PublicID, job.PublicID, first.PublicID)
+		assert.Equal(attachments.ErrNotFound, err)
+
+		result, err := repository.GetJobAttachments(job.PublicID)
+		assert.Nil(err)
+
+		if assert.Len(result, 1) {
+			assert.Equal(second.PublicID, result[0].PublicID)
+		}
+	})
+
+	t.Run("GetCompanyAttachmentSize", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+		colleague := createEmployer(t, repositories)
+
+		if err := repositories.Employers.SetEmployerCompany(colleague.PublicID, company.PublicID); err != nil {
+			t.Fatal(err)
+		}
+
+		attach(t, employer.PublicID, createJob(t, repositories, employer.PublicID).PublicID, 1000)
+
+		deleted := createJob(t, repositories, colleague.PublicID)
+		attach(t, colleague.PublicID, deleted.PublicID, 500)
+
+		_, err := repositories.Jobs.DeleteJob(colleague.PublicID, deleted.PublicID)
+		assert.Nil(err)
+
+		// another company's attachments are not counted
+		stranger, _ := createEmployerWithCompany(t, repositories)
+		attach(t, stranger.PublicID, createJob(t, repositories, stranger.PublicID).PublicID, 300)
+
+		size, err := repository.GetCompanyAttachmentSize(employer.PublicID)
+		assert.Nil(err)
+		assert.Equal(int64(1500), size)
+
+		size, err = repository.GetCompanyAttachmentSize(colleague.PublicID)
+		assert.Nil(err)
+		assert.Equal(int64(1500), size)
+
+		// without a company only the employer's own jobs count
+		loner := createEmployer(t, repositories)
+		attach(t, loner.PublicID, createJob(t, repositories, loner.PublicID).PublicID, 42)
+
+		size, err = repository.GetCompanyAttachmentSize(loner.PublicID)
+		assert.Nil(err)
+		assert.Equal(int64(42), size)
+	})
+
+	t.Run("GetPurgeableAttachments", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+		attachment := attach(t, employer.PublicID, job.PublicID, 1)
+		kept := attach(t, employer.PublicID, createJob(t, repositories, employer.PublicID).PublicID, 1)
+
+		_, err := repositories.Jobs.DeleteJob(employer.PublicID, job.PublicID)
+		assert.Nil(err)
+
+		result, err := repository.GetPurgeableAttachments(time.Now().Add(time.Minute))
+		assert.Nil(err)
+
+		urls := map[string]bool{}
+		for _, found := range result {
+			urls[found.URL] = true
+		}
+
+		assert.True(urls[attachment.URL])
+		assert.False(urls[kept.URL])
+
+		_, err = repositories.Jobs.PurgeDeletedJobs(time.Now().Add(time.Minute))
+		assert.Nil(err)
+
+		result, err = repository.GetPurgeableAttachments(time.Now().Add(time.Minute))
+		assert.Nil(err)
+
+		for _, found := range result {
+			assert.NotEqual(attachment.URL, found.URL)
+		}
+	})
+}
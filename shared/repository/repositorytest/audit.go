@@ -0,0 +1,120 @@
+package repositorytest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// AuditRepository is the contract for audit.AuditRepository
+func AuditRepository(t *testing.T, factory Factory) {
+
+	repositories := factory(t)
+	repository := repositories.Audit
+
+	record := func(t *testing.T, actorPublicID, action, targetPublicID string) *audit.Entry {
+		entry, err := repository.Record(&audit.Entry{
+			ActorPublicID:  actorPublicID,
+			Action:         action,
+			TargetType:     "job",
+			TargetPublicID: targetPublicID,
+			Changes:        json.RawMessage(`{"title":{"before":"Old","after":"New"}}`),
+			IP:             "203.0.113.7",
+			UserAgent:      "test",
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return entry
+	}
+
+	t.Run("Record", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+
+		entry := record(t, employer.PublicID, "job.edit", "job-1")
+
+		assert.NotEqual("", entry.PublicID)
+		assert.Equal(company.PublicID, entry.CompanyPublicID)
+		assert.False(entry.CreatedAt.IsZero())
+
+		_, err := repository.Record(&audit.Entry{ActorPublicID: employer.PublicID, TargetType: "job"})
+		assert.NotNil(err)
+	})
+
+	t.Run("GetEntries", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+		colleague := createEmployer(t, repositories)
+		outsider, _ := createEmployerWithCompany(t, repositories)
+
+		if err := repositories.Employers.SetEmployerCompany(colleague.PublicID, company.PublicID); err != nil {
+			t.Fatal(err)
+		}
+
+		first := record(t, employer.PublicID, "job.create", "job-1")
+		second := record(t, colleague.PublicID, "job.edit", "job-1")
+		record(t, outsider.PublicID, "job.edit", "job-2")
+
+		entries, err := repository.GetEntries(employer.PublicID, nil)
+
+		assert.Nil(err)
+
+		if assert.Len(entries, 2) {
+			assert.Equal(second.PublicID, entries[0].PublicID)
+			assert.Equal(first.PublicID, entries[1].PublicID)
+			assert.JSONEq(`{"title":{"before":"Old","after":"New"}}`, string(entries[0].Changes))
+			assert.Equal("203.0.113.7", entries[0].IP)
+		}
+
+		entries, err = repository.GetEntries(colleague.PublicID, &audit.Filter{Action: "job.create"})
+
+		assert.Nil(err)
+
+		if assert.Len(entries, 1) {
+			assert.Equal(first.PublicID, entries[0].PublicID)
+		}
+
+		entries, err = repository.GetEntries(employer.PublicID, &audit.Filter{ActorPublicID: colleague.PublicID, TargetPublicID: "job-1"})
+
+		assert.Nil(err)
+		assert.Len(entries, 1)
+
+		entries, err = repository.GetEntries(employer.PublicID, &audit.Filter{Since: time.Now().Add(time.Hour)})
+
+		assert.Nil(err)
+		assert.Len(entries, 0)
+
+		entries, err = repository.GetEntries(employer.PublicID, &audit.Filter{Limit: 1})
+
+		assert.Nil(err)
+		assert.Len(entries, 1)
+	})
+
+	t.Run("GetEntries_WithoutCompany", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		entry := record(t, employer.PublicID, "employer.signup", employer.PublicID)
+
+		assert.Equal("", entry.CompanyPublicID)
+
+		entries, err := repository.GetEntries(employer.PublicID, nil)
+
+		assert.Nil(err)
+		assert.Len(entries, 1)
+
+		entries, err = repository.GetEntries(createEmployer(t, repositories).PublicID, nil)
+
+		assert.Nil(err)
+		assert.Len(entries, 0)
+	})
+}
@@ -0,0 +1,43 @@
+package repositorytest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// CompanyRepository is the contract for companies.CompanyRepository
+func CompanyRepository(t *testing.T, factory Factory) {
+
+	repository := factory(t).Companies
+
+	t.Run("GetOrCreateCompany_Create", func(t *testing.T) {
+		assert := assert.New(t)
+
+		domain := randomString() + ".com"
+
+		result, err := repository.GetOrCreateCompany(domain, "Name", "Location", "https://example.com", "", "", "", "", "", "", "15218")
+
+		assert.Nil(err)
+		assert.NotEqual("", result.PublicID)
+		assert.Equal(domain, result.Domain)
+		assert.Equal("Name", result.Name)
+		assert.Equal("15218", result.Zipcode)
+	})
+
+	t.Run("GetOrCreateCompany_Get", func(t *testing.T) {
+		assert := assert.New(t)
+
+		domain := randomString() + ".com"
+
+		created, err := repository.GetOrCreateCompany(domain, "Original", "", "", "", "", "", "", "", "", "")
+		assert.Nil(err)
+
+		result, err := repository.GetOrCreateCompany(domain, "Ignored", "", "", "", "", "", "", "", "", "")
+
+		assert.Nil(err)
+		assert.Equal(created.PublicID, result.PublicID)
+		assert.Equal(domain, result.Domain)
+		assert.Equal("Original", result.Name)
+	})
+}
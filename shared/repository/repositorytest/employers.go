@@ -0,0 +1,379 @@
+package repositorytest
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// EmployerRepository is the contract for accountmanagement.EmployerRepository
+func EmployerRepository(t *testing.T, factory Factory) {
+
+	repositories := factory(t)
+	repository := repositories.Employers
+
+	t.Run("CreateEmployer", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		assert.NotEqual("", employer.PublicID)
+		assert.Equal("First", employer.FirstName)
+
+		other := createEmployer(t, repositories)
+
+		assert.NotEqual(employer.PublicID, other.PublicID)
+	})
+
+	t.Run("CreateEmployer_EmptyData", func(t *testing.T) {
+		assert := assert.New(t)
+
+		result, err := repository.CreateEmployer("", "Last", "empty@example.com", "password")
+
+		assert.NotNil(err)
+		assert.Nil(result)
+	})
+
+	t.Run("GetEmployer", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		result, err := repository.GetEmployer(employer.PublicID)
+
+		assert.Nil(err)
+		assert.Equal(employer.Email, result.Email)
+		assert.Equal(employer.PublicID, result.PublicID)
+		assert.Equal(accountmanagement.ChangePassword.String(), result.RegistrationStep)
+	})
+
+	t.Run("GetEmployer_Unknown", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := repository.GetEmployer("")
+		assert.NotNil(err)
+
+		_, err = repository.GetEmployer("00000000-0000-0000-0000-000000000000")
+		assert.NotNil(err)
+	})
+
+	t.Run("AuthenticateEmployerPassword", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		match, step, publicID, err := repository.AuthenticateEmployerPassword(employer.Email, employer.Password)
+
+		assert.Nil(err)
+		assert.True(match)
+		assert.Equal(accountmanagement.ChangePassword.String(), step)
+		assert.Equal(employer.PublicID, publicID)
+
+		match, _, publicID, err = repository.AuthenticateEmployerPassword(employer.Email, "wrong-password")
+
+		assert.Nil(err)
+		assert.False(match)
+		assert.Equal("", publicID)
+
+		match, _, _, err = repository.AuthenticateEmployerPassword("nobody-"+randomString()+"@example.com", employer.Password)
+
+		assert.Nil(err)
+		assert.False(match)
+	})
+
+	t.Run("UpdateEmployerPassword", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		updated, err := repository.UpdateEmployerPassword(employer.PublicID, "wrong-password", "new-password")
+
+		assert.Nil(err)
+		assert.False(updated)
+
+		updated, err = repository.UpdateEmployerPassword(employer.PublicID, employer.Password, "new-password")
+
+		assert.Nil(err)
+		assert.True(updated)
+
+		match, step, _, err := repository.AuthenticateEmployerPassword(employer.Email, "new-password")
+
+		assert.Nil(err)
+		assert.True(match)
+		assert.Equal(accountmanagement.PersonalInformation.String(), step)
+	})
+
+	t.Run("UpdateEmployerAccount", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		_, err := repository.UpdateEmployerAccount(employer.PublicID, "", "", "", "555-0100", "", "Recruiter", "", "", "")
+		assert.Nil(err)
+
+		result, err := repository.UpdateEmployerAccount(employer.PublicID, "Changed", "", "", "", "", "", "fb", "", "")
+
+		assert.Nil(err)
+		assert.Equal("Changed", result.FirstName)
+		assert.Equal("Last", result.LastName)
+		assert.Equal("555-0100", result.PhoneNumber)
+		assert.Equal("Recruiter", result.Role)
+		assert.Equal("fb", result.Facebook)
+
+		stored, err := repository.GetEmployer(employer.PublicID)
+
+		assert.Nil(err)
+		assert.Equal("Changed", stored.FirstName)
+		assert.Equal("555-0100", stored.PhoneNumber)
+	})
+
+	t.Run("RegistrationSteps", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, _ := createEmployerWithCompany(t, repositories)
+
+		step := func() string {
+			result, err := repository.GetEmployer(employer.PublicID)
+			assert.Nil(err)
+			return result.RegistrationStep
+		}
+
+		// steps only advance from the step that is current
+		assert.Nil(repository.UpdateEmployerPaymentDetails(employer.PublicID, ""))
+		assert.Equal(accountmanagement.ChangePassword.String(), step())
+
+		_, err := repository.UpdateEmployerPassword(employer.PublicID, employer.Password, "new-password")
+		assert.Nil(err)
+		assert.Equal(accountmanagement.PersonalInformation.String(), step())
+
+		_, err = repository.UpdateEmployerAccount(employer.PublicID, "", "", "", "", "", "", "", "", "")
+		assert.Nil(err)
+		assert.Equal(accountmanagement.CompanyDetails.String(), step())
+
+		_, err = repository.UpdateEmployerCompany(employer.PublicID, "", "", "", "", "", "", "", "", "", "", 0, 0, 0)
+		assert.Nil(err)
+		assert.Equal(accountmanagement.PaymentMethod.String(), step())
+
+		assert.Nil(repository.UpdateEmployerPaymentMethod(employer.PublicID, "card"))
+		assert.Equal(accountmanagement.PaymentDetails.String(), step())
+
+		assert.Nil(repository.UpdateEmployerPaymentDetails(employer.PublicID, "details"))
+		assert.Equal(accountmanagement.RegistrationComplete.String(), step())
+
+		assert.Nil(repository.UpdateEmployerPaymentDetails(employer.PublicID, "details"))
+		assert.Equal(accountmanagement.RegistrationComplete.String(), step())
+	})
+
+	t.Run("UpdateEmployerPaymentMethod_Unknown", func(t *testing.T) {
+		assert.NotNil(t, repository.UpdateEmployerPaymentMethod("00000000-0000-0000-0000-000000000000", "card"))
+	})
+
+	t.Run("SetEmployerCompany", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+
+		result, err := repository.GetEmployerCompany(employer.PublicID)
+
+		assert.Nil(err)
+		assert.Equal(company.PublicID, result.PublicID)
+		assert.Equal(company.Domain, result.Domain)
+
+		assert.NotNil(repository.SetEmployerCompany("", company.PublicID))
+		assert.NotNil(repository.SetEmployerCompany(employer.PublicID, ""))
+		assert.NotNil(repository.SetEmployerCompany(employer.PublicID, "00000000-0000-0000-0000-000000000000"))
+	})
+
+	t.Run("GetEmployerCompany_NoCompany", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		result, err := repository.GetEmployerCompany(employer.PublicID)
+
+		assert.NotNil(err)
+		assert.Nil(result)
+	})
+
+	t.Run("UpdateEmployerAccount_KeepsOmittedFields", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		_, err := repository.UpdateEmployerAccount(employer.PublicID, "", "", "", "", "", "", "fb", "tw", "")
+		assert.Nil(err)
+
+		result, err := repository.UpdateEmployerAccount(employer.PublicID, "", "", "", "", "", "", "", "tw2", "")
+
+		assert.Nil(err)
+		assert.Equal("fb", result.Facebook)
+		assert.Equal("tw2", result.Twitter)
+	})
+
+	t.Run("ReplaceEmployerAccount", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		_, err := repository.UpdateEmployerAccount(employer.PublicID, "", "", "", "555-0100", "", "Recruiter", "fb", "", "")
+		assert.Nil(err)
+
+		current, err := repository.GetEmployer(employer.PublicID)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		account := current.Account()
+		account.PhoneNumber = ""
+		account.Facebook = ""
+
+		result, err := repository.ReplaceEmployerAccount(employer.PublicID, account)
+
+		assert.Nil(err)
+		assert.Equal("", result.PhoneNumber)
+		assert.Equal("Recruiter", result.Role)
+
+		stored, err := repository.GetEmployer(employer.PublicID)
+
+		assert.Nil(err)
+		assert.Equal("", stored.PhoneNumber)
+		assert.Equal("", stored.Facebook)
+		assert.Equal("Recruiter", stored.Role)
+
+		account.Email = ""
+		_, err = repository.ReplaceEmployerAccount(employer.PublicID, account)
+		assert.NotNil(err, "an employer always has an email")
+	})
+
+	t.Run("ReplaceEmployerCompany", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+
+		profile := company.Profile()
+		profile.Logo = "logo.png"
+		profile.Longitude = -79.9
+		profile.City = "Pittsburgh"
+		profile.County = "Allegheny"
+
+		located, err := repository.ReplaceEmployerCompany(employer.PublicID, profile, 0)
+		assert.Nil(err)
+
+		if assert.NotNil(located) {
+			assert.Equal("Pittsburgh", located.City)
+			assert.Equal("Allegheny", located.County)
+		}
+
+		profile.Logo = ""
+		profile.Longitude = 0
+
+		result, err := repository.ReplaceEmployerCompany(employer.PublicID, profile, 2)
+
+		assert.Nil(err)
+		assert.Equal(3, result.Version)
+
+		stored, err := repository.GetEmployerCompany(employer.PublicID)
+
+		assert.Nil(err)
+		assert.Equal("", stored.Logo)
+		assert.Equal(float64(0), stored.Longitude)
+		assert.Equal(company.Name, stored.Name)
+
+		_, err = repository.ReplaceEmployerCompany(employer.PublicID, profile, 2)
+		assert.Equal(companies.ErrStale, err)
+	})
+
+	t.Run("SetEmployerCompanyLogo", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+
+		variants := map[string]string{"logo": "https://cdn.example.com/logo.png", "thumbnail": "https://cdn.example.com/thumbnail.png"}
+
+		result, err := repository.SetEmployerCompanyLogo(employer.PublicID, variants["logo"], variants)
+
+		assert.Nil(err)
+		assert.Equal(variants["logo"], result.Logo)
+		assert.Equal(variants, result.LogoVariants)
+		assert.Equal(company.Version+1, result.Version)
+
+		// replacing the profile keeps the variants of the same logo
+		profile := result.Profile()
+		profile.Description = "About us"
+
+		result, err = repository.ReplaceEmployerCompany(employer.PublicID, profile, 0)
+
+		assert.Nil(err)
+		assert.Equal(variants, result.LogoVariants)
+
+		// and drops them when the logo changes
+		profile.Logo = "https://example.com/other.png"
+
+		result, err = repository.ReplaceEmployerCompany(employer.PublicID, profile, 0)
+
+		assert.Nil(err)
+		assert.Empty(result.LogoVariants)
+
+		stored, err := repository.GetEmployerCompany(employer.PublicID)
+
+		assert.Nil(err)
+		assert.Empty(stored.LogoVariants)
+
+		_, err = repository.SetEmployerCompanyLogo(createEmployer(t, repositories).PublicID, "logo.png", nil)
+		assert.Equal(companies.ErrNotFound, err)
+	})
+
+	t.Run("UpdateEmployerCompany", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+
+		_, err := repository.UpdateEmployerCompany(employer.PublicID, "", "", "", "", "", "", "About us", "logo.png", "", "", 0, 0, 0)
+		assert.Nil(err)
+
+		result, err := repository.UpdateEmployerCompany(employer.PublicID, "Renamed", "Pittsburgh, PA", "", "", "", "", "", "", "", "15218", -79.9, 40.4, 0)
+
+		assert.Nil(err)
+		assert.Equal(company.PublicID, result.PublicID)
+		assert.Equal("Renamed", result.Name)
+		assert.Equal("About us", result.Description)
+		assert.Equal("logo.png", result.Logo)
+		assert.Equal("15218", result.Zipcode)
+
+		stored, err := repository.GetEmployerCompany(employer.PublicID)
+
+		assert.Nil(err)
+		assert.Equal("Renamed", stored.Name)
+		assert.Equal("About us", stored.Description)
+		assert.Equal("logo.png", stored.Logo)
+		assert.Equal(40.4, stored.Latitude)
+	})
+
+	t.Run("UpdateEmployerCompany_Stale", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+
+		assert.Equal(1, company.Version)
+
+		result, err := repository.UpdateEmployerCompany(employer.PublicID, "First", "", "", "", "", "", "", "", "", "", 0, 0, company.Version)
+
+		assert.Nil(err)
+		assert.Equal(2, result.Version)
+
+		result, err = repository.UpdateEmployerCompany(employer.PublicID, "Second", "", "", "", "", "", "", "", "", "", 0, 0, company.Version)
+
+		assert.Equal(companies.ErrStale, err)
+		assert.Nil(result)
+
+		stored, err := repository.GetEmployerCompany(employer.PublicID)
+
+		assert.Nil(err)
+		assert.Equal("First", stored.Name)
+		assert.Equal(2, stored.Version)
+	})
+}
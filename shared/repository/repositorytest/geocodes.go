@@ -0,0 +1,75 @@
+package repositorytest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/geocodes"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// GeocodeRepository is the contract for geocodes.GeocodeRepository
+func GeocodeRepository(t *testing.T, factory Factory) {
+
+	repositories := factory(t)
+	repository := repositories.Geocodes
+
+	t.Run("PutGeocode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		key := "zipcode:" + randomString()
+		now := time.Now()
+
+		_, err := repository.GetGeocode(key, now)
+		assert.Equal(geocodes.ErrNotFound, err)
+
+		assert.Nil(repository.PutGeocode(key, json.RawMessage(`{"city":"Springfield"}`), now.Add(time.Hour)))
+
+		geocode, err := repository.GetGeocode(key, now)
+
+		if assert.Nil(err) {
+			assert.Equal(key, geocode.Key)
+			assert.JSONEq(`{"city":"Springfield"}`, string(geocode.Value))
+			assert.WithinDuration(now.Add(time.Hour), geocode.ExpiresAt, time.Second)
+		}
+
+		// a later answer replaces the cached one
+		assert.Nil(repository.PutGeocode(key, json.RawMessage(`{"city":"Shelbyville"}`), now.Add(2*time.Hour)))
+
+		geocode, err = repository.GetGeocode(key, now)
+
+		if assert.Nil(err) {
+			assert.JSONEq(`{"city":"Shelbyville"}`, string(geocode.Value))
+		}
+
+		_, err = repository.GetGeocode(key, now.Add(3*time.Hour))
+		assert.Equal(geocodes.ErrNotFound, err)
+
+		assert.NotNil(repository.PutGeocode("", json.RawMessage(`{}`), now))
+		assert.NotNil(repository.PutGeocode(key, nil, now))
+	})
+
+	t.Run("PurgeExpiredGeocodes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		now := time.Now()
+		expired := "zipcode:" + randomString()
+		fresh := "zipcode:" + randomString()
+
+		assert.Nil(repository.PutGeocode(expired, json.RawMessage(`[]`), now.Add(-time.Minute)))
+		assert.Nil(repository.PutGeocode(fresh, json.RawMessage(`[]`), now.Add(time.Hour)))
+
+		purged, err := repository.PurgeExpiredGeocodes(now)
+		assert.Nil(err)
+		assert.True(purged >= 1)
+
+		_, err = repository.GetGeocode(fresh, now)
+		assert.Nil(err)
+
+		// purged, so not found even by a clock that runs behind
+		_, err = repository.GetGeocode(expired, now.Add(-time.Hour))
+		assert.Equal(geocodes.ErrNotFound, err)
+	})
+}
@@ -0,0 +1,50 @@
+package repositorytest
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// JobPackageRepository is the contract for jobpackages.JobPackageRepository
+func JobPackageRepository(t *testing.T, factory Factory) {
+
+	repositories := factory(t)
+	repository := repositories.JobPackages
+
+	active := repositories.AddJobPackage(t, &jobpackages.JobPackage{TypeID: randomString(), IsActive: true, Title: "Three jobs", NumberOfJobs: 3, Description: "Three postings", Price: 100})
+	inactive := repositories.AddJobPackage(t, &jobpackages.JobPackage{TypeID: randomString(), IsActive: false, Title: "Retired", NumberOfJobs: 1, Description: "No longer sold", Price: 50})
+
+	t.Run("GetActiveJobPackages", func(t *testing.T) {
+		assert := assert.New(t)
+
+		result, err := repository.GetActiveJobPackages()
+
+		assert.Nil(err)
+
+		found := map[string]bool{}
+		for _, pack := range result {
+			assert.True(pack.IsActive)
+			found[pack.TypeID] = true
+		}
+
+		assert.True(found[active.TypeID])
+		assert.False(found[inactive.TypeID])
+	})
+
+	t.Run("GetJobPackage", func(t *testing.T) {
+		assert := assert.New(t)
+
+		result, err := repository.GetJobPackage(active.TypeID)
+
+		assert.Nil(err)
+		assert.Equal(active.Title, result.Title)
+		assert.Equal(3, result.NumberOfJobs)
+		assert.Equal(100.0, result.Price)
+
+		_, err = repository.GetJobPackage(randomString())
+		assert.NotNil(err)
+	})
+}
@@ -0,0 +1,508 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// JobRepository is the contract for jobs.JobRepository
+func JobRepository(t *testing.T, factory Factory) {
+
+	repositories := factory(t)
+	repository := repositories.Jobs
+
+	t.Run("EmployerCreateJob", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		job := createJob(t, repositories, employer.PublicID)
+
+		assert.NotEqual("", job.PublicID)
+		assert.Equal("Senior Engineer", job.Title)
+		assert.Equal(employer.PublicID, job.EmployerPublicID)
+		assert.Equal(int64(100000), job.MinSalary)
+		assert.Equal("yearly", job.PayPeriod)
+		assert.True(job.Remote)
+	})
+
+	t.Run("EmployerCreateJob_NoTitle", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		job, err := repository.EmployerCreateJob(employer.PublicID, "", "", "", "", "", "", false, 0, 0)
+
+		assert.NotNil(err)
+		assert.Nil(job)
+	})
+
+	t.Run("CreateJob", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+
+		content := jobs.RevisionContent{Title: "Welder", JobType: "full-time", PayPeriod: "hourly", Location: "Pittsburgh, PA", ZipCode: "15222",
+			Latitude: 40.4495, Longitude: -79.9931, City: "Pittsburgh", State: "PA", County: "Allegheny", Country: "US"}
+
+		job, err := repository.CreateJob(employer.PublicID, content)
+
+		if assert.Nil(err) {
+			assert.Equal(content, job.Content())
+			assert.Equal(1, job.Revision)
+		}
+
+		revision, err := repository.GetJobRevision(employer.PublicID, job.PublicID, 1)
+
+		if assert.Nil(err) {
+			assert.Equal(content, revision.Content)
+		}
+
+		_, err = repository.CreateJob(employer.PublicID, jobs.RevisionContent{})
+		assert.NotNil(err)
+	})
+
+	t.Run("GetJob", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		result, err := repository.GetJob(job.PublicID)
+
+		assert.Nil(err)
+		assert.Equal(job.PublicID, result.PublicID)
+		assert.Equal(job.Description, result.Description)
+		assert.Equal(employer.PublicID, result.EmployerPublicID)
+
+		_, err = repository.GetJob("")
+		assert.NotNil(err)
+
+		_, err = repository.GetJob("00000000-0000-0000-0000-000000000000")
+		assert.NotNil(err)
+	})
+
+	t.Run("GetEmployerJobs", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		other := createEmployer(t, repositories)
+
+		createJob(t, repositories, employer.PublicID)
+		createJob(t, repositories, employer.PublicID)
+		createJob(t, repositories, other.PublicID)
+
+		result, err := repository.GetEmployerJobs(employer.PublicID, false)
+
+		assert.Nil(err)
+		assert.Equal(2, len(result))
+
+		for _, job := range result {
+			assert.Equal(employer.PublicID, job.EmployerPublicID)
+		}
+
+		_, err = repository.GetEmployerJobs("", false)
+		assert.NotNil(err)
+	})
+
+	t.Run("EditJob", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		result, err := repository.EditJob(employer.PublicID, job.PublicID, "Staff Engineer", "", "", "", "", "monthly", false, 0, 200000, 0)
+
+		assert.Nil(err)
+		assert.Equal("Staff Engineer", result.Title)
+		assert.Equal(job.Description, result.Description)
+		assert.Equal("monthly", result.PayPeriod)
+		assert.Equal(int64(100000), result.MinSalary)
+		assert.Equal(int64(200000), result.MaxSalary)
+		assert.False(result.Remote)
+
+		stored, err := repository.GetJob(job.PublicID)
+
+		assert.Nil(err)
+		assert.Equal("Staff Engineer", stored.Title)
+		assert.Equal("monthly", stored.PayPeriod)
+	})
+
+	t.Run("EditJob_NotOwner", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		other := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		result, err := repository.EditJob(other.PublicID, job.PublicID, "Hijacked", "", "", "", "", "", false, 0, 0, 0)
+
+		assert.NotNil(err)
+		assert.Nil(result)
+
+		stored, err := repository.GetJob(job.PublicID)
+
+		assert.Nil(err)
+		assert.Equal(job.Title, stored.Title)
+	})
+
+	t.Run("EditJob_Stale", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		result, err := repository.EditJob(employer.PublicID, job.PublicID, "First", "", "", "", "", "", true, 0, 0, job.Revision)
+
+		assert.Nil(err)
+		assert.Equal(job.Revision+1, result.Revision)
+
+		result, err = repository.EditJob(employer.PublicID, job.PublicID, "Second", "", "", "", "", "", true, 0, 0, job.Revision)
+
+		assert.Equal(jobs.ErrStale, err)
+		assert.Nil(result)
+
+		stored, err := repository.GetJob(job.PublicID)
+
+		assert.Nil(err)
+		assert.Equal("First", stored.Title)
+	})
+
+	t.Run("ReplaceJob", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		other := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		content := job.Content()
+		content.MinSalary = 0
+		content.Category = ""
+		content.Remote = false
+
+		_, err := repository.ReplaceJob(other.PublicID, job.PublicID, content, 0)
+		assert.Equal(jobs.ErrNotFound, err)
+
+		result, err := repository.ReplaceJob(employer.PublicID, job.PublicID, content, job.Revision)
+
+		assert.Nil(err)
+
+		if assert.NotNil(result) {
+			assert.Equal(job.Revision+1, result.Revision)
+		}
+
+		stored, err := repository.GetJob(job.PublicID)
+
+		assert.Nil(err)
+		assert.Equal(content, stored.Content())
+
+		content.Title = ""
+		_, err = repository.ReplaceJob(employer.PublicID, job.PublicID, content, 0)
+		assert.NotNil(err, "a job always has a title")
+	})
+
+	t.Run("GetJobRevisions", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		other := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		assert.Equal(1, job.Revision)
+
+		edited, err := repository.EditJob(employer.PublicID, job.PublicID, "Staff Engineer", "", "", "", "", "", true, 0, 0, 0)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(2, edited.Revision)
+
+		revisions, err := repository.GetJobRevisions(employer.PublicID, job.PublicID)
+
+		assert.Nil(err)
+
+		if assert.Len(revisions, 2) {
+			assert.Equal(2, revisions[0].Number)
+			assert.Equal("Staff Engineer", revisions[0].Content.Title)
+			assert.Equal(employer.PublicID, revisions[0].EditorPublicID)
+			assert.Equal(1, revisions[1].Number)
+			assert.Equal(job.Content(), revisions[1].Content)
+		}
+
+		revision, err := repository.GetJobRevision(employer.PublicID, job.PublicID, 1)
+
+		assert.Nil(err)
+
+		if assert.NotNil(revision) {
+			assert.Equal(job.Title, revision.Content.Title)
+		}
+
+		_, err = repository.GetJobRevision(employer.PublicID, job.PublicID, 3)
+		assert.Equal(jobs.ErrRevisionNotFound, err)
+
+		_, err = repository.GetJobRevisions(other.PublicID, job.PublicID)
+		assert.Equal(jobs.ErrNotFound, err)
+
+		_, err = repository.GetJobRevision(other.PublicID, job.PublicID, 1)
+		assert.Equal(jobs.ErrRevisionNotFound, err)
+	})
+
+	t.Run("RollbackJob", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		other := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		if _, err := repository.EditJob(employer.PublicID, job.PublicID, "Staff Engineer", "", "", "Lead things", "", "monthly", true, 0, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := repository.RollbackJob(other.PublicID, job.PublicID, 1)
+		assert.Equal(jobs.ErrRevisionNotFound, err)
+
+		_, err = repository.RollbackJob(employer.PublicID, job.PublicID, 5)
+		assert.Equal(jobs.ErrRevisionNotFound, err)
+
+		rolledBack, err := repository.RollbackJob(employer.PublicID, job.PublicID, 1)
+
+		assert.Nil(err)
+
+		if assert.NotNil(rolledBack) {
+			assert.Equal(3, rolledBack.Revision)
+			assert.Equal(job.Content(), rolledBack.Content())
+		}
+
+		stored, err := repository.GetJob(job.PublicID)
+
+		assert.Nil(err)
+		assert.Equal(job.Title, stored.Title)
+		assert.Equal(job.PayPeriod, stored.PayPeriod)
+
+		revisions, err := repository.GetJobRevisions(employer.PublicID, job.PublicID)
+
+		assert.Nil(err)
+		assert.Len(revisions, 3, "a rollback is saved as a new revision")
+	})
+
+	t.Run("DeleteJob", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		result, err := repository.DeleteJob(employer.PublicID, job.PublicID)
+
+		assert.Nil(err)
+		assert.Equal(job.PublicID, result.PublicID)
+		assert.Equal(job.Title, result.Title)
+
+		_, err = repository.GetJob(job.PublicID)
+		assert.NotNil(err)
+	})
+
+	t.Run("DeleteJob_IsSoft", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+		kept := createJob(t, repositories, employer.PublicID)
+
+		result, err := repository.DeleteJob(employer.PublicID, job.PublicID)
+
+		assert.Nil(err)
+		assert.NotEqual("", result.DeletedAt)
+
+		employerJobs, err := repository.GetEmployerJobs(employer.PublicID, false)
+
+		assert.Nil(err)
+
+		if assert.Len(employerJobs, 1) {
+			assert.Equal(kept.PublicID, employerJobs[0].PublicID)
+			assert.Equal("", employerJobs[0].DeletedAt)
+		}
+
+		// unless deleted jobs are asked for
+		employerJobs, err = repository.GetEmployerJobs(employer.PublicID, true)
+
+		assert.Nil(err)
+
+		if assert.Len(employerJobs, 2) {
+			deletedAt := map[string]string{}
+
+			for _, employerJob := range employerJobs {
+				deletedAt[employerJob.PublicID] = employerJob.DeletedAt
+			}
+
+			assert.Equal("", deletedAt[kept.PublicID])
+			assert.NotEqual("", deletedAt[job.PublicID])
+		}
+
+		_, err = repository.EditJob(employer.PublicID, job.PublicID, "Changed", "", "", "", "", "", false, 0, 0, 0)
+		assert.NotNil(err)
+
+		// deleting twice fails
+		_, err = repository.DeleteJob(employer.PublicID, job.PublicID)
+		assert.NotNil(err)
+	})
+
+	t.Run("RestoreJob", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		other := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		_, err := repository.RestoreJob(employer.PublicID, job.PublicID, time.Now().Add(-time.Hour))
+		assert.Equal(jobs.ErrNotFound, err, "a job that is not deleted cannot be restored")
+
+		if _, err := repository.DeleteJob(employer.PublicID, job.PublicID); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = repository.RestoreJob(other.PublicID, job.PublicID, time.Now().Add(-time.Hour))
+		assert.Equal(jobs.ErrNotFound, err)
+
+		_, err = repository.RestoreJob(employer.PublicID, job.PublicID, time.Now().Add(time.Hour))
+		assert.Equal(jobs.ErrNotFound, err, "the retention window has passed")
+
+		restored, err := repository.RestoreJob(employer.PublicID, job.PublicID, time.Now().Add(-time.Hour))
+
+		assert.Nil(err)
+
+		if assert.NotNil(restored) {
+			assert.Equal(job.Title, restored.Title)
+			assert.Equal("", restored.DeletedAt)
+		}
+
+		_, err = repository.GetJob(job.PublicID)
+		assert.Nil(err)
+	})
+
+	t.Run("PurgeDeletedJobs", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+		kept := createJob(t, repositories, employer.PublicID)
+
+		if _, err := repository.DeleteJob(employer.PublicID, job.PublicID); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := repository.PurgeDeletedJobs(time.Now().Add(-time.Hour))
+
+		assert.Nil(err)
+
+		_, err = repository.RestoreJob(employer.PublicID, job.PublicID, time.Now().Add(-2*time.Hour))
+		assert.Nil(err, "only jobs deleted before the cutoff are purged")
+
+		if _, err := repository.DeleteJob(employer.PublicID, job.PublicID); err != nil {
+			t.Fatal(err)
+		}
+
+		purged, err := repository.PurgeDeletedJobs(time.Now().Add(time.Second))
+
+		assert.Nil(err)
+		assert.True(purged >= 1)
+
+		_, err = repository.RestoreJob(employer.PublicID, job.PublicID, time.Now().Add(-time.Hour))
+		assert.Equal(jobs.ErrNotFound, err)
+
+		_, err = repository.GetJob(kept.PublicID)
+		assert.Nil(err)
+	})
+
+	t.Run("DeleteJob_NotOwner", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		other := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		result, err := repository.DeleteJob(other.PublicID, job.PublicID)
+
+		assert.NotNil(err)
+		assert.Nil(result)
+
+		_, err = repository.GetJob(job.PublicID)
+		assert.Nil(err)
+	})
+
+	t.Run("GoLive", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		visibleDate := time.Now().Add(time.Hour).Format(time.RFC3339)
+		scheduled, err := repository.EmployerCreateJob(employer.PublicID, "Scheduled", "", "", "", visibleDate, "", false, 0, 0)
+		assert.Nil(err)
+
+		contains := func(changed []*jobs.Job, publicID string) bool {
+			for _, candidate := range changed {
+				if candidate.PublicID == publicID {
+					return true
+				}
+			}
+			return false
+		}
+
+		live, err := repository.GoLive(time.Now().Add(time.Second))
+
+		assert.Nil(err)
+		assert.True(contains(live, job.PublicID))
+		assert.False(contains(live, scheduled.PublicID))
+
+		live, err = repository.GoLive(time.Now().Add(2 * time.Hour))
+
+		assert.Nil(err)
+		assert.False(contains(live, job.PublicID))
+		assert.True(contains(live, scheduled.PublicID))
+	})
+
+	t.Run("ExpireJobs", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		job := createJob(t, repositories, employer.PublicID)
+
+		result, err := repository.GetJob(job.PublicID)
+
+		if assert.Nil(err) {
+			assert.NotEqual("", result.ExpiresAt)
+			assert.False(result.Expired)
+		}
+
+		expiresAt, err := time.Parse(time.RFC3339, result.ExpiresAt)
+		assert.Nil(err)
+
+		expired, err := repository.ExpireJobs(expiresAt.Add(time.Second))
+		assert.Nil(err)
+
+		found := false
+		for _, candidate := range expired {
+			found = found || candidate.PublicID == job.PublicID
+		}
+		assert.True(found)
+
+		result, err = repository.GetJob(job.PublicID)
+
+		if assert.Nil(err) {
+			assert.True(result.Expired)
+		}
+
+		// already expired
+		expired, err = repository.ExpireJobs(expiresAt.Add(time.Second))
+		assert.Nil(err)
+
+		for _, candidate := range expired {
+			assert.NotEqual(job.PublicID, candidate.PublicID)
+		}
+	})
+}
@@ -0,0 +1,224 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// QueueRepository is the contract for queue.QueueRepository
+func QueueRepository(t *testing.T, factory Factory) {
+
+	repositories := factory(t)
+	repository := repositories.Queue
+
+	// every task gets its own kind, so rows left by other runs against a
+	// shared database do not affect the assertions
+	enqueue := func(t *testing.T) *queue.Task {
+		task, err := repository.Enqueue("test-"+randomString(), map[string]string{"value": randomString()})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return task
+	}
+
+	// claim returns task if it was claimed, nil otherwise. Other due tasks
+	// may be claimed along with it.
+	claim := func(t *testing.T, task *queue.Task) *queue.Task {
+		claimed, err := repository.Claim(1000, time.Minute)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, candidate := range claimed {
+			if candidate.ID == task.ID {
+				return candidate
+			}
+		}
+
+		return nil
+	}
+
+	t.Run("Enqueue", func(t *testing.T) {
+		assert := assert.New(t)
+
+		task := enqueue(t)
+
+		assert.NotEqual(int64(0), task.ID)
+		assert.Equal(queue.Pending, task.Status)
+		assert.Contains(string(task.Payload), `"value"`)
+
+		_, err := repository.Enqueue("", nil)
+		assert.NotNil(err)
+	})
+
+	t.Run("Schedule", func(t *testing.T) {
+		assert := assert.New(t)
+
+		key := randomString()
+
+		task, err := repository.Schedule("test", nil, time.Now().Add(time.Hour), key)
+
+		assert.Nil(err)
+		assert.Nil(claim(t, task))
+
+		_, err = repository.Schedule("test", nil, time.Time{}, key)
+		assert.Equal(queue.ErrDuplicate, err)
+	})
+
+	t.Run("Claim", func(t *testing.T) {
+		assert := assert.New(t)
+
+		task := enqueue(t)
+
+		claimed := claim(t, task)
+
+		if assert.NotNil(claimed) {
+			assert.Equal(queue.Running, claimed.Status)
+			assert.Equal(1, claimed.Attempts)
+			assert.JSONEq(string(task.Payload), string(claimed.Payload))
+		}
+
+		// leased
+		assert.Nil(claim(t, task))
+	})
+
+	t.Run("Claim_ExpiredLease", func(t *testing.T) {
+		assert := assert.New(t)
+
+		task := enqueue(t)
+
+		claimed, err := repository.Claim(1000, -time.Second)
+		assert.Nil(err)
+		assert.NotEmpty(claimed)
+
+		reclaimed := claim(t, task)
+
+		if assert.NotNil(reclaimed) {
+			assert.Equal(2, reclaimed.Attempts)
+		}
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		assert := assert.New(t)
+
+		task := enqueue(t)
+		claim(t, task)
+
+		assert.Nil(repository.Retry(task.ID, "unreachable", time.Now().Add(-time.Second)))
+
+		claimed := claim(t, task)
+
+		if assert.NotNil(claimed) {
+			assert.Equal(2, claimed.Attempts)
+			assert.Equal("unreachable", claimed.LastError)
+		}
+
+		assert.Nil(repository.Retry(task.ID, "unreachable", time.Now().Add(time.Hour)))
+		assert.Nil(claim(t, task))
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		assert := assert.New(t)
+
+		task := enqueue(t)
+		claim(t, task)
+
+		assert.Nil(repository.Complete(task.ID))
+		assert.Nil(claim(t, task))
+
+		result, err := repository.GetTask(task.ID)
+
+		if assert.Nil(err) {
+			assert.Equal(queue.Done, result.Status)
+			assert.NotNil(result.FinishedAt)
+			assert.Equal(0, len(result.Payload))
+		}
+
+		// only running tasks can be completed
+		assert.Equal(queue.ErrNotFound, repository.Complete(task.ID))
+	})
+
+	t.Run("Bury_Requeue", func(t *testing.T) {
+		assert := assert.New(t)
+
+		task := enqueue(t)
+		claim(t, task)
+
+		assert.Nil(repository.Bury(task.ID, "rejected"))
+		assert.Nil(claim(t, task))
+
+		dead, err := repository.GetTasks(queue.Dead, 1000)
+		assert.Nil(err)
+
+		found := false
+		for _, candidate := range dead {
+			found = found || candidate.ID == task.ID
+		}
+		assert.True(found)
+
+		assert.Nil(repository.Requeue(task.ID))
+
+		claimed := claim(t, task)
+
+		if assert.NotNil(claimed) {
+			assert.Equal(1, claimed.Attempts)
+			assert.Equal("rejected", claimed.LastError)
+		}
+
+		// only dead tasks can be requeued
+		assert.Equal(queue.ErrNotFound, repository.Requeue(task.ID))
+	})
+
+	t.Run("GetStats", func(t *testing.T) {
+		assert := assert.New(t)
+
+		task := enqueue(t)
+
+		stats, err := repository.GetStats()
+		assert.Nil(err)
+
+		count := 0
+		for _, row := range stats {
+			if row.Kind == task.Kind && row.Status == queue.Pending {
+				count = row.Count
+			}
+		}
+
+		assert.Equal(1, count)
+	})
+
+	t.Run("DeleteFinished", func(t *testing.T) {
+		assert := assert.New(t)
+
+		task := enqueue(t)
+		claim(t, task)
+		assert.Nil(repository.Complete(task.ID))
+
+		deleted, err := repository.DeleteFinished(time.Now().Add(time.Minute))
+
+		assert.Nil(err)
+		assert.True(deleted >= 1)
+
+		_, err = repository.GetTask(task.ID)
+		assert.Equal(queue.ErrNotFound, err)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Equal(queue.ErrNotFound, repository.Complete(-1))
+		assert.Equal(queue.ErrNotFound, repository.Retry(-1, "", time.Now()))
+		assert.Equal(queue.ErrNotFound, repository.Bury(-1, ""))
+		assert.Equal(queue.ErrNotFound, repository.Requeue(-1))
+
+		_, err := repository.GetTask(-1)
+		assert.Equal(queue.ErrNotFound, err)
+	})
+}
@@ -0,0 +1,115 @@
+// Package repositorytest is the contract every repository implementation must
+// satisfy. The Postgres repository tests and the memory package tests both run
+// these suites, so the in-memory fakes cannot drift from production semantics.
+package repositorytest
+
+import (
+	"fmt"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/attachments"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/geocodes"
+	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+	"autumnomous-jobs-employer-api/shared/services/security/encryption"
+)
+
+// Repositories is one implementation of every repository, sharing storage
+type Repositories struct {
+	Employers   accountmanagement.EmployerRepository
+	Companies   companies.CompanyRepository
+	Jobs        jobs.JobRepository
+	JobPackages jobpackages.JobPackageRepository
+	Queue       queue.QueueRepository
+	Webhooks    webhooks.WebhookRepository
+	Audit       audit.AuditRepository
+	Uploads     uploads.UploadRepository
+	Attachments attachments.AttachmentRepository
+	Geocodes    geocodes.GeocodeRepository
+	UnitOfWork  transaction.UnitOfWork
+
+	// AddJobPackage seeds a job package, JobPackageRepository is read only
+	AddJobPackage func(t *testing.T, pack *jobpackages.JobPackage) *jobpackages.JobPackage
+}
+
+// Factory returns the repositories under test. It is called once per suite.
+type Factory func(t *testing.T) *Repositories
+
+// Run runs every suite against factory
+func Run(t *testing.T, factory Factory) {
+	t.Run("EmployerRepository", func(t *testing.T) { EmployerRepository(t, factory) })
+	t.Run("CompanyRepository", func(t *testing.T) { CompanyRepository(t, factory) })
+	t.Run("JobRepository", func(t *testing.T) { JobRepository(t, factory) })
+	t.Run("JobPackageRepository", func(t *testing.T) { JobPackageRepository(t, factory) })
+	t.Run("QueueRepository", func(t *testing.T) { QueueRepository(t, factory) })
+	t.Run("WebhookRepository", func(t *testing.T) { WebhookRepository(t, factory) })
+	t.Run("AuditRepository", func(t *testing.T) { AuditRepository(t, factory) })
+	t.Run("UploadRepository", func(t *testing.T) { UploadRepository(t, factory) })
+	t.Run("AttachmentRepository", func(t *testing.T) { AttachmentRepository(t, factory) })
+	t.Run("GeocodeRepository", func(t *testing.T) { GeocodeRepository(t, factory) })
+	t.Run("UnitOfWork", func(t *testing.T) { UnitOfWork(t, factory) })
+}
+
+// randomString returns a value unlikely to collide with rows from other runs
+// against the same database
+func randomString() string {
+	return fmt.Sprintf("%x", encryption.GeneratePassword(6))
+}
+
+type testEmployer struct {
+	*accountmanagement.Employer
+	Password string
+}
+
+func createEmployer(t *testing.T, repositories *Repositories) *testEmployer {
+
+	password := randomString()
+	hashedPassword, err := encryption.HashPassword([]byte(password))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	employer, err := repositories.Employers.CreateEmployer("First", "Last", fmt.Sprintf("employer-%s@%s.com", randomString(), randomString()), string(hashedPassword))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testEmployer{Employer: employer, Password: password}
+}
+
+func createEmployerWithCompany(t *testing.T, repositories *Repositories) (*testEmployer, *companies.Company) {
+
+	employer := createEmployer(t, repositories)
+
+	company, err := repositories.Companies.GetOrCreateCompany(randomString()+".com", "Company", "", "", "", "", "", "", "", "", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repositories.Employers.SetEmployerCompany(employer.PublicID, company.PublicID); err != nil {
+		t.Fatal(err)
+	}
+
+	return employer, company
+}
+
+func createJob(t *testing.T, repositories *Repositories, employerPublicID string) *jobs.Job {
+
+	job, err := repositories.Jobs.EmployerCreateJob(employerPublicID, "Senior Engineer", "full-time", "engineering", "Build things", "", "yearly", true, 100000, 150000)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return job
+}
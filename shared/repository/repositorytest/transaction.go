@@ -0,0 +1,86 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// UnitOfWork is the contract for transaction.UnitOfWork
+func UnitOfWork(t *testing.T, factory Factory) {
+
+	repositories := factory(t)
+	unit := repositories.UnitOfWork
+
+	t.Run("Commit", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var employerPublicID string
+
+		err := unit.Do(context.Background(), func(tx *transaction.Repositories) error {
+
+			employer, err := tx.Employers.CreateEmployer("First", "Last", fmt.Sprintf("employer-%s@%s.com", randomString(), randomString()), "password")
+
+			if err != nil {
+				return err
+			}
+
+			company, err := tx.Companies.GetOrCreateCompany(randomString()+".com", "", "", "", "", "", "", "", "", "", "")
+
+			if err != nil {
+				return err
+			}
+
+			employerPublicID = employer.PublicID
+
+			return tx.Employers.SetEmployerCompany(employer.PublicID, company.PublicID)
+		})
+
+		assert.Nil(err)
+
+		_, err = repositories.Employers.GetEmployerCompany(employerPublicID)
+		assert.Nil(err)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		assert := assert.New(t)
+
+		failure := errors.New("failed midway")
+
+		var employerPublicID string
+		var task *queue.Task
+
+		err := unit.Do(context.Background(), func(tx *transaction.Repositories) error {
+
+			employer, err := tx.Employers.CreateEmployer("First", "Last", fmt.Sprintf("employer-%s@%s.com", randomString(), randomString()), "password")
+
+			if err != nil {
+				return err
+			}
+
+			employerPublicID = employer.PublicID
+
+			task, err = tx.Queue.Enqueue("test", employer.PublicID)
+
+			if err != nil {
+				return err
+			}
+
+			return failure
+		})
+
+		assert.Equal(failure, err)
+
+		_, err = repositories.Employers.GetEmployer(employerPublicID)
+		assert.NotNil(err)
+
+		_, err = repositories.Queue.GetTask(task.ID)
+		assert.NotNil(err)
+	})
+}
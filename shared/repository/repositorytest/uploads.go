@@ -0,0 +1,241 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// UploadRepository is the contract for uploads.UploadRepository
+func UploadRepository(t *testing.T, factory Factory) {
+
+	repositories := factory(t)
+	repository := repositories.Uploads
+
+	createUpload := func(t *testing.T, employerPublicID string, expiresAt time.Time) *uploads.Upload {
+		upload, err := repository.CreateUpload(employerPublicID, "uploads/"+randomString()+".png", "logo.png", "image/png", 1024, expiresAt)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return upload
+	}
+
+	t.Run("CreateUpload", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		upload := createUpload(t, employer.PublicID, time.Now().Add(time.Hour))
+
+		assert.NotEqual("", upload.PublicID)
+		assert.Equal(employer.PublicID, upload.EmployerPublicID)
+		assert.Equal("logo.png", upload.FileName)
+		assert.Equal("image/png", upload.ContentType)
+		assert.Equal(int64(1024), upload.Size)
+		assert.Equal(uploads.Pending, upload.Status)
+
+		found, err := repository.GetEmployerUpload(employer.PublicID, upload.PublicID)
+		assert.Nil(err)
+		assert.Equal(upload.Key, found.Key)
+
+		// only the employer who uploaded it sees it
+		_, err = repository.GetEmployerUpload(createEmployer(t, repositories).PublicID, upload.PublicID)
+		assert.Equal(uploads.ErrNotFound, err)
+
+		_, err = repository.CreateUpload(randomString(), "uploads/"+randomString(), "", "image/png", 1, time.Now())
+		assert.NotNil(err)
+
+		_, err = repository.CreateUpload(employer.PublicID, "", "", "image/png", 1, time.Now())
+		assert.NotNil(err)
+	})
+
+	t.Run("CompleteUpload", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		upload := createUpload(t, employer.PublicID, time.Now().Add(time.Hour))
+
+		expiresAt := time.Now().Add(uploads.OrphanRetention).Truncate(time.Second)
+
+		complete, err := repository.CompleteUpload(upload.PublicID, 900, expiresAt)
+
+		if assert.Nil(err) {
+			assert.Equal(uploads.Complete, complete.Status)
+			assert.Equal(int64(900), complete.Size)
+			assert.True(expiresAt.Equal(complete.ExpiresAt))
+		}
+
+		_, err = repository.CompleteUpload(upload.PublicID, 900, expiresAt)
+		assert.Equal(uploads.ErrNotFound, err)
+	})
+
+	t.Run("Quarantine", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		upload := createUpload(t, employer.PublicID, time.Now().Add(time.Hour))
+		expiresAt := time.Now().Add(uploads.QuarantineRetention).Truncate(time.Second)
+
+		quarantined, err := repository.QuarantineUpload(upload.PublicID, "quarantine/"+upload.Key, "Eicar-Test-Signature", expiresAt)
+
+		if assert.Nil(err) {
+			assert.Equal(uploads.Quarantined, quarantined.Status)
+			assert.Equal("quarantine/"+upload.Key, quarantined.Key)
+			assert.Equal("Eicar-Test-Signature", quarantined.ScanResult)
+			assert.True(expiresAt.Equal(quarantined.ExpiresAt))
+		}
+
+		// only a pending upload can be quarantined or completed
+		_, err = repository.QuarantineUpload(upload.PublicID, upload.Key, "", expiresAt)
+		assert.Equal(uploads.ErrNotFound, err)
+
+		_, err = repository.CompleteUpload(upload.PublicID, 1, expiresAt)
+		assert.Equal(uploads.ErrNotFound, err)
+
+		recorded, err := repository.RecordQuarantine("", "quarantine/"+randomString(), "logo.png", "image/png", 10, "too large", expiresAt)
+
+		if assert.Nil(err) {
+			assert.Equal(uploads.Quarantined, recorded.Status)
+			assert.Equal("", recorded.EmployerPublicID)
+			assert.Equal("logo.png", recorded.FileName)
+			assert.Equal("too large", recorded.ScanResult)
+		}
+
+		_, err = repository.RecordQuarantine(randomString(), "quarantine/"+randomString(), "", "image/png", 1, "", expiresAt)
+		assert.NotNil(err)
+	})
+
+	t.Run("RecordUpload", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+		key := "images/" + randomString() + ".png"
+		expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+		upload, err := repository.RecordUpload(employer.PublicID, key, "https://cdn.test/"+key, "image/png", 100, expiresAt)
+
+		if assert.Nil(err) {
+			assert.Equal(employer.PublicID, upload.EmployerPublicID)
+			assert.Equal(company.PublicID, upload.CompanyPublicID)
+			assert.Equal("https://cdn.test/"+key, upload.URL)
+			assert.Equal(uploads.Complete, upload.Status)
+			assert.Equal(0, upload.RefCount)
+		}
+
+		// storing the same content again keeps the owner and extends the expiry
+		again, err := repository.RecordUpload("", key, "https://cdn.test/"+key, "image/png", 100, expiresAt.Add(time.Hour))
+
+		if assert.Nil(err) {
+			assert.Equal(upload.PublicID, again.PublicID)
+			assert.Equal(employer.PublicID, again.EmployerPublicID)
+			assert.True(expiresAt.Add(time.Hour).Equal(again.ExpiresAt))
+		}
+
+		anonymous, err := repository.RecordUpload("", "images/"+randomString()+".png", "", "image/png", 1, expiresAt)
+
+		if assert.Nil(err) {
+			assert.Equal("", anonymous.EmployerPublicID)
+		}
+
+		_, err = repository.RecordUpload(randomString(), "images/"+randomString(), "", "image/png", 1, expiresAt)
+		assert.NotNil(err)
+
+		_, err = repository.RecordUpload(employer.PublicID, "", "", "image/png", 1, expiresAt)
+		assert.NotNil(err)
+
+		result, err := repository.GetEmployerUploads(employer.PublicID)
+
+		if assert.Nil(err) && assert.Len(result, 1) {
+			assert.Equal(upload.PublicID, result[0].PublicID)
+		}
+	})
+
+	t.Run("RetainAndRelease", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		key := "images/" + randomString() + ".png"
+		url := "https://cdn.test/" + key
+
+		upload, err := repository.RecordUpload(employer.PublicID, key, url, "image/png", 1, time.Now().Add(time.Hour))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// a URL listed twice is one use, and unknown URLs are ignored
+		assert.Nil(repository.Retain([]string{url, url, "https://cdn.test/" + randomString(), ""}))
+		assert.Nil(repository.Retain([]string{url}))
+
+		found, _ := repository.GetEmployerUpload(employer.PublicID, upload.PublicID)
+		assert.Equal(2, found.RefCount)
+
+		_, err = repository.DeleteEmployerUpload(employer.PublicID, upload.PublicID)
+		assert.Equal(uploads.ErrInUse, err)
+
+		expiresAt := time.Now().Add(uploads.OrphanRetention).Truncate(time.Second)
+
+		assert.Nil(repository.Release([]string{url}, expiresAt))
+		assert.Nil(repository.Release([]string{url}, expiresAt))
+		assert.Nil(repository.Release([]string{url}, expiresAt))
+
+		found, _ = repository.GetEmployerUpload(employer.PublicID, upload.PublicID)
+		assert.Equal(0, found.RefCount)
+		assert.True(expiresAt.Equal(found.ExpiresAt))
+
+		// only the employer who uploaded it may delete it
+		_, err = repository.DeleteEmployerUpload(createEmployer(t, repositories).PublicID, upload.PublicID)
+		assert.Equal(uploads.ErrNotFound, err)
+
+		deleted, err := repository.DeleteEmployerUpload(employer.PublicID, upload.PublicID)
+
+		if assert.Nil(err) {
+			assert.Equal(key, deleted.Key)
+		}
+
+		_, err = repository.GetEmployerUpload(employer.PublicID, upload.PublicID)
+		assert.Equal(uploads.ErrNotFound, err)
+	})
+
+	t.Run("GetOrphanedUploads", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer := createEmployer(t, repositories)
+		now := time.Now().Add(24 * 365 * time.Hour)
+
+		orphaned := createUpload(t, employer.PublicID, now.Add(-time.Hour))
+		waiting := createUpload(t, employer.PublicID, now.Add(time.Hour))
+
+		key := "images/" + randomString() + ".png"
+		used, err := repository.RecordUpload(employer.PublicID, key, "https://cdn.test/"+key, "image/png", 1, now.Add(-time.Minute))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Nil(repository.Retain([]string{used.URL}))
+
+		result, err := repository.GetOrphanedUploads(now, 1000)
+		assert.Nil(err)
+
+		found := map[string]bool{}
+		for _, upload := range result {
+			found[upload.PublicID] = true
+		}
+
+		assert.True(found[orphaned.PublicID])
+		assert.False(found[waiting.PublicID])
+		assert.False(found[used.PublicID])
+
+		assert.Equal(uploads.ErrNotFound, repository.DeleteOrphanedUpload(used.PublicID, now))
+		assert.Nil(repository.DeleteOrphanedUpload(orphaned.PublicID, now))
+		assert.Equal(uploads.ErrNotFound, repository.DeleteOrphanedUpload(orphaned.PublicID, now))
+
+		_, err = repository.GetEmployerUpload(employer.PublicID, orphaned.PublicID)
+		assert.Equal(uploads.ErrNotFound, err)
+	})
+}
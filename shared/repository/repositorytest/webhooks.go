@@ -0,0 +1,186 @@
+package repositorytest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// WebhookRepository is the contract for webhooks.WebhookRepository
+func WebhookRepository(t *testing.T, factory Factory) {
+
+	repositories := factory(t)
+	repository := repositories.Webhooks
+
+	createWebhook := func(t *testing.T, employerPublicID string, events ...string) *webhooks.Webhook {
+		webhook, err := repository.CreateWebhook(employerPublicID, "https://example.com/hooks", "secret", events)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return webhook
+	}
+
+	t.Run("CreateWebhook", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+
+		webhook := createWebhook(t, employer.PublicID, "job.live", "job.deleted")
+
+		assert.NotEqual("", webhook.PublicID)
+		assert.Equal(company.PublicID, webhook.CompanyPublicID)
+		assert.Equal("secret", webhook.Secret)
+		assert.Equal([]string{"job.live", "job.deleted"}, webhook.Events)
+
+		// an employer without a company cannot subscribe
+		_, err := repository.CreateWebhook(createEmployer(t, repositories).PublicID, "https://example.com", "secret", []string{"job.live"})
+		assert.NotNil(err)
+
+		_, err = repository.CreateWebhook(employer.PublicID, "", "secret", []string{"job.live"})
+		assert.NotNil(err)
+	})
+
+	t.Run("GetWebhooks", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, company := createEmployerWithCompany(t, repositories)
+		colleague := createEmployer(t, repositories)
+
+		if err := repositories.Employers.SetEmployerCompany(colleague.PublicID, company.PublicID); err != nil {
+			t.Fatal(err)
+		}
+
+		webhook := createWebhook(t, employer.PublicID, "job.live")
+
+		// shared by everyone at the company
+		result, err := repository.GetWebhooks(colleague.PublicID)
+
+		assert.Nil(err)
+
+		if assert.Equal(1, len(result)) {
+			assert.Equal(webhook.PublicID, result[0].PublicID)
+		}
+
+		other, _ := createEmployerWithCompany(t, repositories)
+
+		result, err = repository.GetWebhooks(other.PublicID)
+
+		assert.Nil(err)
+		assert.Equal(0, len(result))
+
+		_, err = repository.GetEmployerWebhook(other.PublicID, webhook.PublicID)
+		assert.Equal(webhooks.ErrNotFound, err)
+
+		found, err := repository.GetWebhook(webhook.PublicID)
+
+		if assert.Nil(err) {
+			assert.Equal("secret", found.Secret)
+		}
+	})
+
+	t.Run("GetSubscribedWebhooks", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, _ := createEmployerWithCompany(t, repositories)
+
+		live := createWebhook(t, employer.PublicID, "job.live")
+		createWebhook(t, employer.PublicID, "job.deleted")
+
+		result, err := repository.GetSubscribedWebhooks(employer.PublicID, "job.live")
+
+		assert.Nil(err)
+
+		if assert.Equal(1, len(result)) {
+			assert.Equal(live.PublicID, result[0].PublicID)
+		}
+	})
+
+	t.Run("DeleteWebhook", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, _ := createEmployerWithCompany(t, repositories)
+		other, _ := createEmployerWithCompany(t, repositories)
+
+		webhook := createWebhook(t, employer.PublicID, "job.live")
+
+		assert.Equal(webhooks.ErrNotFound, repository.DeleteWebhook(other.PublicID, webhook.PublicID))
+		assert.Nil(repository.DeleteWebhook(employer.PublicID, webhook.PublicID))
+
+		_, err := repository.GetWebhook(webhook.PublicID)
+		assert.Equal(webhooks.ErrNotFound, err)
+	})
+
+	t.Run("Deliveries", func(t *testing.T) {
+		assert := assert.New(t)
+
+		employer, _ := createEmployerWithCompany(t, repositories)
+		other, _ := createEmployerWithCompany(t, repositories)
+
+		webhook := createWebhook(t, employer.PublicID, "job.live")
+
+		first, err := repository.CreateDelivery(webhook.PublicID, "job.live", json.RawMessage(`{"id":"first"}`))
+
+		if assert.Nil(err) {
+			assert.Equal(webhooks.Pending, first.Status)
+			assert.Equal(0, first.Attempts)
+			assert.Equal(webhook.PublicID, first.WebhookPublicID)
+			assert.JSONEq(`{"id":"first"}`, string(first.Payload))
+		}
+
+		second, err := repository.CreateDelivery(webhook.PublicID, "job.live", json.RawMessage(`{"id":"second"}`))
+		assert.Nil(err)
+
+		at := time.Now()
+
+		failed, err := repository.RecordAttempt(first.PublicID, &webhooks.Attempt{At: at, Status: webhooks.Pending, ResponseStatus: 500, ResponseBody: "oops", Error: "500 Internal Server Error"})
+
+		if assert.Nil(err) {
+			assert.Equal(1, failed.Attempts)
+			assert.Equal(500, failed.ResponseStatus)
+			assert.Equal("oops", failed.ResponseBody)
+			assert.NotNil(failed.LastAttemptAt)
+			assert.Nil(failed.DeliveredAt)
+		}
+
+		delivered, err := repository.RecordAttempt(first.PublicID, &webhooks.Attempt{At: at, Status: webhooks.Delivered, ResponseStatus: 204})
+
+		if assert.Nil(err) {
+			assert.Equal(2, delivered.Attempts)
+			assert.Equal(webhooks.Delivered, delivered.Status)
+			assert.Equal("", delivered.LastError)
+			assert.NotNil(delivered.DeliveredAt)
+		}
+
+		log, err := repository.GetDeliveries(employer.PublicID, webhook.PublicID, 10)
+
+		assert.Nil(err)
+
+		if assert.Equal(2, len(log)) {
+			assert.Equal(second.PublicID, log[0].PublicID)
+			assert.Equal(first.PublicID, log[1].PublicID)
+		}
+
+		log, err = repository.GetDeliveries(employer.PublicID, webhook.PublicID, 1)
+		assert.Nil(err)
+		assert.Equal(1, len(log))
+
+		log, err = repository.GetDeliveries(other.PublicID, webhook.PublicID, 10)
+		assert.Nil(err)
+		assert.Equal(0, len(log))
+
+		_, err = repository.GetEmployerDelivery(employer.PublicID, first.PublicID)
+		assert.Nil(err)
+
+		_, err = repository.GetEmployerDelivery(other.PublicID, first.PublicID)
+		assert.Equal(webhooks.ErrDeliveryNotFound, err)
+
+		_, err = repository.RecordAttempt("00000000-0000-0000-0000-000000000000", &webhooks.Attempt{At: at, Status: webhooks.Failed})
+		assert.Equal(webhooks.ErrDeliveryNotFound, err)
+	})
+}
@@ -0,0 +1,16 @@
+package transaction_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func init() {
+	testhelper.Init()
+}
+
+func Test_UnitOfWork_Contract(t *testing.T) {
+	repositorytest.UnitOfWork(t, testhelper.Repositories)
+}
@@ -0,0 +1,76 @@
+// Package transaction runs several repository calls as one unit of work.
+// The repositories handed to the callback share a transaction, so either
+// every write they make is committed or none is.
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"autumnomous-jobs-employer-api/shared/repository/attachments"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+)
+
+// Repositories are the repositories that can join a unit of work
+type Repositories struct {
+	Employers   accountmanagement.EmployerRepository
+	Companies   companies.CompanyRepository
+	Jobs        jobs.JobRepository
+	Queue       queue.QueueRepository
+	Webhooks    webhooks.WebhookRepository
+	Audit       audit.AuditRepository
+	Uploads     uploads.UploadRepository
+	Attachments attachments.AttachmentRepository
+}
+
+// UnitOfWork runs fn against repositories sharing one transaction. The
+// transaction commits if fn returns nil and rolls back otherwise.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repositories *Repositories) error) error
+}
+
+// PostgresUnitOfWork is the UnitOfWork backed by a database transaction
+type PostgresUnitOfWork struct {
+	Database *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) *PostgresUnitOfWork {
+	return &PostgresUnitOfWork{Database: db}
+}
+
+func (unit *PostgresUnitOfWork) Do(ctx context.Context, fn func(repositories *Repositories) error) error {
+
+	tx, err := unit.Database.BeginTx(ctx, nil)
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	// a no-op once the transaction has committed
+	defer tx.Rollback()
+
+	err = fn(&Repositories{
+		Employers:   accountmanagement.NewEmployerRepository(tx),
+		Companies:   companies.NewCompanyRepository(tx),
+		Jobs:        jobs.NewJobRepository(tx),
+		Queue:       queue.NewQueueRepository(tx),
+		Webhooks:    webhooks.NewWebhookRepository(tx),
+		Audit:       audit.NewAuditRepository(tx),
+		Uploads:     uploads.NewUploadRepository(tx),
+		Attachments: attachments.NewAttachmentRepository(tx),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
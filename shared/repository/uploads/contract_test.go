@@ -0,0 +1,16 @@
+package uploads_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func init() {
+	testhelper.Init()
+}
+
+func Test_UploadRepository_Contract(t *testing.T) {
+	repositorytest.UploadRepository(t, testhelper.Repositories)
+}
@@ -0,0 +1,421 @@
+package uploads
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Upload statuses
+const (
+	Pending     = "pending"
+	Complete    = "complete"
+	Quarantined = "quarantined"
+)
+
+const (
+	// PendingRetention is how long an upload may stay pending, which outlasts
+	// its presigned URL so a PUT started before the URL expires can finish
+	PendingRetention = time.Hour
+
+	// OrphanRetention is how long a complete upload is kept while nothing
+	// uses it, before it is swept
+	OrphanRetention = 24 * time.Hour
+
+	// QuarantineRetention is how long a quarantined upload is kept for review
+	QuarantineRetention = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrNotFound is returned for an upload that does not exist, belongs to
+	// another employer, or is not in the status a change needs
+	ErrNotFound = domain.NewNotFound("upload_not_found", "upload not found")
+
+	// ErrInUse is returned when deleting an upload that is still used
+	ErrInUse = domain.NewConflict("upload_in_use", "the upload is still in use")
+)
+
+// UploadRepository records the objects the API stores for employers and how
+// many records use each of them, so unused objects can be swept
+type UploadRepository interface {
+	CreateUpload(employerPublicID, key, fileName, contentType string, size int64, expiresAt time.Time) (*Upload, error)
+	RecordUpload(employerPublicID, key, url, contentType string, size int64, expiresAt time.Time) (*Upload, error)
+	GetEmployerUpload(employerPublicID, uploadPublicID string) (*Upload, error)
+	GetEmployerUploads(employerPublicID string) ([]*Upload, error)
+	CompleteUpload(uploadPublicID string, size int64, expiresAt time.Time) (*Upload, error)
+	QuarantineUpload(uploadPublicID, key, scanResult string, expiresAt time.Time) (*Upload, error)
+	RecordQuarantine(employerPublicID, key, fileName, contentType string, size int64, scanResult string, expiresAt time.Time) (*Upload, error)
+	Retain(urls []string) error
+	Release(urls []string, expiresAt time.Time) error
+	DeleteEmployerUpload(employerPublicID, uploadPublicID string) (*Upload, error)
+	GetOrphanedUploads(now time.Time, limit int) ([]*Upload, error)
+	DeleteOrphanedUpload(uploadPublicID string, now time.Time) error
+}
+
+// PostgresUploadRepository is the UploadRepository backed by the uploads table
+type PostgresUploadRepository struct {
+	Database database.Querier
+}
+
+// Upload is a file in object storage under Key. Size is the size declared
+// when the URL was issued until the upload is complete, then the size stored.
+// URL is empty for uploads that are not served publicly. RefCount is the
+// number of records using the upload; at zero it expires at ExpiresAt.
+// ScanResult is why a quarantined upload failed its scan.
+type Upload struct {
+	PublicID         string    `json:"publicid"`
+	EmployerPublicID string    `json:"employerpublicid"`
+	CompanyPublicID  string    `json:"companypublicid"`
+	Key              string    `json:"key"`
+	URL              string    `json:"url"`
+	FileName         string    `json:"filename"`
+	ContentType      string    `json:"contenttype"`
+	Size             int64     `json:"size"`
+	Status           string    `json:"status"`
+	RefCount         int       `json:"refcount"`
+	ScanResult       string    `json:"scanresult,omitempty"`
+	CreatedAt        time.Time `json:"createdat"`
+	ExpiresAt        time.Time `json:"expiresat"`
+}
+
+func NewUploadRepository(db database.Querier) *PostgresUploadRepository {
+	return &PostgresUploadRepository{Database: db}
+}
+
+const uploadColumns = `uploads.publicid, COALESCE(employers.publicid, ''), COALESCE(companies.publicid, ''), uploads.key, uploads.url,
+	uploads.filename, uploads.contenttype, uploads.size, uploads.status, uploads.refcount, uploads.scanresult, uploads.createdat, uploads.expiresat`
+
+const uploadTables = `uploads
+	LEFT JOIN employers ON employers.id=uploads.employerid
+	LEFT JOIN companies ON companies.id=uploads.companyid`
+
+func (repository *PostgresUploadRepository) CreateUpload(employerPublicID, key, fileName, contentType string, size int64, expiresAt time.Time) (*Upload, error) {
+
+	if employerPublicID == "" || key == "" || contentType == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	var publicID string
+
+	err := repository.Database.QueryRow(`
+		INSERT INTO uploads(publicid, employerid, companyid, key, filename, contenttype, size, expiresat)
+		SELECT $1, id, companyid, $3, $4, $5, $6, $7 FROM employers WHERE publicid=$2
+		RETURNING publicid;`, uuid.NewString(), employerPublicID, key, fileName, contentType, size, expiresAt).Scan(&publicID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, accountmanagement.ErrNotFound)
+	}
+
+	return repository.getUpload(publicID)
+}
+
+// RecordUpload records an object the API stored itself under key. Keys are
+// content addressed, so storing the same content again keeps the upload's
+// owner and only extends its expiry. employerPublicID may be empty for
+// objects stored for no employer.
+func (repository *PostgresUploadRepository) RecordUpload(employerPublicID, key, url, contentType string, size int64, expiresAt time.Time) (*Upload, error) {
+
+	if key == "" || contentType == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	var publicID string
+
+	err := repository.Database.QueryRow(`
+		INSERT INTO uploads(publicid, employerid, companyid, key, url, contenttype, size, status, expiresat)
+		SELECT $1, employers.id, employers.companyid, $3, $4, $5, $6, $7, $8
+		FROM (SELECT 1) AS one LEFT JOIN employers ON employers.publicid=$2
+		WHERE $2='' OR employers.id IS NOT NULL
+		ON CONFLICT (key) DO UPDATE SET url=EXCLUDED.url, expiresat=GREATEST(uploads.expiresat, EXCLUDED.expiresat)
+		RETURNING publicid;`, uuid.NewString(), employerPublicID, key, url, contentType, size, Complete, expiresAt).Scan(&publicID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, accountmanagement.ErrNotFound)
+	}
+
+	return repository.getUpload(publicID)
+}
+
+func (repository *PostgresUploadRepository) GetEmployerUpload(employerPublicID, uploadPublicID string) (*Upload, error) {
+
+	upload, err := scanUpload(repository.Database.QueryRow(`
+		SELECT `+uploadColumns+`
+		FROM `+uploadTables+`
+		WHERE employers.publicid=$1 AND uploads.publicid=$2;`, employerPublicID, uploadPublicID))
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, ErrNotFound)
+	}
+
+	return upload, nil
+}
+
+// GetEmployerUploads returns the employer's uploads, newest first
+func (repository *PostgresUploadRepository) GetEmployerUploads(employerPublicID string) ([]*Upload, error) {
+
+	return repository.queryUploads(`
+		SELECT `+uploadColumns+`
+		FROM `+uploadTables+`
+		WHERE employers.publicid=$1
+		ORDER BY uploads.createdat DESC, uploads.id DESC;`, employerPublicID)
+}
+
+// CompleteUpload records that a pending upload's object was checked and
+// holds size bytes. It then waits to be used until expiresAt.
+func (repository *PostgresUploadRepository) CompleteUpload(uploadPublicID string, size int64, expiresAt time.Time) (*Upload, error) {
+
+	result, err := repository.Database.Exec(`
+		UPDATE uploads SET status=$2, size=$3, expiresat=$4
+		WHERE publicid=$1 AND status=$5;`, uploadPublicID, Complete, size, expiresAt, Pending)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	if err := requireRow(result); err != nil {
+		return nil, err
+	}
+
+	return repository.getUpload(uploadPublicID)
+}
+
+// QuarantineUpload records that the object of a pending upload failed its
+// scan for scanResult and was moved to key, where it is kept until expiresAt
+func (repository *PostgresUploadRepository) QuarantineUpload(uploadPublicID, key, scanResult string, expiresAt time.Time) (*Upload, error) {
+
+	result, err := repository.Database.Exec(`
+		UPDATE uploads SET status=$2, key=$3, scanresult=$4, expiresat=$5
+		WHERE publicid=$1 AND status=$6;`, uploadPublicID, Quarantined, key, scanResult, expiresAt, Pending)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	if err := requireRow(result); err != nil {
+		return nil, err
+	}
+
+	return repository.getUpload(uploadPublicID)
+}
+
+// RecordQuarantine records an object sent through the API that failed its
+// scan for scanResult and was kept under key until expiresAt.
+// employerPublicID may be empty for objects sent for no employer.
+func (repository *PostgresUploadRepository) RecordQuarantine(employerPublicID, key, fileName, contentType string, size int64, scanResult string, expiresAt time.Time) (*Upload, error) {
+
+	if key == "" || contentType == "" {
+		return nil, domain.ErrMissingValue
+	}
+
+	var publicID string
+
+	err := repository.Database.QueryRow(`
+		INSERT INTO uploads(publicid, employerid, companyid, key, filename, contenttype, size, status, scanresult, expiresat)
+		SELECT $1, employers.id, employers.companyid, $3, $4, $5, $6, $7, $8, $9
+		FROM (SELECT 1) AS one LEFT JOIN employers ON employers.publicid=$2
+		WHERE $2='' OR employers.id IS NOT NULL
+		RETURNING publicid;`, uuid.NewString(), employerPublicID, key, fileName, contentType, size, Quarantined, scanResult, expiresAt).Scan(&publicID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, accountmanagement.ErrNotFound)
+	}
+
+	return repository.getUpload(publicID)
+}
+
+// Retain records one more use of each upload served at one of urls. URLs of
+// objects without an upload, stored before uploads were recorded, are ignored.
+func (repository *PostgresUploadRepository) Retain(urls []string) error {
+
+	urls = Distinct(urls)
+
+	if len(urls) == 0 {
+		return nil
+	}
+
+	_, err := repository.Database.Exec(`
+		UPDATE uploads SET refcount=refcount+1 WHERE url=ANY($1);`, pq.Array(urls))
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	return err
+}
+
+// Release records one less use of each upload served at one of urls. Uploads
+// left unused expire at expiresAt.
+func (repository *PostgresUploadRepository) Release(urls []string, expiresAt time.Time) error {
+
+	urls = Distinct(urls)
+
+	if len(urls) == 0 {
+		return nil
+	}
+
+	_, err := repository.Database.Exec(`
+		UPDATE uploads SET refcount=refcount-1, expiresat=CASE WHEN refcount=1 THEN $2 ELSE expiresat END
+		WHERE url=ANY($1) AND refcount>0;`, pq.Array(urls), expiresAt)
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	return err
+}
+
+// DeleteEmployerUpload deletes the row of an upload of the employer that
+// nothing uses and returns it, so its object can be deleted
+func (repository *PostgresUploadRepository) DeleteEmployerUpload(employerPublicID, uploadPublicID string) (*Upload, error) {
+
+	upload, err := repository.GetEmployerUpload(employerPublicID, uploadPublicID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := repository.Database.Exec(`
+		DELETE FROM uploads WHERE publicid=$1 AND refcount=0;`, uploadPublicID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	if err := requireRow(result); err != nil {
+		return nil, ErrInUse
+	}
+
+	return upload, nil
+}
+
+// GetOrphanedUploads returns up to limit uploads that nothing uses and that
+// expired before now, oldest first
+func (repository *PostgresUploadRepository) GetOrphanedUploads(now time.Time, limit int) ([]*Upload, error) {
+
+	return repository.queryUploads(`
+		SELECT `+uploadColumns+`
+		FROM `+uploadTables+`
+		WHERE uploads.refcount=0 AND uploads.expiresat<$1
+		ORDER BY uploads.expiresat, uploads.id
+		LIMIT $2;`, now, limit)
+}
+
+// DeleteOrphanedUpload deletes the row of an upload returned by
+// GetOrphanedUploads, unless it was used again since
+func (repository *PostgresUploadRepository) DeleteOrphanedUpload(uploadPublicID string, now time.Time) error {
+
+	result, err := repository.Database.Exec(`
+		DELETE FROM uploads WHERE publicid=$1 AND refcount=0 AND expiresat<$2;`, uploadPublicID, now)
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return requireRow(result)
+}
+
+func (repository *PostgresUploadRepository) getUpload(uploadPublicID string) (*Upload, error) {
+
+	upload, err := scanUpload(repository.Database.QueryRow(`
+		SELECT `+uploadColumns+`
+		FROM `+uploadTables+`
+		WHERE uploads.publicid=$1;`, uploadPublicID))
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, ErrNotFound)
+	}
+
+	return upload, nil
+}
+
+func (repository *PostgresUploadRepository) queryUploads(query string, args ...interface{}) ([]*Upload, error) {
+
+	rows, err := repository.Database.Query(query, args...)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var result []*Upload
+
+	for rows.Next() {
+		upload, err := scanUpload(rows)
+
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		result = append(result, upload)
+	}
+
+	return result, rows.Err()
+}
+
+// Distinct returns the non-empty values of urls, each once, in order
+func Distinct(urls []string) []string {
+
+	seen := map[string]bool{}
+	var result []string
+
+	for _, url := range urls {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			result = append(result, url)
+		}
+	}
+
+	return result
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUpload(row scanner) (*Upload, error) {
+
+	var upload Upload
+
+	err := row.Scan(&upload.PublicID, &upload.EmployerPublicID, &upload.CompanyPublicID, &upload.Key, &upload.URL,
+		&upload.FileName, &upload.ContentType, &upload.Size, &upload.Status, &upload.RefCount, &upload.ScanResult, &upload.CreatedAt, &upload.ExpiresAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+func requireRow(result sql.Result) error {
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package webhooks_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/testhelper"
+)
+
+func init() {
+	testhelper.Init()
+}
+
+func Test_WebhookRepository_Contract(t *testing.T) {
+	repositorytest.WebhookRepository(t, testhelper.Repositories)
+}
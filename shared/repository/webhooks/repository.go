@@ -0,0 +1,399 @@
+package webhooks
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/services/utils"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Delivery statuses
+const (
+	Pending   = "pending"
+	Delivered = "delivered"
+	Failed    = "failed"
+)
+
+var (
+	// ErrNotFound is returned for a webhook that does not exist or belongs to
+	// another company
+	ErrNotFound = domain.NewNotFound("webhook_not_found", "webhook not found")
+	// ErrDeliveryNotFound is returned for a delivery that does not exist or
+	// belongs to another company
+	ErrDeliveryNotFound = domain.NewNotFound("webhook_delivery_not_found", "webhook delivery not found")
+)
+
+// WebhookRepository manages the endpoints companies subscribe to events and
+// the log of deliveries made to them. Methods taking an employerPublicID only
+// see the webhooks of that employer's company.
+type WebhookRepository interface {
+	CreateWebhook(employerPublicID, url, secret string, events []string) (*Webhook, error)
+	GetWebhooks(employerPublicID string) ([]*Webhook, error)
+	GetEmployerWebhook(employerPublicID, webhookPublicID string) (*Webhook, error)
+	GetWebhook(webhookPublicID string) (*Webhook, error)
+	DeleteWebhook(employerPublicID, webhookPublicID string) error
+	GetSubscribedWebhooks(employerPublicID, event string) ([]*Webhook, error)
+
+	CreateDelivery(webhookPublicID, event string, payload json.RawMessage) (*Delivery, error)
+	GetEmployerDelivery(employerPublicID, deliveryPublicID string) (*Delivery, error)
+	GetDelivery(deliveryPublicID string) (*Delivery, error)
+	GetDeliveries(employerPublicID, webhookPublicID string, limit int) ([]*Delivery, error)
+	RecordAttempt(deliveryPublicID string, attempt *Attempt) (*Delivery, error)
+}
+
+// PostgresWebhookRepository is the WebhookRepository backed by the webhooks
+// and webhookdeliveries tables
+type PostgresWebhookRepository struct {
+	Database database.Querier
+}
+
+type Webhook struct {
+	PublicID        string    `json:"publicid"`
+	CompanyPublicID string    `json:"companypublicid"`
+	URL             string    `json:"url"`
+	Secret          string    `json:"secret,omitempty"`
+	Events          []string  `json:"events"`
+	CreatedAt       time.Time `json:"createdat"`
+}
+
+type Delivery struct {
+	PublicID        string          `json:"publicid"`
+	WebhookPublicID string          `json:"webhookpublicid"`
+	Event           string          `json:"event"`
+	Payload         json.RawMessage `json:"payload"`
+	Status          string          `json:"status"`
+	Attempts        int             `json:"attempts"`
+	ResponseStatus  int             `json:"responsestatus,omitempty"`
+	ResponseBody    string          `json:"responsebody,omitempty"`
+	LastError       string          `json:"lasterror,omitempty"`
+	CreatedAt       time.Time       `json:"createdat"`
+	LastAttemptAt   *time.Time      `json:"lastattemptat,omitempty"`
+	DeliveredAt     *time.Time      `json:"deliveredat,omitempty"`
+}
+
+// Attempt is the outcome of one delivery attempt. Status is Delivered,
+// Failed when no more attempts will be made, or Pending.
+type Attempt struct {
+	At             time.Time
+	Status         string
+	ResponseStatus int
+	ResponseBody   string
+	Error          string
+}
+
+func NewWebhookRepository(db database.Querier) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{Database: db}
+}
+
+const webhookColumns = `webhooks.publicid, companies.publicid, webhooks.url, webhooks.secret, webhooks.events, webhooks.createdat`
+
+const deliveryColumns = `webhookdeliveries.publicid, webhooks.publicid, webhookdeliveries.event, webhookdeliveries.payload, webhookdeliveries.status,
+	webhookdeliveries.attempts, COALESCE(webhookdeliveries.responsestatus, 0), COALESCE(webhookdeliveries.responsebody, ''),
+	COALESCE(webhookdeliveries.lasterror, ''), webhookdeliveries.createdat, webhookdeliveries.lastattemptat, webhookdeliveries.deliveredat`
+
+func (repository *PostgresWebhookRepository) CreateWebhook(employerPublicID, url, secret string, events []string) (*Webhook, error) {
+
+	if employerPublicID == "" || url == "" || secret == "" || len(events) == 0 {
+		return nil, domain.ErrMissingValue
+	}
+
+	var webhook Webhook
+
+	err := repository.Database.QueryRow(`
+		INSERT INTO webhooks(publicid, companyid, url, secret, events)
+		SELECT $1, employers.companyid, $3, $4, $5 FROM employers WHERE employers.publicid=$2 AND employers.companyid IS NOT NULL
+		RETURNING publicid;`, uuid.NewString(), employerPublicID, url, secret, pq.Array(events)).Scan(&webhook.PublicID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return repository.GetWebhook(webhook.PublicID)
+}
+
+func (repository *PostgresWebhookRepository) GetWebhooks(employerPublicID string) ([]*Webhook, error) {
+
+	rows, err := repository.Database.Query(`
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		JOIN companies ON companies.id=webhooks.companyid
+		JOIN employers ON employers.companyid=webhooks.companyid
+		WHERE employers.publicid=$1
+		ORDER BY webhooks.createdat, webhooks.id;`, employerPublicID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return scanWebhooks(rows)
+}
+
+func (repository *PostgresWebhookRepository) GetEmployerWebhook(employerPublicID, webhookPublicID string) (*Webhook, error) {
+
+	webhook, err := scanWebhook(repository.Database.QueryRow(`
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		JOIN companies ON companies.id=webhooks.companyid
+		JOIN employers ON employers.companyid=webhooks.companyid
+		WHERE employers.publicid=$1 AND webhooks.publicid=$2;`, employerPublicID, webhookPublicID))
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, ErrNotFound)
+	}
+
+	return webhook, nil
+}
+
+func (repository *PostgresWebhookRepository) GetWebhook(webhookPublicID string) (*Webhook, error) {
+
+	webhook, err := scanWebhook(repository.Database.QueryRow(`
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		JOIN companies ON companies.id=webhooks.companyid
+		WHERE webhooks.publicid=$1;`, webhookPublicID))
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, ErrNotFound)
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook removes the webhook and its delivery log
+func (repository *PostgresWebhookRepository) DeleteWebhook(employerPublicID, webhookPublicID string) error {
+
+	result, err := repository.Database.Exec(`
+		DELETE FROM webhooks USING employers
+		WHERE employers.companyid=webhooks.companyid AND employers.publicid=$1 AND webhooks.publicid=$2;`, employerPublicID, webhookPublicID)
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return requireRow(result, ErrNotFound)
+}
+
+// GetSubscribedWebhooks returns the webhooks of the employer's company that
+// subscribe to event
+func (repository *PostgresWebhookRepository) GetSubscribedWebhooks(employerPublicID, event string) ([]*Webhook, error) {
+
+	rows, err := repository.Database.Query(`
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		JOIN companies ON companies.id=webhooks.companyid
+		JOIN employers ON employers.companyid=webhooks.companyid
+		WHERE employers.publicid=$1 AND $2=ANY(webhooks.events)
+		ORDER BY webhooks.id;`, employerPublicID, event)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return scanWebhooks(rows)
+}
+
+func (repository *PostgresWebhookRepository) CreateDelivery(webhookPublicID, event string, payload json.RawMessage) (*Delivery, error) {
+
+	if webhookPublicID == "" || event == "" || len(payload) == 0 {
+		return nil, domain.ErrMissingValue
+	}
+
+	var publicID string
+
+	err := repository.Database.QueryRow(`
+		INSERT INTO webhookdeliveries(publicid, webhookid, event, payload)
+		SELECT $1, id, $3, $4 FROM webhooks WHERE publicid=$2
+		RETURNING publicid;`, uuid.NewString(), webhookPublicID, event, string(payload)).Scan(&publicID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return repository.GetDelivery(publicID)
+}
+
+func (repository *PostgresWebhookRepository) GetEmployerDelivery(employerPublicID, deliveryPublicID string) (*Delivery, error) {
+
+	delivery, err := scanDelivery(repository.Database.QueryRow(`
+		SELECT `+deliveryColumns+`
+		FROM webhookdeliveries
+		JOIN webhooks ON webhooks.id=webhookdeliveries.webhookid
+		JOIN employers ON employers.companyid=webhooks.companyid
+		WHERE employers.publicid=$1 AND webhookdeliveries.publicid=$2;`, employerPublicID, deliveryPublicID))
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, ErrDeliveryNotFound)
+	}
+
+	return delivery, nil
+}
+
+func (repository *PostgresWebhookRepository) GetDelivery(deliveryPublicID string) (*Delivery, error) {
+
+	delivery, err := scanDelivery(repository.Database.QueryRow(`
+		SELECT `+deliveryColumns+`
+		FROM webhookdeliveries
+		JOIN webhooks ON webhooks.id=webhookdeliveries.webhookid
+		WHERE webhookdeliveries.publicid=$1;`, deliveryPublicID))
+
+	if err != nil {
+		log.Println(err)
+		return nil, domain.NotFoundOr(err, ErrDeliveryNotFound)
+	}
+
+	return delivery, nil
+}
+
+// GetDeliveries returns up to limit deliveries to the webhook, newest first
+func (repository *PostgresWebhookRepository) GetDeliveries(employerPublicID, webhookPublicID string, limit int) ([]*Delivery, error) {
+
+	rows, err := repository.Database.Query(`
+		SELECT `+deliveryColumns+`
+		FROM webhookdeliveries
+		JOIN webhooks ON webhooks.id=webhookdeliveries.webhookid
+		JOIN employers ON employers.companyid=webhooks.companyid
+		WHERE employers.publicid=$1 AND webhooks.publicid=$2
+		ORDER BY webhookdeliveries.createdat DESC, webhookdeliveries.id DESC
+		LIMIT $3;`, employerPublicID, webhookPublicID, limit)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var deliveries []*Delivery
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (repository *PostgresWebhookRepository) RecordAttempt(deliveryPublicID string, attempt *Attempt) (*Delivery, error) {
+
+	var deliveredAt sql.NullTime
+
+	if attempt.Status == Delivered {
+		deliveredAt = sql.NullTime{Time: attempt.At, Valid: true}
+	}
+
+	result, err := repository.Database.Exec(`
+		UPDATE webhookdeliveries SET attempts=attempts+1, lastattemptat=$2, status=$3, responsestatus=$4, responsebody=$5, lasterror=$6, deliveredat=COALESCE($7, deliveredat)
+		WHERE publicid=$1;`,
+		deliveryPublicID, attempt.At, attempt.Status, attempt.ResponseStatus, utils.NewNullString(attempt.ResponseBody), utils.NewNullString(attempt.Error), deliveredAt)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	if err := requireRow(result, ErrDeliveryNotFound); err != nil {
+		return nil, err
+	}
+
+	return repository.GetDelivery(deliveryPublicID)
+}
+
+func requireRow(result sql.Result, notFound *domain.Error) error {
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhook(row scanner) (*Webhook, error) {
+
+	var webhook Webhook
+
+	err := row.Scan(&webhook.PublicID, &webhook.CompanyPublicID, &webhook.URL, &webhook.Secret, pq.Array(&webhook.Events), &webhook.CreatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func scanWebhooks(rows *sql.Rows) ([]*Webhook, error) {
+
+	defer rows.Close()
+
+	var webhooks []*Webhook
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func scanDelivery(row scanner) (*Delivery, error) {
+
+	var delivery Delivery
+	var payload []byte
+	var lastAttemptAt, deliveredAt sql.NullTime
+
+	err := row.Scan(&delivery.PublicID, &delivery.WebhookPublicID, &delivery.Event, &payload, &delivery.Status,
+		&delivery.Attempts, &delivery.ResponseStatus, &delivery.ResponseBody,
+		&delivery.LastError, &delivery.CreatedAt, &lastAttemptAt, &deliveredAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = payload
+
+	if lastAttemptAt.Valid {
+		delivery.LastAttemptAt = &lastAttemptAt.Time
+	}
+
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &delivery, nil
+}
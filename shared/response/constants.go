@@ -10,4 +10,20 @@ const (
 	Unauthorized         = "Authorization failed."
 	Success              = "Success!"
 	EmptyResult          = "The result was empty."
+	InvalidRequest       = "Some fields of the request are invalid."
+)
+
+// Codes of the problems handlers send themselves. Repositories and services
+// define the codes of their own domain errors.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeUnauthorized         = "unauthorized"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeInvalidAPIKey        = "invalid_api_key"
+	CodeCompanyRequired      = "company_required"
+	CodeNotFound             = "not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeValidation           = "validation_failed"
+	CodeInternal             = "internal_error"
 )
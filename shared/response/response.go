@@ -2,16 +2,43 @@ package response
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/services/validation"
 )
 
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// CoreResponse is the body of a request that succeeds without returning a
+// resource
 type CoreResponse struct {
-	Status  http.ConnState `json:"status"`
-	Message string         `json:"message"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// Problem is an RFC 7807 problem details object, sent for every failed
+// request. Code is stable and says what went wrong; Detail is for people and
+// may change. Errors lists the invalid fields of a validation_failed problem.
+type Problem struct {
+	Type   string            `json:"type"`
+	Title  string            `json:"title"`
+	Status int               `json:"status"`
+	Detail string            `json:"detail,omitempty"`
+	Code   string            `json:"code"`
+	Errors validation.Errors `json:"errors,omitempty"`
 }
 
 func SendJSON(w http.ResponseWriter, i interface{}) { // 200, success
+	SendJSONStatus(w, http.StatusOK, i)
+}
+
+// SendJSONStatus sends i with a status other than 200, such as a 412 that
+// carries the current version of a resource
+func SendJSONStatus(w http.ResponseWriter, status int, i interface{}) {
 
 	js, err := json.Marshal(i)
 
@@ -23,26 +50,68 @@ func SendJSON(w http.ResponseWriter, i interface{}) { // 200, success
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	w.Write(js)
 
 }
 
-func SendJSONMessage(w http.ResponseWriter, status http.ConnState, message string) {
+// SendJSONMessage sends a CoreResponse with status and message
+func SendJSONMessage(w http.ResponseWriter, status int, message string) {
+	SendJSONStatus(w, status, &CoreResponse{Status: status, Message: message})
+}
 
-	i := &CoreResponse{
-		Status:  status,
-		Message: message}
+// SendProblem responds with a problem of status. Detail may be empty.
+func SendProblem(w http.ResponseWriter, status int, code, detail string) {
+	sendProblem(w, &Problem{Status: status, Code: code, Detail: detail})
+}
+
+// SendValidationErrors responds 400 with every invalid field of a request
+func SendValidationErrors(w http.ResponseWriter, errs validation.Errors) {
+	sendProblem(w, &Problem{Status: http.StatusBadRequest, Code: CodeValidation, Detail: InvalidRequest, Errors: errs})
+}
+
+// SendError responds with the problem for err. Domain errors and validation
+// errors keep their code; anything else is logged and hidden behind a 500.
+func SendError(w http.ResponseWriter, err error) {
+
+	var invalid validation.Errors
+	var domainErr *domain.Error
+
+	switch {
+	case errors.As(err, &invalid):
+		SendValidationErrors(w, invalid)
+	case errors.As(err, &domainErr):
+		SendProblem(w, statuses[domainErr.Kind], domainErr.Code, domainErr.Message)
+	default:
+		log.Println(err)
+		SendProblem(w, http.StatusInternalServerError, CodeInternal, FriendlyError)
+	}
+}
+
+var statuses = map[domain.Kind]int{
+	domain.NotFound:  http.StatusNotFound,
+	domain.Conflict:  http.StatusConflict,
+	domain.Forbidden: http.StatusForbidden,
+	domain.Invalid:   http.StatusBadRequest,
+}
+
+func sendProblem(w http.ResponseWriter, problem *Problem) {
+
+	// about:blank says the status alone explains the problem, which is why
+	// Code carries the detail
+	problem.Type = "about:blank"
+	problem.Title = http.StatusText(problem.Status)
+
+	js, err := json.Marshal(problem)
 
-	js, err := json.Marshal(i)
 	if err != nil {
 		http.Error(w, "JSON error:"+err.Error(), http.StatusInternalServerError)
 		log.Println(err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", ProblemContentType)
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.WriteHeader(int(status))
+	w.WriteHeader(problem.Status)
 	w.Write(js)
 }
@@ -0,0 +1,58 @@
+package response_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/response"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Response_SendError(t *testing.T) {
+
+	tests := map[string]struct {
+		err    error
+		status int
+		code   string
+	}{
+		"NotFound":   {domain.NewNotFound("job_not_found", "job not found"), http.StatusNotFound, "job_not_found"},
+		"Conflict":   {domain.NewConflict("email_taken", "email taken"), http.StatusConflict, "email_taken"},
+		"Forbidden":  {domain.NewForbidden("not_owner", "not yours"), http.StatusForbidden, "not_owner"},
+		"Invalid":    {domain.ErrMissingValue, http.StatusBadRequest, "missing_value"},
+		"Wrapped":    {fmt.Errorf("saving: %w", domain.NewConflict("job_modified", "stale")), http.StatusConflict, "job_modified"},
+		"Validation": {validation.Errors{{Field: "title", Code: validation.Required}}, http.StatusBadRequest, response.CodeValidation},
+		"Internal":   {errors.New("connection refused"), http.StatusInternalServerError, response.CodeInternal},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			response.SendError(recorder, test.err)
+
+			assert.Equal(t, test.status, recorder.Code)
+			assert.Equal(t, response.ProblemContentType, recorder.Header().Get("Content-Type"))
+
+			var problem response.Problem
+			assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&problem))
+			assert.Equal(t, test.code, problem.Code)
+			assert.Equal(t, test.status, problem.Status)
+			assert.Equal(t, "about:blank", problem.Type)
+			assert.Equal(t, http.StatusText(test.status), problem.Title)
+		})
+	}
+}
+
+func Test_Response_SendError_HidesInternalErrors(t *testing.T) {
+
+	recorder := httptest.NewRecorder()
+	response.SendError(recorder, errors.New("pq: password authentication failed"))
+
+	assert.NotContains(t, recorder.Body.String(), "password authentication")
+}
@@ -0,0 +1,85 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// orientationTag is the EXIF tag saying how a camera was held
+const orientationTag = 0x0112
+
+// orientation returns the EXIF orientation of a JPEG, 1 when it has none
+func orientation(data []byte) int {
+
+	// the segments before the image data, each a marker and a length
+	for i := 2; i+4 <= len(data) && data[i] == 0xff; {
+
+		marker := data[i+1]
+
+		if marker == 0xd9 || marker == 0xda {
+			break
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+
+		if length < 2 || i+2+length > len(data) {
+			break
+		}
+
+		segment := data[i+4 : i+2+length]
+
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+
+		i += 2 + length
+	}
+
+	return 1
+}
+
+// tiffOrientation reads the orientation from the first directory of the
+// TIFF structure EXIF data is stored in
+func tiffOrientation(tiff []byte) int {
+
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[offset:]))
+
+	for i := 0; i < entries; i++ {
+
+		entry := offset + 2 + i*12
+
+		if entry+12 > len(tiff) {
+			break
+		}
+
+		if order.Uint16(tiff[entry:]) == orientationTag {
+			if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+				return value
+			}
+			break
+		}
+	}
+
+	return 1
+}
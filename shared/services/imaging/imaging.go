@@ -0,0 +1,200 @@
+// Package imaging checks uploaded images and prepares the variants that are
+// stored. The format is decided by the file's magic bytes, never by its name
+// or the Content-Type the client sent. Images are decoded and encoded again,
+// which leaves their EXIF and other metadata behind, after being turned
+// upright by their EXIF orientation and scaled down to fit each variant.
+//
+// WebP has no decoder in the standard library, so WebP files are checked and
+// stripped of their EXIF and XMP chunks at the container level, and stored
+// once, at their own size, for every variant.
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+)
+
+// The image formats accepted, by content type
+const (
+	PNG  = "image/png"
+	JPEG = "image/jpeg"
+	GIF  = "image/gif"
+	WebP = "image/webp"
+)
+
+// Limits on what is decoded, so a small file cannot expand into an image
+// that exhausts memory
+const (
+	MaxDimension = 10000
+	MaxPixels    = 40000000
+)
+
+var (
+	// ErrUnsupported is returned for a file that is not a PNG, JPEG, GIF or WebP image
+	ErrUnsupported = domain.NewInvalid("unsupported_image", "upload a PNG, JPEG, GIF or WebP image")
+	// ErrTooLarge is returned for an image with more pixels than allowed
+	ErrTooLarge = domain.NewInvalid("image_too_large", "the image is too large, upload one of at most 10000 by 10000 and 40 megapixels")
+	// ErrCorrupt is returned for an image that cannot be decoded
+	ErrCorrupt = domain.NewInvalid("invalid_image", "the image could not be read")
+)
+
+// Variant is a size an image is stored at. Images are scaled down, keeping
+// their aspect ratio, to fit within Width by Height, and never scaled up.
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// The variants of a company logo
+var (
+	Logo      = Variant{Name: "logo", Width: 512, Height: 512}
+	Thumbnail = Variant{Name: "thumbnail", Width: 128, Height: 128}
+
+	LogoVariants = []Variant{Logo, Thumbnail}
+)
+
+// Image is one variant of an upload, ready to store
+type Image struct {
+	Variant     string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Key returns the storage key of image, named after the hash of its
+// content, so the same image is only stored once
+func (image *Image) Key() string {
+
+	sum := sha256.Sum256(image.Data)
+
+	return "images/" + hex.EncodeToString(sum[:]) + extensions[image.ContentType]
+}
+
+var extensions = map[string]string{
+	PNG:  ".png",
+	JPEG: ".jpg",
+	WebP: ".webp",
+}
+
+// Sniff returns the content type of the image in data, or empty when data
+// is not an image in an accepted format
+func Sniff(data []byte) string {
+
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return PNG
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return JPEG
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return GIF
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return WebP
+	}
+
+	return ""
+}
+
+// Process checks the image in data and returns it at each of variants.
+// JPEGs stay JPEGs; PNGs and GIFs, of which only the first frame is kept,
+// become PNGs.
+func Process(data []byte, variants []Variant) ([]*Image, error) {
+
+	contentType := Sniff(data)
+
+	if contentType == "" {
+		return nil, ErrUnsupported
+	}
+
+	if contentType == WebP {
+		return processWebP(data, variants)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+
+	if err := checkSize(config.Width, config.Height); err != nil {
+		return nil, err
+	}
+
+	decoded, err := decode(contentType, data)
+
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+
+	source := image.NewRGBA(image.Rect(0, 0, decoded.Bounds().Dx(), decoded.Bounds().Dy()))
+	draw.Draw(source, source.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+
+	if contentType == JPEG {
+		source = orient(source, orientation(data))
+	}
+
+	var images []*Image
+
+	for _, variant := range variants {
+
+		resized := fit(source, variant.Width, variant.Height)
+
+		var buffer bytes.Buffer
+		output := PNG
+
+		if contentType == JPEG {
+			output = JPEG
+			err = jpeg.Encode(&buffer, resized, &jpeg.Options{Quality: 85})
+		} else {
+			err = png.Encode(&buffer, resized)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, &Image{
+			Variant:     variant.Name,
+			ContentType: output,
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+			Data:        buffer.Bytes(),
+		})
+	}
+
+	return images, nil
+}
+
+func decode(contentType string, data []byte) (image.Image, error) {
+
+	switch contentType {
+	case PNG:
+		return png.Decode(bytes.NewReader(data))
+	case JPEG:
+		return jpeg.Decode(bytes.NewReader(data))
+	default:
+		return gif.Decode(bytes.NewReader(data))
+	}
+}
+
+func checkSize(width, height int) error {
+
+	if width <= 0 || height <= 0 {
+		return ErrCorrupt
+	}
+
+	if width > MaxDimension || height > MaxDimension || width*height > MaxPixels {
+		return ErrTooLarge
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/services/imaging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+
+	var buffer bytes.Buffer
+
+	if err := png.Encode(&buffer, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
+
+// exifJPEG returns a JPEG of width by height whose EXIF data holds orientation
+func exifJPEG(t *testing.T, width, height int, orientation uint16) []byte {
+
+	var buffer bytes.Buffer
+
+	if err := jpeg.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// a little endian TIFF header and one directory with one entry
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00")
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry, 0x0112)
+	binary.LittleEndian.PutUint16(entry[2:], 3)
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], orientation)
+	tiff = append(append(tiff, entry...), 0, 0, 0, 0)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+
+	data := buffer.Bytes()
+
+	return append(append(append([]byte{0xff, 0xd8}, app1...), segment...), data[2:]...)
+}
+
+func Test_Imaging_Sniff(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(imaging.PNG, imaging.Sniff(encodePNG(t, 1, 1)))
+	assert.Equal(imaging.JPEG, imaging.Sniff(exifJPEG(t, 1, 1, 1)))
+	assert.Equal(imaging.GIF, imaging.Sniff([]byte("GIF89a...")))
+	assert.Equal(imaging.WebP, imaging.Sniff([]byte("RIFF\x00\x00\x00\x00WEBPVP8L")))
+	assert.Equal("", imaging.Sniff([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>")))
+}
+
+func Test_Imaging_Process_Resizes(t *testing.T) {
+	assert := assert.New(t)
+
+	images, err := imaging.Process(encodePNG(t, 1024, 256), imaging.LogoVariants)
+
+	if !assert.NoError(err) || !assert.Len(images, 2) {
+		return
+	}
+
+	assert.Equal("logo", images[0].Variant)
+	assert.Equal(512, images[0].Width)
+	assert.Equal(128, images[0].Height)
+	assert.Equal("thumbnail", images[1].Variant)
+	assert.Equal(128, images[1].Width)
+	assert.Equal(32, images[1].Height)
+
+	decoded, err := png.Decode(bytes.NewReader(images[1].Data))
+	assert.NoError(err)
+	assert.Equal(image.Rect(0, 0, 128, 32), decoded.Bounds())
+
+	assert.Regexp(`^images/[0-9a-f]{64}\.png$`, images[0].Key())
+	assert.NotEqual(images[0].Key(), images[1].Key())
+
+	// small images are not scaled up
+	images, err = imaging.Process(encodePNG(t, 64, 48), imaging.LogoVariants)
+	assert.NoError(err)
+	assert.Equal(64, images[0].Width)
+	assert.Equal(images[0].Key(), images[1].Key())
+}
+
+func Test_Imaging_Process_JPEG(t *testing.T) {
+	assert := assert.New(t)
+
+	data := exifJPEG(t, 200, 100, 6)
+	assert.Contains(string(data), "Exif")
+
+	images, err := imaging.Process(data, []imaging.Variant{imaging.Logo})
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	// turned a quarter, and the EXIF data left behind
+	assert.Equal(imaging.JPEG, images[0].ContentType)
+	assert.Equal(100, images[0].Width)
+	assert.Equal(200, images[0].Height)
+	assert.NotContains(string(images[0].Data), "Exif")
+	assert.Regexp(`\.jpg$`, images[0].Key())
+}
+
+func Test_Imaging_Process_GIF(t *testing.T) {
+	assert := assert.New(t)
+
+	var buffer bytes.Buffer
+	paletted := image.NewPaletted(image.Rect(0, 0, 300, 300), color.Palette{color.Black, color.White})
+	assert.NoError(gif.Encode(&buffer, paletted, nil))
+
+	images, err := imaging.Process(buffer.Bytes(), imaging.LogoVariants)
+
+	if assert.NoError(err) {
+		assert.Equal(imaging.PNG, images[1].ContentType)
+		assert.Equal(128, images[1].Width)
+	}
+}
+
+func Test_Imaging_Process_Rejects(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := imaging.Process([]byte("#!/bin/sh\necho hello"), imaging.LogoVariants)
+	assert.Equal(imaging.ErrUnsupported, err)
+
+	_, err = imaging.Process([]byte("\x89PNG\r\n\x1a\nnot really"), imaging.LogoVariants)
+	assert.Equal(imaging.ErrCorrupt, err)
+
+	// a tiny file claiming to be 20000 by 20000 pixels is never decoded
+	data := encodePNG(t, 1, 1)
+	ihdr := data[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr, 20000)
+	binary.BigEndian.PutUint32(ihdr[4:], 20000)
+	binary.BigEndian.PutUint32(data[8+8+13:], crc32.ChecksumIEEE(data[8+4:8+8+13]))
+
+	_, err = imaging.Process(data, imaging.LogoVariants)
+	assert.Equal(imaging.ErrTooLarge, err)
+}
+
+func Test_Imaging_Process_WebP(t *testing.T) {
+	assert := assert.New(t)
+
+	chunk := func(fourCC string, payload []byte) []byte {
+		header := make([]byte, 8)
+		copy(header, fourCC)
+		binary.LittleEndian.PutUint32(header[4:], uint32(len(payload)))
+		if len(payload)%2 == 1 {
+			payload = append(payload, 0)
+		}
+		return append(header, payload...)
+	}
+
+	// a 300 by 200 canvas flagged as carrying EXIF and XMP data
+	vp8x := []byte{0x08 | 0x04, 0, 0, 0, 43, 1, 0, 199, 0, 0}
+	vp8l := []byte{0x2f, 0, 0, 0, 0}
+
+	body := append([]byte("WEBP"), chunk("VP8X", vp8x)...)
+	body = append(body, chunk("VP8L", vp8l)...)
+	body = append(body, chunk("EXIF", []byte("Exif\x00\x00GPS"))...)
+	body = append(body, chunk("XMP ", []byte("<x:xmpmeta/>"))...)
+
+	data := append([]byte("RIFF\x00\x00\x00\x00"), body...)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(body)))
+
+	images, err := imaging.Process(data, imaging.LogoVariants)
+
+	if !assert.NoError(err) || !assert.Len(images, 2) {
+		return
+	}
+
+	stripped := images[0].Data
+	assert.Equal(imaging.WebP, images[0].ContentType)
+	assert.Equal(300, images[0].Width)
+	assert.Equal(200, images[0].Height)
+	assert.NotContains(string(stripped), "EXIF")
+	assert.NotContains(string(stripped), "xmpmeta")
+	assert.Equal(byte(0), stripped[20]&(0x08|0x04))
+	assert.Equal(uint32(len(stripped)-8), binary.LittleEndian.Uint32(stripped[4:]))
+	assert.Regexp(`\.webp$`, images[0].Key())
+}
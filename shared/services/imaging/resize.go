@@ -0,0 +1,127 @@
+package imaging
+
+import (
+	"image"
+)
+
+// fit scales source down to fit within width by height, averaging the
+// source pixels that fall in each pixel of the result
+func fit(source *image.RGBA, width, height int) *image.RGBA {
+
+	w, h := source.Bounds().Dx(), source.Bounds().Dy()
+
+	if w <= width && h <= height {
+		return source
+	}
+
+	// the smaller of the two ratios decides the size
+	newWidth, newHeight := width, h*width/w
+
+	if h*width > w*height {
+		newWidth, newHeight = w*height/h, height
+	}
+
+	if newWidth < 1 {
+		newWidth = 1
+	}
+
+	if newHeight < 1 {
+		newHeight = 1
+	}
+
+	result := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+
+	for y := 0; y < newHeight; y++ {
+
+		y0, y1 := span(y, h, newHeight)
+
+		for x := 0; x < newWidth; x++ {
+
+			x0, x1 := span(x, w, newWidth)
+
+			var r, g, b, a, count uint64
+
+			for sy := y0; sy < y1; sy++ {
+
+				row := source.Pix[sy*source.Stride:]
+
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += uint64(pixel[0])
+					g += uint64(pixel[1])
+					b += uint64(pixel[2])
+					a += uint64(pixel[3])
+					count++
+				}
+			}
+
+			pixel := result.Pix[y*result.Stride+x*4:]
+			pixel[0] = uint8(r / count)
+			pixel[1] = uint8(g / count)
+			pixel[2] = uint8(b / count)
+			pixel[3] = uint8(a / count)
+		}
+	}
+
+	return result
+}
+
+// span returns the source pixels [from, to) that pixel i of a size scaled
+// down from source to size covers
+func span(i, source, size int) (int, int) {
+
+	from, to := i*source/size, (i+1)*source/size
+
+	if to <= from {
+		to = from + 1
+	}
+
+	return from, to
+}
+
+// orient turns source upright by its EXIF orientation, 1 to 8
+func orient(source *image.RGBA, orientation int) *image.RGBA {
+
+	if orientation < 2 || orientation > 8 {
+		return source
+	}
+
+	w, h := source.Bounds().Dx(), source.Bounds().Dy()
+
+	// 5 to 8 turn the image on its side
+	bounds := image.Rect(0, 0, w, h)
+
+	if orientation >= 5 {
+		bounds = image.Rect(0, 0, h, w)
+	}
+
+	result := image.NewRGBA(bounds)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+
+			var dx, dy int
+
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // upside down
+				dx, dy = w-1-x, h-1-y
+			case 4: // upside down and mirrored
+				dx, dy = x, h-1-y
+			case 5: // on its side and mirrored
+				dx, dy = y, x
+			case 6: // needs a quarter turn clockwise
+				dx, dy = h-1-y, x
+			case 7: // on its other side and mirrored
+				dx, dy = h-1-y, w-1-x
+			case 8: // needs a quarter turn anticlockwise
+				dx, dy = y, w-1-x
+			}
+
+			copy(result.Pix[dy*result.Stride+dx*4:dy*result.Stride+dx*4+4], source.Pix[y*source.Stride+x*4:y*source.Stride+x*4+4])
+		}
+	}
+
+	return result
+}
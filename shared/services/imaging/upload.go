@@ -0,0 +1,94 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/services/storage"
+	"autumnomous-jobs-employer-api/shared/services/validation"
+)
+
+// MaxUploadSize is the largest image file accepted, in bytes
+const MaxUploadSize = 10 << 20
+
+// ErrFileTooLarge is returned for an upload over MaxUploadSize
+var ErrFileTooLarge = domain.NewInvalid("file_too_large", "upload a file of at most 10 MB")
+
+// ReadFormFile returns the content of the multipart file field of r
+func ReadFormFile(w http.ResponseWriter, r *http.Request, field string) ([]byte, error) {
+
+	// the form's other fields and headers take a little more than the file
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize+1<<20)
+
+	file, _, err := r.FormFile(field)
+
+	if err != nil {
+		// net/http does not export the error of MaxBytesReader
+		if strings.Contains(err.Error(), "request body too large") {
+			return nil, ErrFileTooLarge
+		}
+		return nil, validation.Errors{{Field: field, Code: validation.Required, Message: field + " is required"}}
+	}
+
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > MaxUploadSize {
+		return nil, ErrFileTooLarge
+	}
+
+	return data, nil
+}
+
+// Store puts images in store under their keys, records them as uploads of
+// the employer, which may be empty, and returns their URLs by variant. The
+// uploads are unused until retained, so they expire unless the caller goes on
+// to use them.
+func Store(store storage.Storage, repository uploads.UploadRepository, employerPublicID string, images []*Image) (map[string]string, error) {
+
+	if store == nil {
+		return nil, errors.New("imaging: no storage configured")
+	}
+
+	urls := map[string]string{}
+	stored := map[string]string{}
+
+	for _, image := range images {
+
+		key := image.Key()
+		url, ok := stored[key]
+
+		if !ok {
+			var err error
+
+			url, err = store.Put(key, bytes.NewReader(image.Data), image.ContentType)
+
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = repository.RecordUpload(employerPublicID, key, url, image.ContentType, int64(len(image.Data)), time.Now().Add(uploads.OrphanRetention))
+
+			if err != nil {
+				return nil, err
+			}
+
+			stored[key] = url
+		}
+
+		urls[image.Variant] = url
+	}
+
+	return urls, nil
+}
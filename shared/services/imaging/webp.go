@@ -0,0 +1,121 @@
+package imaging
+
+import (
+	"encoding/binary"
+)
+
+// Flags of the VP8X chunk saying metadata chunks follow
+const (
+	webpEXIFFlag = 0x08
+	webpXMPFlag  = 0x04
+)
+
+// processWebP checks a WebP file and returns it without its EXIF and XMP
+// chunks, as is, for every variant
+func processWebP(data []byte, variants []Variant) ([]*Image, error) {
+
+	stripped, width, height, err := stripWebP(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSize(width, height); err != nil {
+		return nil, err
+	}
+
+	var images []*Image
+
+	for _, variant := range variants {
+		images = append(images, &Image{Variant: variant.Name, ContentType: WebP, Width: width, Height: height, Data: stripped})
+	}
+
+	return images, nil
+}
+
+// stripWebP returns the RIFF container in data without its metadata chunks,
+// and the size of its canvas
+func stripWebP(data []byte) ([]byte, int, int, error) {
+
+	if len(data) < 12 || int(binary.LittleEndian.Uint32(data[4:]))+8 > len(data) {
+		return nil, 0, 0, ErrCorrupt
+	}
+
+	data = data[:binary.LittleEndian.Uint32(data[4:])+8]
+
+	result := append([]byte(nil), data[:12]...)
+	width, height := 0, 0
+
+	for i := 12; i < len(data); {
+
+		if i+8 > len(data) {
+			return nil, 0, 0, ErrCorrupt
+		}
+
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2 // chunks are padded to an even size
+
+		if size < 0 || i+8+size > len(data) {
+			return nil, 0, 0, ErrCorrupt
+		}
+
+		if end > len(data) {
+			end = len(data)
+		}
+
+		payload := data[i+8 : i+8+size]
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+			i = end
+			continue
+		case "VP8X":
+			if size < 10 {
+				return nil, 0, 0, ErrCorrupt
+			}
+			width = int(uint24(payload[4:])) + 1
+			height = int(uint24(payload[7:])) + 1
+		case "VP8 ":
+			// a frame tag, the start code 9d 01 2a and 14 bit dimensions
+			if size < 10 || payload[3] != 0x9d || payload[4] != 0x01 || payload[5] != 0x2a {
+				return nil, 0, 0, ErrCorrupt
+			}
+			if width == 0 {
+				width = int(binary.LittleEndian.Uint16(payload[6:]) & 0x3fff)
+				height = int(binary.LittleEndian.Uint16(payload[8:]) & 0x3fff)
+			}
+		case "VP8L":
+			// a signature byte then 14 bits each of width-1 and height-1
+			if size < 5 || payload[0] != 0x2f {
+				return nil, 0, 0, ErrCorrupt
+			}
+			if width == 0 {
+				bits := binary.LittleEndian.Uint32(payload[1:])
+				width = int(bits&0x3fff) + 1
+				height = int(bits>>14&0x3fff) + 1
+			}
+		}
+
+		chunk := append([]byte(nil), data[i:end]...)
+
+		if fourCC == "VP8X" {
+			chunk[8] &^= webpEXIFFlag | webpXMPFlag
+		}
+
+		result = append(result, chunk...)
+		i = end
+	}
+
+	if width == 0 {
+		return nil, 0, 0, ErrCorrupt
+	}
+
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+
+	return result, width, height, nil
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
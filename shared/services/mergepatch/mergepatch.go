@@ -0,0 +1,101 @@
+// Package mergepatch applies RFC 7396 JSON Merge Patch documents. Members of
+// the patch replace those of the target, null removes a member, and members
+// the patch leaves out are unchanged.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// ContentType is the media type of a merge patch document
+const ContentType = "application/merge-patch+json"
+
+// ErrNotObject is returned for a patch that is not a JSON object. RFC 7396
+// allows any value, but a resource can only be patched by an object.
+var ErrNotObject = errors.New("a merge patch must be a JSON object")
+
+// Apply returns target with patch merged into it
+func Apply(target, patch []byte) ([]byte, error) {
+
+	var patchValue interface{}
+
+	if err := decode(patch, &patchValue); err != nil {
+		return nil, err
+	}
+
+	if _, ok := patchValue.(map[string]interface{}); !ok {
+		return nil, ErrNotObject
+	}
+
+	var targetValue interface{}
+
+	if err := decode(target, &targetValue); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(merge(targetValue, patchValue))
+}
+
+// Patch merges patch into the JSON form of value, a pointer to a struct,
+// then decodes the result back into it. Removed members are cleared, and
+// members value does not have are rejected.
+func Patch(value interface{}, patch []byte) error {
+
+	target, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	patched, err := Apply(target, patch)
+
+	if err != nil {
+		return err
+	}
+
+	element := reflect.ValueOf(value).Elem()
+	element.Set(reflect.Zero(element.Type()))
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(value)
+}
+
+func merge(target, patch interface{}) interface{} {
+
+	patchObject, ok := patch.(map[string]interface{})
+
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = merge(targetObject[name], value)
+		}
+	}
+
+	return targetObject
+}
+
+// decode keeps numbers as json.Number, so large integers survive the round
+// trip
+func decode(data []byte, value *interface{}) error {
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(value)
+}
@@ -0,0 +1,69 @@
+package mergepatch_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/services/mergepatch"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MergePatch_Apply(t *testing.T) {
+
+	// the object examples of RFC 7396 appendix A
+	tests := map[string]struct {
+		target, patch, result string
+	}{
+		"Replace":       {`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		"Add":           {`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		"Remove":        {`{"a":"b"}`, `{"a":null}`, `{}`},
+		"RemoveOne":     {`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		"ArrayValue":    {`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		"ReplaceArray":  {`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		"Nested":        {`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		"ArrayOfObject": {`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		"NotObject":     {`["a","b"]`, `{"a":"b"}`, `{"a":"b"}`},
+		"NullTarget":    {`null`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		"Empty":         {`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		"LargeInteger":  {`{"a":9007199254740993}`, `{"b":1}`, `{"a":9007199254740993,"b":1}`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := mergepatch.Apply([]byte(test.target), []byte(test.patch))
+
+			assert.Nil(t, err)
+			assert.JSONEq(t, test.result, string(result))
+		})
+	}
+}
+
+func Test_MergePatch_Apply_NotObject(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := mergepatch.Apply([]byte(`{"a":"b"}`), []byte(`["c"]`))
+	assert.Equal(mergepatch.ErrNotObject, err)
+
+	_, err = mergepatch.Apply([]byte(`{"a":"b"}`), []byte(`{"a":`))
+	assert.NotNil(err)
+}
+
+func Test_MergePatch_Patch(t *testing.T) {
+	assert := assert.New(t)
+
+	type resource struct {
+		Name   string `json:"name"`
+		Phone  string `json:"phone"`
+		Salary int64  `json:"salary"`
+		Remote bool   `json:"remote"`
+	}
+
+	value := resource{Name: "Welder", Phone: "555-0100", Salary: 40000, Remote: true}
+
+	err := mergepatch.Patch(&value, []byte(`{"phone":null,"salary":0,"remote":false}`))
+
+	assert.Nil(err)
+	assert.Equal(resource{Name: "Welder"}, value)
+
+	assert.NotNil(mergepatch.Patch(&value, []byte(`{"colour":"red"}`)), "unknown members are rejected")
+}
@@ -1 +1,87 @@
+// Package email sends transactional email to employers
 package email
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/config"
+
+	mailgun "github.com/mailgun/mailgun-go/v4"
+)
+
+// Sender is the From address of every message
+const Sender = "BiT Jobs Support <admin@autumnomous.git.beanstalkapp.com/autumnomous-jobs-employer-api>"
+
+// Message is a plain text email
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// Mailer delivers messages and returns the provider's message id
+type Mailer interface {
+	Send(ctx context.Context, message *Message) (string, error)
+}
+
+// WelcomeMessage is sent to a new employer with their temporary password
+func WelcomeMessage(firstName, address, password string) *Message {
+	return &Message{
+		To:      address,
+		Subject: "Welcome to BiT Jobs!",
+		Text:    fmt.Sprintf("Thank you for joining BiT Jobs, %s!\nYour temporary password is %s", firstName, password),
+	}
+}
+
+// MailgunMailer sends messages through the Mailgun API
+type MailgunMailer struct {
+	client  mailgun.Mailgun
+	timeout time.Duration
+}
+
+// NewMailgunMailer returns a Mailer for the configured Mailgun domain
+func NewMailgunMailer(settings config.Mailgun) *MailgunMailer {
+	return &MailgunMailer{
+		client:  mailgun.NewMailgun(settings.Domain, settings.APIKey),
+		timeout: 10 * time.Second,
+	}
+}
+
+func (mailer *MailgunMailer) Send(ctx context.Context, message *Message) (string, error) {
+
+	if message == nil || message.To == "" {
+		return "", errors.New("message has no recipient")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, mailer.timeout)
+	defer cancel()
+
+	m := mailer.client.NewMessage(Sender, message.Subject, message.Text, message.To)
+
+	_, id, err := mailer.client.Send(ctx, m)
+
+	return id, err
+}
+
+// Kind is the queue task kind of messages waiting to be sent
+const Kind = "email"
+
+// Deliver returns a task handler that sends each queued Message through mailer
+func Deliver(mailer Mailer) func(ctx context.Context, payload json.RawMessage) error {
+	return func(ctx context.Context, payload json.RawMessage) error {
+
+		var message Message
+
+		if err := json.Unmarshal(payload, &message); err != nil {
+			return err
+		}
+
+		_, err := mailer.Send(ctx, &message)
+
+		return err
+	}
+}
@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+//go:embed swagger.html
+var swaggerPage string
+
+var swaggerTemplate = template.Must(template.New("swagger").Parse(swaggerPage))
+
+// Handler serves document as JSON. It is encoded once, as it does not change
+// while the API runs.
+func Handler(document *Document) http.HandlerFunc {
+
+	js, err := json.Marshal(document)
+
+	if err != nil {
+		panic("openapi: encoding the document: " + err.Error())
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write(js)
+	}
+}
+
+// UI serves a Swagger UI page for the document at specURL. The page loads
+// Swagger UI itself from unpkg.com.
+func UI(specURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		if err := swaggerTemplate.Execute(w, specURL); err != nil {
+			log.Println(err)
+		}
+	}
+}
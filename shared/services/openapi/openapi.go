@@ -0,0 +1,451 @@
+// Package openapi builds an OpenAPI 3 document from a list of operations,
+// generating the schemas of their request and response bodies from the Go
+// types the handlers decode and send. The json tags name the properties and
+// the validate tags, as read by the validation package, become their
+// constraints.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version of the documents built here
+const Version = "3.0.3"
+
+// Operation describes one route
+type Operation struct {
+	// ID names the operation, and the method calling it in the client package
+	ID string
+
+	Method  string
+	Path    string // in httprouter syntax, such as /v2/jobs/:id
+	Summary string
+	Tags    []string
+
+	// Security names the security scheme of the route, empty for none
+	Security string
+
+	Query []Parameter
+
+	// Request is a value of the body type, nil for none. MediaType defaults
+	// to application/json.
+	Request   interface{}
+	MediaType string
+
+	// Response is a value of the body sent on success, nil for none, and
+	// Status its status, 200 by default
+	Response interface{}
+	Status   int
+
+	Deprecated bool
+}
+
+// Parameter is a query parameter
+type Parameter struct {
+	Name        string
+	Description string
+	Required    bool
+	Type        string // a JSON schema type, string by default
+}
+
+// Form is the request body of a multipart form upload, listing its file fields
+type Form []string
+
+// SecurityScheme is how a client authenticates
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]*Endpoint `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components holds the schemas and security schemes operations refer to
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// Endpoint is one method of one path of a Document
+type Endpoint struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*parameter          `json:"parameters,omitempty"`
+	RequestBody *body                 `json:"requestBody,omitempty"`
+	Responses   map[string]*response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type body struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a JSON schema, in the subset OpenAPI 3.0 supports
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// Builder collects operations into a Document
+type Builder struct {
+	document *Document
+	types    map[string]reflect.Type
+
+	// Problem is the body of every error response, nil for none
+	Problem interface{}
+}
+
+// NewBuilder returns a Builder for a document described by info, whose
+// routes authenticate with schemes
+func NewBuilder(info Info, schemes map[string]*SecurityScheme) *Builder {
+	return &Builder{
+		document: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]map[string]*Endpoint{},
+			Components: Components{
+				Schemas:         map[string]*Schema{},
+				SecuritySchemes: schemes,
+			},
+		},
+		types: map[string]reflect.Type{},
+	}
+}
+
+// Add documents operations
+func (builder *Builder) Add(operations ...Operation) {
+	for _, operation := range operations {
+		builder.add(operation)
+	}
+}
+
+// Document returns the document built so far
+func (builder *Builder) Document() *Document {
+	return builder.document
+}
+
+func (builder *Builder) add(operation Operation) {
+
+	path, names := Path(operation.Path)
+
+	endpoint := &Endpoint{
+		OperationID: operation.ID,
+		Summary:     operation.Summary,
+		Tags:        operation.Tags,
+		Responses:   map[string]*response{},
+		Deprecated:  operation.Deprecated,
+	}
+
+	for _, name := range names {
+		endpoint.Parameters = append(endpoint.Parameters, &parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+
+	for _, query := range operation.Query {
+		kind := query.Type
+
+		if kind == "" {
+			kind = "string"
+		}
+
+		endpoint.Parameters = append(endpoint.Parameters, &parameter{Name: query.Name, In: "query", Description: query.Description, Required: query.Required, Schema: &Schema{Type: kind}})
+	}
+
+	if operation.Request != nil {
+		media := operation.MediaType
+
+		if media == "" {
+			media = "application/json"
+		}
+
+		var schema *Schema
+
+		if form, ok := operation.Request.(Form); ok {
+			media = "multipart/form-data"
+			schema = &Schema{Type: "object", Properties: map[string]*Schema{}, Required: form}
+
+			for _, field := range form {
+				schema.Properties[field] = &Schema{Type: "string", Format: "binary"}
+			}
+		} else {
+			schema = builder.Schema(reflect.TypeOf(operation.Request))
+		}
+
+		endpoint.RequestBody = &body{Required: true, Content: map[string]*mediaType{media: {Schema: schema}}}
+	}
+
+	status := operation.Status
+
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := &response{Description: http.StatusText(status)}
+
+	if operation.Response != nil {
+		success.Content = map[string]*mediaType{"application/json": {Schema: builder.Schema(reflect.TypeOf(operation.Response))}}
+	}
+
+	endpoint.Responses[strconv.Itoa(status)] = success
+
+	if builder.Problem != nil {
+		endpoint.Responses["default"] = &response{
+			Description: "A problem, see RFC 7807",
+			Content:     map[string]*mediaType{"application/problem+json": {Schema: builder.Schema(reflect.TypeOf(builder.Problem))}},
+		}
+	}
+
+	if operation.Security != "" {
+		endpoint.Security = []map[string][]string{{operation.Security: {}}}
+	}
+
+	if builder.document.Paths[path] == nil {
+		builder.document.Paths[path] = map[string]*Endpoint{}
+	}
+
+	builder.document.Paths[path][strings.ToLower(operation.Method)] = endpoint
+}
+
+// Path converts an httprouter path to an OpenAPI path, returning the names of
+// its parameters
+func Path(route string) (string, []string) {
+
+	segments := strings.Split(route, "/")
+	var names []string
+
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			names = append(names, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), names
+}
+
+var (
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Schema returns the schema of t. Named structs are added to the document's
+// components and referred to.
+func (builder *Builder) Schema(t reflect.Type) *Schema {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.PkgPath() == "time" && t.Name() == "Time":
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType):
+		// a custom encoding could be anything
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: builder.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: builder.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return builder.object(t)
+		}
+		return builder.ref(t)
+	}
+
+	// interface{} and anything else accepts any value
+	return &Schema{}
+}
+
+func (builder *Builder) ref(t reflect.Type) *Schema {
+
+	name := t.Name()
+
+	if other, ok := builder.types[name]; ok && other != t {
+		// the same name in another package
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	if _, ok := builder.types[name]; !ok {
+		builder.types[name] = t
+		// added before its fields, so recursive types refer to themselves
+		builder.document.Components.Schemas[name] = &Schema{}
+		*builder.document.Components.Schemas[name] = *builder.object(t)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (builder *Builder) object(t reflect.Type) *Schema {
+
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+
+		field := t.Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("json"), ",")
+
+		if tag[0] == "-" {
+			continue
+		}
+
+		if field.Anonymous && tag[0] == "" && field.Type.Kind() == reflect.Struct {
+			embedded := builder.object(field.Type)
+
+			for name, property := range embedded.Properties {
+				schema.Properties[name] = property
+			}
+
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		name := tag[0]
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := builder.Schema(field.Type)
+
+		if constrain(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+	}
+
+	return schema
+}
+
+// constrain applies the validate rules to schema, and reports whether the
+// field is required
+func constrain(schema *Schema, rules string) bool {
+
+	required := false
+
+	if rules == "" {
+		return false
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+
+		name, argument := rule, ""
+
+		if index := strings.Index(rule, "="); index >= 0 {
+			name, argument = rule[:index], rule[index+1:]
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "oneof":
+			schema.Enum = strings.Fields(argument)
+		case "min", "max":
+			limit(schema, name == "min", argument)
+		}
+	}
+
+	return required
+}
+
+func limit(schema *Schema, lower bool, argument string) {
+
+	number, err := strconv.ParseFloat(argument, 64)
+
+	if err != nil {
+		return
+	}
+
+	size := int(number)
+
+	switch {
+	case schema.Type == "string" && lower:
+		schema.MinLength = &size
+	case schema.Type == "string":
+		schema.MaxLength = &size
+	case schema.Type == "array" && lower:
+		schema.MinItems = &size
+	case schema.Type == "array":
+		schema.MaxItems = &size
+	case lower:
+		schema.Minimum = &number
+	default:
+		schema.Maximum = &number
+	}
+}
@@ -0,0 +1,79 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/services/openapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `json:"city"`
+}
+
+type person struct {
+	Name     string            `json:"name" validate:"required,max=50"`
+	Email    string            `json:"email" validate:"email"`
+	Site     string            `json:"site" validate:"url"`
+	Role     string            `json:"role" validate:"oneof=admin member"`
+	Age      int               `json:"age" validate:"min=18"`
+	Tags     []string          `json:"tags" validate:"max=5"`
+	Born     time.Time         `json:"born"`
+	Home     *address          `json:"home"`
+	Extra    map[string]string `json:"extra"`
+	Password string            `json:"-"`
+	secret   string
+}
+
+func Test_OpenAPI_Schema(t *testing.T) {
+	assert := assert.New(t)
+
+	builder := openapi.NewBuilder(openapi.Info{Title: "Test", Version: "1"}, nil)
+
+	assert.Equal("#/components/schemas/person", builder.Schema(reflect.TypeOf(&person{})).Ref)
+
+	schema := builder.Document().Components.Schemas["person"]
+
+	assert.Equal([]string{"name"}, schema.Required)
+	assert.Equal(50, *schema.Properties["name"].MaxLength)
+	assert.Equal("email", schema.Properties["email"].Format)
+	assert.Equal("uri", schema.Properties["site"].Format)
+	assert.Equal([]string{"admin", "member"}, schema.Properties["role"].Enum)
+	assert.Equal(float64(18), *schema.Properties["age"].Minimum)
+	assert.Equal(5, *schema.Properties["tags"].MaxItems)
+	assert.Equal("date-time", schema.Properties["born"].Format)
+	assert.Equal("#/components/schemas/address", schema.Properties["home"].Ref)
+	assert.Equal("string", schema.Properties["extra"].AdditionalProperties.Type)
+	assert.NotContains(schema.Properties, "Password")
+	assert.NotContains(schema.Properties, "secret")
+}
+
+func Test_OpenAPI_Add(t *testing.T) {
+	assert := assert.New(t)
+
+	builder := openapi.NewBuilder(openapi.Info{Title: "Test", Version: "1"}, nil)
+	builder.Add(openapi.Operation{ID: "patchPerson", Method: http.MethodPatch, Path: "/people/:id", Request: person{}, MediaType: "application/merge-patch+json", Response: person{}, Security: "token"})
+
+	endpoint := builder.Document().Paths["/people/{id}"]["patch"]
+
+	if assert.NotNil(endpoint) {
+		assert.Equal("patchPerson", endpoint.OperationID)
+		assert.Equal("id", endpoint.Parameters[0].Name)
+		assert.Contains(endpoint.RequestBody.Content, "application/merge-patch+json")
+		assert.Contains(endpoint.Responses, "200")
+		assert.Equal([]map[string][]string{{"token": {}}}, endpoint.Security)
+	}
+
+	path, names := openapi.Path("/v2/jobs/:id/revisions")
+	assert.Equal("/v2/jobs/{id}/revisions", path)
+	assert.Equal([]string{"id"}, names)
+
+	recorder := httptest.NewRecorder()
+	openapi.UI("/openapi.json")(recorder, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Contains(recorder.Body.String(), `url: "\/openapi.json"`)
+}
@@ -0,0 +1,276 @@
+// Package presign lets clients upload files straight to object storage
+// instead of through the API. A client declares the file it will upload and
+// is given a presigned PUT URL, uploads to it, then completes the upload,
+// which checks the stored object against what was declared. Only a complete
+// upload can be used.
+package presign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/services/imaging"
+	"autumnomous-jobs-employer-api/shared/services/storage"
+
+	"github.com/google/uuid"
+)
+
+// URLExpiry is how long a presigned URL can be uploaded to
+const URLExpiry = 15 * time.Minute
+
+// MaxSize is the largest file that can be uploaded, in bytes
+const MaxSize = imaging.MaxUploadSize
+
+// Document types that can be uploaded besides images
+const (
+	PDF  = "application/pdf"
+	DOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+)
+
+// extensions are the types that can be uploaded, and the extension of their keys
+var extensions = map[string]string{
+	imaging.PNG:  ".png",
+	imaging.JPEG: ".jpg",
+	imaging.GIF:  ".gif",
+	imaging.WebP: ".webp",
+	PDF:          ".pdf",
+	DOCX:         ".docx",
+}
+
+var (
+	// ErrUnsupportedType is returned for a declared type that cannot be uploaded
+	ErrUnsupportedType = domain.NewInvalid("unsupported_type", "upload a PNG, JPEG, GIF or WebP image, or a PDF or DOCX document")
+	// ErrNotUploaded is returned when completing an upload that has no object
+	ErrNotUploaded = domain.NewConflict("upload_missing", "nothing has been uploaded to the presigned URL")
+	// ErrSizeMismatch is returned for an object that is not the size declared
+	ErrSizeMismatch = domain.NewInvalid("upload_size_mismatch", "the uploaded file is not the size that was declared")
+	// ErrTypeMismatch is returned for an object that is not the type declared
+	ErrTypeMismatch = domain.NewInvalid("upload_type_mismatch", "the uploaded file is not of the type that was declared")
+	// ErrNotComplete is returned for using an upload that is not complete
+	ErrNotComplete = domain.NewConflict("upload_not_complete", "complete the upload before using it")
+
+	errNoStorage = errors.New("presign: no storage configured")
+)
+
+// ContentTypes returns the types that can be uploaded
+func ContentTypes() []string {
+
+	var types []string
+
+	for contentType := range extensions {
+		types = append(types, contentType)
+	}
+
+	sort.Strings(types)
+
+	return types
+}
+
+// Sniff returns the content type of the image or document in data, or empty
+// when data is neither. A DOCX is told from other zip files by the names of
+// its first entries, which are within the first 512 bytes.
+func Sniff(data []byte) string {
+
+	if contentType := imaging.Sniff(data); contentType != "" {
+		return contentType
+	}
+
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return PDF
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		head := data
+		if len(head) > 512 {
+			head = head[:512]
+		}
+		if bytes.Contains(head, []byte("[Content_Types].xml")) || bytes.Contains(head, []byte("word/")) {
+			return DOCX
+		}
+	}
+
+	return ""
+}
+
+// Request declares a file a client will upload
+type Request struct {
+	FileName    string `json:"filename" validate:"max=255"`
+	ContentType string `json:"contenttype" validate:"required"`
+	Size        int64  `json:"size" validate:"required,min=1"`
+}
+
+// Presigned is where to upload a file: a PUT of the file to URL, with
+// Headers, before ExpiresAt
+type Presigned struct {
+	Upload    *uploads.Upload   `json:"upload"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresat"`
+}
+
+// UploadReference names an upload
+type UploadReference struct {
+	Upload string `json:"upload" validate:"required"`
+}
+
+// Check returns an error for a file that cannot be uploaded
+func Check(request *Request) error {
+
+	if _, ok := extensions[request.ContentType]; !ok {
+		return ErrUnsupportedType
+	}
+
+	if request.Size > MaxSize {
+		return imaging.ErrFileTooLarge
+	}
+
+	return nil
+}
+
+// Key returns a new storage key for a file of contentType
+func Key(contentType string) string {
+	return "uploads/" + uuid.NewString() + extensions[contentType]
+}
+
+// Sign returns where to upload the file of a pending upload
+func Sign(store storage.Storage, upload *uploads.Upload) (*Presigned, error) {
+
+	if store == nil {
+		return nil, errNoStorage
+	}
+
+	url, err := store.SignedURL(http.MethodPut, upload.Key, URLExpiry)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Presigned{
+		Upload:    upload,
+		Method:    http.MethodPut,
+		URL:       url,
+		Headers:   map[string]string{"Content-Type": upload.ContentType},
+		ExpiresAt: time.Now().Add(URLExpiry),
+	}, nil
+}
+
+// Verify checks the object of a pending upload is the size and type that
+// were declared, by its content, and returns its size. An object that is
+// not is deleted, so it cannot be used.
+func Verify(store storage.Storage, upload *uploads.Upload) (int64, error) {
+
+	if store == nil {
+		return 0, errNoStorage
+	}
+
+	object, err := store.Get(upload.Key)
+
+	if err == storage.ErrNotFound {
+		return 0, ErrNotUploaded
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer object.Body.Close()
+
+	if object.Size != upload.Size {
+		return 0, reject(store, upload, ErrSizeMismatch)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(object.Body, head)
+
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+
+	if Sniff(head[:n]) != upload.ContentType {
+		return 0, reject(store, upload, ErrTypeMismatch)
+	}
+
+	return object.Size, nil
+}
+
+// Read returns the content of a complete upload
+func Read(store storage.Storage, upload *uploads.Upload) ([]byte, error) {
+
+	if upload.Status != uploads.Complete {
+		return nil, ErrNotComplete
+	}
+
+	if store == nil {
+		return nil, errNoStorage
+	}
+
+	object, err := store.Get(upload.Key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer object.Body.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(object.Body, MaxSize+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the presigned URL may have been used again after the upload completed
+	if int64(len(data)) != upload.Size {
+		return nil, ErrSizeMismatch
+	}
+
+	if Sniff(data) != upload.ContentType {
+		return nil, ErrTypeMismatch
+	}
+
+	return data, nil
+}
+
+// Publish puts content in store under a key derived from it, records it as
+// an upload of the employer and returns its URL. Like imaging.Store, the
+// upload expires unless the caller goes on to retain it.
+func Publish(store storage.Storage, repository uploads.UploadRepository, employerPublicID, contentType string, content []byte) (string, error) {
+
+	if store == nil {
+		return "", errNoStorage
+	}
+
+	sum := sha256.Sum256(content)
+	key := "files/" + hex.EncodeToString(sum[:]) + extensions[contentType]
+
+	url, err := store.Put(key, bytes.NewReader(content), contentType)
+
+	if err != nil {
+		return "", err
+	}
+
+	_, err = repository.RecordUpload(employerPublicID, key, url, contentType, int64(len(content)), time.Now().Add(uploads.OrphanRetention))
+
+	if err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+func reject(store storage.Storage, upload *uploads.Upload, reason error) error {
+
+	if err := store.Delete(upload.Key); err != nil {
+		return err
+	}
+
+	return reason
+}
@@ -0,0 +1,145 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// chunkSize is the largest chunk of content sent in one INSTREAM frame. It
+// must stay under clamd's StreamMaxLength.
+const chunkSize = 64 << 10
+
+// ClamAV scans content with a ClamAV daemon, clamd, through its INSTREAM
+// command over TCP or a unix socket
+type ClamAV struct {
+	Network string // tcp or unix
+	Address string
+
+	// Timeout bounds a whole scan, connecting included, when the context
+	// has no earlier deadline
+	Timeout time.Duration
+}
+
+// NewClamAV returns a client of the daemon at address, given as
+// tcp://host:port or unix:///path/to/clamd.sock
+func NewClamAV(address string, timeout time.Duration) (*ClamAV, error) {
+
+	parsed, err := url.Parse(address)
+
+	if err != nil {
+		return nil, fmt.Errorf("scan: clamd address %q: %w", address, err)
+	}
+
+	switch parsed.Scheme {
+	case "tcp":
+		if parsed.Host == "" {
+			break
+		}
+		return &ClamAV{Network: "tcp", Address: parsed.Host, Timeout: timeout}, nil
+	case "unix":
+		if parsed.Path == "" {
+			break
+		}
+		return &ClamAV{Network: "unix", Address: parsed.Path, Timeout: timeout}, nil
+	}
+
+	return nil, fmt.Errorf("scan: clamd address %q is not tcp://host:port or unix:///path", address)
+}
+
+func (clamav *ClamAV) Scan(ctx context.Context, content []byte) (*Result, error) {
+
+	if clamav.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, clamav.Timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, clamav.Network, clamav.Address)
+
+	if err != nil {
+		return nil, fmt.Errorf("scan: clamd: %w", err)
+	}
+
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if err := instream(conn, content); err != nil {
+		return nil, fmt.Errorf("scan: clamd: %w", err)
+	}
+
+	// the z prefix of the command makes clamd end its reply with a NUL
+	reply, err := bufio.NewReader(conn).ReadString(0)
+
+	if err != nil && reply == "" {
+		return nil, fmt.Errorf("scan: clamd: %w", err)
+	}
+
+	return parseReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// instream sends content as INSTREAM frames: each chunk after its length as a
+// 4 byte big endian integer, then an empty chunk to end the stream
+func instream(conn net.Conn, content []byte) error {
+
+	writer := bufio.NewWriter(conn)
+
+	if _, err := writer.WriteString("zINSTREAM\x00"); err != nil {
+		return err
+	}
+
+	length := make([]byte, 4)
+
+	for len(content) > 0 {
+		chunk := content
+
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+
+		binary.BigEndian.PutUint32(length, uint32(len(chunk)))
+
+		if _, err := writer.Write(length); err != nil {
+			return err
+		}
+
+		if _, err := writer.Write(chunk); err != nil {
+			return err
+		}
+
+		content = content[len(chunk):]
+	}
+
+	binary.BigEndian.PutUint32(length, 0)
+
+	if _, err := writer.Write(length); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// parseReply reads clamd's answer to INSTREAM: "stream: OK",
+// "stream: <signature> FOUND", or a message ending in ERROR
+func parseReply(reply string) (*Result, error) {
+
+	status := strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case status == "OK":
+		return &Result{Verdict: Clean}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		return &Result{Verdict: Infected, Reason: strings.TrimSuffix(status, " FOUND")}, nil
+	}
+
+	return nil, fmt.Errorf("scan: clamd: %s", reply)
+}
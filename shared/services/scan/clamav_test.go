@@ -0,0 +1,151 @@
+package scan_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/services/scan"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// serveClamd answers INSTREAM commands on listener like clamd, finding
+// the EICAR test string
+func serveClamd(t *testing.T, listener net.Listener) {
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				reader := bufio.NewReader(conn)
+				command, err := reader.ReadString(0)
+
+				if err != nil || command != "zINSTREAM\x00" {
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+
+				var content bytes.Buffer
+				length := make([]byte, 4)
+
+				for {
+					if _, err := io.ReadFull(reader, length); err != nil {
+						return
+					}
+
+					size := binary.BigEndian.Uint32(length)
+
+					if size == 0 {
+						break
+					}
+
+					if _, err := io.CopyN(&content, reader, int64(size)); err != nil {
+						return
+					}
+				}
+
+				if bytes.Contains(content.Bytes(), []byte(eicar)) {
+					conn.Write([]byte("stream: Win.Test.EICAR_HDB-1 FOUND\x00"))
+					return
+				}
+
+				conn.Write([]byte("stream: OK\x00"))
+			}()
+		}
+	}()
+}
+
+func Test_ClamAV_Scan(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serveClamd(t, listener)
+
+	clamav, err := scan.NewClamAV("tcp://"+listener.Addr().String(), time.Second)
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	result, err := clamav.Scan(ctx, png)
+	assert.NoError(err)
+	assert.Equal(scan.Clean, result.Verdict)
+
+	// content is sent in chunks
+	infected := append(bytes.Repeat([]byte{' '}, 200<<10), eicar...)
+
+	result, err = clamav.Scan(ctx, infected)
+	assert.NoError(err)
+	assert.Equal(&scan.Result{Verdict: scan.Infected, Reason: "Win.Test.EICAR_HDB-1"}, result)
+}
+
+func Test_ClamAV_Unix(t *testing.T) {
+	assert := assert.New(t)
+
+	socket := filepath.Join(t.TempDir(), "clamd.sock")
+	listener, err := net.Listen("unix", socket)
+
+	if err != nil {
+		t.Skip("unix sockets are not available:", err)
+	}
+
+	serveClamd(t, listener)
+
+	clamav, err := scan.NewClamAV("unix://"+socket, time.Second)
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	result, err := clamav.Scan(context.Background(), []byte(eicar))
+	assert.NoError(err)
+	assert.Equal(scan.Infected, result.Verdict)
+}
+
+func Test_ClamAV_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, address := range []string{"clamd:3310", "tcp://", "unix://", "http://clamd:3310"} {
+		_, err := scan.NewClamAV(address, time.Second)
+		assert.Error(err, address)
+	}
+
+	// a daemon that is down fails the scan rather than passing the content
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := listener.Addr().String()
+	listener.Close()
+
+	clamav, _ := scan.NewClamAV("tcp://"+address, time.Second)
+
+	_, err = clamav.Scan(context.Background(), png)
+	assert.Error(err)
+}
@@ -0,0 +1,50 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+)
+
+// Policy rejects content larger than MaxSize bytes, or not of one of
+// ContentTypes as Sniff detects it from the content. A zero MaxSize or empty
+// ContentTypes allows any size or type. Sniff defaults to
+// http.DetectContentType.
+type Policy struct {
+	MaxSize      int64
+	ContentTypes []string
+	Sniff        func(content []byte) string
+}
+
+func (policy *Policy) Scan(ctx context.Context, content []byte) (*Result, error) {
+
+	if policy.MaxSize > 0 && int64(len(content)) > policy.MaxSize {
+		return &Result{Verdict: Rejected, Reason: fmt.Sprintf("larger than %d bytes", policy.MaxSize)}, nil
+	}
+
+	if len(policy.ContentTypes) == 0 {
+		return &Result{Verdict: Clean}, nil
+	}
+
+	contentType := policy.sniff(content)
+
+	for _, allowed := range policy.ContentTypes {
+		if contentType == allowed {
+			return &Result{Verdict: Clean}, nil
+		}
+	}
+
+	if contentType == "" {
+		contentType = "unknown"
+	}
+
+	return &Result{Verdict: Rejected, Reason: "content type " + contentType + " is not allowed"}, nil
+}
+
+func (policy *Policy) sniff(content []byte) string {
+
+	if policy.Sniff != nil {
+		return policy.Sniff(content)
+	}
+
+	return detect(content)
+}
@@ -0,0 +1,108 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/services/storage"
+
+	"github.com/google/uuid"
+)
+
+var errNoStorage = errors.New("scan: no storage configured")
+
+// Check scans content sent through the API for the employer, which may be
+// empty. Content that does not pass is quarantined and recorded as an upload,
+// and the error returned reports it.
+func Check(ctx context.Context, scanner Scanner, store storage.Storage, repository uploads.UploadRepository, employerPublicID, fileName string, content []byte) error {
+
+	result, err := scanner.Scan(ctx, content)
+
+	if err != nil || result.Verdict == Clean {
+		return err
+	}
+
+	if store == nil {
+		return errNoStorage
+	}
+
+	contentType := detect(content)
+
+	key, err := store.Quarantine(uuid.NewString(), bytes.NewReader(content), contentType)
+
+	if err != nil {
+		return err
+	}
+
+	upload, err := repository.RecordQuarantine(employerPublicID, key, fileName, contentType, int64(len(content)), result.String(), time.Now().Add(uploads.QuarantineRetention))
+
+	if err != nil {
+		return err
+	}
+
+	return report(result, upload.PublicID)
+}
+
+// CheckUpload scans the object of a pending upload. An object that does not
+// pass is moved to quarantine and the upload with it, and the error returned
+// reports it.
+func CheckUpload(ctx context.Context, scanner Scanner, store storage.Storage, repository uploads.UploadRepository, upload *uploads.Upload) error {
+
+	if store == nil {
+		return errNoStorage
+	}
+
+	object, err := store.Get(upload.Key)
+
+	if err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadAll(object.Body)
+	object.Body.Close()
+
+	if err != nil {
+		return err
+	}
+
+	result, err := scanner.Scan(ctx, content)
+
+	if err != nil || result.Verdict == Clean {
+		return err
+	}
+
+	key, err := store.Quarantine(upload.Key, bytes.NewReader(content), upload.ContentType)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := repository.QuarantineUpload(upload.PublicID, key, result.String(), time.Now().Add(uploads.QuarantineRetention)); err != nil {
+		return err
+	}
+
+	if err := store.Delete(upload.Key); err != nil {
+		return err
+	}
+
+	return report(result, upload.PublicID)
+}
+
+// detect returns the type http.DetectContentType sniffs from content, without
+// parameters such as the charset of text types
+func detect(content []byte) string {
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(content))
+
+	if err != nil {
+		return "application/octet-stream"
+	}
+
+	return mediaType
+}
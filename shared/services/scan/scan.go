@@ -0,0 +1,96 @@
+// Package scan checks uploads before they are published. A Scanner returns a
+// Result for the content of an upload: Policy checks its size and type,
+// ClamAV sends it to a ClamAV daemon and Noop passes everything. Content that
+// does not pass is kept in quarantine, where it is never served, and the
+// error returned for it tells the client so.
+package scan
+
+import (
+	"context"
+	"fmt"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+)
+
+// Verdicts
+const (
+	Clean    = "clean"
+	Infected = "infected"
+	Rejected = "rejected"
+)
+
+// Result is a scanner's verdict on content, and the reason for it when it is
+// not clean, such as the name of the malware found
+type Result struct {
+	Verdict string `json:"verdict"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+func (result *Result) String() string {
+
+	if result.Reason == "" {
+		return result.Verdict
+	}
+
+	return result.Verdict + ": " + result.Reason
+}
+
+// Scanner checks content before it is published. It returns an error only
+// when it could not reach a verdict.
+type Scanner interface {
+	Scan(ctx context.Context, content []byte) (*Result, error)
+}
+
+var (
+	// ErrInfected is returned for an upload a scanner found malware in
+	ErrInfected = domain.NewInvalid("upload_infected", "the file was quarantined because malware was found in it")
+
+	// ErrRejected is returned for an upload that breaks the upload policy
+	ErrRejected = domain.NewInvalid("upload_rejected", "the file was quarantined because it breaks the upload policy")
+)
+
+// Noop passes all content, for when uploads are scanned elsewhere
+type Noop struct{}
+
+func (Noop) Scan(ctx context.Context, content []byte) (*Result, error) {
+	return &Result{Verdict: Clean}, nil
+}
+
+// Chain runs its scanners in order and returns the first result that is not
+// clean, so cheap checks can go before a daemon is asked
+type Chain []Scanner
+
+func (chain Chain) Scan(ctx context.Context, content []byte) (*Result, error) {
+
+	for _, scanner := range chain {
+		result, err := scanner.Scan(ctx, content)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Verdict != Clean {
+			return result, nil
+		}
+	}
+
+	return &Result{Verdict: Clean}, nil
+}
+
+// report returns the error that tells the client content was quarantined as
+// the upload uploadPublicID because of result
+func report(result *Result, uploadPublicID string) error {
+
+	reported := ErrRejected
+
+	if result.Verdict == Infected {
+		reported = ErrInfected
+	}
+
+	return &domain.Error{
+		Kind:    reported.Kind,
+		Code:    reported.Code,
+		Message: fmt.Sprintf("%s (upload %s, %s)", reported.Message, uploadPublicID, result),
+		Err:     reported,
+	}
+}
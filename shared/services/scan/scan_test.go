@@ -0,0 +1,177 @@
+package scan_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+	"autumnomous-jobs-employer-api/shared/repository/memory"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/services/imaging"
+	"autumnomous-jobs-employer-api/shared/services/scan"
+	"autumnomous-jobs-employer-api/shared/services/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+// verdict is a Scanner with a fixed result
+type verdict scan.Result
+
+func (fixed verdict) Scan(ctx context.Context, content []byte) (*scan.Result, error) {
+	result := scan.Result(fixed)
+	return &result, nil
+}
+
+func Test_Policy_Scan(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	policy := &scan.Policy{MaxSize: 100, ContentTypes: []string{imaging.PNG}, Sniff: imaging.Sniff}
+
+	result, err := policy.Scan(ctx, png)
+	assert.NoError(err)
+	assert.Equal(scan.Clean, result.Verdict)
+
+	result, _ = policy.Scan(ctx, append(png, make([]byte, 100)...))
+	assert.Equal(&scan.Result{Verdict: scan.Rejected, Reason: "larger than 100 bytes"}, result)
+
+	result, _ = policy.Scan(ctx, []byte("<svg/>"))
+	assert.Equal(&scan.Result{Verdict: scan.Rejected, Reason: "content type unknown is not allowed"}, result)
+
+	// without Sniff the type is what net/http detects, without parameters
+	result, _ = (&scan.Policy{ContentTypes: []string{"application/pdf"}}).Scan(ctx, []byte("<html></html>"))
+	assert.Equal(&scan.Result{Verdict: scan.Rejected, Reason: "content type text/html is not allowed"}, result)
+
+	result, _ = (&scan.Policy{}).Scan(ctx, []byte("anything"))
+	assert.Equal(scan.Clean, result.Verdict)
+}
+
+func Test_Chain_Scan(t *testing.T) {
+	assert := assert.New(t)
+
+	chain := scan.Chain{scan.Noop{}, verdict{Verdict: scan.Infected, Reason: "Eicar"}, verdict{Verdict: scan.Rejected}}
+
+	result, err := chain.Scan(context.Background(), png)
+	assert.NoError(err)
+	assert.Equal(&scan.Result{Verdict: scan.Infected, Reason: "Eicar"}, result)
+
+	result, _ = scan.Chain{scan.Noop{}}.Scan(context.Background(), png)
+	assert.Equal(scan.Clean, result.Verdict)
+}
+
+func Test_Check_Quarantines(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	store := memory.NewStore()
+	repository := store.UploadRepository()
+
+	local, err := storage.NewLocalStorage(t.TempDir(), "http://localhost/uploads", []byte("secret"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(scan.Check(ctx, scan.Noop{}, local, repository, "", "logo.png", png))
+
+	err = scan.Check(ctx, verdict{Verdict: scan.Infected, Reason: "Eicar-Test-Signature"}, local, repository, "", "logo.png", png)
+	assert.Equal(scan.ErrInfected.Code, codeOf(err))
+
+	quarantined, _ := repository.GetOrphanedUploads(farFuture(), 10)
+
+	if assert.Len(quarantined, 1) {
+		upload := quarantined[0]
+
+		assert.Equal(uploads.Quarantined, upload.Status)
+		assert.Equal("logo.png", upload.FileName)
+		assert.Equal("image/png", upload.ContentType)
+		assert.Equal("infected: Eicar-Test-Signature", upload.ScanResult)
+		assert.True(strings.HasPrefix(upload.Key, storage.QuarantinePrefix))
+
+		// the response names the quarantined upload and why
+		assert.True(strings.Contains(err.Error(), upload.PublicID))
+		assert.True(strings.Contains(err.Error(), "Eicar-Test-Signature"))
+
+		object, err := local.Get(upload.Key)
+
+		if assert.NoError(err) {
+			object.Body.Close()
+		}
+	}
+
+	err = scan.Check(ctx, verdict{Verdict: scan.Rejected, Reason: "too large"}, local, repository, "", "logo.png", png)
+	assert.Equal(scan.ErrRejected.Code, codeOf(err))
+}
+
+func Test_CheckUpload_Quarantines(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	store := memory.NewStore()
+	repository := store.UploadRepository()
+
+	employer, err := store.EmployerRepository().CreateEmployer("First", "Last", "employer@example.com", "password")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local, err := storage.NewLocalStorage(t.TempDir(), "http://localhost/uploads", []byte("secret"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upload, err := repository.CreateUpload(employer.PublicID, "uploads/logo.png", "logo.png", imaging.PNG, int64(len(png)), farFuture())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := local.Put(upload.Key, strings.NewReader(string(png)), imaging.PNG); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(scan.CheckUpload(ctx, scan.Noop{}, local, repository, upload))
+
+	err = scan.CheckUpload(ctx, verdict{Verdict: scan.Infected, Reason: "Eicar-Test-Signature"}, local, repository, upload)
+	assert.Equal(scan.ErrInfected.Code, codeOf(err))
+
+	// the object is moved out of reach of its public key
+	_, err = local.Get(upload.Key)
+	assert.Equal(storage.ErrNotFound, err)
+
+	quarantined, err := repository.GetEmployerUpload(employer.PublicID, upload.PublicID)
+
+	if assert.NoError(err) {
+		assert.Equal(uploads.Quarantined, quarantined.Status)
+		assert.Equal(storage.QuarantinePrefix+upload.Key, quarantined.Key)
+		assert.Equal("infected: Eicar-Test-Signature", quarantined.ScanResult)
+
+		object, err := local.Get(quarantined.Key)
+
+		if assert.NoError(err) {
+			object.Body.Close()
+		}
+	}
+}
+
+func farFuture() time.Time {
+	return time.Now().Add(365 * 24 * time.Hour)
+}
+
+func codeOf(err error) string {
+
+	var domainErr *domain.Error
+
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+
+	return ""
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -32,6 +31,14 @@ type Token struct {
 	return tokenStr, err
 } */
 
+// signingKey signs and verifies every token, set once at startup from config
+var signingKey []byte
+
+// SetSigningKey sets the HMAC key used by GenerateToken and ParseToken
+func SetSigningKey(key string) {
+	signingKey = []byte(key)
+}
+
 type JWTData struct {
 	// Standard claims are the standard jwt claims from the IETF standard
 	// https://tools.ietf.org/html/rfc7519
@@ -52,7 +59,7 @@ func GenerateToken(userId string) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(os.Getenv("KNIT_SIGNING_KEY")))
+	tokenString, err := token.SignedString(signingKey)
 
 	if err != nil {
 		return "", err
@@ -68,7 +75,7 @@ func ParseToken(inputTokenString string) (*JWTData, error) {
 		if jwt.SigningMethodHS256 != token.Method && !token.Valid {
 			return nil, errors.New("invalid signing algorithm")
 		}
-		return []byte(os.Getenv("KNIT_SIGNING_KEY")), nil
+		return signingKey, nil
 	})
 
 	if err != nil {
@@ -89,6 +96,11 @@ func GetUserClaim(r *http.Request) string {
 
 	auth := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
 
+	if len(auth) != 2 {
+		log.Println(errors.New("problem with bearer token"))
+		return ""
+	}
+
 	authKey, err := base64.StdEncoding.DecodeString(auth[1])
 
 	if err != nil {
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+)
+
+// LocalPath is where the API serves the files of the local driver
+const LocalPath = "/uploads"
+
+// MaxLocalPutSize is the largest object PutSigned accepts, in bytes
+const MaxLocalPutSize = 100 << 20
+
+var (
+	// ErrInvalidSignature is returned for a signed URL that was altered or has
+	// expired
+	ErrInvalidSignature = domain.NewForbidden("invalid_signature", "the signature of this URL is invalid or has expired")
+
+	// ErrObjectTooLarge is returned by PutSigned for a body over
+	// MaxLocalPutSize
+	ErrObjectTooLarge = domain.NewInvalid("file_too_large", "upload a file of at most 100 MB")
+)
+
+// LocalStorage stores objects as files under a directory, for development.
+// The API serves them at LocalPath, and accepts PUTs to URLs it signed.
+type LocalStorage struct {
+	directory string
+	url       string
+	secret    []byte
+	now       func() time.Time
+}
+
+// NewLocalStorage returns a Storage keeping files under directory, which it
+// creates, and serving them from baseURL. Signed URLs are signed with secret.
+func NewLocalStorage(directory, baseURL string, secret []byte) (*LocalStorage, error) {
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{directory: directory, url: strings.TrimSuffix(baseURL, "/"), secret: secret, now: time.Now}, nil
+}
+
+func (storage *LocalStorage) Put(key string, body io.ReadSeeker, contentType string) (string, error) {
+
+	if err := storage.write(key, body); err != nil {
+		return "", err
+	}
+
+	return storage.url + "/" + key, nil
+}
+
+// Quarantine writes the object under QuarantinePrefix, which the API never
+// serves
+func (storage *LocalStorage) Quarantine(key string, body io.ReadSeeker, contentType string) (string, error) {
+
+	key = QuarantinePrefix + key
+
+	if err := storage.write(key, body); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+func (storage *LocalStorage) Get(key string) (*Object, error) {
+
+	name, err := storage.path(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(name)
+
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+
+	if err != nil || info.IsDir() {
+		file.Close()
+		if err == nil {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+
+	// files keep no metadata, so the type comes from the key's extension
+	contentType := mime.TypeByExtension(path.Ext(key))
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &Object{Body: file, ContentType: contentType, Size: info.Size()}, nil
+}
+
+func (storage *LocalStorage) Delete(key string) error {
+
+	name, err := storage.path(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (storage *LocalStorage) SignedURL(method, key string, expires time.Duration) (string, error) {
+
+	if method != http.MethodGet && method != http.MethodPut {
+		return "", fmt.Errorf("storage: cannot sign a %s request", method)
+	}
+
+	if _, err := storage.path(key); err != nil {
+		return "", err
+	}
+
+	expiry := strconv.FormatInt(storage.now().Add(expires).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expiry)
+	query.Set("signature", storage.sign(method, key, expiry))
+
+	return storage.url + "/" + key + "?" + query.Encode(), nil
+}
+
+// PutSigned stores body under key for a PUT to a URL signed by SignedURL,
+// whose query carries the signature
+func (storage *LocalStorage) PutSigned(key string, query url.Values, body io.Reader) error {
+
+	if err := storage.verify(http.MethodPut, key, query); err != nil {
+		return err
+	}
+
+	return storage.write(key, &limitedReader{reader: body, remaining: MaxLocalPutSize})
+}
+
+// path returns the file that holds key, refusing keys that leave directory
+func (storage *LocalStorage) path(key string) (string, error) {
+
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(storage.directory, filepath.FromSlash(key)), nil
+}
+
+// write stores body under key through a temporary file, so a failed write
+// never leaves part of an object behind
+func (storage *LocalStorage) write(key string, body io.Reader) error {
+
+	name, err := storage.path(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(name), ".upload-*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), name)
+}
+
+func (storage *LocalStorage) sign(method, key, expiry string) string {
+
+	mac := hmac.New(sha256.New, storage.secret)
+	mac.Write([]byte(method + "\n" + key + "\n" + expiry))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks the signature and expiry SignedURL put in query
+func (storage *LocalStorage) verify(method, key string, query url.Values) error {
+
+	expiry := query.Get("expires")
+	seconds, err := strconv.ParseInt(expiry, 10, 64)
+
+	if err != nil || storage.now().Unix() > seconds {
+		return ErrInvalidSignature
+	}
+
+	signature, err := hex.DecodeString(query.Get("signature"))
+	expected, _ := hex.DecodeString(storage.sign(method, key, expiry))
+
+	if err != nil || !hmac.Equal(signature, expected) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// limitedReader fails with ErrObjectTooLarge once more than remaining bytes
+// are read
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (limited *limitedReader) Read(p []byte) (int, error) {
+
+	if int64(len(p)) > limited.remaining+1 {
+		p = p[:limited.remaining+1]
+	}
+
+	n, err := limited.reader.Read(p)
+	limited.remaining -= int64(n)
+
+	if limited.remaining < 0 {
+		return n, ErrObjectTooLarge
+	}
+
+	return n, err
+}
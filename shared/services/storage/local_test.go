@@ -0,0 +1,126 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/services/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newLocalStorage(t *testing.T) *storage.LocalStorage {
+
+	local, err := storage.NewLocalStorage(t.TempDir(), "http://localhost:7000/uploads/", []byte("secret"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return local
+}
+
+func Test_LocalStorage_PutGetDelete(t *testing.T) {
+	assert := assert.New(t)
+
+	local := newLocalStorage(t)
+
+	location, err := local.Put("images/logo.png", strings.NewReader("png"), "image/png")
+	assert.NoError(err)
+	assert.Equal("http://localhost:7000/uploads/images/logo.png", location)
+
+	object, err := local.Get("images/logo.png")
+
+	if assert.NoError(err) {
+		content, _ := ioutil.ReadAll(object.Body)
+		object.Body.Close()
+		assert.Equal("png", string(content))
+		assert.Equal("image/png", object.ContentType)
+		assert.Equal(int64(3), object.Size)
+	}
+
+	assert.NoError(local.Delete("images/logo.png"))
+	assert.NoError(local.Delete("images/logo.png"))
+
+	_, err = local.Get("images/logo.png")
+	assert.Equal(storage.ErrNotFound, err)
+
+	_, err = local.Get("images")
+	assert.Equal(storage.ErrNotFound, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "images/../../secret", "images//logo.png"} {
+		_, err = local.Put(key, strings.NewReader(""), "")
+		assert.Equal(storage.ErrInvalidKey, err, key)
+	}
+}
+
+func Test_LocalStorage_SignedPut(t *testing.T) {
+	assert := assert.New(t)
+
+	local := newLocalStorage(t)
+
+	put := func(signed string, body string) error {
+
+		parsed, err := url.Parse(signed)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return local.PutSigned(strings.TrimPrefix(parsed.Path, storage.LocalPath+"/"), parsed.Query(), strings.NewReader(body))
+	}
+
+	signed, err := local.SignedURL(http.MethodPut, "files/report.pdf", time.Minute)
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.NoError(put(signed, "%PDF-1.4"))
+
+	object, err := local.Get("files/report.pdf")
+
+	if assert.NoError(err) {
+		content, _ := ioutil.ReadAll(object.Body)
+		object.Body.Close()
+		assert.Equal("%PDF-1.4", string(content))
+		assert.Equal("application/pdf", object.ContentType)
+	}
+
+	// the signature covers the method, the key and the expiry
+	assert.Equal(storage.ErrInvalidSignature, put(strings.Replace(signed, "report.pdf", "other.pdf", 1), "x"))
+	assert.Equal(storage.ErrInvalidSignature, put(strings.Replace(signed, "expires=", "expires=9", 1), "x"))
+
+	signed, _ = local.SignedURL(http.MethodGet, "files/report.pdf", time.Minute)
+	assert.Equal(storage.ErrInvalidSignature, put(signed, "x"))
+
+	signed, _ = local.SignedURL(http.MethodPut, "files/report.pdf", -time.Minute)
+	assert.Equal(storage.ErrInvalidSignature, put(signed, "x"))
+
+	_, err = local.SignedURL(http.MethodDelete, "files/report.pdf", time.Minute)
+	assert.Error(err)
+}
+
+func Test_LocalStorage_Quarantine(t *testing.T) {
+	assert := assert.New(t)
+
+	local := newLocalStorage(t)
+
+	key, err := local.Quarantine("images/eicar.png", strings.NewReader("X5O!P%@AP"), "image/png")
+
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal(storage.QuarantinePrefix+"images/eicar.png", key)
+
+	object, err := local.Get(key)
+
+	if assert.NoError(err) {
+		object.Body.Close()
+	}
+}
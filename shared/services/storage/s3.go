@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/config"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3Storage stores objects in an S3 compatible bucket, such as DigitalOcean
+// Spaces
+type S3Storage struct {
+	client   *s3.S3
+	bucket   string
+	endpoint string
+}
+
+// NewS3Storage returns a Storage for the configured bucket
+func NewS3Storage(settings config.Spaces) (*S3Storage, error) {
+
+	s3Config := &aws.Config{
+		Credentials: credentials.NewStaticCredentials(settings.Key, settings.Secret, ""),
+		Endpoint:    aws.String(settings.Endpoint),
+		Region:      aws.String(settings.Region),
+	}
+
+	newSession, err := session.NewSession(s3Config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Storage{client: s3.New(newSession), bucket: settings.Bucket, endpoint: settings.Endpoint}, nil
+}
+
+func (storage *S3Storage) Put(key string, body io.ReadSeeker, contentType string) (string, error) {
+
+	object := s3.PutObjectInput{
+		Bucket: aws.String(storage.bucket),
+		Key:    aws.String(key),
+		Body:   body,
+		ACL:    aws.String("public-read"),
+	}
+
+	if contentType != "" {
+		object.ContentType = aws.String(contentType)
+	}
+
+	_, err := storage.client.PutObject(&object)
+
+	if err != nil {
+		return "", err
+	}
+
+	return storage.url(key), nil
+}
+
+// Quarantine saves the object without an ACL, so it stays private
+func (storage *S3Storage) Quarantine(key string, body io.ReadSeeker, contentType string) (string, error) {
+
+	key = QuarantinePrefix + key
+
+	object := s3.PutObjectInput{
+		Bucket: aws.String(storage.bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	}
+
+	if contentType != "" {
+		object.ContentType = aws.String(contentType)
+	}
+
+	if _, err := storage.client.PutObject(&object); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+func (storage *S3Storage) Get(key string) (*Object, error) {
+
+	output, err := storage.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(storage.bucket),
+		Key:    aws.String(key),
+	})
+
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &Object{Body: output.Body, ContentType: aws.StringValue(output.ContentType), Size: aws.Int64Value(output.ContentLength)}, nil
+}
+
+func (storage *S3Storage) Delete(key string) error {
+
+	_, err := storage.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(storage.bucket),
+		Key:    aws.String(key),
+	})
+
+	return err
+}
+
+// SignedURL presigns the request. A presigned PUT does not set the object's
+// ACL, so the object stays private until it is copied or its ACL is set.
+func (storage *S3Storage) SignedURL(method, key string, expires time.Duration) (string, error) {
+
+	var presigned *request.Request
+
+	switch method {
+	case http.MethodGet:
+		presigned, _ = storage.client.GetObjectRequest(&s3.GetObjectInput{Bucket: aws.String(storage.bucket), Key: aws.String(key)})
+	case http.MethodPut:
+		presigned, _ = storage.client.PutObjectRequest(&s3.PutObjectInput{Bucket: aws.String(storage.bucket), Key: aws.String(key)})
+	default:
+		return "", fmt.Errorf("storage: cannot sign a %s request", method)
+	}
+
+	return presigned.Presign(expires)
+}
+
+func (storage *S3Storage) url(key string) string {
+	return fmt.Sprintf("https://%s.%s/%s", storage.bucket, storage.endpoint, key)
+}
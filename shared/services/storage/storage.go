@@ -0,0 +1,50 @@
+// Package storage stores uploaded files in object storage. The S3 driver
+// keeps them in an S3 compatible bucket such as DigitalOcean Spaces, the
+// local driver in a directory the API serves itself, for development.
+package storage
+
+import (
+	"io"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+)
+
+// Storage saves objects under a key and returns the public URL of each
+type Storage interface {
+	Put(key string, body io.ReadSeeker, contentType string) (string, error)
+
+	// Quarantine saves an object that must never be served, under key below
+	// QuarantinePrefix, and returns the key it was saved under
+	Quarantine(key string, body io.ReadSeeker, contentType string) (string, error)
+
+	// Get returns the object stored under key. The caller closes its Body.
+	Get(key string) (*Object, error)
+
+	// Delete removes the object stored under key. Deleting a key that holds
+	// nothing is not an error.
+	Delete(key string) error
+
+	// SignedURL returns a URL that allows method, GET or PUT, on key to
+	// anyone holding it until expires has passed
+	SignedURL(method, key string, expires time.Duration) (string, error)
+}
+
+// QuarantinePrefix starts the keys of quarantined objects
+const QuarantinePrefix = "quarantine/"
+
+// Object is the content of a stored object
+type Object struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+}
+
+var (
+	// ErrNotFound is returned for a key that holds no object
+	ErrNotFound = domain.NewNotFound("object_not_found", "no object is stored under this key")
+
+	// ErrInvalidKey is returned for a key that is empty or leaves the storage
+	// root, such as one holding ".."
+	ErrInvalidKey = domain.NewInvalid("invalid_key", "object keys are relative slash separated paths")
+)
@@ -0,0 +1,321 @@
+// Package validation decodes request bodies and checks them against the
+// rules in their `validate` struct tags, collecting an error for every
+// invalid field rather than stopping at the first.
+//
+// Rules are separated by commas:
+//
+//	required       the field is not empty
+//	min=N, max=N   the length of a string, or the value of a number
+//	email          an email address
+//	url            an absolute http or https URL
+//	oneof=a b c    one of the listed values
+//	ltefield=F     not more than field F, when both are set
+//
+// Every rule but required accepts an empty value, so optional fields are
+// only checked when they are sent.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Codes of FieldError
+const (
+	Required  = "required"
+	TooShort  = "min"
+	TooLong   = "max"
+	Email     = "email"
+	URL       = "url"
+	OneOf     = "oneof"
+	Range     = "ltefield"
+	Unknown   = "unknown"
+	WrongType = "type"
+	Malformed = "malformed"
+
+	// Unresolved and Inconsistent describe locations, see zipcode.Resolve
+	Unresolved   = "unresolved"
+	Inconsistent = "inconsistent"
+)
+
+// FieldError is one invalid field. Field is its JSON name, and is empty
+// when the body as a whole could not be read.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors lists every invalid field of a request
+type Errors []*FieldError
+
+func (errs Errors) Error() string {
+
+	messages := make([]string, len(errs))
+
+	for i, err := range errs {
+		messages[i] = err.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Decode reads a JSON body into value, a pointer to a struct, and validates
+// it. Fields value does not have are rejected.
+func Decode(body io.Reader, value interface{}) Errors {
+
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(value); err != nil {
+		return DecodeErrors(err)
+	}
+
+	return Validate(value)
+}
+
+// DecodeErrors describes an error from decoding JSON into a struct with
+// unknown fields disallowed
+func DecodeErrors(err error) Errors {
+
+	var typeErr *json.UnmarshalTypeError
+
+	if errors.As(err, &typeErr) {
+		return Errors{{Field: typeErr.Field, Code: WrongType, Message: fmt.Sprintf("%s must be a %s", typeErr.Field, kindName(typeErr.Type))}}
+	}
+
+	// encoding/json has no error type for unknown fields
+	if name := strings.TrimPrefix(err.Error(), "json: unknown field "); name != err.Error() {
+		if unquoted, unquoteErr := strconv.Unquote(name); unquoteErr == nil {
+			name = unquoted
+		}
+
+		return Errors{{Field: name, Code: Unknown, Message: fmt.Sprintf("%s is not a known field", name)}}
+	}
+
+	if err == io.EOF {
+		return Errors{{Code: Malformed, Message: "the request body is empty"}}
+	}
+
+	return Errors{{Code: Malformed, Message: "the request body is not valid JSON"}}
+}
+
+// Validate checks the fields of value, a struct or a pointer to one, against
+// their rules. It returns nil when every field is valid.
+func Validate(value interface{}) Errors {
+
+	structValue := reflect.Indirect(reflect.ValueOf(value))
+	structType := structValue.Type()
+
+	var errs Errors
+
+	for i := 0; i < structType.NumField(); i++ {
+
+		field := structType.Field(i)
+		rules := field.Tag.Get("validate")
+
+		if rules == "" {
+			continue
+		}
+
+		for _, rule := range strings.Split(rules, ",") {
+
+			name, argument := rule, ""
+
+			if index := strings.Index(rule, "="); index >= 0 {
+				name, argument = rule[:index], rule[index+1:]
+			}
+
+			if err := check(structValue, field, name, argument); err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+// Between parses the query parameter name, whose value must be a whole number
+// from min to max
+func Between(name, value string, min, max int) (int, *FieldError) {
+
+	number, err := strconv.Atoi(value)
+	message := fmt.Sprintf("%s must be between %d and %d", name, min, max)
+
+	switch {
+	case err != nil:
+		return 0, &FieldError{Field: name, Code: WrongType, Message: message}
+	case number < min:
+		return 0, &FieldError{Field: name, Code: TooShort, Message: message}
+	case number > max:
+		return 0, &FieldError{Field: name, Code: TooLong, Message: message}
+	}
+
+	return number, nil
+}
+
+// Boolean parses the query parameter name, whose value must be true or false
+func Boolean(name, value string) (bool, *FieldError) {
+
+	boolean, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return false, &FieldError{Field: name, Code: WrongType, Message: fmt.Sprintf("%s must be true or false", name)}
+	}
+
+	return boolean, nil
+}
+
+func check(structValue reflect.Value, field reflect.StructField, rule, argument string) *FieldError {
+
+	value := structValue.FieldByIndex(field.Index)
+	name := jsonName(field)
+
+	invalid := func(code, format string, args ...interface{}) *FieldError {
+		return &FieldError{Field: name, Code: code, Message: name + " " + fmt.Sprintf(format, args...)}
+	}
+
+	if value.IsZero() || isEmptyCollection(value) {
+		if rule == Required {
+			return invalid(Required, "is required")
+		}
+
+		return nil
+	}
+
+	switch rule {
+	case Required:
+		return nil
+
+	case TooShort, TooLong:
+		limit, err := strconv.ParseFloat(argument, 64)
+
+		if err != nil {
+			panic(fmt.Sprintf("validation: %s=%s on %s is not a number", rule, argument, field.Name))
+		}
+
+		size, unit := measure(value)
+
+		if rule == TooShort && size < limit {
+			return invalid(TooShort, "must be at least %s%s", argument, unit)
+		}
+
+		if rule == TooLong && size > limit {
+			return invalid(TooLong, "must be at most %s%s", argument, unit)
+		}
+
+	case Email:
+		address, err := mail.ParseAddress(value.String())
+
+		if err != nil || address.Address != value.String() {
+			return invalid(Email, "must be an email address")
+		}
+
+	case URL:
+		parsed, err := url.Parse(value.String())
+
+		if err != nil || parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+			return invalid(URL, "must be an http or https URL")
+		}
+
+	case OneOf:
+		options := strings.Fields(argument)
+
+		for _, option := range options {
+			if fmt.Sprint(value.Interface()) == option {
+				return nil
+			}
+		}
+
+		return invalid(OneOf, "must be one of %s", strings.Join(options, ", "))
+
+	case Range:
+		otherField, ok := structValue.Type().FieldByName(argument)
+
+		if !ok {
+			panic(fmt.Sprintf("validation: %s has no field %s", structValue.Type(), argument))
+		}
+
+		other := structValue.FieldByIndex(otherField.Index)
+
+		if !other.IsZero() && number(value) > number(other) {
+			return invalid(Range, "must not be more than %s", jsonName(otherField))
+		}
+
+	default:
+		panic(fmt.Sprintf("validation: unknown rule %q on %s", rule, field.Name))
+	}
+
+	return nil
+}
+
+func isEmptyCollection(value reflect.Value) bool {
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+
+	return false
+}
+
+// measure is the length of a string or collection, or the value of a number
+func measure(value reflect.Value) (float64, string) {
+
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), " items"
+	default:
+		return number(value), ""
+	}
+}
+
+func number(value reflect.Value) float64 {
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	}
+
+	panic(fmt.Sprintf("validation: %s is not a number", value.Type()))
+}
+
+func jsonName(field reflect.StructField) string {
+
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+
+	return field.Name
+}
+
+func kindName(kind reflect.Type) string {
+
+	switch kind.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "number"
+	}
+}
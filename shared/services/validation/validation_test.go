@@ -0,0 +1,114 @@
+package validation_test
+
+import (
+	"strings"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/services/validation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type request struct {
+	Name      string   `json:"name" validate:"required,max=5"`
+	Email     string   `json:"email" validate:"email"`
+	Site      string   `json:"site" validate:"url"`
+	Period    string   `json:"period" validate:"oneof=daily yearly"`
+	MinSalary int64    `json:"minsalary" validate:"min=0,ltefield=MaxSalary"`
+	MaxSalary int64    `json:"maxsalary"`
+	Tags      []string `json:"tags" validate:"required"`
+}
+
+func codes(errs validation.Errors) map[string]string {
+
+	result := map[string]string{}
+
+	for _, err := range errs {
+		result[err.Field] = err.Code
+	}
+
+	return result
+}
+
+func Test_Validation_Validate(t *testing.T) {
+	assert := assert.New(t)
+
+	valid := request{Name: "Ada", Email: "ada@example.com", Site: "https://example.com", Period: "yearly", MinSalary: 10, MaxSalary: 20, Tags: []string{"a"}}
+	assert.Nil(validation.Validate(&valid))
+
+	// optional fields are only checked when they are set
+	assert.Nil(validation.Validate(request{Name: "Ada", MinSalary: 30, Tags: []string{"a"}}))
+
+	invalid := request{Name: "Adaline", Email: "ada", Site: "/relative", Period: "weekly", MinSalary: 30, MaxSalary: 20, Tags: []string{}}
+
+	assert.Equal(map[string]string{
+		"name":      validation.TooLong,
+		"email":     validation.Email,
+		"site":      validation.URL,
+		"period":    validation.OneOf,
+		"minsalary": validation.Range,
+		"tags":      validation.Required,
+	}, codes(validation.Validate(invalid)))
+
+	assert.Equal(map[string]string{"minsalary": validation.TooShort, "name": validation.Required, "tags": validation.Required}, codes(validation.Validate(request{MinSalary: -1})))
+}
+
+func Test_Validation_Decode(t *testing.T) {
+
+	var value request
+
+	assert.Nil(t, validation.Decode(strings.NewReader(`{"name":"Ada","tags":["a"]}`), &value))
+	assert.Equal(t, "Ada", value.Name)
+
+	tests := map[string]struct {
+		body, field, code string
+	}{
+		"Unknown":   {`{"name":"Ada","colour":"red"}`, "colour", validation.Unknown},
+		"WrongType": {`{"name":7}`, "name", validation.WrongType},
+		"Empty":     {``, "", validation.Malformed},
+		"Malformed": {`{"name":`, "", validation.Malformed},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := validation.Decode(strings.NewReader(test.body), &request{})
+
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, test.field, errs[0].Field)
+				assert.Equal(t, test.code, errs[0].Code)
+			}
+		})
+	}
+}
+
+func Test_Validation_Between(t *testing.T) {
+	assert := assert.New(t)
+
+	number, err := validation.Between("limit", "20", 1, 500)
+	assert.Nil(err)
+	assert.Equal(20, number)
+
+	for value, code := range map[string]string{"ten": validation.WrongType, "0": validation.TooShort, "501": validation.TooLong} {
+		_, err := validation.Between("limit", value, 1, 500)
+
+		if assert.NotNil(err, value) {
+			assert.Equal("limit", err.Field)
+			assert.Equal(code, err.Code)
+		}
+	}
+}
+
+func Test_Validation_Boolean(t *testing.T) {
+	assert := assert.New(t)
+
+	boolean, err := validation.Boolean("deleted", "true")
+	assert.Nil(err)
+	assert.True(boolean)
+
+	_, err = validation.Boolean("deleted", "yes")
+
+	if assert.NotNil(err) {
+		assert.Equal("deleted", err.Field)
+		assert.Equal(validation.WrongType, err.Code)
+	}
+}
@@ -0,0 +1,222 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+	"autumnomous-jobs-employer-api/shared/services/worker"
+)
+
+// responseBodyLimit is how much of a receiver's response is kept in the log
+const responseBodyLimit = 1024
+
+// Deliverer sends deliveries and records each attempt
+type Deliverer struct {
+	Webhooks webhooks.WebhookRepository
+	Client   *http.Client
+}
+
+// NewDeliverer returns a Deliverer using client
+func NewDeliverer(hooks webhooks.WebhookRepository, client *http.Client) *Deliverer {
+	return &Deliverer{Webhooks: hooks, Client: client}
+}
+
+// NewClient returns the HTTP client used to call receivers. Redirects are
+// not followed, and unless allowPrivate is set it refuses to connect to
+// loopback, private and link-local addresses, so a webhook cannot be used to
+// reach internal services.
+func NewClient(allowPrivate bool) *http.Client {
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+
+			host, _, err := net.SplitHostPort(address)
+
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+
+			if ip == nil || ip.IsLoopback() || isPrivate(ip) || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+				return fmt.Errorf("webhook: refusing to connect to %s", host)
+			}
+
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   10 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// privateNetworks are the IPv4 private ranges of RFC 1918, the carrier-grade
+// NAT range of RFC 6598, "this network" of RFC 1122 and the IPv6 unique local
+// range of RFC 4193
+var privateNetworks = parseNetworks("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "0.0.0.0/8", "fc00::/7")
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+
+	networks := make([]*net.IPNet, len(cidrs))
+
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+
+		if err != nil {
+			panic(err)
+		}
+
+		networks[i] = network
+	}
+
+	return networks
+}
+
+// isPrivate reports whether ip is in a private network
+func isPrivate(ip net.IP) bool {
+
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Perform is the DeliverKind task handler. It returns an error while the
+// delivery should be retried.
+func (deliverer *Deliverer) Perform(ctx context.Context, payload json.RawMessage) error {
+
+	var task deliverTask
+
+	if err := json.Unmarshal(payload, &task); err != nil {
+		return worker.Permanent(err)
+	}
+
+	delivery, err := deliverer.Webhooks.GetDelivery(task.DeliveryPublicID)
+
+	// the webhook, and its log, were deleted after the event was queued
+	if err == webhooks.ErrDeliveryNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if delivery.Status != webhooks.Pending {
+		return nil
+	}
+
+	hook, err := deliverer.Webhooks.GetWebhook(delivery.WebhookPublicID)
+
+	if err == webhooks.ErrNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	final := delivery.Attempts+1 >= MaxAttempts
+
+	delivery, err = deliverer.Send(ctx, hook, delivery, final)
+
+	if err != nil {
+		return err
+	}
+
+	switch delivery.Status {
+	case webhooks.Delivered:
+		return nil
+	case webhooks.Failed:
+		return worker.Permanent(errors.New(delivery.LastError))
+	default:
+		return errors.New(delivery.LastError)
+	}
+}
+
+// Send makes one attempt to deliver to hook and records it. Unless it
+// succeeds the delivery stays pending, or is marked failed when final is set.
+// The returned error is only set when the attempt could not be recorded.
+func (deliverer *Deliverer) Send(ctx context.Context, hook *webhooks.Webhook, delivery *webhooks.Delivery, final bool) (*webhooks.Delivery, error) {
+
+	attempt := deliverer.post(ctx, hook, delivery)
+
+	if attempt.Status != webhooks.Delivered {
+		attempt.Status = webhooks.Pending
+
+		if final {
+			attempt.Status = webhooks.Failed
+		}
+	}
+
+	return deliverer.Webhooks.RecordAttempt(delivery.PublicID, attempt)
+}
+
+func (deliverer *Deliverer) post(ctx context.Context, hook *webhooks.Webhook, delivery *webhooks.Delivery) *webhooks.Attempt {
+
+	attempt := &webhooks.Attempt{At: time.Now()}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	timestamp := attempt.At.Unix()
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "BiT-Jobs-Webhooks/1.0")
+	request.Header.Set("X-Webhook-Event", delivery.Event)
+	request.Header.Set("X-Webhook-Delivery", delivery.PublicID)
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Webhook-Signature", Sign(hook.Secret, timestamp, delivery.Payload))
+
+	response, err := deliverer.Client.Do(request)
+
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	defer response.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(response.Body, responseBodyLimit))
+
+	attempt.ResponseStatus = response.StatusCode
+	attempt.ResponseBody = string(body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("receiver responded %s", response.Status)
+		return attempt
+	}
+
+	attempt.Status = webhooks.Delivered
+
+	return attempt
+}
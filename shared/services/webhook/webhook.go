@@ -0,0 +1,210 @@
+// Package webhook notifies the endpoints companies subscribe to events.
+//
+// Publishing an event records a delivery for every subscribed webhook and
+// queues a task to send it, in the caller's transaction. The worker sends
+// each delivery as a signed POST, retrying with exponential backoff, and
+// records every attempt in the delivery log.
+//
+// Requests carry the headers
+//
+//	X-Webhook-Event:     the event type, e.g. job.live
+//	X-Webhook-Delivery:  the delivery publicid, the same across retries
+//	X-Webhook-Timestamp: unix seconds when the request was signed
+//	X-Webhook-Signature: sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+//
+// Receivers should recompute the signature and reject stale timestamps.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+	"autumnomous-jobs-employer-api/shared/services/worker"
+
+	"github.com/google/uuid"
+)
+
+// Event types a webhook can subscribe to
+const (
+	JobLive            = "job.live"
+	JobExpired         = "job.expired"
+	JobDeleted         = "job.deleted"
+	JobRestored        = "job.restored"
+	ApplicationCreated = "application.created"
+)
+
+// Ping is sent by the test endpoint. Every webhook receives it.
+const Ping = "ping"
+
+// Events lists the event types a webhook can subscribe to
+var Events = []string{JobLive, JobExpired, JobDeleted, JobRestored, ApplicationCreated}
+
+// Task kinds
+const (
+	// DeliverKind sends one delivery
+	DeliverKind = "webhook.deliver"
+
+	// PublishKind publishes an event raised outside this API. Applications
+	// are created by the applicant-facing service, which enqueues
+	//
+	//	{"event": "application.created", "employerpublicid": "...", "data": {...}}
+	//
+	// in the shared tasks table to notify the employer's webhooks.
+	PublishKind = "webhook.publish"
+)
+
+// MaxAttempts is how many times a delivery is attempted before it is
+// marked failed, about a day with the worker's default backoff
+const MaxAttempts = 12
+
+// Envelope is the body of every webhook request
+type Envelope struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"createdat"`
+	Data      interface{} `json:"data"`
+}
+
+// PublishTask is the payload of a PublishKind task
+type PublishTask struct {
+	Event            string          `json:"event"`
+	EmployerPublicID string          `json:"employerpublicid"`
+	Data             json.RawMessage `json:"data"`
+}
+
+type deliverTask struct {
+	DeliveryPublicID string `json:"deliverypublicid"`
+}
+
+// IsEvent reports whether event is one a webhook can subscribe to
+func IsEvent(event string) bool {
+
+	for _, candidate := range Events {
+		if candidate == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewSecret returns a random signing secret for a new webhook
+func NewSecret() (string, error) {
+
+	secret := make([]byte, 32)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Publish records and queues a delivery of event to every webhook of the
+// employer's company that subscribes to it. Pass repositories joined to the
+// transaction making the change, so the event is only sent if it commits.
+func Publish(hooks webhooks.WebhookRepository, tasks queue.QueueRepository, employerPublicID, event string, data interface{}) error {
+
+	subscribed, err := hooks.GetSubscribedWebhooks(employerPublicID, event)
+
+	if err != nil {
+		return err
+	}
+
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(&Envelope{ID: uuid.NewString(), Event: event, CreatedAt: time.Now().UTC(), Data: data})
+
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range subscribed {
+		if _, err := Enqueue(hooks, tasks, hook.PublicID, event, body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Enqueue records a delivery of body to the webhook and queues it
+func Enqueue(hooks webhooks.WebhookRepository, tasks queue.QueueRepository, webhookPublicID, event string, body json.RawMessage) (*webhooks.Delivery, error) {
+
+	delivery, err := hooks.CreateDelivery(webhookPublicID, event, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tasks.Enqueue(DeliverKind, deliverTask{DeliveryPublicID: delivery.PublicID}); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// PublishHandler performs PublishKind tasks. The deliveries for one event
+// are recorded together, so a retry does not duplicate them.
+func PublishHandler(unit transaction.UnitOfWork) func(ctx context.Context, payload json.RawMessage) error {
+	return func(ctx context.Context, payload json.RawMessage) error {
+
+		var task PublishTask
+
+		if err := json.Unmarshal(payload, &task); err != nil {
+			return worker.Permanent(err)
+		}
+
+		if !IsEvent(task.Event) || task.EmployerPublicID == "" {
+			return worker.Permanent(errors.New("webhook: publish task needs a known event and an employerpublicid"))
+		}
+
+		return unit.Do(ctx, func(tx *transaction.Repositories) error {
+			return Publish(tx.Webhooks, tx.Queue, task.EmployerPublicID, task.Event, task.Data)
+		})
+	}
+}
+
+// ValidateURL checks that raw can receive webhooks. Plain http is only
+// accepted when allowInsecure is set, for receivers in development.
+func ValidateURL(raw string, allowInsecure bool) error {
+
+	parsed, err := url.Parse(raw)
+
+	if err != nil || parsed.Host == "" {
+		return errors.New("url must be absolute, e.g. https://example.com/webhooks")
+	}
+
+	if parsed.Scheme != "https" && !(allowInsecure && parsed.Scheme == "http") {
+		return errors.New("url must use https")
+	}
+
+	if parsed.User != nil {
+		return errors.New("url must not contain credentials")
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+// Package worker runs background tasks from the queue. A pool of goroutines
+// claims due tasks and passes each to the handler registered for its kind.
+// A failed task is retried with exponential backoff until its handler's
+// MaxAttempts is reached, then it is buried as a dead letter.
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+)
+
+// Handler performs the tasks of one kind
+type Handler struct {
+	Kind    string
+	Perform func(ctx context.Context, payload json.RawMessage) error
+
+	MaxAttempts int           // defaults to 10
+	Timeout     time.Duration // defaults to one minute
+}
+
+// Permanent wraps an error that retrying cannot fix, such as a malformed
+// payload. The task is buried straight away.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+type permanentError struct {
+	error
+}
+
+// Worker claims tasks from the queue and performs them
+type Worker struct {
+	Queue    queue.QueueRepository
+	handlers map[string]Handler
+	periodic map[string]time.Duration
+
+	Concurrency  int
+	PollInterval time.Duration
+
+	// Backoff returns how long to wait before the next attempt
+	Backoff func(attempts int) time.Duration
+}
+
+// New returns a Worker over repository with no handlers registered
+func New(repository queue.QueueRepository, concurrency int) *Worker {
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &Worker{
+		Queue:        repository,
+		handlers:     map[string]Handler{},
+		periodic:     map[string]time.Duration{},
+		Concurrency:  concurrency,
+		PollInterval: time.Second,
+		Backoff:      ExponentialBackoff(10*time.Second, time.Hour),
+	}
+}
+
+// Register adds handler, replacing any handler for the same kind
+func (worker *Worker) Register(handler Handler) {
+
+	if handler.MaxAttempts < 1 {
+		handler.MaxAttempts = 10
+	}
+
+	if handler.Timeout <= 0 {
+		handler.Timeout = time.Minute
+	}
+
+	worker.handlers[handler.Kind] = handler
+}
+
+// Every schedules a task of kind, with no payload, once per interval. A
+// task is not scheduled while the previous one is pending or running, so
+// several processes can run the same schedule.
+func (worker *Worker) Every(kind string, interval time.Duration) {
+	worker.periodic[kind] = interval
+}
+
+// ExponentialBackoff doubles the delay after every attempt, from base up to
+// max, with up to 20% jitter so failed tasks do not retry in lockstep
+func ExponentialBackoff(base, max time.Duration) func(attempts int) time.Duration {
+	return func(attempts int) time.Duration {
+
+		delay := base
+
+		for i := 1; i < attempts && delay < max; i++ {
+			delay *= 2
+		}
+
+		if delay > max {
+			delay = max
+		}
+
+		return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+	}
+}
+
+// Run performs tasks until ctx is cancelled, then waits for the tasks in
+// progress to finish
+func (worker *Worker) Run(ctx context.Context) {
+
+	var wg sync.WaitGroup
+
+	for kind, interval := range worker.periodic {
+		wg.Add(1)
+
+		go func(kind string, interval time.Duration) {
+			defer wg.Done()
+			worker.schedule(ctx, kind, interval)
+		}(kind, interval)
+	}
+
+	for i := 0; i < worker.Concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			worker.poll(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (worker *Worker) poll(ctx context.Context) {
+
+	for {
+		performed, err := worker.Work(ctx, 1)
+
+		if err != nil && ctx.Err() == nil {
+			log.Println("Worker error", err)
+		}
+
+		// keep going while there is a backlog
+		if performed > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(worker.PollInterval):
+		}
+	}
+}
+
+func (worker *Worker) schedule(ctx context.Context, kind string, interval time.Duration) {
+
+	for {
+		_, err := worker.Queue.Schedule(kind, nil, time.Time{}, "every:"+kind)
+
+		if err != nil && err != queue.ErrDuplicate {
+			log.Println("Worker schedule error", kind, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Work claims up to limit tasks and performs them. It returns the number of
+// tasks claimed.
+func (worker *Worker) Work(ctx context.Context, limit int) (int, error) {
+
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	tasks, err := worker.Queue.Claim(limit, worker.lease())
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, task := range tasks {
+		worker.perform(task)
+	}
+
+	return len(tasks), nil
+}
+
+// lease covers the longest handler timeout, so a task is only claimed again
+// once its worker has given up on it
+func (worker *Worker) lease() time.Duration {
+
+	lease := time.Minute
+
+	for _, handler := range worker.handlers {
+		if handler.Timeout > lease {
+			lease = handler.Timeout
+		}
+	}
+
+	return lease + 30*time.Second
+}
+
+// perform runs task with its own timeout rather than the worker's context,
+// so a shutdown lets the tasks in progress finish
+func (worker *Worker) perform(task *queue.Task) {
+
+	handler, ok := worker.handlers[task.Kind]
+
+	if !ok {
+		worker.fail(task, fmt.Errorf("no handler for task kind %q", task.Kind), true)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), handler.Timeout)
+	defer cancel()
+
+	if err := run(ctx, handler, task.Payload); err != nil {
+		var permanent permanentError
+		worker.fail(task, err, errors.As(err, &permanent) || task.Attempts >= handler.MaxAttempts)
+		return
+	}
+
+	if err := worker.Queue.Complete(task.ID); err != nil {
+		log.Println(err)
+	}
+}
+
+// run calls handler, turning a panic into an error so the task is retried or
+// buried like any other failure instead of taking the process down
+func run(ctx context.Context, handler Handler, payload json.RawMessage) (err error) {
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Task handler %s panicked: %v\n%s", handler.Kind, recovered, debug.Stack())
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return handler.Perform(ctx, payload)
+}
+
+func (worker *Worker) fail(task *queue.Task, cause error, bury bool) {
+
+	log.Printf("Task %d (%s) attempt %d failed: %v", task.ID, task.Kind, task.Attempts, cause)
+
+	var err error
+
+	if bury {
+		err = worker.Queue.Bury(task.ID, cause.Error())
+	} else {
+		err = worker.Queue.Retry(task.ID, cause.Error(), time.Now().Add(worker.Backoff(task.Attempts)))
+	}
+
+	if err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,268 @@
+package worker_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/memory"
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/services/worker"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newWorker() (*worker.Worker, *memory.QueueRepository) {
+
+	repository := memory.NewStore().QueueRepository()
+
+	tasks := worker.New(repository, 1)
+	tasks.Backoff = func(attempts int) time.Duration { return 0 }
+
+	return tasks, repository
+}
+
+func status(t *testing.T, repository queue.QueueRepository, id int64) string {
+
+	task, err := repository.GetTask(id)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return task.Status
+}
+
+func Test_Worker_Work(t *testing.T) {
+	assert := assert.New(t)
+
+	tasks, repository := newWorker()
+
+	var received []string
+	tasks.Register(worker.Handler{Kind: "test", Perform: func(ctx context.Context, payload json.RawMessage) error {
+		var value string
+		err := json.Unmarshal(payload, &value)
+		received = append(received, value)
+		return err
+	}})
+
+	task, err := repository.Enqueue("test", "first")
+	assert.Nil(err)
+
+	performed, err := tasks.Work(context.Background(), 10)
+
+	assert.Nil(err)
+	assert.Equal(1, performed)
+	assert.Equal([]string{"first"}, received)
+	assert.Equal(queue.Done, status(t, repository, task.ID))
+}
+
+func Test_Worker_Work_Retries(t *testing.T) {
+	assert := assert.New(t)
+
+	tasks, repository := newWorker()
+
+	calls := 0
+	tasks.Register(worker.Handler{Kind: "test", Perform: func(ctx context.Context, payload json.RawMessage) error {
+		calls++
+		if calls < 3 {
+			return errors.New("unreachable")
+		}
+		return nil
+	}})
+
+	task, _ := repository.Enqueue("test", "value")
+
+	for i := 0; i < 3; i++ {
+		_, err := tasks.Work(context.Background(), 10)
+		assert.Nil(err)
+	}
+
+	assert.Equal(3, calls)
+	assert.Equal(queue.Done, status(t, repository, task.ID))
+}
+
+func Test_Worker_Work_Backoff(t *testing.T) {
+	assert := assert.New(t)
+
+	tasks, repository := newWorker()
+	tasks.Backoff = func(attempts int) time.Duration { return time.Hour }
+
+	tasks.Register(worker.Handler{Kind: "test", Perform: func(ctx context.Context, payload json.RawMessage) error {
+		return errors.New("unreachable")
+	}})
+
+	task, _ := repository.Enqueue("test", "value")
+
+	performed, _ := tasks.Work(context.Background(), 10)
+	assert.Equal(1, performed)
+
+	// not due again for an hour
+	performed, _ = tasks.Work(context.Background(), 10)
+	assert.Equal(0, performed)
+
+	result, err := repository.GetTask(task.ID)
+
+	if assert.Nil(err) {
+		assert.Equal(queue.Pending, result.Status)
+		assert.Equal("unreachable", result.LastError)
+		assert.True(result.RunAt.After(time.Now().Add(59 * time.Minute)))
+	}
+}
+
+func Test_Worker_Work_DeadLetter(t *testing.T) {
+	assert := assert.New(t)
+
+	tasks, repository := newWorker()
+
+	calls := 0
+	tasks.Register(worker.Handler{Kind: "test", MaxAttempts: 2, Perform: func(ctx context.Context, payload json.RawMessage) error {
+		calls++
+		return errors.New("rejected")
+	}})
+
+	task, _ := repository.Enqueue("test", "value")
+
+	for i := 0; i < 4; i++ {
+		tasks.Work(context.Background(), 10)
+	}
+
+	assert.Equal(2, calls)
+	assert.Equal(queue.Dead, status(t, repository, task.ID))
+}
+
+func Test_Worker_Work_Panic(t *testing.T) {
+	assert := assert.New(t)
+
+	tasks, repository := newWorker()
+
+	calls := 0
+	tasks.Register(worker.Handler{Kind: "test", MaxAttempts: 2, Perform: func(ctx context.Context, payload json.RawMessage) error {
+		calls++
+		panic("nil map")
+	}})
+
+	task, _ := repository.Enqueue("test", "value")
+
+	_, err := tasks.Work(context.Background(), 10)
+	assert.Nil(err)
+	assert.Equal(queue.Pending, status(t, repository, task.ID), "a panic is retried")
+
+	tasks.Work(context.Background(), 10)
+
+	assert.Equal(2, calls)
+	assert.Equal(queue.Dead, status(t, repository, task.ID))
+
+	stored, err := repository.GetTask(task.ID)
+
+	if assert.Nil(err) {
+		assert.Equal("panic: nil map", stored.LastError)
+	}
+}
+
+func Test_Worker_Work_Permanent(t *testing.T) {
+	assert := assert.New(t)
+
+	tasks, repository := newWorker()
+
+	tasks.Register(worker.Handler{Kind: "test", Perform: func(ctx context.Context, payload json.RawMessage) error {
+		return worker.Permanent(errors.New("malformed"))
+	}})
+
+	task, _ := repository.Enqueue("test", "value")
+
+	tasks.Work(context.Background(), 10)
+
+	assert.Equal(queue.Dead, status(t, repository, task.ID))
+}
+
+func Test_Worker_Work_UnknownKind(t *testing.T) {
+	assert := assert.New(t)
+
+	tasks, repository := newWorker()
+
+	task, _ := repository.Enqueue("unknown", "value")
+
+	performed, err := tasks.Work(context.Background(), 10)
+
+	assert.Nil(err)
+	assert.Equal(1, performed)
+	assert.Equal(queue.Dead, status(t, repository, task.ID))
+}
+
+func Test_Worker_Run(t *testing.T) {
+	assert := assert.New(t)
+
+	tasks, repository := newWorker()
+	tasks.Concurrency = 3
+	tasks.PollInterval = 10 * time.Millisecond
+
+	var mu sync.Mutex
+	performed := map[string]int{}
+
+	perform := func(ctx context.Context, payload json.RawMessage) error {
+		var value string
+		json.Unmarshal(payload, &value)
+
+		mu.Lock()
+		performed[value]++
+		mu.Unlock()
+
+		return nil
+	}
+
+	tasks.Register(worker.Handler{Kind: "test", Perform: perform})
+	tasks.Register(worker.Handler{Kind: "periodic", Perform: perform})
+	tasks.Every("periodic", time.Hour)
+
+	for _, value := range []string{"a", "b", "c", "d"} {
+		repository.Enqueue("test", value)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		tasks.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		count := len(performed)
+		mu.Unlock()
+
+		if count == 5 {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// every task once, and the periodic task (which has no payload) once
+	assert.Equal(map[string]int{"a": 1, "b": 1, "c": 1, "d": 1, "": 1}, performed)
+}
+
+func Test_ExponentialBackoff(t *testing.T) {
+	assert := assert.New(t)
+
+	backoff := worker.ExponentialBackoff(10*time.Second, time.Minute)
+
+	for attempts, expected := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute, 10: time.Minute} {
+		delay := backoff(attempts)
+
+		assert.True(delay >= expected, "attempt %d", attempts)
+		assert.True(delay <= expected+expected/5, "attempt %d", attempts)
+	}
+}
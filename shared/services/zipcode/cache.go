@@ -0,0 +1,222 @@
+package zipcode
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/geocodes"
+)
+
+// Cache is a Geocoder that remembers the answers of another. Recent answers
+// are kept in memory, least recently used first to go, and every answer in
+// the repository, when there is one, so they survive restarts and are
+// shared between processes. Both forget an answer after TTL. Errors are not
+// cached.
+type Cache struct {
+	geocoder   Geocoder
+	repository geocodes.GeocodeRepository
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	size    int
+	recent  *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key       string
+	value     json.RawMessage
+	expiresAt time.Time
+}
+
+// NewCache returns a Cache of geocoder keeping size answers in memory.
+// repository may be nil.
+func NewCache(geocoder Geocoder, repository geocodes.GeocodeRepository, size int, ttl time.Duration) *Cache {
+	return &Cache{
+		geocoder:   geocoder,
+		repository: repository,
+		ttl:        ttl,
+		size:       size,
+		recent:     list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+func (cache *Cache) GetZipCode(zip string) (*ZipCodeResponse, error) {
+
+	var result *ZipCodeResponse
+
+	err := cache.get("zipcode:"+strings.TrimSpace(zip), &result, func() (interface{}, error) {
+		return cache.geocoder.GetZipCode(zip)
+	})
+
+	return result, err
+}
+
+func (cache *Cache) GetAutoComplete(chars string) ([]CityAutoCompleteResponse, error) {
+
+	var result []CityAutoCompleteResponse
+
+	err := cache.get("autocomplete:"+strings.ToLower(strings.TrimSpace(chars)), &result, func() (interface{}, error) {
+		return cache.geocoder.GetAutoComplete(chars)
+	})
+
+	return result, err
+}
+
+func (cache *Cache) GetJSAutoComplete(chars string) ([]CityLatLongAutoCompleteResponse, error) {
+
+	var result []CityLatLongAutoCompleteResponse
+
+	err := cache.get("jsautocomplete:"+strings.ToLower(strings.TrimSpace(chars)), &result, func() (interface{}, error) {
+		return cache.geocoder.GetJSAutoComplete(chars)
+	})
+
+	return result, err
+}
+
+func (cache *Cache) GetLocationByLatLong(longitude float64, latitude float64) (*ZipCodeResponse, error) {
+
+	var result *ZipCodeResponse
+
+	err := cache.get(fmt.Sprintf("location:%.4f,%.4f", latitude, longitude), &result, func() (interface{}, error) {
+		return cache.geocoder.GetLocationByLatLong(longitude, latitude)
+	})
+
+	return result, err
+}
+
+func (cache *Cache) GetDistanceBetweenZipCodes(zipcode1 string, zipcode2 string) (*ZipCodesDistanceResponse, error) {
+
+	var result *ZipCodesDistanceResponse
+
+	err := cache.get("distance:"+strings.TrimSpace(zipcode1)+","+strings.TrimSpace(zipcode2), &result, func() (interface{}, error) {
+		return cache.geocoder.GetDistanceBetweenZipCodes(zipcode1, zipcode2)
+	})
+
+	return result, err
+}
+
+func (cache *Cache) GetZipCodesInRadius(zipcode string, radius float64) ([]ZipCodeResponseWithDistance, error) {
+
+	var result []ZipCodeResponseWithDistance
+
+	err := cache.get(fmt.Sprintf("radius:%s,%g", strings.TrimSpace(zipcode), radius), &result, func() (interface{}, error) {
+		return cache.geocoder.GetZipCodesInRadius(zipcode, radius)
+	})
+
+	return result, err
+}
+
+// get decodes the answer cached under key into result, asking fetch for it
+// and caching it when neither memory nor the repository has it
+func (cache *Cache) get(key string, result interface{}, fetch func() (interface{}, error)) error {
+
+	now := time.Now()
+
+	if value, ok := cache.recall(key, now); ok {
+		return json.Unmarshal(value, result)
+	}
+
+	if cache.repository != nil {
+		geocode, err := cache.repository.GetGeocode(key, now)
+
+		if err == nil {
+			cache.remember(key, geocode.Value, geocode.ExpiresAt)
+			return json.Unmarshal(geocode.Value, result)
+		}
+
+		if err != geocodes.ErrNotFound {
+			log.Println("zipcode: reading cache:", err)
+		}
+	}
+
+	answer, err := fetch()
+
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(answer)
+
+	if err != nil {
+		return err
+	}
+
+	expiresAt := now.Add(cache.ttl)
+	cache.remember(key, value, expiresAt)
+
+	// the answer is good without the repository, which is only a cache
+	if cache.repository != nil {
+		if err := cache.repository.PutGeocode(key, value, expiresAt); err != nil {
+			log.Println("zipcode: writing cache:", err)
+		}
+	}
+
+	return json.Unmarshal(value, result)
+}
+
+// recall returns the answer kept in memory under key, unless it expired
+func (cache *Cache) recall(key string, now time.Time) (json.RawMessage, bool) {
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.entries[key]
+
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+
+	if !entry.expiresAt.After(now) {
+		cache.recent.Remove(element)
+		delete(cache.entries, key)
+		return nil, false
+	}
+
+	cache.recent.MoveToFront(element)
+
+	return entry.value, true
+}
+
+// remember keeps an answer in memory, forgetting the least recently used
+// answer when there are more than size
+func (cache *Cache) remember(key string, value json.RawMessage, expiresAt time.Time) {
+
+	if cache.size <= 0 {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if element, ok := cache.entries[key]; ok {
+		element.Value = &cacheEntry{key: key, value: value, expiresAt: expiresAt}
+		cache.recent.MoveToFront(element)
+		return
+	}
+
+	cache.entries[key] = cache.recent.PushFront(&cacheEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for cache.recent.Len() > cache.size {
+		oldest := cache.recent.Back()
+		cache.recent.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len returns how many answers are kept in memory
+func (cache *Cache) Len() int {
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return cache.recent.Len()
+}
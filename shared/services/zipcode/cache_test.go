@@ -0,0 +1,194 @@
+package zipcode_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"autumnomous-jobs-employer-api/shared/repository/memory"
+	"autumnomous-jobs-employer-api/shared/services/zipcode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// counting answers from the offline dataset and counts the questions it is
+// asked, failing them all while down
+type counting struct {
+	*zipcode.Offline
+	calls int
+	down  bool
+}
+
+func (geocoder *counting) GetZipCode(zip string) (*zipcode.ZipCodeResponse, error) {
+
+	geocoder.calls++
+
+	if geocoder.down {
+		return nil, errors.New("unavailable")
+	}
+
+	return geocoder.Offline.GetZipCode(zip)
+}
+
+func (geocoder *counting) GetAutoComplete(chars string) ([]zipcode.CityAutoCompleteResponse, error) {
+
+	geocoder.calls++
+
+	if geocoder.down {
+		return nil, errors.New("unavailable")
+	}
+
+	return geocoder.Offline.GetAutoComplete(chars)
+}
+
+func Test_Cache_Get(t *testing.T) {
+	assert := assert.New(t)
+
+	remote := &counting{Offline: loadOffline(t)}
+	repository := memory.NewStore().GeocodeRepository()
+
+	cache := zipcode.NewCache(remote, repository, 2, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		cities, err := cache.GetAutoComplete("Pittsbu")
+
+		if assert.Nil(err) && assert.Len(cities, 1) {
+			assert.Equal("Pittsburgh", cities[0].City)
+		}
+	}
+
+	assert.Equal(1, remote.calls, "repeated keystrokes are answered from memory")
+
+	_, err := cache.GetAutoComplete("pittsbu ")
+	assert.Nil(err)
+	assert.Equal(1, remote.calls, "autocomplete ignores case and spaces")
+
+	// the least recently used answer is forgotten, but kept in the repository
+	_, err = cache.GetZipCode("15218")
+	assert.Nil(err)
+	_, err = cache.GetZipCode("85004")
+	assert.Nil(err)
+	assert.Equal(3, remote.calls)
+	assert.Equal(2, cache.Len())
+
+	restarted := zipcode.NewCache(remote, repository, 2, time.Hour)
+
+	zip, err := restarted.GetZipCode("15218")
+
+	if assert.Nil(err) {
+		assert.Equal("Pittsburgh", zip.City)
+	}
+
+	assert.Equal(3, remote.calls, "answers survive a restart in the repository")
+
+	// errors are not cached
+	remote.down = true
+
+	_, err = cache.GetZipCode("45505")
+	assert.NotNil(err)
+
+	remote.down = false
+
+	_, err = cache.GetZipCode("45505")
+	assert.Nil(err)
+	assert.Equal(5, remote.calls)
+
+	// expired answers are asked again
+	expiring := zipcode.NewCache(remote, nil, 10, -time.Second)
+
+	_, err = expiring.GetZipCode("45505")
+	assert.Nil(err)
+	_, err = expiring.GetZipCode("45505")
+	assert.Nil(err)
+	assert.Equal(7, remote.calls)
+}
+
+func Test_Fallback_GetZipCode(t *testing.T) {
+	assert := assert.New(t)
+
+	remote := &counting{Offline: loadOffline(t), down: true}
+	fallback := zipcode.Fallback{remote, loadOffline(t)}
+
+	zip, err := fallback.GetZipCode("15218")
+
+	if assert.Nil(err) {
+		assert.Equal("Pittsburgh", zip.City)
+	}
+
+	assert.Equal(1, remote.calls)
+
+	_, err = fallback.GetZipCode("99999")
+	assert.Equal(zipcode.ErrNotFound, err)
+
+	_, err = zipcode.Fallback{}.GetZipCode("15218")
+	assert.NotNil(err)
+}
+
+func Test_ZipCodeGateway_Retries(t *testing.T) {
+	assert := assert.New(t)
+
+	var requests int32
+	status := http.StatusServiceUnavailable
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":"try again"}`))
+			return
+		}
+
+		if r.URL.Path == "/v1/zipcode" {
+			w.Write([]byte(`{"zip_code":"45505","city":"Springfield","state":"OH"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid zip code"}`))
+	}))
+	defer server.Close()
+
+	gateway := zipcode.NewZipCodeGateway("key")
+	gateway.URL = server.URL + "/v1"
+	gateway.Backoff = time.Millisecond
+
+	zip, err := gateway.GetZipCode("45505")
+
+	if assert.Nil(err) {
+		assert.Equal("Springfield", zip.City)
+	}
+
+	assert.Equal(int32(2), atomic.LoadInt32(&requests))
+
+	// client errors are not retried
+	_, err = gateway.GetZipCodesInRadius("45505", 10)
+	assert.EqualError(err, "invalid zip code")
+	assert.Equal(int32(3), atomic.LoadInt32(&requests))
+
+	// nor is anything past the last attempt
+	atomic.StoreInt32(&requests, 0)
+	gateway.Attempts = 1
+
+	_, err = gateway.GetZipCode("45505")
+	assert.EqualError(err, "try again")
+	assert.Equal(int32(1), atomic.LoadInt32(&requests))
+
+	// a request that takes longer than the timeout is retried
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	gateway = zipcode.NewZipCodeGateway("key")
+	gateway.URL = slow.URL
+	gateway.Client.Timeout = 10 * time.Millisecond
+	gateway.Backoff = time.Millisecond
+
+	started := time.Now()
+	_, err = gateway.GetZipCode("45505")
+	assert.NotNil(err)
+	assert.True(time.Since(started) < time.Second)
+}
@@ -4,288 +4,196 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
-	// "57channels.io/geocode/geoerror"
+	"time"
 )
 
 type GeoCodeError struct {
 	Message string `json:"error"`
 }
 
-type ZipCodeGateway struct {
-	apiKey string
-}
-
-func NewZipCodeGateway(apiKey string) *ZipCodeGateway {
-	return &ZipCodeGateway{apiKey}
+// Geocoder resolves zip codes, coordinates and partial city names
+type Geocoder interface {
+	GetZipCode(zip string) (*ZipCodeResponse, error)
+	GetAutoComplete(chars string) ([]CityAutoCompleteResponse, error)
+	GetJSAutoComplete(chars string) ([]CityLatLongAutoCompleteResponse, error)
+	GetLocationByLatLong(longitude float64, latitude float64) (*ZipCodeResponse, error)
+	GetDistanceBetweenZipCodes(zipcode1 string, zipcode2 string) (*ZipCodesDistanceResponse, error)
+	GetZipCodesInRadius(zipcode string, radius float64) ([]ZipCodeResponseWithDistance, error)
 }
 
-func (gateway *ZipCodeGateway) GetZipCode(zip string) (*ZipCodeResponse, error) {
-
-	var zipcode ZipCodeResponse
-	var zipRequest = ZipCodeRequest{ZipCode: zip}
+const (
+	// DefaultURL is the address of api.zipcodeservices.io
+	DefaultURL = "https://api.zipcodeservices.io/v1"
 
-	var uri = "https://api.zipcodeservices.io/v1/zipcode"
+	// DefaultTimeout bounds one request to the service
+	DefaultTimeout = 5 * time.Second
 
-	jsonRequest, marshalErr := json.Marshal(zipRequest)
+	// DefaultAttempts is how many times a request is made before giving up
+	DefaultAttempts = 3
+)
 
-	if marshalErr != nil {
-		return &zipcode, marshalErr
-	}
+// ZipCodeGateway is the Geocoder backed by api.zipcodeservices.io. It
+// returns empty answers, not nil, with its errors, and ErrNotFound when the
+// service responds 404. Requests that fail to connect, time out or get a
+// 429 or 5xx response are retried after a backoff that doubles each attempt.
+type ZipCodeGateway struct {
+	apiKey string
 
-	request, requestErr := http.NewRequest("POST", uri, bytes.NewBuffer(jsonRequest))
+	URL      string
+	Client   *http.Client
+	Attempts int
+	Backoff  time.Duration
+}
 
-	if requestErr != nil {
-		return &zipcode, requestErr
+func NewZipCodeGateway(apiKey string) *ZipCodeGateway {
+	return &ZipCodeGateway{
+		apiKey:   apiKey,
+		URL:      DefaultURL,
+		Client:   &http.Client{Timeout: DefaultTimeout},
+		Attempts: DefaultAttempts,
+		Backoff:  200 * time.Millisecond,
 	}
+}
 
-	request.Header.Set("Content-Type", "application/json")
-	request.SetBasicAuth(gateway.apiKey, "")
-	request.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	response, responseErr := client.Do(request)
-
-	if responseErr != nil {
-		return &zipcode, responseErr
-	}
+func (gateway *ZipCodeGateway) GetZipCode(zip string) (*ZipCodeResponse, error) {
 
-	body, _ := ioutil.ReadAll(response.Body)
-	defer response.Body.Close()
+	var zipcode ZipCodeResponse
 
-	if response.StatusCode != http.StatusOK {
-		var errorString GeoCodeError
-		json.Unmarshal(body, &errorString)
-		log.Println(errorString.Message)
-		return &zipcode, errors.New(errorString.Message)
+	if err := gateway.post("/zipcode", ZipCodeRequest{ZipCode: zip}, &zipcode); err != nil {
+		return &zipcode, err
 	}
 
-	json.Unmarshal(body, &zipcode)
-
 	return &zipcode, nil
 }
 
 func (gateway *ZipCodeGateway) GetAutoComplete(chars string) ([]CityAutoCompleteResponse, error) {
 
 	var autocompleteResponse []CityAutoCompleteResponse
-	var autoCompleteRequest = AutoCompleteRequest{Chars: chars}
 
-	var uri = "https://api.zipcodeservices.io/v1/autocomplete"
-
-	jsonRequest, marshalErr := json.Marshal(autoCompleteRequest)
-
-	if marshalErr != nil {
-		return autocompleteResponse, marshalErr
+	if err := gateway.post("/autocomplete", AutoCompleteRequest{Chars: chars}, &autocompleteResponse); err != nil {
+		return nil, err
 	}
 
-	request, requestErr := http.NewRequest("POST", uri, bytes.NewBuffer(jsonRequest))
-
-	if requestErr != nil {
-		return autocompleteResponse, requestErr
-	}
-
-	request.Header.Set("Content-Type", "application/json")
-	request.SetBasicAuth(gateway.apiKey, "")
-	request.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	response, responseErr := client.Do(request)
-
-	if responseErr != nil {
-		return autocompleteResponse, responseErr
-	}
-
-	body, _ := ioutil.ReadAll(response.Body)
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		var errorString GeoCodeError
-		json.Unmarshal(body, &errorString)
-		log.Println(errorString.Message)
-		return autocompleteResponse, errors.New(errorString.Message)
-	}
-
-	json.Unmarshal(body, &autocompleteResponse)
-
 	return autocompleteResponse, nil
 }
 
 func (gateway *ZipCodeGateway) GetJSAutoComplete(chars string) ([]CityLatLongAutoCompleteResponse, error) {
 
 	var autocompleteResponse []CityLatLongAutoCompleteResponse
-	var autoCompleteRequest = AutoCompleteRequest{Chars: chars}
 
-	var uri = "https://api.zipcodeservices.io/v1/jsautocomplete"
+	if err := gateway.post("/jsautocomplete", AutoCompleteRequest{Chars: chars}, &autocompleteResponse); err != nil {
+		return nil, err
+	}
 
-	jsonRequest, marshalErr := json.Marshal(autoCompleteRequest)
+	return autocompleteResponse, nil
+}
 
-	if marshalErr != nil {
-		return autocompleteResponse, marshalErr
-	}
+func (gateway *ZipCodeGateway) GetLocationByLatLong(longitude float64, latitude float64) (*ZipCodeResponse, error) {
 
-	request, requestErr := http.NewRequest("POST", uri, bytes.NewBuffer(jsonRequest))
+	var zipcode ZipCodeResponse
 
-	if requestErr != nil {
-		return autocompleteResponse, requestErr
+	if err := gateway.post("/location", LocationByLatLongRequest{Latitude: latitude, Longitude: longitude}, &zipcode); err != nil {
+		return &zipcode, err
 	}
 
-	request.Header.Set("Content-Type", "application/json")
-	request.SetBasicAuth(gateway.apiKey, "")
-	request.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	response, responseErr := client.Do(request)
+	return &zipcode, nil
+}
 
-	if responseErr != nil {
-		return autocompleteResponse, responseErr
-	}
+func (gateway *ZipCodeGateway) GetDistanceBetweenZipCodes(zipcode1 string, zipcode2 string) (*ZipCodesDistanceResponse, error) {
 
-	body, _ := ioutil.ReadAll(response.Body)
-	defer response.Body.Close()
+	var zipDistance ZipCodesDistanceResponse
 
-	if response.StatusCode != http.StatusOK {
-		var errorString GeoCodeError
-		json.Unmarshal(body, &errorString)
-		log.Println(errorString.Message)
-		return autocompleteResponse, errors.New(errorString.Message)
+	if err := gateway.post("/zipcodedistance", ZipCodesDistanceRequest{ZipCode1: zipcode1, ZipCode2: zipcode2}, &zipDistance); err != nil {
+		return &zipDistance, err
 	}
 
-	json.Unmarshal(body, &autocompleteResponse)
-
-	return autocompleteResponse, nil
+	return &zipDistance, nil
 }
 
-func (gateway *ZipCodeGateway) GetLocationByLatLong(longitude float64, latitude float64) (*ZipCodeResponse, error) {
-	var zipcode ZipCodeResponse
-	var locationRequest = LocationByLatLongRequest{Latitude: latitude, Longitude: longitude}
-
-	var uri = "https://api.zipcodeservices.io/v1/location"
-
-	jsonRequest, marshalErr := json.Marshal(locationRequest)
-
-	if marshalErr != nil {
-		return &zipcode, marshalErr
-	}
+func (gateway *ZipCodeGateway) GetZipCodesInRadius(zipcode string, radius float64) ([]ZipCodeResponseWithDistance, error) {
 
-	request, requestErr := http.NewRequest("POST", uri, bytes.NewBuffer(jsonRequest))
+	var zipcodes []ZipCodeResponseWithDistance
 
-	if requestErr != nil {
-		return &zipcode, requestErr
+	if err := gateway.post("/zipsinradius", ZipCodesInRadiusRequest{ZipCode: zipcode, Radius: radius}, &zipcodes); err != nil {
+		return nil, err
 	}
 
-	request.Header.Set("Content-Type", "application/json")
-	request.SetBasicAuth(gateway.apiKey, "")
-	request.Header.Set("Content-Type", "application/json")
+	return zipcodes, nil
+}
 
-	client := &http.Client{}
-	response, responseErr := client.Do(request)
+// post sends request as JSON to the service's path and decodes its answer
+// into result, retrying as many times as Attempts allows
+func (gateway *ZipCodeGateway) post(path string, request, result interface{}) error {
 
-	if responseErr != nil {
-		return &zipcode, responseErr
-	}
+	body, err := json.Marshal(request)
 
-	body, _ := ioutil.ReadAll(response.Body)
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		var errorString GeoCodeError
-		json.Unmarshal(body, &errorString)
-		log.Println(errorString.Message)
-		return &zipcode, errors.New(errorString.Message)
+	if err != nil {
+		return err
 	}
 
-	json.Unmarshal(body, &zipcode)
+	backoff := gateway.Backoff
 
-	return &zipcode, nil
-}
+	for attempt := 1; ; attempt++ {
 
-func (gateway *ZipCodeGateway) GetDistanceBetweenZipCodes(zipcode1 string, zipcode2 string) (*ZipCodesDistanceResponse, error) {
-	var zipDistance ZipCodesDistanceResponse
-	var zipDistanceRequest = ZipCodesDistanceRequest{ZipCode1: zipcode1, ZipCode2: zipcode2}
+		retry, err := gateway.attempt(path, body, result)
 
-	var uri = "https://api.zipcodeservices.io/v1/zipcodedistance"
+		if err == nil || !retry || attempt >= gateway.Attempts {
+			return err
+		}
 
-	jsonRequest, marshalErr := json.Marshal(zipDistanceRequest)
+		log.Printf("zipcode: attempt %d of %s failed, retrying: %v", attempt, path, err)
 
-	if marshalErr != nil {
-		return &zipDistance, marshalErr
+		time.Sleep(backoff)
+		backoff *= 2
 	}
+}
+
+// attempt makes one request, and reports whether a failure is worth retrying
+func (gateway *ZipCodeGateway) attempt(path string, body []byte, result interface{}) (bool, error) {
 
-	request, requestErr := http.NewRequest("POST", uri, bytes.NewBuffer(jsonRequest))
+	request, err := http.NewRequest(http.MethodPost, gateway.URL+path, bytes.NewReader(body))
 
-	if requestErr != nil {
-		return &zipDistance, requestErr
+	if err != nil {
+		return false, err
 	}
 
 	request.Header.Set("Content-Type", "application/json")
 	request.SetBasicAuth(gateway.apiKey, "")
-	request.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	response, responseErr := client.Do(request)
+	response, err := gateway.Client.Do(request)
 
-	if responseErr != nil {
-		return &zipDistance, responseErr
+	if err != nil {
+		return true, err
 	}
 
-	body, _ := ioutil.ReadAll(response.Body)
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		var errorString GeoCodeError
-		json.Unmarshal(body, &errorString)
-		log.Println(errorString.Message)
-		return &zipDistance, errors.New(errorString.Message)
-	}
-
-	json.Unmarshal(body, &zipDistance)
-
-	return &zipDistance, nil
-}
-
-func (gateway *ZipCodeGateway) GetZipCodesInRadius(zipcode string, radius float64) ([]ZipCodeResponseWithDistance, error) {
-
-	var zipcodes []ZipCodeResponseWithDistance
-	var radiusRequest = ZipCodesInRadiusRequest{ZipCode: zipcode, Radius: radius}
-
-	var uri = "https://api.zipcodeservices.io/v1/zipsinradius"
+	data, err := ioutil.ReadAll(response.Body)
 
-	jsonRequest, marshalErr := json.Marshal(radiusRequest)
-
-	if marshalErr != nil {
-		return zipcodes, marshalErr
+	if err != nil {
+		return true, err
 	}
 
-	request, requestErr := http.NewRequest("POST", uri, bytes.NewBuffer(jsonRequest))
-
-	if requestErr != nil {
-		return zipcodes, requestErr
+	if response.StatusCode == http.StatusNotFound {
+		return false, ErrNotFound
 	}
 
-	request.Header.Set("Content-Type", "application/json")
-	request.SetBasicAuth(gateway.apiKey, "")
-	request.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	response, responseErr := client.Do(request)
+	if response.StatusCode != http.StatusOK {
+		var errorString GeoCodeError
+		json.Unmarshal(data, &errorString)
 
-	if responseErr != nil {
-		return zipcodes, responseErr
-	}
+		if errorString.Message == "" {
+			errorString.Message = fmt.Sprintf("zipcode: %s responded %d", path, response.StatusCode)
+		}
 
-	body, _ := ioutil.ReadAll(response.Body)
-	defer response.Body.Close()
+		retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
 
-	if response.StatusCode != http.StatusOK {
-		var errorString GeoCodeError
-		json.Unmarshal(body, &errorString)
-		log.Println(errorString.Message)
-		return zipcodes, errors.New(errorString.Message)
+		return retry, errors.New(errorString.Message)
 	}
 
-	json.Unmarshal(body, &zipcodes)
-
-	return zipcodes, nil
+	return false, json.Unmarshal(data, result)
 }
@@ -0,0 +1,101 @@
+package zipcode
+
+import "log"
+
+// Fallback is a Geocoder that asks each Geocoder in turn until one answers,
+// so the remote service can fall back to Offline
+type Fallback []Geocoder
+
+func (fallback Fallback) GetZipCode(zip string) (*ZipCodeResponse, error) {
+
+	var result *ZipCodeResponse
+
+	err := fallback.try(func(geocoder Geocoder) (err error) {
+		result, err = geocoder.GetZipCode(zip)
+		return err
+	})
+
+	return result, err
+}
+
+func (fallback Fallback) GetAutoComplete(chars string) ([]CityAutoCompleteResponse, error) {
+
+	var result []CityAutoCompleteResponse
+
+	err := fallback.try(func(geocoder Geocoder) (err error) {
+		result, err = geocoder.GetAutoComplete(chars)
+		return err
+	})
+
+	return result, err
+}
+
+func (fallback Fallback) GetJSAutoComplete(chars string) ([]CityLatLongAutoCompleteResponse, error) {
+
+	var result []CityLatLongAutoCompleteResponse
+
+	err := fallback.try(func(geocoder Geocoder) (err error) {
+		result, err = geocoder.GetJSAutoComplete(chars)
+		return err
+	})
+
+	return result, err
+}
+
+func (fallback Fallback) GetLocationByLatLong(longitude float64, latitude float64) (*ZipCodeResponse, error) {
+
+	var result *ZipCodeResponse
+
+	err := fallback.try(func(geocoder Geocoder) (err error) {
+		result, err = geocoder.GetLocationByLatLong(longitude, latitude)
+		return err
+	})
+
+	return result, err
+}
+
+func (fallback Fallback) GetDistanceBetweenZipCodes(zipcode1 string, zipcode2 string) (*ZipCodesDistanceResponse, error) {
+
+	var result *ZipCodesDistanceResponse
+
+	err := fallback.try(func(geocoder Geocoder) (err error) {
+		result, err = geocoder.GetDistanceBetweenZipCodes(zipcode1, zipcode2)
+		return err
+	})
+
+	return result, err
+}
+
+func (fallback Fallback) GetZipCodesInRadius(zipcode string, radius float64) ([]ZipCodeResponseWithDistance, error) {
+
+	var result []ZipCodeResponseWithDistance
+
+	err := fallback.try(func(geocoder Geocoder) (err error) {
+		result, err = geocoder.GetZipCodesInRadius(zipcode, radius)
+		return err
+	})
+
+	return result, err
+}
+
+// try calls ask with each Geocoder until one succeeds, returning the last
+// error when none does
+func (fallback Fallback) try(ask func(geocoder Geocoder) error) error {
+
+	var err error = ErrNotFound
+
+	for i, geocoder := range fallback {
+
+		err = ask(geocoder)
+
+		if err == nil {
+			return nil
+		}
+
+		if i < len(fallback)-1 {
+			log.Println("zipcode: falling back:", err)
+		}
+	}
+
+	return err
+}
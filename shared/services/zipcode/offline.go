@@ -0,0 +1,379 @@
+package zipcode
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"autumnomous-jobs-employer-api/shared/domain"
+)
+
+// AutoCompleteLimit is how many cities Offline suggests at most
+const AutoCompleteLimit = 10
+
+// ErrNotFound is returned by Offline, and by ZipCodeGateway on a 404, for a
+// place they do not know
+var ErrNotFound = domain.NewNotFound("zipcode_not_found", "zip code not found")
+
+// columns are the names a dataset's header may give each field, the first
+// being the one Offline documents
+var columns = map[string][]string{
+	"zip":       {"zip", "zip_code", "zipcode"},
+	"city":      {"city", "primary_city"},
+	"state":     {"state", "state_id"},
+	"county":    {"county", "county_name"},
+	"latitude":  {"latitude", "lat"},
+	"longitude": {"longitude", "lng", "lon"},
+}
+
+// Offline is the Geocoder over a dataset of US zip codes, for use without
+// the remote service. The dataset is a CSV file whose header names the
+// columns zip, city, state, county, latitude and longitude, in any order;
+// county is optional and other columns are ignored. The column names of
+// common free datasets, such as lat, lng and state_id, are also accepted.
+type Offline struct {
+	zipcodes map[string]*ZipCodeResponse
+	ordered  []*ZipCodeResponse // by zip code
+	cities   []*offlineCity     // by lower case name, then state
+}
+
+type offlineCity struct {
+	name  string
+	state string
+	point *ZipCodeResponse // the city's first zip code
+}
+
+// LoadOffline reads the dataset at path
+func LoadOffline(path string) (*Offline, error) {
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return ReadOffline(file)
+}
+
+// ReadOffline reads a dataset from r
+func ReadOffline(r io.Reader) (*Offline, error) {
+
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+
+	if err != nil {
+		return nil, fmt.Errorf("zipcode: reading dataset header: %v", err)
+	}
+
+	index := map[string]int{}
+
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+
+		for field, names := range columns {
+			for _, alias := range names {
+				if _, ok := index[field]; !ok && name == alias {
+					index[field] = i
+				}
+			}
+		}
+	}
+
+	for _, field := range []string{"zip", "city", "state", "latitude", "longitude"} {
+		if _, ok := index[field]; !ok {
+			return nil, fmt.Errorf("zipcode: dataset has no %s column", field)
+		}
+	}
+
+	offline := &Offline{zipcodes: map[string]*ZipCodeResponse{}}
+	seen := map[string]bool{}
+
+	for line := 2; ; line++ {
+
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("zipcode: reading dataset: %v", err)
+		}
+
+		value := func(field string) string {
+			if i, ok := index[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		latitude, err := strconv.ParseFloat(value("latitude"), 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("zipcode: dataset line %d: latitude: %v", line, err)
+		}
+
+		longitude, err := strconv.ParseFloat(value("longitude"), 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("zipcode: dataset line %d: longitude: %v", line, err)
+		}
+
+		zipcode := &ZipCodeResponse{
+			ZipCode:   padZipCode(value("zip")),
+			City:      value("city"),
+			State:     strings.ToUpper(value("state")),
+			Country:   "US",
+			Latitude:  latitude,
+			Longitude: longitude,
+			County:    value("county"),
+		}
+
+		if zipcode.ZipCode == "" || zipcode.City == "" {
+			return nil, fmt.Errorf("zipcode: dataset line %d: zip and city are required", line)
+		}
+
+		if _, ok := offline.zipcodes[zipcode.ZipCode]; ok {
+			continue
+		}
+
+		offline.zipcodes[zipcode.ZipCode] = zipcode
+		offline.ordered = append(offline.ordered, zipcode)
+
+		city := strings.ToLower(zipcode.City) + "\x00" + zipcode.State
+
+		if !seen[city] {
+			seen[city] = true
+			offline.cities = append(offline.cities, &offlineCity{name: strings.ToLower(zipcode.City), state: zipcode.State, point: zipcode})
+		}
+	}
+
+	sort.Slice(offline.ordered, func(i, j int) bool {
+		return offline.ordered[i].ZipCode < offline.ordered[j].ZipCode
+	})
+
+	sort.SliceStable(offline.cities, func(i, j int) bool {
+		if offline.cities[i].name != offline.cities[j].name {
+			return offline.cities[i].name < offline.cities[j].name
+		}
+		return offline.cities[i].state < offline.cities[j].state
+	})
+
+	return offline, nil
+}
+
+// Len returns how many zip codes the dataset has
+func (offline *Offline) Len() int {
+	return len(offline.ordered)
+}
+
+func (offline *Offline) GetZipCode(zip string) (*ZipCodeResponse, error) {
+
+	zipcode, ok := offline.zipcodes[padZipCode(strings.TrimSpace(zip))]
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	result := *zipcode
+	return &result, nil
+}
+
+// GetAutoComplete suggests the cities whose name starts with chars, which
+// may go on to a comma and the start of a state, as in "Springfield, O"
+func (offline *Offline) GetAutoComplete(chars string) ([]CityAutoCompleteResponse, error) {
+
+	result := []CityAutoCompleteResponse{}
+
+	for _, city := range offline.match(chars) {
+		result = append(result, CityAutoCompleteResponse{
+			City:      city.point.City,
+			State:     city.state,
+			Country:   city.point.Country,
+			Latitude:  city.point.Latitude,
+			Longitude: city.point.Longitude,
+		})
+	}
+
+	return result, nil
+}
+
+func (offline *Offline) GetJSAutoComplete(chars string) ([]CityLatLongAutoCompleteResponse, error) {
+
+	result := []CityLatLongAutoCompleteResponse{}
+
+	for _, city := range offline.match(chars) {
+		result = append(result, CityLatLongAutoCompleteResponse{
+			Location: city.point.City + ", " + city.state,
+			Point:    CityPoint{Latitude: city.point.Latitude, Longitude: city.point.Longitude},
+		})
+	}
+
+	return result, nil
+}
+
+// GetLocationByLatLong returns the zip code nearest to the coordinates
+func (offline *Offline) GetLocationByLatLong(longitude float64, latitude float64) (*ZipCodeResponse, error) {
+
+	var nearest *ZipCodeResponse
+	shortest := math.Inf(1)
+
+	for _, zipcode := range offline.ordered {
+		if distance := miles(latitude, longitude, zipcode.Latitude, zipcode.Longitude); distance < shortest {
+			nearest, shortest = zipcode, distance
+		}
+	}
+
+	if nearest == nil {
+		return nil, ErrNotFound
+	}
+
+	result := *nearest
+	return &result, nil
+}
+
+func (offline *Offline) GetDistanceBetweenZipCodes(zipcode1 string, zipcode2 string) (*ZipCodesDistanceResponse, error) {
+
+	from, err := offline.GetZipCode(zipcode1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := offline.GetZipCode(zipcode2)
+
+	if err != nil {
+		return nil, err
+	}
+
+	distance := miles(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+
+	return &ZipCodesDistanceResponse{
+		ZipCode1:             from.ZipCode,
+		ZipCode2:             to.ZipCode,
+		DistanceInMiles:      round(distance),
+		DistanceInKilometers: round(distance * kilometersPerMile),
+	}, nil
+}
+
+// GetZipCodesInRadius returns the zip codes within radius miles of zipcode,
+// nearest first
+func (offline *Offline) GetZipCodesInRadius(zipcode string, radius float64) ([]ZipCodeResponseWithDistance, error) {
+
+	center, err := offline.GetZipCode(zipcode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []ZipCodeResponseWithDistance{}
+
+	for _, other := range offline.ordered {
+
+		distance := miles(center.Latitude, center.Longitude, other.Latitude, other.Longitude)
+
+		if distance > radius {
+			continue
+		}
+
+		result = append(result, ZipCodeResponseWithDistance{
+			ZipCode:                  other.ZipCode,
+			City:                     other.City,
+			State:                    other.State,
+			Country:                  other.Country,
+			Latitude:                 other.Latitude,
+			Longitude:                other.Longitude,
+			County:                   other.County,
+			DistanceAwayInMiles:      round(distance),
+			DistanceAwayInKilometers: round(distance * kilometersPerMile),
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DistanceAwayInMiles < result[j].DistanceAwayInMiles
+	})
+
+	return result, nil
+}
+
+// match returns the first AutoCompleteLimit cities matching chars
+func (offline *Offline) match(chars string) []*offlineCity {
+
+	name, state := chars, ""
+
+	if comma := strings.Index(chars, ","); comma >= 0 {
+		name, state = chars[:comma], chars[comma+1:]
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	state = strings.ToUpper(strings.TrimSpace(state))
+
+	if name == "" {
+		return nil
+	}
+
+	var result []*offlineCity
+
+	first := sort.Search(len(offline.cities), func(i int) bool {
+		return offline.cities[i].name >= name
+	})
+
+	for _, city := range offline.cities[first:] {
+
+		if !strings.HasPrefix(city.name, name) || len(result) == AutoCompleteLimit {
+			break
+		}
+
+		// a complete city name is followed by its state
+		if state != "" && (city.name != name || !strings.HasPrefix(city.state, state)) {
+			continue
+		}
+
+		result = append(result, city)
+	}
+
+	return result
+}
+
+// padZipCode restores the leading zeros a spreadsheet drops from zip codes
+func padZipCode(zip string) string {
+
+	if zip != "" && len(zip) < 5 {
+		return strings.Repeat("0", 5-len(zip)) + zip
+	}
+
+	return zip
+}
+
+const (
+	earthRadiusInMiles = 3958.8
+	kilometersPerMile  = 1.609344
+)
+
+// miles returns the great-circle distance between two points
+func miles(latitude1, longitude1, latitude2, longitude2 float64) float64 {
+
+	radians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	dLatitude := radians(latitude2 - latitude1)
+	dLongitude := radians(longitude2 - longitude1)
+
+	a := math.Sin(dLatitude/2)*math.Sin(dLatitude/2) +
+		math.Cos(radians(latitude1))*math.Cos(radians(latitude2))*math.Sin(dLongitude/2)*math.Sin(dLongitude/2)
+
+	return earthRadiusInMiles * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// round rounds a distance to hundredths
+func round(distance float64) float64 {
+	return math.Round(distance*100) / 100
+}
@@ -0,0 +1,137 @@
+package zipcode_test
+
+import (
+	"strings"
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/services/zipcode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loadOffline(t *testing.T) *zipcode.Offline {
+
+	offline, err := zipcode.LoadOffline("testdata/zipcodes.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return offline
+}
+
+func Test_Offline_GetZipCode(t *testing.T) {
+	assert := assert.New(t)
+
+	offline := loadOffline(t)
+	assert.Equal(9, offline.Len())
+
+	zip, err := offline.GetZipCode("45505")
+
+	if assert.Nil(err) {
+		assert.Equal("Springfield", zip.City)
+		assert.Equal("OH", zip.State)
+		assert.Equal("Clark", zip.County)
+		assert.Equal("US", zip.Country)
+	}
+
+	// leading zeros dropped by a spreadsheet are restored
+	zip, err = offline.GetZipCode("01101")
+
+	if assert.Nil(err) {
+		assert.Equal("MA", zip.State)
+	}
+
+	_, err = offline.GetZipCode("99999")
+	assert.Equal(zipcode.ErrNotFound, err)
+}
+
+func Test_Offline_GetAutoComplete(t *testing.T) {
+	assert := assert.New(t)
+
+	offline := loadOffline(t)
+
+	cities, err := offline.GetAutoComplete("Pittsbu")
+
+	if assert.Nil(err) && assert.Len(cities, 1) {
+		assert.Equal("Pittsburgh", cities[0].City)
+		assert.Equal("PA", cities[0].State)
+	}
+
+	cities, err = offline.GetAutoComplete("springfield")
+
+	if assert.Nil(err) && assert.Len(cities, 3) {
+		assert.Equal("IL", cities[0].State)
+		assert.Equal("MA", cities[1].State)
+		assert.Equal("OH", cities[2].State)
+	}
+
+	cities, err = offline.GetAutoComplete("Springfield, o")
+
+	if assert.Nil(err) && assert.Len(cities, 1) {
+		assert.Equal("OH", cities[0].State)
+	}
+
+	cities, err = offline.GetAutoComplete("Nowhere")
+	assert.Nil(err)
+	assert.Empty(cities)
+
+	labels, err := offline.GetJSAutoComplete("phoe")
+
+	if assert.Nil(err) && assert.Len(labels, 1) {
+		assert.Equal("Phoenix, AZ", labels[0].Location)
+		assert.Equal(33.4513, labels[0].Point.Latitude)
+	}
+}
+
+func Test_Offline_Distances(t *testing.T) {
+	assert := assert.New(t)
+
+	offline := loadOffline(t)
+
+	location, err := offline.GetLocationByLatLong(-112.0712, 33.4499)
+
+	if assert.Nil(err) {
+		assert.Equal("85004", location.ZipCode)
+	}
+
+	distance, err := offline.GetDistanceBetweenZipCodes("85258", "85004")
+
+	if assert.Nil(err) {
+		assert.InDelta(12.9, distance.DistanceInMiles, 0.5)
+		assert.InDelta(distance.DistanceInMiles*1.609344, distance.DistanceInKilometers, 0.01)
+	}
+
+	_, err = offline.GetDistanceBetweenZipCodes("85258", "99999")
+	assert.Equal(zipcode.ErrNotFound, err)
+
+	nearby, err := offline.GetZipCodesInRadius("15218", 10)
+
+	if assert.Nil(err) && assert.Len(nearby, 3) {
+		assert.Equal("15218", nearby[0].ZipCode)
+		assert.Equal(0.0, nearby[0].DistanceAwayInMiles)
+		assert.True(nearby[1].DistanceAwayInMiles <= nearby[2].DistanceAwayInMiles)
+	}
+}
+
+func Test_Offline_ReadOffline(t *testing.T) {
+	assert := assert.New(t)
+
+	// the column names of other datasets are accepted, in any order
+	offline, err := zipcode.ReadOffline(strings.NewReader("lat,lng,zip,city,state_id,population\n40.4243,-79.8880,15218,Pittsburgh,pa,100\n"))
+
+	if assert.Nil(err) {
+		zip, err := offline.GetZipCode("15218")
+
+		if assert.Nil(err) {
+			assert.Equal("PA", zip.State)
+			assert.Equal("", zip.County)
+		}
+	}
+
+	_, err = zipcode.ReadOffline(strings.NewReader("zip,city,state\n15218,Pittsburgh,PA\n"))
+	assert.EqualError(err, "zipcode: dataset has no latitude column")
+
+	_, err = zipcode.ReadOffline(strings.NewReader("zip,city,state,latitude,longitude\n15218,Pittsburgh,PA,north,-79.8880\n"))
+	assert.NotNil(err)
+}
@@ -0,0 +1,197 @@
+package zipcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"autumnomous-jobs-employer-api/shared/services/validation"
+)
+
+// MaxDistance is how many miles coordinates may be from the zip code given
+// with them
+const MaxDistance = 25
+
+// Query is a location as a client describes it: free text such as
+// "Springfield, OH", a zip code and coordinates, any of which may be empty
+type Query struct {
+	Location  string
+	ZipCode   string
+	Latitude  float64
+	Longitude float64
+}
+
+// Place is a location resolved by a Geocoder
+type Place struct {
+	Location  string
+	ZipCode   string
+	City      string
+	State     string
+	County    string
+	Country   string
+	Latitude  float64
+	Longitude float64
+}
+
+// IsNotFound reports whether err means a Geocoder knows no such place
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// Resolve finds the place query describes. The zip code is trusted first,
+// then the coordinates, then the location text, which is kept as given.
+// Parts of the query that cannot be found or disagree with the place are
+// returned as field errors named after the JSON fields zipcode, latitude
+// and location. The place is nil when the query is empty or its text
+// matches no city, and the error is only for a Geocoder that could not
+// answer.
+func Resolve(geocoder Geocoder, query Query) (*Place, validation.Errors, error) {
+
+	query.Location = strings.TrimSpace(query.Location)
+	query.ZipCode = strings.TrimSpace(query.ZipCode)
+	located := query.Latitude != 0 || query.Longitude != 0
+
+	var (
+		place *Place
+		errs  validation.Errors
+		err   error
+	)
+
+	switch {
+	case query.ZipCode != "":
+
+		place, err = lookup(geocoder.GetZipCode(query.ZipCode))
+
+		if place == nil {
+			if err == nil {
+				errs = append(errs, &validation.FieldError{Field: "zipcode", Code: validation.Unresolved, Message: fmt.Sprintf("zipcode %s is not a known zip code", query.ZipCode)})
+			}
+			return nil, errs, err
+		}
+
+		if located {
+			if distance := miles(query.Latitude, query.Longitude, place.Latitude, place.Longitude); distance > MaxDistance {
+				errs = append(errs, &validation.FieldError{Field: "latitude", Code: validation.Inconsistent, Message: fmt.Sprintf("latitude and longitude are %.0f miles from zipcode %s", distance, place.ZipCode)})
+			}
+			place.Latitude, place.Longitude = query.Latitude, query.Longitude
+		}
+
+	case located:
+
+		place, err = lookup(geocoder.GetLocationByLatLong(query.Longitude, query.Latitude))
+
+		if place == nil {
+			if err == nil {
+				errs = append(errs, &validation.FieldError{Field: "latitude", Code: validation.Unresolved, Message: "latitude and longitude are not near a known zip code"})
+			}
+			return nil, errs, err
+		}
+
+		place.Latitude, place.Longitude = query.Latitude, query.Longitude
+
+	case query.Location != "":
+
+		place, err = search(geocoder, query.Location)
+
+		if place == nil {
+			return nil, nil, err
+		}
+	}
+
+	if place == nil {
+		return nil, nil, nil
+	}
+
+	// only text in the form "City, ST" is checked, other text being free
+	if city, state := split(query.Location); len(state) == 2 && (!strings.EqualFold(city, place.City) || !strings.EqualFold(state, place.State)) {
+		errs = append(errs, &validation.FieldError{Field: "location", Code: validation.Inconsistent, Message: fmt.Sprintf("location %s is not %s, %s", query.Location, place.City, place.State)})
+	}
+
+	place.Location = query.Location
+
+	if place.Location == "" {
+		place.Location = place.City + ", " + place.State
+	}
+
+	return place, errs, nil
+}
+
+// lookup turns a Geocoder's answer for one zip code into a Place, which is
+// nil when the Geocoder found none
+func lookup(zipcode *ZipCodeResponse, err error) (*Place, error) {
+
+	if IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if zipcode == nil || zipcode.City == "" {
+		return nil, nil
+	}
+
+	return &Place{
+		ZipCode:   zipcode.ZipCode,
+		City:      zipcode.City,
+		State:     zipcode.State,
+		County:    zipcode.County,
+		Country:   zipcode.Country,
+		Latitude:  zipcode.Latitude,
+		Longitude: zipcode.Longitude,
+	}, nil
+}
+
+// search finds the city location names, given as "City" or "City, ST",
+// and the zip code nearest its center
+func search(geocoder Geocoder, location string) (*Place, error) {
+
+	city, state := split(location)
+
+	if city == "" {
+		return nil, nil
+	}
+
+	cities, err := geocoder.GetAutoComplete(location)
+
+	if IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range cities {
+
+		if !strings.EqualFold(candidate.City, city) || (state != "" && !strings.EqualFold(candidate.State, state)) {
+			continue
+		}
+
+		place, err := lookup(geocoder.GetLocationByLatLong(candidate.Longitude, candidate.Latitude))
+
+		if place == nil {
+			return nil, err
+		}
+
+		place.City, place.State = candidate.City, candidate.State
+		place.Latitude, place.Longitude = candidate.Latitude, candidate.Longitude
+
+		return place, nil
+	}
+
+	return nil, nil
+}
+
+// split divides location text into a city and state
+func split(location string) (string, string) {
+
+	city, state := location, ""
+
+	if comma := strings.Index(location, ","); comma >= 0 {
+		city, state = location[:comma], location[comma+1:]
+	}
+
+	return strings.TrimSpace(city), strings.TrimSpace(state)
+}
@@ -0,0 +1,85 @@
+package zipcode_test
+
+import (
+	"testing"
+
+	"autumnomous-jobs-employer-api/shared/services/validation"
+	"autumnomous-jobs-employer-api/shared/services/zipcode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Resolve(t *testing.T) {
+	assert := assert.New(t)
+
+	offline := loadOffline(t)
+
+	// a zip code alone fills in everything else
+	place, errs, err := zipcode.Resolve(offline, zipcode.Query{ZipCode: "15222"})
+
+	if assert.Nil(err) && assert.Empty(errs) && assert.NotNil(place) {
+		assert.Equal("Pittsburgh, PA", place.Location)
+		assert.Equal("Pittsburgh", place.City)
+		assert.Equal("PA", place.State)
+		assert.Equal("Allegheny", place.County)
+		assert.Equal("US", place.Country)
+		assert.Equal(40.4495, place.Latitude)
+	}
+
+	// coordinates alone find the nearest zip code, and are kept
+	place, errs, err = zipcode.Resolve(offline, zipcode.Query{Location: "Downtown", Latitude: 39.92, Longitude: -83.81})
+
+	if assert.Nil(err) && assert.Empty(errs) && assert.NotNil(place) {
+		assert.Equal("Downtown", place.Location)
+		assert.Equal("45501", place.ZipCode)
+		assert.Equal("Springfield", place.City)
+		assert.Equal(39.92, place.Latitude)
+	}
+
+	// text alone must name a city
+	place, errs, err = zipcode.Resolve(offline, zipcode.Query{Location: "springfield, il"})
+
+	if assert.Nil(err) && assert.Empty(errs) && assert.NotNil(place) {
+		assert.Equal("springfield, il", place.Location)
+		assert.Equal("62701", place.ZipCode)
+		assert.Equal("Sangamon", place.County)
+	}
+
+	place, errs, err = zipcode.Resolve(offline, zipcode.Query{Location: "Remote"})
+	assert.Nil(place)
+	assert.Empty(errs)
+	assert.Nil(err)
+
+	place, errs, err = zipcode.Resolve(offline, zipcode.Query{})
+	assert.Nil(place)
+	assert.Empty(errs)
+	assert.Nil(err)
+}
+
+func Test_Resolve_Inconsistent(t *testing.T) {
+	assert := assert.New(t)
+
+	offline := loadOffline(t)
+
+	place, errs, err := zipcode.Resolve(offline, zipcode.Query{ZipCode: "99999"})
+	assert.Nil(err)
+	assert.Nil(place)
+	assert.Equal(validation.Errors{{Field: "zipcode", Code: validation.Unresolved, Message: "zipcode 99999 is not a known zip code"}}, errs)
+
+	// Phoenix is not Pittsburgh, and its coordinates are far from 15222
+	place, errs, err = zipcode.Resolve(offline, zipcode.Query{Location: "Phoenix, AZ", ZipCode: "15222", Latitude: 33.4513, Longitude: -112.0686})
+	assert.Nil(err)
+	assert.NotNil(place)
+
+	if assert.Len(errs, 2) {
+		assert.Equal("latitude", errs[0].Field)
+		assert.Equal(validation.Inconsistent, errs[0].Code)
+		assert.Equal("location", errs[1].Field)
+		assert.Equal(validation.Inconsistent, errs[1].Code)
+	}
+
+	// Scottsdale is within MaxDistance of Phoenix
+	_, errs, err = zipcode.Resolve(offline, zipcode.Query{ZipCode: "85004", Latitude: 33.5649, Longitude: -111.8931})
+	assert.Nil(err)
+	assert.Empty(errs)
+}
@@ -1,17 +1,38 @@
 package testhelper
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log"
-	"os"
 	"testing"
 	"time"
 
+	"autumnomous-jobs-employer-api/app"
+	"autumnomous-jobs-employer-api/shared/config"
 	"autumnomous-jobs-employer-api/shared/database"
+	"autumnomous-jobs-employer-api/shared/repository/attachments"
+	"autumnomous-jobs-employer-api/shared/repository/audit"
+	"autumnomous-jobs-employer-api/shared/repository/companies"
+	"autumnomous-jobs-employer-api/shared/repository/employers/accountmanagement"
+	"autumnomous-jobs-employer-api/shared/repository/geocodes"
+	"autumnomous-jobs-employer-api/shared/repository/jobpackages"
+	"autumnomous-jobs-employer-api/shared/repository/jobs"
+	"autumnomous-jobs-employer-api/shared/repository/memory"
+	"autumnomous-jobs-employer-api/shared/repository/queue"
+	"autumnomous-jobs-employer-api/shared/repository/repositorytest"
+	"autumnomous-jobs-employer-api/shared/repository/transaction"
+	"autumnomous-jobs-employer-api/shared/repository/uploads"
+	"autumnomous-jobs-employer-api/shared/repository/webhooks"
+	"autumnomous-jobs-employer-api/shared/services/messaging/email"
+	"autumnomous-jobs-employer-api/shared/services/scan"
 	"autumnomous-jobs-employer-api/shared/services/security/encryption"
+	"autumnomous-jobs-employer-api/shared/services/security/jwt"
+	"autumnomous-jobs-employer-api/shared/services/webhook"
+	"autumnomous-jobs-employer-api/shared/services/zipcode"
 
-	"github.com/joho/godotenv"
+	"github.com/google/uuid"
 )
 
 type TestEmployer struct {
@@ -75,23 +96,152 @@ type TestCompany struct {
 	ID           string `json:"id"`
 }
 
+// Config is the test profile configuration loaded by Init
+var Config *config.Config
+
+// DB is the test database pool opened by Init
+var DB *sql.DB
+
 func Init() {
-	os.Clearenv()
 
-	err := godotenv.Load("test.env")
+	cfg, err := config.LoadProfile(config.Test, "test.env")
+
+	if err != nil {
+		log.Println(err)
+		log.Fatal("Error loading test configuration:", err)
+	}
+
+	Config = cfg
+
+	jwt.SetSigningKey(cfg.SigningKey)
+
+	DB, err = database.Connect(cfg.DatabaseURL)
 
 	if err != nil {
 		log.Println(err)
-		log.Fatal("Error loading test.env file:", err)
+		return
 	}
 
-	database.Connect("DATABASE_URL")
+	if err := database.Migrate(DB); err != nil {
+		log.Println(err)
+	}
 
 }
 
+// NewApp returns an App wired to the test database with mailer in place of
+// Mailgun. Override any other field with a fake before building handlers.
+func NewApp(mailer email.Mailer) *app.App {
+	return &app.App{
+		Config: Config,
+		DB:     DB,
+
+		Employers:   accountmanagement.NewEmployerRepository(DB),
+		Companies:   companies.NewCompanyRepository(DB),
+		Jobs:        jobs.NewJobRepository(DB),
+		JobPackages: jobpackages.NewJobPackageRepository(DB),
+		Queue:       queue.NewQueueRepository(DB),
+		Webhooks:    webhooks.NewWebhookRepository(DB),
+		Audit:       audit.NewAuditRepository(DB),
+		Uploads:     uploads.NewUploadRepository(DB),
+		Attachments: attachments.NewAttachmentRepository(DB),
+		Geocodes:    geocodes.NewGeocodeRepository(DB),
+
+		UnitOfWork: transaction.NewUnitOfWork(DB),
+
+		Mailer:   mailer,
+		Scanner:  scan.Noop{},
+		Geocoder: zipcode.NewZipCodeGateway(Config.ZipCodeServices.APIKey),
+
+		WebhookClient: webhook.NewClient(true),
+	}
+}
+
+// NewMemoryApp returns an App over in-memory repositories, for handler tests
+// that should not need a database. The Store is returned for seeding.
+func NewMemoryApp(mailer email.Mailer) (*app.App, *memory.Store) {
+
+	store := memory.NewStore()
+
+	return &app.App{
+		Config: config.Defaults(config.Test),
+
+		Employers:   store.EmployerRepository(),
+		Companies:   store.CompanyRepository(),
+		Jobs:        store.JobRepository(),
+		JobPackages: store.JobPackageRepository(),
+		Queue:       store.QueueRepository(),
+		Webhooks:    store.WebhookRepository(),
+		Audit:       store.AuditRepository(),
+		Uploads:     store.UploadRepository(),
+		Attachments: store.AttachmentRepository(),
+		Geocodes:    store.GeocodeRepository(),
+
+		UnitOfWork: store.UnitOfWork(),
+
+		Mailer:  mailer,
+		Scanner: scan.Noop{},
+
+		WebhookClient: webhook.NewClient(true),
+	}, store
+}
+
+// Repositories returns the Postgres repositories over the test database, for
+// running the repositorytest contract suites
+func Repositories(t *testing.T) *repositorytest.Repositories {
+	return &repositorytest.Repositories{
+		Employers:   accountmanagement.NewEmployerRepository(DB),
+		Companies:   companies.NewCompanyRepository(DB),
+		Jobs:        jobs.NewJobRepository(DB),
+		JobPackages: jobpackages.NewJobPackageRepository(DB),
+		Queue:       queue.NewQueueRepository(DB),
+		Webhooks:    webhooks.NewWebhookRepository(DB),
+		Audit:       audit.NewAuditRepository(DB),
+		Uploads:     uploads.NewUploadRepository(DB),
+		Attachments: attachments.NewAttachmentRepository(DB),
+		Geocodes:    geocodes.NewGeocodeRepository(DB),
+		UnitOfWork:  transaction.NewUnitOfWork(DB),
+		AddJobPackage: func(t *testing.T, pack *jobpackages.JobPackage) *jobpackages.JobPackage {
+
+			created := Helper_CreateJobPackage(&TestJobPackage{
+				TypeID:       pack.TypeID,
+				IsActive:     pack.IsActive,
+				Title:        pack.Title,
+				NumberOfJobs: pack.NumberOfJobs,
+				Description:  pack.Description,
+				Price:        pack.Price,
+			}, t)
+
+			if created == nil {
+				t.Fatal("could not create job package")
+			}
+
+			result := *pack
+			result.ID = created.ID
+			return &result
+		},
+	}
+}
+
+// Mailer records messages instead of sending them
+type Mailer struct {
+	Messages []*email.Message
+	Err      error
+}
+
+func (mailer *Mailer) Send(ctx context.Context, message *email.Message) (string, error) {
+
+	if mailer.Err != nil {
+		return "", mailer.Err
+	}
+
+	mailer.Messages = append(mailer.Messages, message)
+
+	return fmt.Sprintf("test-%d", len(mailer.Messages)), nil
+}
+
 func Helper_CreateEmployer(employer *TestEmployer, t *testing.T) *TestEmployer {
 
-	stmt, err := database.DB.Prepare(`INSERT INTO employers (firstname, lastname, email, password) VALUES ($1, $2, $3, $4) RETURNING publicid;`)
+	stmt, err := DB.Prepare(`INSERT INTO employers (firstname, lastname, email, password) VALUES ($1, $2, $3, $4) RETURNING publicid;`)
 
 	if err != nil {
 		log.Println(err)
@@ -108,10 +258,16 @@ func Helper_CreateEmployer(employer *TestEmployer, t *testing.T) *TestEmployer {
 	return employer
 }
 
+// Helper_RandomEmail is a unique address that passes request validation,
+// which GeneratePassword's special characters would not
+func Helper_RandomEmail(prefix string) string {
+	return fmt.Sprintf("%s-%s@site.com", prefix, uuid.New().String())
+}
+
 func Helper_RandomEmployer(t *testing.T) *TestEmployer {
 	employer := &TestEmployer{FirstName: string(encryption.GeneratePassword(5)),
 		LastName: string(encryption.GeneratePassword(5)),
-		Email:    fmt.Sprintf("email-%s@site.com", string(encryption.GeneratePassword(9))),
+		Email:    Helper_RandomEmail("email"),
 		Password: string(encryption.GeneratePassword(9))}
 
 	hashedPassword, err := encryption.HashPassword([]byte(employer.Password))
@@ -127,7 +283,7 @@ func Helper_RandomEmployer(t *testing.T) *TestEmployer {
 
 func Helper_GetEmployer(publicID string, t *testing.T) *TestEmployer {
 
-	stmt, err := database.DB.Prepare(`SELECT 
+	stmt, err := DB.Prepare(`SELECT 
 			email, firstname, lastname, (SELECT publicid FROM companies WHERE id=companyid)
 		FROM employers 
 		WHERE publicid=$1;`)
@@ -148,7 +304,7 @@ func Helper_GetEmployer(publicID string, t *testing.T) *TestEmployer {
 }
 
 func Helper_CreateJob(job *TestJob, t *testing.T) *TestJob {
-	stmt, err := database.DB.Prepare(`INSERT INTO 
+	stmt, err := DB.Prepare(`INSERT INTO 
 											jobs(title, jobtype, category, description, poststartdatetime, postenddatetime,remote, employerid) 
 											VALUES ($1, $2, $3, $4, $5, $6, $7, (SELECT id FROM employers WHERE publicid=$8)) 
 											RETURNING publicid;`)
@@ -185,7 +341,7 @@ func Helper_RandomJob(employer *TestEmployer, t *testing.T) *TestJob {
 
 func Helper_CreateJobPackage(pack *TestJobPackage, t *testing.T) *TestJobPackage {
 
-	stmt, err := database.DB.Prepare(`INSERT INTO 
+	stmt, err := DB.Prepare(`INSERT INTO 
 											jobpackages(typeid, isactive, title, numberofjobs, description, price) 
 											VALUES ($1, $2, $3, $4, $5, $6) 
 											RETURNING id;`)
@@ -220,7 +376,7 @@ func Helper_RandomJobPackage(t *testing.T) *TestJobPackage {
 
 func Helper_CreateCompany(company *TestCompany, t *testing.T) *TestCompany {
 
-	stmt, err := database.DB.Prepare(`INSERT INTO 
+	stmt, err := DB.Prepare(`INSERT INTO 
 			companies(domain, name, location, url, facebook, twitter, instagram, description, logo, extradetails) 
 			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
 			RETURNING publicid;`)
@@ -260,7 +416,7 @@ func Helper_SetEmployerCompany(employerPublicID, companyPublicID string) error {
 		return errors.New("missing required value")
 	}
 
-	stmt, err := database.DB.Prepare(`UPDATE employers SET companyid=(SELECT id FROM companies WHERE publicid=$1) WHERE publicid=$2;`)
+	stmt, err := DB.Prepare(`UPDATE employers SET companyid=(SELECT id FROM companies WHERE publicid=$1) WHERE publicid=$2;`)
 
 	if err != nil {
 		return err
@@ -278,7 +434,7 @@ func Helper_SetEmployerCompany(employerPublicID, companyPublicID string) error {
 
 // func Helper_ChangeRegistrationStep(step string, Applicant *TestUser, t *testing.T) error {
 
-// 	stmt, err := database.DB.Prepare(`UPDATE applications SET registrationstep=$1 WHERE Applicantid=(SELECT id FROM Applicants WHERE publicid=$2);`)
+// 	stmt, err := DB.Prepare(`UPDATE applications SET registrationstep=$1 WHERE Applicantid=(SELECT id FROM Applicants WHERE publicid=$2);`)
 
 // 	if err != nil {
 // 		t.Fatal()